@@ -11,6 +11,7 @@ import (
 	http_server "github.com/rusystem/crm-api/internal/server/http"
 	"github.com/rusystem/crm-api/internal/service"
 	http_handler "github.com/rusystem/crm-api/internal/transport/http"
+	"github.com/rusystem/crm-api/internal/worker"
 	"github.com/rusystem/crm-api/pkg/auth"
 	"github.com/rusystem/crm-api/pkg/client/geonames"
 	"github.com/rusystem/crm-api/pkg/database"
@@ -100,12 +101,22 @@ func main() {
 
 	logger.Info("server started")
 
+	// Background workers
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	go worker.Periodic(workersCtx, "reservations expiration", cfg.Workers.ReservationsExpiration, srv.Reservations.ReleaseExpired)
+	go worker.Periodic(workersCtx, "low stock check", cfg.Workers.LowStockCheck, srv.Notifications.CheckLowStock)
+	go worker.Periodic(workersCtx, "expired write-off", cfg.Workers.ExpiredWriteOff, srv.Materials.WriteOffExpired)
+
 	// Graceful Shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
 	<-quit
 
+	stopWorkers()
+
 	const timeout = 5 * time.Second
 
 	ctx, shutdown := context.WithTimeout(context.Background(), timeout)
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"github.com/rusystem/cache"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/internal/service"
+	"github.com/rusystem/crm-api/pkg/database"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"sort"
+)
+
+// init logger
+func init() {
+	logger.ZapLoggerInit()
+}
+
+// Разовый пересчет общей стоимости без НДС у материалов, где она не равна цене, умноженной на количество.
+// Запуск: go run ./cmd/recalc [-dry-run]
+func main() {
+	dryRun := flag.Bool("dry-run", false, "только подсчитать строки с неверной стоимостью, не изменяя их")
+	flag.Parse()
+
+	// init configs
+	cfg, err := config.New(false)
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("failed to initialize config, err: %v", err))
+	}
+
+	// init postgres connection
+	pc, err := database.NewPostgresConnection(database.PostgresConfig{
+		Host:     cfg.Postgres.Host,
+		Port:     cfg.Postgres.Port,
+		Username: cfg.Postgres.User,
+		Password: cfg.Postgres.Password,
+		DBName:   cfg.Postgres.DBName,
+		SSLMode:  cfg.Postgres.SSLMode,
+	})
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("failed to connect to postgres, err: %v", err))
+	}
+	defer func(pc *sql.DB) {
+		if err = pc.Close(); err != nil {
+			logger.Error(fmt.Sprintf("postgres: failed to close connection, err: %v", err.Error()))
+		}
+	}(pc)
+
+	repo := repository.New(cfg, cache.New(), pc)
+	materials := service.NewMaterialsService(cfg, repo)
+
+	result, err := materials.RecalculateTotals(context.Background(), *dryRun)
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("failed to recalculate material totals, err: %v", err))
+	}
+
+	tables := make([]string, 0, len(result))
+	for table := range result {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	action := "recalculated"
+	if *dryRun {
+		action = "need recalculation"
+	}
+
+	for _, table := range tables {
+		logger.Info(fmt.Sprintf("%s: %d rows %s", table, result[table], action))
+	}
+}
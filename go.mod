@@ -12,6 +12,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/rusystem/cache v1.0.4
 	github.com/segmentio/ksuid v1.0.4
+	github.com/shopspring/decimal v1.4.0
 	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/viper v1.19.0
 	github.com/swaggo/files v1.0.1
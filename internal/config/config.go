@@ -18,6 +18,7 @@ type Config struct {
 	Limiter    Limiter    `mapstructure:"limiter"`
 	Auth       Auth       `mapstructure:"auth"`
 	HttpClient HttpClient `mapstructure:"http_client"`
+	Workers    Workers    `mapstructure:"workers"`
 	IsProd     bool
 
 	Http struct {
@@ -59,6 +60,13 @@ type HttpClient struct {
 	Timeout time.Duration `mapstructure:"timeout"`
 }
 
+type Workers struct {
+	ReservationsExpiration time.Duration `mapstructure:"reservations_expiration"`
+	LowStockCheck          time.Duration `mapstructure:"low_stock_check"`
+	LowStockRepeat         time.Duration `mapstructure:"low_stock_repeat"`
+	ExpiredWriteOff        time.Duration `mapstructure:"expired_write_off"`
+}
+
 func New(isProd bool) (*Config, error) {
 	cfg := new(Config)
 
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type Audit interface {
+	Create(ctx context.Context, log domain.AuditLog) error
+	GetList(ctx context.Context, params domain.AuditParams) ([]domain.AuditLog, int64, error)
+}
+
+type AuditRepository struct {
+	cfg  *config.Config
+	psql database.Audit
+}
+
+func NewAuditRepository(cfg *config.Config, psql *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		cfg:  cfg,
+		psql: database.NewAuditPostgresRepository(psql),
+	}
+}
+
+func (ar *AuditRepository) Create(ctx context.Context, log domain.AuditLog) error {
+	return ar.psql.Create(ctx, log)
+}
+
+func (ar *AuditRepository) GetList(ctx context.Context, params domain.AuditParams) ([]domain.AuditLog, int64, error) {
+	return ar.psql.GetList(ctx, params)
+}
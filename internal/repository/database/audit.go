@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"strings"
+	"time"
+)
+
+type Audit interface {
+	Create(ctx context.Context, log domain.AuditLog) error
+	GetList(ctx context.Context, params domain.AuditParams) ([]domain.AuditLog, int64, error)
+}
+
+type AuditPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewAuditPostgresRepository(psql *sql.DB) *AuditPostgresRepository {
+	return &AuditPostgresRepository{
+		psql: psql,
+	}
+}
+
+func (ar *AuditPostgresRepository) Create(ctx context.Context, log domain.AuditLog) error {
+	changesJSON, err := json.Marshal(log.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit changes to JSON: %v", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (user_id, company_id, entity_type, entity_id, action, changes, created_at)
+		VALUES (NULLIF($1, 0), NULLIF($2, 0), $3, $4, $5, $6, $7)`,
+		domain.TableAuditLog)
+
+	if _, err = ar.psql.ExecContext(ctx, query,
+		log.UserID, log.CompanyID, log.EntityType, log.EntityID, log.Action, changesJSON, time.Now().UTC(),
+	); err != nil {
+		return fmt.Errorf("failed to insert audit log: %v", err)
+	}
+
+	return nil
+}
+
+func (ar *AuditPostgresRepository) GetList(ctx context.Context, params domain.AuditParams) ([]domain.AuditLog, int64, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if params.CompanyId != 0 {
+		addCondition("company_id = $%d", params.CompanyId)
+	}
+
+	if params.UserId != 0 {
+		addCondition("user_id = $%d", params.UserId)
+	}
+
+	if params.EntityType != "" {
+		addCondition("entity_type = $%d", params.EntityType)
+	}
+
+	if params.EntityId != 0 {
+		addCondition("entity_id = $%d", params.EntityId)
+	}
+
+	if !params.From.IsZero() {
+		addCondition("created_at >= $%d", params.From)
+	}
+
+	if !params.To.IsZero() {
+		addCondition("created_at <= $%d", params.To)
+	}
+
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int64
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", domain.TableAuditLog, where)
+
+	if err := ar.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, COALESCE(user_id, 0), COALESCE(company_id, 0), entity_type, entity_id, action, changes, created_at
+	FROM %s WHERE %s ORDER BY %s %s LIMIT $%d OFFSET $%d
+	`, domain.TableAuditLog, where, params.SortField, params.Sort, len(args)+1, len(args)+2)
+
+	rows, err := ar.psql.QueryContext(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var logs []domain.AuditLog
+
+	for rows.Next() {
+		var log domain.AuditLog
+		var changesJSON []byte
+
+		if err = rows.Scan(
+			&log.ID, &log.UserID, &log.CompanyID, &log.EntityType, &log.EntityID, &log.Action, &changesJSON,
+			&log.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if err = json.Unmarshal(changesJSON, &log.Changes); err != nil {
+			return nil, 0, err
+		}
+
+		logs = append(logs, log)
+	}
+
+	return logs, totalCount, rows.Err()
+}
@@ -28,7 +28,7 @@ func NewCompanyDatabase(db *sql.DB) *CompanyDatabaseRepository {
 }
 
 func (cdr *CompanyDatabaseRepository) GetById(ctx context.Context, id int64) (domain.Company, error) {
-	query := fmt.Sprintf(`SELECT id, name_ru, name_en, country, address, phone, email, website, is_active, created_at, updated_at, is_approved, timezone FROM %s WHERE id = $1`,
+	query := fmt.Sprintf(`SELECT id, name_ru, name_en, country, address, phone, email, website, is_active, created_at, updated_at, is_approved, timezone, allow_over_capacity, default_currency FROM %s WHERE id = $1`,
 		domain.CompaniesTable)
 
 	var company domain.Company
@@ -46,6 +46,8 @@ func (cdr *CompanyDatabaseRepository) GetById(ctx context.Context, id int64) (do
 		&company.UpdatedAt,
 		&company.IsApproved,
 		&company.Timezone,
+		&company.AllowOverCapacity,
+		&company.DefaultCurrency,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -77,14 +79,15 @@ func (cdr *CompanyDatabaseRepository) Create(ctx context.Context, company domain
 	var id int64
 	query := fmt.Sprintf(`
 		INSERT INTO %s
-		(name_ru, name_en, country, address, phone, email, website, is_active, created_at, updated_at, is_approved, timezone)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		(name_ru, name_en, country, address, phone, email, website, is_active, created_at, updated_at, is_approved, timezone, allow_over_capacity, default_currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id;
 	`, domain.CompaniesTable)
 
 	err := cdr.db.QueryRowContext(ctx, query,
 		company.NameRu, company.NameEn, company.Country, company.Address, company.Phone, company.Email,
 		company.Website, company.IsActive, company.CreatedAt, company.UpdatedAt, company.IsApproved, company.Timezone,
+		company.AllowOverCapacity, company.DefaultCurrency,
 	).Scan(&id)
 	if err != nil {
 		return 0, err
@@ -98,14 +101,15 @@ func (cdr *CompanyDatabaseRepository) Update(ctx context.Context, company domain
 		UPDATE %s
 		SET
 		    name_ru = $1, name_en = $2, country = $3, address = $4, phone = $5, email = $6,
-		    website = $7, is_active = $8, updated_at = $9, is_approved = $10, timezone = $11
-		WHERE id = $12;
+		    website = $7, is_active = $8, updated_at = $9, is_approved = $10, timezone = $11,
+		    allow_over_capacity = $12, default_currency = $13
+		WHERE id = $14;
 	`, domain.CompaniesTable)
 
 	_, err := cdr.db.ExecContext(ctx, query,
 		company.NameRu, company.NameEn, company.Country, company.Address, company.Phone, company.Email,
 		company.Website, company.IsActive, company.UpdatedAt, company.IsApproved, company.Timezone,
-		company.ID,
+		company.AllowOverCapacity, company.DefaultCurrency, company.ID,
 	)
 	if err != nil {
 		return err
@@ -138,7 +142,7 @@ func (cdr *CompanyDatabaseRepository) List(ctx context.Context, param domain.Par
 	query := fmt.Sprintf(`
 		SELECT 
 		    id, name_ru, name_en, country, address, phone, email, website, 
-		    is_active, created_at, updated_at, is_approved, timezone 
+		    is_active, created_at, updated_at, is_approved, timezone, allow_over_capacity, default_currency
 		FROM %s ORDER BY %s %s
 		LIMIT $1 OFFSET $2;
 	`, domain.CompaniesTable, param.SortField, param.Sort)
@@ -159,6 +163,7 @@ func (cdr *CompanyDatabaseRepository) List(ctx context.Context, param domain.Par
 		if err := rows.Scan(
 			&company.ID, &company.NameRu, &company.NameEn, &company.Country, &company.Address, &company.Phone, &company.Email,
 			&company.Website, &company.IsActive, &company.CreatedAt, &company.UpdatedAt, &company.IsApproved, &company.Timezone,
+			&company.AllowOverCapacity, &company.DefaultCurrency,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"strings"
+	"time"
+)
+
+type ExchangeRates interface {
+	Create(ctx context.Context, rate domain.ExchangeRate) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.ExchangeRate, error)
+	GetList(ctx context.Context, params domain.ExchangeRateParams) ([]domain.ExchangeRate, int64, error)
+	Update(ctx context.Context, rate domain.ExchangeRate) error
+	Delete(ctx context.Context, id int64) error
+	GetActual(ctx context.Context, companyId int64, from, to string, date time.Time) (domain.ExchangeRate, error)
+}
+
+type ExchangeRatesPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewExchangeRatesPostgresRepository(psql *sql.DB) *ExchangeRatesPostgresRepository {
+	return &ExchangeRatesPostgresRepository{
+		psql: psql,
+	}
+}
+
+// exchangeRateColumns колонки курса валюты. Порядок совпадает с scanExchangeRate
+const exchangeRateColumns = `id, company_id, from_currency, to_currency, rate, rate_date, COALESCE(created_by, 0),
+	created_at, updated_at`
+
+func (er *ExchangeRatesPostgresRepository) Create(ctx context.Context, rate domain.ExchangeRate) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (company_id, from_currency, to_currency, rate, rate_date, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, $8) RETURNING id`,
+		domain.TableExchangeRates)
+
+	var id int64
+	if err := er.psql.QueryRowContext(ctx, query,
+		rate.CompanyID, rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.RateDate, rate.CreatedBy, rate.CreatedAt,
+		rate.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, domain.ErrExchangeRateAlreadyExists
+		}
+
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (er *ExchangeRatesPostgresRepository) GetById(ctx context.Context, id int64) (domain.ExchangeRate, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", exchangeRateColumns, domain.TableExchangeRates)
+
+	var rate domain.ExchangeRate
+	if err := scanExchangeRate(er.psql.QueryRowContext(ctx, query, id), &rate); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ExchangeRate{}, domain.ErrExchangeRateNotFound
+		}
+
+		return domain.ExchangeRate{}, err
+	}
+
+	return rate, nil
+}
+
+func (er *ExchangeRatesPostgresRepository) GetList(ctx context.Context, params domain.ExchangeRateParams) ([]domain.ExchangeRate, int64, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	addCondition("company_id = $%d", params.CompanyId)
+
+	if params.FromCurrency != "" {
+		addCondition("from_currency = $%d", params.FromCurrency)
+	}
+
+	if params.ToCurrency != "" {
+		addCondition("to_currency = $%d", params.ToCurrency)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var totalCount int64
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", domain.TableExchangeRates, where)
+
+	if err := er.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s %s LIMIT $%d OFFSET $%d",
+		exchangeRateColumns, domain.TableExchangeRates, where, params.SortField, params.Sort, len(args)+1, len(args)+2)
+
+	rows, err := er.psql.QueryContext(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var rates []domain.ExchangeRate
+
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		if err = scanExchangeRate(rows, &rate); err != nil {
+			return nil, 0, err
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, totalCount, rows.Err()
+}
+
+func (er *ExchangeRatesPostgresRepository) Update(ctx context.Context, rate domain.ExchangeRate) error {
+	query := fmt.Sprintf("UPDATE %s SET rate = $1, rate_date = $2, updated_at = $3 WHERE id = $4",
+		domain.TableExchangeRates)
+
+	if _, err := er.psql.ExecContext(ctx, query, rate.Rate, rate.RateDate, rate.UpdatedAt, rate.ID); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrExchangeRateAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (er *ExchangeRatesPostgresRepository) Delete(ctx context.Context, id int64) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", domain.TableExchangeRates)
+
+	_, err := er.psql.ExecContext(ctx, query, id)
+	return err
+}
+
+// GetActual возвращает курс from -> to компании, действующий на дату date: последний курс с датой не позже date
+func (er *ExchangeRatesPostgresRepository) GetActual(ctx context.Context, companyId int64, from, to string, date time.Time) (domain.ExchangeRate, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE company_id = $1 AND from_currency = $2 AND to_currency = $3 AND rate_date <= $4
+		ORDER BY rate_date DESC LIMIT 1`,
+		exchangeRateColumns, domain.TableExchangeRates)
+
+	var rate domain.ExchangeRate
+	if err := scanExchangeRate(er.psql.QueryRowContext(ctx, query, companyId, from, to, date), &rate); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ExchangeRate{}, domain.ErrExchangeRateNotFound
+		}
+
+		return domain.ExchangeRate{}, err
+	}
+
+	return rate, nil
+}
+
+func scanExchangeRate(row scanner, r *domain.ExchangeRate) error {
+	return row.Scan(
+		&r.ID, &r.CompanyID, &r.FromCurrency, &r.ToCurrency, &r.Rate, &r.RateDate, &r.CreatedBy, &r.CreatedAt,
+		&r.UpdatedAt,
+	)
+}
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"sort"
+)
+
+type GoodsIssues interface {
+	Create(ctx context.Context, issue domain.GoodsIssue) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.GoodsIssue, error)
+	GetListByWarehouseId(ctx context.Context, warehouseId int64, params domain.Param) ([]domain.GoodsIssue, int64, error)
+	Post(ctx context.Context, issue domain.GoodsIssue) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type GoodsIssuesPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewGoodsIssuesPostgresRepository(psql *sql.DB) *GoodsIssuesPostgresRepository {
+	return &GoodsIssuesPostgresRepository{
+		psql: psql,
+	}
+}
+
+// goodsIssueColumns колонки документа отпуска. Порядок совпадает с scanGoodsIssue
+const goodsIssueColumns = `id, warehouse_id, company_id, COALESCE(responsible_user_id, 0), status, comment,
+	COALESCE(created_by, 0), COALESCE(posted_by, 0), created_at, updated_at, posted_at`
+
+func (gr *GoodsIssuesPostgresRepository) Create(ctx context.Context, issue domain.GoodsIssue) (int64, error) {
+	tx, err := gr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (warehouse_id, company_id, responsible_user_id, status, comment, created_by, created_at,
+			updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, NULLIF($6, 0), $7, $8) RETURNING id`,
+		domain.TableGoodsIssues)
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, query,
+		issue.WarehouseID, issue.CompanyID, issue.ResponsibleUserID, issue.Status, issue.Comment, issue.CreatedBy,
+		issue.CreatedAt, issue.UpdatedAt,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert goods issue: %v", err)
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s (goods_issue_id, purchased_material_id, item_id, name, article, unit, quantity, comment)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8)`,
+		domain.TableGoodsIssueLines)
+
+	for _, l := range issue.Lines {
+		if _, err = tx.ExecContext(ctx, query,
+			id, l.PurchasedMaterialID, l.ItemID, l.Name, l.Article, l.Unit, l.Quantity, l.Comment,
+		); err != nil {
+			return 0, fmt.Errorf("failed to insert goods issue line: %v", err)
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// GetById возвращает документ отпуска вместе со строками
+func (gr *GoodsIssuesPostgresRepository) GetById(ctx context.Context, id int64) (domain.GoodsIssue, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", goodsIssueColumns, domain.TableGoodsIssues)
+
+	var issue domain.GoodsIssue
+	if err := scanGoodsIssue(gr.psql.QueryRowContext(ctx, query, id), &issue); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.GoodsIssue{}, domain.ErrGoodsIssueNotFound
+		}
+
+		return domain.GoodsIssue{}, err
+	}
+
+	lines, err := goodsIssueLines(ctx, gr.psql, id)
+	if err != nil {
+		return domain.GoodsIssue{}, err
+	}
+
+	issue.Lines = lines
+
+	return issue, nil
+}
+
+// GetListByWarehouseId возвращает документы отпуска склада без строк
+func (gr *GoodsIssuesPostgresRepository) GetListByWarehouseId(ctx context.Context, warehouseId int64, params domain.Param) ([]domain.GoodsIssue, int64, error) {
+	var totalCount int64
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE warehouse_id = $1 AND company_id = $2",
+		domain.TableGoodsIssues)
+
+	if err := gr.psql.QueryRowContext(ctx, countQuery, warehouseId, params.CompanyId).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s WHERE warehouse_id = $1 AND company_id = $2 ORDER BY %s %s LIMIT $3 OFFSET $4`,
+		goodsIssueColumns, domain.TableGoodsIssues, params.SortField, params.Sort)
+
+	rows, err := gr.psql.QueryContext(ctx, query, warehouseId, params.CompanyId, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var issues []domain.GoodsIssue
+
+	for rows.Next() {
+		var issue domain.GoodsIssue
+		if err = scanGoodsIssue(rows, &issue); err != nil {
+			return nil, 0, err
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues, totalCount, rows.Err()
+}
+
+// Post проводит документ отпуска: списывает количество каждой строки с остатка закупленного материала одной
+// транзакцией. Если остатка или незарезервированного количества по одной из строк не хватает, документ не
+// проводится целиком
+func (gr *GoodsIssuesPostgresRepository) Post(ctx context.Context, issue domain.GoodsIssue) error {
+	tx, err := gr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	var status string
+	if err = tx.QueryRowContext(ctx, fmt.Sprintf("SELECT status FROM %s WHERE id = $1 FOR UPDATE",
+		domain.TableGoodsIssues), issue.ID).Scan(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrGoodsIssueNotFound
+		}
+
+		return err
+	}
+
+	if status != domain.GoodsIssueDraft {
+		return domain.ErrGoodsIssueNotDraft
+	}
+
+	lines, err := goodsIssueLines(ctx, tx, issue.ID)
+	if err != nil {
+		return err
+	}
+
+	// строки материалов блокируются в порядке id, чтобы документы с теми же материалами в другом порядке
+	// не блокировали друг друга
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].PurchasedMaterialID < lines[j].PurchasedMaterialID
+	})
+
+	for _, l := range lines {
+		if _, err = applyStockMovement(ctx, tx, domain.StockMovement{
+			PurchasedMaterialID: l.PurchasedMaterialID,
+			MovementType:        domain.MovementIssue,
+			Quantity:            -l.Quantity,
+			Reference:           fmt.Sprintf("Требование-накладная №%d", issue.ID),
+			Comment:             l.Comment,
+			UserID:              issue.PostedBy,
+		}); err != nil {
+			return fmt.Errorf("goods issue line %d: %w", l.ID, err)
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET status = $1, posted_by = NULLIF($2, 0), posted_at = $3, updated_at = $4 WHERE id = $5",
+		domain.TableGoodsIssues)
+
+	if _, err = tx.ExecContext(ctx, query,
+		domain.GoodsIssuePosted, issue.PostedBy, issue.PostedAt, issue.UpdatedAt, issue.ID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete удаляет непроведенный документ отпуска
+func (gr *GoodsIssuesPostgresRepository) Delete(ctx context.Context, id int64) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND status = $2", domain.TableGoodsIssues)
+
+	res, err := gr.psql.ExecContext(ctx, query, id, domain.GoodsIssueDraft)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrGoodsIssueNotDraft
+	}
+
+	return nil
+}
+
+func goodsIssueLines(ctx context.Context, q querier, id int64) ([]domain.GoodsIssueLine, error) {
+	query := fmt.Sprintf(`
+		SELECT id, goods_issue_id, purchased_material_id, COALESCE(item_id, 0), name, article, unit, quantity, comment
+		FROM %s WHERE goods_issue_id = $1 ORDER BY id`,
+		domain.TableGoodsIssueLines)
+
+	rows, err := q.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var lines []domain.GoodsIssueLine
+
+	for rows.Next() {
+		var l domain.GoodsIssueLine
+		if err = rows.Scan(
+			&l.ID, &l.GoodsIssueID, &l.PurchasedMaterialID, &l.ItemID, &l.Name, &l.Article, &l.Unit, &l.Quantity,
+			&l.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines, rows.Err()
+}
+
+func scanGoodsIssue(row scanner, g *domain.GoodsIssue) error {
+	return row.Scan(
+		&g.ID, &g.WarehouseID, &g.CompanyID, &g.ResponsibleUserID, &g.Status, &g.Comment, &g.CreatedBy, &g.PostedBy,
+		&g.CreatedAt, &g.UpdatedAt, &g.PostedAt,
+	)
+}
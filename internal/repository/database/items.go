@@ -0,0 +1,243 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"strings"
+	"time"
+)
+
+type Items interface {
+	Create(ctx context.Context, item domain.Item) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Item, error)
+	Update(ctx context.Context, item domain.Item) error
+	Delete(ctx context.Context, id int64) error
+	GetList(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error)
+	Search(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error)
+}
+
+type ItemsPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewItemsPostgresRepository(psql *sql.DB) *ItemsPostgresRepository {
+	return &ItemsPostgresRepository{
+		psql: psql,
+	}
+}
+
+// itemColumns колонки товара каталога. Порядок совпадает с scanItem
+const itemColumns = `id, company_id, name, article, COALESCE(default_unit_id, 0), COALESCE(category_id, 0), barcode,
+	COALESCE(default_supplier_id, 0), description, is_active, created_at, updated_at`
+
+func (ir *ItemsPostgresRepository) Create(ctx context.Context, item domain.Item) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (company_id, name, article, default_unit_id, category_id, barcode, default_supplier_id,
+			description, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, 0), $6, NULLIF($7, 0), $8, $9, $10, $11) RETURNING id`,
+		domain.TableItems)
+
+	var id int64
+	if err := ir.psql.QueryRowContext(ctx, query,
+		item.CompanyID, item.Name, item.Article, item.DefaultUnitID, item.CategoryID, item.Barcode,
+		item.DefaultSupplierID, item.Description, item.IsActive, item.CreatedAt, item.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, domain.ErrItemAlreadyExists
+		}
+
+		return 0, fmt.Errorf("failed to insert item: %v", err)
+	}
+
+	return id, nil
+}
+
+func (ir *ItemsPostgresRepository) GetById(ctx context.Context, id int64) (domain.Item, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", itemColumns, domain.TableItems)
+
+	var item domain.Item
+	if err := scanItem(ir.psql.QueryRowContext(ctx, query, id), &item); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Item{}, domain.ErrItemNotFound
+		}
+
+		return domain.Item{}, err
+	}
+
+	return item, nil
+}
+
+func (ir *ItemsPostgresRepository) Update(ctx context.Context, item domain.Item) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET name = $1, article = $2, default_unit_id = NULLIF($3, 0), category_id = NULLIF($4, 0), barcode = $5,
+			default_supplier_id = NULLIF($6, 0), description = $7, is_active = $8, updated_at = $9
+		WHERE id = $10`,
+		domain.TableItems)
+
+	if _, err := ir.psql.ExecContext(ctx, query,
+		item.Name, item.Article, item.DefaultUnitID, item.CategoryID, item.Barcode, item.DefaultSupplierID,
+		item.Description, item.IsActive, item.UpdatedAt, item.ID,
+	); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrItemAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет товар из каталога, если на него не ссылается ни один материал, в том числе архивный
+func (ir *ItemsPostgresRepository) Delete(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s i
+		WHERE i.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM %s WHERE item_id = i.id)
+		  AND NOT EXISTS (SELECT 1 FROM %s WHERE item_id = i.id)
+		  AND NOT EXISTS (SELECT 1 FROM %s WHERE item_id = i.id)
+		  AND NOT EXISTS (SELECT 1 FROM %s WHERE item_id = i.id)`,
+		domain.TableItems, domain.TablePlanningMaterials, domain.TablePurchasedMaterials,
+		domain.TablePlanningMaterialsArchive, domain.TablePurchasedMaterialsArchive)
+
+	res, err := ir.psql.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrItemInUse
+	}
+
+	return nil
+}
+
+func (ir *ItemsPostgresRepository) GetList(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error) {
+	return ir.list(ctx, "company_id = $1", []any{companyId}, params)
+}
+
+// Search ищет товары компании по вхождению строки в наименование, артикул или штрихкод
+func (ir *ItemsPostgresRepository) Search(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error) {
+	pattern := "%" + escapeLike(params.Query) + "%"
+
+	return ir.list(ctx, "company_id = $1 AND (name ILIKE $2 OR article ILIKE $2 OR barcode ILIKE $2)",
+		[]any{companyId, pattern}, params)
+}
+
+func (ir *ItemsPostgresRepository) list(ctx context.Context, where string, args []any, params domain.Param) ([]domain.Item, int64, error) {
+	var totalCount int64
+
+	// при переходе по курсору общее количество не подсчитывается
+	if params.Cursor == nil {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", domain.TableItems, where)
+
+		if err := ir.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	where, args, offset, err := applyCursor(where, args, params.Cursor, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d",
+		itemColumns, domain.TableItems, where, listOrder(params.SortField, params.Sort), len(args)+1, len(args)+2)
+
+	rows, err := ir.psql.QueryContext(ctx, query, append(args, params.Limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var items []domain.Item
+
+	for rows.Next() {
+		var item domain.Item
+		if err = scanItem(rows, &item); err != nil {
+			return nil, 0, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, totalCount, rows.Err()
+}
+
+func scanItem(row scanner, item *domain.Item) error {
+	return row.Scan(
+		&item.ID, &item.CompanyID, &item.Name, &item.Article, &item.DefaultUnitID, &item.CategoryID, &item.Barcode,
+		&item.DefaultSupplierID, &item.Description, &item.IsActive, &item.CreatedAt, &item.UpdatedAt,
+	)
+}
+
+// resolveItemId возвращает товар каталога для материала. Если item_id не указан, товар ищется по артикулу,
+// а при пустом артикуле - по наименованию, и создается, если не найден
+func resolveItemId(ctx context.Context, q querier, material domain.Material) (int64, error) {
+	if material.ItemID != 0 {
+		return material.ItemID, nil
+	}
+
+	name := strings.TrimSpace(material.Name)
+	article := strings.TrimSpace(material.Article)
+
+	now := time.Now().UTC()
+
+	insert := fmt.Sprintf(`
+		INSERT INTO %s (company_id, name, article, default_supplier_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $5)
+		ON CONFLICT DO NOTHING
+		RETURNING id`,
+		domain.TableItems)
+
+	var id int64
+	err := q.QueryRowContext(ctx, insert, material.CompanyID, name, article, material.SupplierID, now).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to insert item: %v", err)
+	}
+
+	// товар с таким артикулом или наименованием уже есть в каталоге
+	query := fmt.Sprintf("SELECT id FROM %s WHERE company_id = $1 AND LOWER(name) = LOWER($2) AND article = ''",
+		domain.TableItems)
+	args := []any{material.CompanyID, name}
+
+	if article != "" {
+		query = fmt.Sprintf("SELECT id FROM %s WHERE company_id = $1 AND LOWER(article) = LOWER($2) AND article <> ''",
+			domain.TableItems)
+		args = []any{material.CompanyID, article}
+	}
+
+	if err = q.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to find item: %v", err)
+	}
+
+	return id, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -15,6 +15,20 @@ type MaterialCategory interface {
 	Delete(ctx context.Context, id, companyId int64) error
 	List(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error)
 	Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error)
+	GetByName(ctx context.Context, name string, companyId int64) (domain.MaterialCategory, error)
+	GetAll(ctx context.Context, companyId int64) ([]domain.MaterialCategory, error)
+	Move(ctx context.Context, id, parentId, companyId int64) error
+}
+
+// materialCategoryColumns колонки категории в порядке, ожидаемом scanMaterialCategory
+const materialCategoryColumns = `id, name, company_id, COALESCE(parent_id, 0), description, slug, created_at, updated_at,
+	is_active, img_url`
+
+func scanMaterialCategory(row scanner, c *domain.MaterialCategory) error {
+	return row.Scan(
+		&c.ID, &c.Name, &c.CompanyID, &c.ParentID, &c.Description, &c.Slug, &c.CreatedAt, &c.UpdatedAt, &c.IsActive,
+		&c.ImgURL,
+	)
 }
 
 type MaterialCategoriesPostgresRepository struct {
@@ -29,13 +43,13 @@ func NewMaterialCategoriesPostgresRepository(psql *sql.DB) *MaterialCategoriesPo
 
 func (mc *MaterialCategoriesPostgresRepository) Create(ctx context.Context, c domain.MaterialCategory) (int64, error) {
 	query := fmt.Sprintf(`
-		INSERT INTO %s (name, company_id, description, slug, created_at, updated_at, is_active, img_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		INSERT INTO %s (name, company_id, parent_id, description, slug, created_at, updated_at, is_active, img_url)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9) RETURNING id`,
 		domain.TableMaterialCategories)
 
 	var id int64
 	if err := mc.psql.QueryRowContext(ctx, query,
-		c.Name, c.CompanyID, c.Description, c.Slug, c.CreatedAt, c.UpdatedAt, c.IsActive, c.ImgURL,
+		c.Name, c.CompanyID, c.ParentID, c.Description, c.Slug, c.CreatedAt, c.UpdatedAt, c.IsActive, c.ImgURL,
 	).Scan(&id); err != nil {
 		return 0, fmt.Errorf("failed to insert material category: %v", err)
 	}
@@ -45,15 +59,11 @@ func (mc *MaterialCategoriesPostgresRepository) Create(ctx context.Context, c do
 
 func (mc *MaterialCategoriesPostgresRepository) GetById(ctx context.Context, id, companyId int64) (domain.MaterialCategory, error) {
 	query := fmt.Sprintf(`
-		SELECT 
-		    id, name, company_id, description, slug, created_at, updated_at, is_active, img_url 
-		FROM %s WHERE id = $1 AND company_id = $2`,
-		domain.TableMaterialCategories)
+		SELECT %s FROM %s WHERE id = $1 AND company_id = $2`,
+		materialCategoryColumns, domain.TableMaterialCategories)
 
 	var c domain.MaterialCategory
-	if err := mc.psql.QueryRowContext(ctx, query, id, companyId).Scan(
-		&c.ID, &c.Name, &c.CompanyID, &c.Description, &c.Slug, &c.CreatedAt, &c.UpdatedAt, &c.IsActive, &c.ImgURL,
-	); err != nil {
+	if err := scanMaterialCategory(mc.psql.QueryRowContext(ctx, query, id, companyId), &c); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.MaterialCategory{}, domain.ErrMaterialCategoryNotFound
 		}
@@ -64,7 +74,28 @@ func (mc *MaterialCategoriesPostgresRepository) GetById(ctx context.Context, id,
 	return c, nil
 }
 
+// Update сохраняет категорию и переименовывает ее в кэше названий категорий связанных материалов
 func (mc *MaterialCategoriesPostgresRepository) Update(ctx context.Context, c domain.MaterialCategory) error {
+	tx, err := mc.psql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var oldName string
+	if err = tx.QueryRowContext(ctx, fmt.Sprintf("SELECT name FROM %s WHERE id = $1 AND company_id = $2 FOR UPDATE",
+		domain.TableMaterialCategories), c.ID, c.CompanyID).Scan(&oldName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrMaterialCategoryNotFound
+		}
+
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE %s
 		SET
@@ -72,40 +103,101 @@ func (mc *MaterialCategoriesPostgresRepository) Update(ctx context.Context, c do
 		WHERE id = $8 AND company_id = $9`,
 		domain.TableMaterialCategories)
 
-	_, err := mc.psql.ExecContext(ctx, query,
+	if _, err = tx.ExecContext(ctx, query,
 		c.Name, c.Description, c.Slug, c.CreatedAt, c.UpdatedAt, c.IsActive, c.ImgURL, c.ID, c.CompanyID,
-	)
+	); err != nil {
+		return err
+	}
 
-	return err
+	if oldName != c.Name {
+		for _, table := range materialTables {
+			if _, err = tx.ExecContext(ctx, fmt.Sprintf(`
+				UPDATE %s SET product_category = array_replace(product_category, $1, $2)
+				WHERE id IN (SELECT material_id FROM %s WHERE material_type = $3 AND category_id = $4)`,
+				table, domain.TableMaterialCategoryLinks), oldName, c.Name, table, c.ID); err != nil {
+				return fmt.Errorf("failed to rename category in %s: %v", table, err)
+			}
+		}
+	}
+
+	return tx.Commit()
 }
 
+// Delete удаляет категорию, переносит ее дочерние категории к ее родителю и убирает ее из кэша названий
+// категорий связанных материалов. Связи материалов с категорией удаляются каскадно
 func (mc *MaterialCategoriesPostgresRepository) Delete(ctx context.Context, id, companyId int64) error {
-	_, err := mc.psql.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND company_id = $2",
-		domain.TableMaterialCategories), id, companyId)
-	return err
+	tx, err := mc.psql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		name     string
+		parentId sql.NullInt64
+	)
+	if err = tx.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT name, parent_id FROM %s WHERE id = $1 AND company_id = $2 FOR UPDATE",
+		domain.TableMaterialCategories), id, companyId).Scan(&name, &parentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET parent_id = $1 WHERE parent_id = $2",
+		domain.TableMaterialCategories), parentId, id); err != nil {
+		return fmt.Errorf("failed to reparent child categories: %v", err)
+	}
+
+	for _, table := range materialTables {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`
+			UPDATE %s SET product_category = array_remove(product_category, $1)
+			WHERE id IN (SELECT material_id FROM %s WHERE material_type = $2 AND category_id = $3)`,
+			table, domain.TableMaterialCategoryLinks), name, table, id); err != nil {
+			return fmt.Errorf("failed to remove category from %s: %v", table, err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND company_id = $2",
+		domain.TableMaterialCategories), id, companyId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (mc *MaterialCategoriesPostgresRepository) List(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*) 
-		FROM %s 
-		WHERE company_id = $1`,
-		domain.TableMaterialCategories)
+	// при переходе по курсору общее количество не подсчитывается
+	if param.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+			SELECT COUNT(*) 
+			FROM %s 
+			WHERE company_id = $1`,
+			domain.TableMaterialCategories)
 
-	err := mc.psql.QueryRowContext(ctx, countQuery, param.CompanyId).Scan(&totalCount)
+		if err := mc.psql.QueryRowContext(ctx, countQuery, param.CompanyId).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	where, args, offset, err := applyCursor("company_id = $1", []any{param.CompanyId}, param.Cursor, param.Offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	query := fmt.Sprintf(`
-		SELECT 
-		    id, name, company_id, description, slug, created_at, updated_at, is_active, img_url 
-		FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3`,
-		domain.TableMaterialCategories, param.SortField, param.Sort)
+		SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		materialCategoryColumns, domain.TableMaterialCategories, where, listOrder(param.SortField, param.Sort), len(args)+1, len(args)+2)
 
-	rows, err := mc.psql.QueryContext(ctx, query, param.CompanyId, param.Limit, param.Offset)
+	rows, err := mc.psql.QueryContext(ctx, query, append(args, param.Limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -119,9 +211,7 @@ func (mc *MaterialCategoriesPostgresRepository) List(ctx context.Context, param
 
 	for rows.Next() {
 		var c domain.MaterialCategory
-		if err = rows.Scan(
-			&c.ID, &c.Name, &c.CompanyID, &c.Description, &c.Slug, &c.CreatedAt, &c.UpdatedAt, &c.IsActive, &c.ImgURL,
-		); err != nil {
+		if err = scanMaterialCategory(rows, &c); err != nil {
 			return nil, 0, err
 		}
 
@@ -148,10 +238,8 @@ func (mc *MaterialCategoriesPostgresRepository) Search(ctx context.Context, para
 	}
 
 	query := fmt.Sprintf(`
-		SELECT 
-		    id, name, company_id, description, slug, created_at, updated_at, is_active, img_url 
-		FROM %s WHERE name ILIKE $1 AND company_id = $2 ORDER BY %s %s LIMIT $3 OFFSET $4`,
-		domain.TableMaterialCategories, param.SortField, param.Sort)
+		SELECT %s FROM %s WHERE name ILIKE $1 AND company_id = $2 ORDER BY %s %s LIMIT $3 OFFSET $4`,
+		materialCategoryColumns, domain.TableMaterialCategories, param.SortField, param.Sort)
 
 	rows, err := mc.psql.QueryContext(ctx, query, searchQuery, param.CompanyId, param.Limit, param.Offset)
 	if err != nil {
@@ -167,9 +255,7 @@ func (mc *MaterialCategoriesPostgresRepository) Search(ctx context.Context, para
 
 	for rows.Next() {
 		var c domain.MaterialCategory
-		if err = rows.Scan(
-			&c.ID, &c.Name, &c.CompanyID, &c.Description, &c.Slug, &c.CreatedAt, &c.UpdatedAt, &c.IsActive, &c.ImgURL,
-		); err != nil {
+		if err = scanMaterialCategory(rows, &c); err != nil {
 			return nil, 0, err
 		}
 
@@ -178,3 +264,97 @@ func (mc *MaterialCategoriesPostgresRepository) Search(ctx context.Context, para
 
 	return categories, totalCount, nil
 }
+
+// GetByName возвращает категорию компании по названию без учета регистра
+func (mc *MaterialCategoriesPostgresRepository) GetByName(ctx context.Context, name string, companyId int64) (domain.MaterialCategory, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s WHERE LOWER(name) = LOWER($1) AND company_id = $2 ORDER BY id LIMIT 1`,
+		materialCategoryColumns, domain.TableMaterialCategories)
+
+	var c domain.MaterialCategory
+	if err := scanMaterialCategory(mc.psql.QueryRowContext(ctx, query, name, companyId), &c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MaterialCategory{}, domain.ErrMaterialCategoryNotFound
+		}
+
+		return domain.MaterialCategory{}, err
+	}
+
+	return c, nil
+}
+
+// GetAll возвращает все категории компании, упорядоченные по названию, для построения дерева
+func (mc *MaterialCategoriesPostgresRepository) GetAll(ctx context.Context, companyId int64) ([]domain.MaterialCategory, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE company_id = $1 ORDER BY name, id",
+		materialCategoryColumns, domain.TableMaterialCategories)
+
+	rows, err := mc.psql.QueryContext(ctx, query, companyId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var categories []domain.MaterialCategory
+
+	for rows.Next() {
+		var c domain.MaterialCategory
+		if err = scanMaterialCategory(rows, &c); err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// Move переносит категорию вместе с поддеревом под категорию parentId, 0 - в корень дерева.
+// Перенос категории под саму себя или своего потомка возвращает domain.ErrCategoryCycle
+func (mc *MaterialCategoriesPostgresRepository) Move(ctx context.Context, id, parentId, companyId int64) error {
+	tx, err := mc.psql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// блокировка категорий компании исключает одновременные переносы, образующие цикл
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("SELECT id FROM %s WHERE company_id = $1 FOR UPDATE",
+		domain.TableMaterialCategories), companyId); err != nil {
+		return err
+	}
+
+	if parentId != 0 {
+		var cycle bool
+		if err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM %[1]s WHERE id = $1 AND company_id = $3
+				UNION
+				SELECT c.id FROM %[1]s c JOIN subtree s ON c.parent_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`,
+			domain.TableMaterialCategories), id, parentId, companyId).Scan(&cycle); err != nil {
+			return err
+		}
+
+		if cycle {
+			err = domain.ErrCategoryCycle
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(
+		"UPDATE %s SET parent_id = NULLIF($1, 0), updated_at = NOW() WHERE id = $2 AND company_id = $3",
+		domain.TableMaterialCategories), parentId, id, companyId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
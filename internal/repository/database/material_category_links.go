@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+// materialTables таблицы материалов, строки которых связываются с категориями через material_category_links
+var materialTables = []string{
+	domain.TablePlanningMaterials,
+	domain.TablePurchasedMaterials,
+	domain.TablePlanningMaterialsArchive,
+	domain.TablePurchasedMaterialsArchive,
+}
+
+// categorySubtreeQuery возвращает запрос id категорий компании $companyParam с id из $idsParam или с названиями
+// из $namesParam вместе со всеми их потомками
+func categorySubtreeQuery(companyParam, idsParam, namesParam int) string {
+	return fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT c.id FROM %[1]s c
+			WHERE c.company_id = $%[2]d AND (c.id = ANY($%[3]d) OR LOWER(c.name) = ANY($%[4]d))
+			UNION
+			SELECT c.id FROM %[1]s c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT id FROM subtree`,
+		domain.TableMaterialCategories, companyParam, idsParam, namesParam)
+}
+
+// saveMaterialCategories заменяет набор категорий строки материала в указанной таблице
+func saveMaterialCategories(ctx context.Context, q querier, table string, materialId int64, categoryIds []int64) error {
+	if err := deleteMaterialCategories(ctx, q, table, materialId); err != nil {
+		return err
+	}
+
+	if len(categoryIds) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (material_type, material_id, category_id)
+		SELECT $1, $2, UNNEST($3::INT[])
+		ON CONFLICT DO NOTHING`,
+		domain.TableMaterialCategoryLinks)
+
+	if _, err := q.ExecContext(ctx, query, table, materialId, pq.Array(categoryIds)); err != nil {
+		return fmt.Errorf("failed to save material categories: %v", err)
+	}
+
+	return nil
+}
+
+// copyMaterialCategories копирует категории строки материала при ее переносе в другую таблицу
+func copyMaterialCategories(ctx context.Context, q querier, fromTable string, fromId int64, toTable string, toId int64) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (material_type, material_id, category_id)
+		SELECT $3, $4, category_id FROM %[1]s WHERE material_type = $1 AND material_id = $2
+		ON CONFLICT DO NOTHING`,
+		domain.TableMaterialCategoryLinks)
+
+	_, err := q.ExecContext(ctx, query, fromTable, fromId, toTable, toId)
+	return err
+}
+
+// deleteMaterialCategories удаляет связи строки материала с категориями
+func deleteMaterialCategories(ctx context.Context, q querier, table string, materialId int64) error {
+	_, err := q.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE material_type = $1 AND material_id = $2",
+		domain.TableMaterialCategoryLinks), table, materialId)
+	return err
+}
+
+// loadMaterialCategories заполняет CategoryIDs материалов, выбранных из указанной таблицы, одним запросом
+func loadMaterialCategories(ctx context.Context, q querier, table string, materials []domain.Material) error {
+	if len(materials) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(materials))
+	for _, material := range materials {
+		ids = append(ids, material.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT material_id, category_id FROM %s
+		WHERE material_type = $1 AND material_id = ANY($2)
+		ORDER BY material_id, category_id`,
+		domain.TableMaterialCategoryLinks)
+
+	rows, err := q.QueryContext(ctx, query, table, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	categories := make(map[int64][]int64, len(materials))
+
+	for rows.Next() {
+		var materialId, categoryId int64
+		if err = rows.Scan(&materialId, &categoryId); err != nil {
+			return err
+		}
+
+		categories[materialId] = append(categories[materialId], categoryId)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range materials {
+		materials[i].CategoryIDs = categories[materials[i].ID]
+	}
+
+	return nil
+}
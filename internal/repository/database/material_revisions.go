@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
+)
+
+type MaterialRevisions interface {
+	GetList(ctx context.Context, materialType string, materialId int64, params domain.Param) ([]domain.MaterialRevision, int64, error)
+	GetByRevision(ctx context.Context, materialType string, materialId, revision int64) (domain.MaterialRevision, error)
+}
+
+type MaterialRevisionsPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewMaterialRevisionsPostgresRepository(psql *sql.DB) *MaterialRevisionsPostgresRepository {
+	return &MaterialRevisionsPostgresRepository{
+		psql: psql,
+	}
+}
+
+const materialRevisionColumns = `id, material_type, material_id, revision, snapshot, COALESCE(user_id, 0), company_id, created_at`
+
+func (mrr *MaterialRevisionsPostgresRepository) GetList(ctx context.Context, materialType string, materialId int64, params domain.Param) ([]domain.MaterialRevision, int64, error) {
+	var totalCount int64
+
+	countQuery := fmt.Sprintf(`
+	SELECT COUNT(*)
+	FROM %s
+	WHERE material_type = $1 AND material_id = $2
+	`, domain.TableMaterialRevisions)
+
+	err := mrr.psql.QueryRowContext(ctx, countQuery, materialType, materialId).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM %s WHERE material_type = $1 AND material_id = $2 ORDER BY %s %s LIMIT $3 OFFSET $4
+	`, materialRevisionColumns, domain.TableMaterialRevisions, params.SortField, params.Sort)
+
+	rows, err := mrr.psql.QueryContext(ctx, query, materialType, materialId, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var revisions []domain.MaterialRevision
+
+	for rows.Next() {
+		revision, err := scanMaterialRevision(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, totalCount, rows.Err()
+}
+
+func (mrr *MaterialRevisionsPostgresRepository) GetByRevision(ctx context.Context, materialType string, materialId, revision int64) (domain.MaterialRevision, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE material_type = $1 AND material_id = $2 AND revision = $3",
+		materialRevisionColumns, domain.TableMaterialRevisions)
+
+	rev, err := scanMaterialRevision(mrr.psql.QueryRowContext(ctx, query, materialType, materialId, revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MaterialRevision{}, domain.ErrRevisionNotFound
+		}
+
+		return domain.MaterialRevision{}, err
+	}
+
+	return rev, nil
+}
+
+func scanMaterialRevision(row scanner) (domain.MaterialRevision, error) {
+	var rev domain.MaterialRevision
+	var snapshotJSON []byte
+
+	if err := row.Scan(
+		&rev.ID, &rev.MaterialType, &rev.MaterialID, &rev.Revision, &snapshotJSON, &rev.UserID, &rev.CompanyID,
+		&rev.CreatedAt,
+	); err != nil {
+		return domain.MaterialRevision{}, err
+	}
+
+	if err := json.Unmarshal(snapshotJSON, &rev.Snapshot); err != nil {
+		return domain.MaterialRevision{}, fmt.Errorf("failed to unmarshal material snapshot: %v", err)
+	}
+
+	return rev, nil
+}
+
+// insertMaterialRevision сохраняет состояние материала до изменения. Вызывается внутри транзакции изменения
+// после блокировки строки материала, поэтому номер версии вычисляется без гонок
+func insertMaterialRevision(ctx context.Context, q querier, materialType string, material domain.Material, userId int64) error {
+	snapshotJSON, err := json.Marshal(material)
+	if err != nil {
+		return fmt.Errorf("failed to marshal material snapshot: %v", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (material_type, material_id, revision, snapshot, user_id, company_id, created_at)
+		SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, NULLIF($4, 0), $5, $6
+		FROM %s WHERE material_type = $1 AND material_id = $2`,
+		domain.TableMaterialRevisions, domain.TableMaterialRevisions)
+
+	if _, err = q.ExecContext(ctx, query,
+		materialType, material.ID, snapshotJSON, userId, material.CompanyID, time.Now().UTC(),
+	); err != nil {
+		return fmt.Errorf("failed to insert material revision: %v", err)
+	}
+
+	return nil
+}
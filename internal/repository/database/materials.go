@@ -8,22 +8,28 @@ import (
 	"fmt"
 	"github.com/lib/pq"
 	"github.com/rusystem/crm-api/pkg/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Materials interface {
 	CreatePlanning(ctx context.Context, material domain.Material) (int64, error)
-	UpdatePlanning(ctx context.Context, material domain.Material) error
+	UpdatePlanning(ctx context.Context, material domain.Material, userId int64) error
 	DeletePlanning(ctx context.Context, id int64) error
 	GetPlanningById(ctx context.Context, id int64) (domain.Material, error)
 	GetPlanningList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
-	MovePlanningToPurchased(ctx context.Context, id int64) (int64, int64, error)
+	MovePlanningToPurchased(ctx context.Context, id, userId int64) (int64, int64, error)
+	ReceivePlanning(ctx context.Context, inp domain.ReceivePlanningMaterial, userId int64) (int64, int64, error)
 
-	CreatePurchased(ctx context.Context, material domain.Material) (int64, int64, error)
-	UpdatePurchased(ctx context.Context, material domain.Material) error
+	CreatePurchased(ctx context.Context, material domain.Material, userId int64) (int64, int64, error)
+	UpdatePurchased(ctx context.Context, update domain.MaterialUpdate, userId int64) error
 	DeletePurchased(ctx context.Context, id int64) error
 	GetPurchasedById(ctx context.Context, id int64) (domain.Material, error)
 	GetPurchasedList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	MovePurchasedToArchive(ctx context.Context, id int64) error
+	TransferPurchased(ctx context.Context, inp domain.TransferPurchasedMaterial, userId int64) (int64, int64, error)
 
 	GetPlanningArchiveById(ctx context.Context, id int64) (domain.Material, error)
 	GetPurchasedArchiveById(ctx context.Context, id int64) (domain.Material, error)
@@ -31,10 +37,65 @@ type Materials interface {
 	GetPurchasedArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	DeletePlanningArchive(ctx context.Context, id int64) error
 	DeletePurchasedArchive(ctx context.Context, id int64) error
+	RestorePlanningArchive(ctx context.Context, id int64) (int64, error)
+	RestorePurchasedArchive(ctx context.Context, id, userId int64) (int64, error)
 
-	Search(ctx context.Context, param domain.MaterialParams) ([]domain.Material, int64, error)
+	Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialSearchResult, int64, error)
 
 	GetIncomeHistoryByWarehouseId(ctx context.Context, id int64, param domain.Param) ([]domain.Material, int64, error)
+	GetPurchasedByLocationId(ctx context.Context, locationId int64, params domain.Param) ([]domain.Material, int64, error)
+	GetLowStockByWarehouseId(ctx context.Context, id int64, params domain.Param) ([]domain.LowStockMaterial, int64, error)
+	GetLowStock(ctx context.Context) ([]domain.LowStockMaterial, error)
+
+	GetExpiringPurchased(ctx context.Context, companyId, warehouseId int64, before time.Time) ([]domain.ExpiringWarehouse, error)
+	GetFefoCandidates(ctx context.Context, params domain.FefoParams) ([]domain.FefoPick, error)
+	GetExpiredPurchasedIds(ctx context.Context) ([]int64, error)
+	WriteOffPurchased(ctx context.Context, id, userId int64, comment string) error
+
+	BulkCreatePlanning(ctx context.Context, materials []domain.Material, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkUpdatePlanning(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkDeletePlanning(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkMovePlanningToPurchased(ctx context.Context, ids []int64, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkCreatePurchased(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkUpdatePurchased(ctx context.Context, updates []domain.MaterialUpdate, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkDeletePurchased(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkMovePurchasedToArchive(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+
+	StreamList(ctx context.Context, materialType string, archive bool, params domain.MaterialParams, fn func(domain.Material) error) error
+
+	RecalculateTotals(ctx context.Context, dryRun bool) (map[string]int64, error)
+}
+
+// materialColumns перечень колонок, общих для всех таблиц материалов. Порядок совпадает с materialArgs и scanMaterial
+const materialColumns = `warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity,
+	volume, price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments,
+	received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost, warehouse_section,
+	incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, supplier_name, contract_number,
+	unit_id, location_id, vat_rate, currency`
+
+// planningMaterialColumns перечень колонок, общих для planning_materials и planning_materials_archive
+const planningMaterialColumns = materialColumns + `, received_quantity`
+
+// expirableLotCondition отбирает несписанные партии с остатком и заполненным сроком годности (таблица с псевдонимом p)
+const expirableLotCondition = `p.expiration_date IS NOT NULL AND p.expiration_date > '0001-01-01'
+	AND COALESCE(p.total_quantity, 0) > 0 AND COALESCE(p.status, '') <> '` + domain.MaterialStatusWrittenOff + `'`
+
+// purchasedMaterialColumns колонки закупленного материала вместе с количеством в активных резервах.
+// Используется в запросах, где таблица материалов имеет псевдоним p
+const purchasedMaterialColumns = materialColumns + `,
+	(SELECT COALESCE(SUM(r.quantity), 0) FROM ` + domain.TableReservations + ` r
+	 WHERE r.purchased_material_id = p.id AND ` + activeReservationCondition + `)`
+
+// querier позволяет выполнять одни и те же запросы как через *sql.DB, так и внутри транзакции
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// scanner реализуется *sql.Row и *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
 }
 
 type MaterialsPostgresRepository struct {
@@ -48,99 +109,92 @@ func NewMaterialsPostgresRepository(psql *sql.DB) *MaterialsPostgresRepository {
 }
 
 func (mr *MaterialsPostgresRepository) CreatePlanning(ctx context.Context, material domain.Material) (int64, error) {
-	otherFieldsJSON, err := json.Marshal(material.OtherFields)
+	tx, err := mr.psql.Begin()
 	if err != nil {
-		return 0, fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
+		return 0, err
 	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
 
-	query := fmt.Sprintf(`
-		INSERT INTO %s (warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume, 
-						price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve, 
-						received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost, 
-						warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, supplier_name, contract_number)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, 
-				$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32) RETURNING id`,
-		domain.TablePlanningMaterials)
+	id, _, err := createPlanning(ctx, tx, material)
+	if err != nil {
+		return 0, err
+	}
 
-	var id int64
-	if err = mr.psql.QueryRowContext(ctx, query,
-		material.WarehouseID, material.ItemID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
-		otherFieldsJSON, material.CompanyID, material.InternalName, material.UnitsPerPackage, material.SupplierName,
-		material.ContractNumber,
-	).Scan(&id); err != nil {
-		return 0, fmt.Errorf("failed to insert planning material: %v", err)
+	return id, tx.Commit()
+}
+
+// createPlanning создает планируемый материал, привязывая его к товару каталога
+func createPlanning(ctx context.Context, tx *sql.Tx, material domain.Material) (int64, int64, error) {
+	itemId, err := resolveItemId(ctx, tx, material)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return id, nil
+	material.ItemID = itemId
+
+	id, err := insertMaterial(ctx, tx, domain.TablePlanningMaterials, material)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to insert planning material: %v", err)
+	}
+
+	return id, itemId, nil
 }
 
-func (mr *MaterialsPostgresRepository) UpdatePlanning(ctx context.Context, material domain.Material) error {
-	otherFieldsJSON, err := json.Marshal(material.OtherFields)
+func (mr *MaterialsPostgresRepository) UpdatePlanning(ctx context.Context, material domain.Material, userId int64) error {
+	tx, err := mr.psql.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
+		return err
 	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
 
-	query := fmt.Sprintf(`
-		UPDATE %s
-		SET
-			warehouse_id = $1, item_id = $2, name = $3, by_invoice = $4, article = $5, product_category = $6, unit = $7,
-			total_quantity = $8, volume = $9, price_without_vat = $10, total_without_vat = $11, supplier_id = $12, location = $13,
-			contract_date = $14, file = $15, status = $16, comments = $17, reserve = $18, received_date = $19, last_updated = $20,
-			min_stock_level = $21, expiration_date = $22, responsible_person = $23, storage_cost = $24, warehouse_section = $25,
-			incoming_delivery_number = $26, other_fields = $27, internal_name = $28, units_per_package = $29, supplier_name = $30, 
-		    contract_number = $31
-		WHERE id = $32`,
-		domain.TablePlanningMaterials)
+	if err = mr.updatePlanning(ctx, tx, material, userId); err != nil {
+		return err
+	}
 
-	_, err = mr.psql.ExecContext(ctx, query,
-		material.WarehouseID, material.ItemID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber, otherFieldsJSON,
-		material.InternalName, material.UnitsPerPackage, material.SupplierName, material.ContractNumber, material.ID,
-	)
+	return tx.Commit()
+}
 
-	return err
+func (mr *MaterialsPostgresRepository) updatePlanning(ctx context.Context, tx *sql.Tx, material domain.Material, userId int64) error {
+	if err := lockMaterial(ctx, tx, domain.TablePlanningMaterials, material.ID); err != nil {
+		return err
+	}
+
+	// 1. сохраняем текущее состояние в историю версий
+	previous, err := mr.getPlanningById(ctx, tx, material.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = insertMaterialRevision(ctx, tx, domain.MaterialTypePlanning, previous, userId); err != nil {
+		return err
+	}
+
+	// 2. обновляем материал
+	return updateMaterial(ctx, tx, domain.TablePlanningMaterials, material)
 }
 
 func (mr *MaterialsPostgresRepository) DeletePlanning(ctx context.Context, id int64) error {
-	_, err := mr.psql.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", domain.TablePlanningMaterials), id)
+	_, err := mr.psql.ExecContext(ctx, deleteMaterialQuery(domain.TablePlanningMaterials), id)
 	return err
 }
 
 func (mr *MaterialsPostgresRepository) GetPlanningById(ctx context.Context, id int64) (domain.Material, error) {
-	return mr.getPlanningById(ctx, id)
+	return mr.getPlanningById(ctx, mr.psql, id)
 }
 
-func (mr *MaterialsPostgresRepository) getPlanningById(ctx context.Context, id int64) (domain.Material, error) {
-	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, supplier_name, 
-		contract_number
-	FROM %s WHERE id = $1
-	`, domain.TablePlanningMaterials)
+func (mr *MaterialsPostgresRepository) getPlanningById(ctx context.Context, q querier, id int64) (domain.Material, error) {
+	query := fmt.Sprintf("SELECT id, %s FROM %s WHERE id = $1", planningMaterialColumns, domain.TablePlanningMaterials)
 
 	var material domain.Material
-	var otherFieldsJSON []byte
-
-	if err := mr.psql.QueryRowContext(ctx, query, id).Scan(
-		&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-		pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-		&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-		&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-		&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-		&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-		&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-		&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-	); err != nil {
+	if err := scanMaterial(q.QueryRowContext(ctx, query, id), &material, &material.ReceivedQuantity); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Material{}, domain.ErrMaterialNotFound
 		}
@@ -148,78 +202,51 @@ func (mr *MaterialsPostgresRepository) getPlanningById(ctx context.Context, id i
 		return domain.Material{}, err
 	}
 
-	if err := json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
-		return domain.Material{}, err
-	}
-
-	return material, nil
+	return withCategories(ctx, q, domain.TablePlanningMaterials, material)
 }
 
 func (mr *MaterialsPostgresRepository) GetPlanningList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1
-	`, domain.TablePlanningMaterials)
+	where, args := materialListConditions(domain.TablePlanningMaterials, params)
+
+	// при переходе по курсору общее количество не подсчитывается
+	if params.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE %s
+		`, domain.TablePlanningMaterials, where)
+
+		if err := mr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
 
-	err := mr.psql.QueryRowContext(ctx, countQuery, params.CompanyId).Scan(&totalCount)
+	where, args, offset, err := applyCursor(where, args, params.Cursor, params.Offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3
-	`, domain.TablePlanningMaterials, params.SortField, params.Sort)
+	SELECT id, %s
+	FROM %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d
+	`, planningMaterialColumns, domain.TablePlanningMaterials, where, listOrder(params.SortField, params.Sort), len(args)+1, len(args)+2)
 
-	rows, err := mr.psql.QueryContext(ctx, query, params.CompanyId, params.Limit, params.Offset)
+	materials, err := queryPlanningMaterials(ctx, mr.psql, query, append(args, params.Limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer func(rows *sql.Rows) {
-		if err = rows.Close(); err != nil {
-			return
-		}
-	}(rows)
-
-	var materials []domain.Material
-
-	for rows.Next() {
-		var material domain.Material
-		var otherFieldsJSON []byte
-
-		if err = rows.Scan(
-			&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-			pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-			&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-			&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-			&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-			&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-			&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-			&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-		); err != nil {
-			return nil, 0, err
-		}
-
-		if err = json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
-			return nil, 0, err
-		}
 
-		materials = append(materials, material)
-	}
+	return materials, totalCount, loadMaterialCategories(ctx, mr.psql, domain.TablePlanningMaterials, materials)
+}
 
-	return materials, totalCount, nil
+func (mr *MaterialsPostgresRepository) MovePlanningToPurchased(ctx context.Context, id, userId int64) (int64, int64, error) {
+	// приемка без указания количества переносит весь оставшийся объем
+	return mr.ReceivePlanning(ctx, domain.ReceivePlanningMaterial{ID: id}, userId)
 }
 
-func (mr *MaterialsPostgresRepository) MovePlanningToPurchased(ctx context.Context, id int64) (int64, int64, error) {
-	// Удаляем из planning, переносим сразу в purchased и archived
+func (mr *MaterialsPostgresRepository) ReceivePlanning(ctx context.Context, inp domain.ReceivePlanningMaterial, userId int64) (int64, int64, error) {
 	tx, err := mr.psql.Begin()
 	if err != nil {
 		return 0, 0, err
@@ -230,254 +257,200 @@ func (mr *MaterialsPostgresRepository) MovePlanningToPurchased(ctx context.Conte
 		}
 	}(tx)
 
-	material, err := mr.getPlanningById(ctx, id)
+	newId, itemId, err := mr.receivePlanning(ctx, tx, inp, userId)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	otherFieldsJSON, err := json.Marshal(material.OtherFields)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
-	}
+	return newId, itemId, tx.Commit()
+}
 
-	// 1. удаляем из planning
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1",
-		domain.TablePlanningMaterials)
+// receivePlanning принимает на склад часть планируемого материала. Планируемая строка хранит оставшееся количество
+// и переносится в архив только после полной приемки.
+func (mr *MaterialsPostgresRepository) receivePlanning(ctx context.Context, tx *sql.Tx, inp domain.ReceivePlanningMaterial, userId int64) (int64, int64, error) {
+	if err := lockMaterial(ctx, tx, domain.TablePlanningMaterials, inp.ID); err != nil {
+		return 0, 0, err
+	}
 
-	_, err = tx.ExecContext(ctx, query, id)
+	material, err := mr.getPlanningById(ctx, tx, inp.ID)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	// 2. переносим в purchased
-	query = fmt.Sprintf(`
-		INSERT INTO %s (warehouse_id, name, by_invoice, article, product_category, unit, total_quantity, volume, 
-						price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve, 
-						received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost, 
-						warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		                supplier_name, contract_number)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, 
-				$22, $23, $24, $25, $26, $27, $28, $29, $30, $31) RETURNING id, item_id`,
-		domain.TablePurchasedMaterials)
+	quantity := inp.Quantity
+	if quantity == 0 {
+		quantity = material.TotalQuantity
+	}
 
-	var newId int64
-	var itemId int64
-	if err = tx.QueryRowContext(ctx, query,
-		material.WarehouseID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
-		otherFieldsJSON, material.CompanyID, material.InternalName, material.UnitsPerPackage, material.SupplierName,
-		material.ContractNumber,
-	).Scan(&newId, &itemId); err != nil {
-		return 0, 0, fmt.Errorf("failed to insert purchased material: %v", err)
+	if quantity <= 0 {
+		return 0, 0, domain.ErrInvalidQuantity
 	}
 
-	// 3. переносим в planning archive
-	query = fmt.Sprintf(`
-		INSERT INTO %s (warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume, 
-						price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve, 
-						received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost, 
-						warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		                supplier_name, contract_number)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, 
-				$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32)`,
-		domain.TablePlanningMaterialsArchive)
+	if quantity > material.TotalQuantity {
+		return 0, 0, domain.ErrQuantityExceedsPlanned
+	}
 
-	_, err = tx.ExecContext(ctx, query,
-		material.WarehouseID, material.ItemID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
-		otherFieldsJSON, material.CompanyID, material.InternalName, material.UnitsPerPackage, material.SupplierName,
-		material.ContractNumber,
-	)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to insert purchased archive material: %v", err)
+	// все поставки по одной планируемой строке ссылаются на ее товар каталога
+	if material.ItemID, err = resolveItemId(ctx, tx, material); err != nil {
+		return 0, 0, err
 	}
 
-	return newId, itemId, tx.Commit()
-}
+	now := time.Now().UTC()
 
-func (mr *MaterialsPostgresRepository) CreatePurchased(ctx context.Context, material domain.Material) (int64, int64, error) {
-	otherFieldsJSON, err := json.Marshal(material.OtherFields)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
-	}
+	// 1. создаем закупленный материал на принятое количество
+	purchased := material
+	purchased.TotalQuantity = quantity
+	purchased.ReceivedDate = now
+	purchased.LastUpdated = now
 
-	query := fmt.Sprintf(`
-		INSERT INTO %s (warehouse_id, name, by_invoice, article, product_category, unit, total_quantity, volume, 
-						price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve, 
-						received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost, 
-						warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		                supplier_name, contract_number)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, 
-				$22, $23, $24, $25, $26, $27, $28, $29, $30, $31) RETURNING id, item_id`,
-		domain.TablePurchasedMaterials)
+	if inp.PriceWithoutVAT != nil {
+		purchased.PriceWithoutVAT = *inp.PriceWithoutVAT
+	}
 
-	var id int64
-	var itemId int64
-	if err = mr.psql.QueryRowContext(ctx, query,
-		material.WarehouseID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
-		otherFieldsJSON, material.CompanyID, material.InternalName, material.UnitsPerPackage, material.SupplierName,
-		material.ContractNumber,
-	).Scan(&id, &itemId); err != nil {
-		return 0, 0, fmt.Errorf("failed to insert purchased material: %v", err)
+	if inp.ByInvoice != nil {
+		purchased.ByInvoice = *inp.ByInvoice
 	}
 
-	return id, itemId, nil
-}
+	if inp.IncomingDeliveryNumber != nil {
+		purchased.IncomingDeliveryNumber = *inp.IncomingDeliveryNumber
+	}
 
-func (mr *MaterialsPostgresRepository) UpdatePurchased(ctx context.Context, material domain.Material) error {
-	otherFieldsJSON, err := json.Marshal(material.OtherFields)
-	if err != nil {
-		return fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
+	if inp.ReceivedDate != nil {
+		purchased.ReceivedDate = *inp.ReceivedDate
 	}
 
-	query := fmt.Sprintf(`
-		UPDATE %s
-		SET
-			warehouse_id = $1, item_id = $2, name = $3, by_invoice = $4, article = $5, product_category = $6, unit = $7,
-			total_quantity = $8, volume = $9, price_without_vat = $10, total_without_vat = $11, supplier_id = $12, location = $13,
-			contract_date = $14, file = $15, status = $16, comments = $17, reserve = $18, received_date = $19, last_updated = $20,
-			min_stock_level = $21, expiration_date = $22, responsible_person = $23, storage_cost = $24, warehouse_section = $25,
-			incoming_delivery_number = $26, other_fields = $27, internal_name = $28, units_per_package = $29, supplier_name = $30, 
-		    contract_number = $31
-		WHERE id = $32`,
-		domain.TablePurchasedMaterials)
+	if inp.LocationID != nil {
+		if purchased, err = placeMaterial(ctx, tx, purchased, *inp.LocationID); err != nil {
+			return 0, 0, err
+		}
+	}
 
-	_, err = mr.psql.ExecContext(ctx, query,
-		material.WarehouseID, material.ItemID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
-		otherFieldsJSON, material.InternalName, material.UnitsPerPackage, material.SupplierName,
-		material.ContractNumber, material.ID,
-	)
+	purchased.TotalWithoutVAT = domain.LineTotal(purchased.PriceWithoutVAT, quantity)
 
-	return err
-}
+	newId, err := insertMaterial(ctx, tx, domain.TablePurchasedMaterials, purchased)
+	if err != nil {
+		return 0, 0, err
+	}
 
-func (mr *MaterialsPostgresRepository) DeletePurchased(ctx context.Context, id int64) error {
-	_, err := mr.psql.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", domain.TablePurchasedMaterials), id)
-	return err
-}
+	// 2. фиксируем поступление в журнале движений
+	if _, err = insertStockMovement(ctx, tx, domain.StockMovement{
+		PurchasedMaterialID: newId,
+		ItemID:              material.ItemID,
+		WarehouseID:         purchased.WarehouseID,
+		MovementType:        domain.MovementReceipt,
+		Quantity:            quantity,
+		BalanceAfter:        quantity,
+		Reference:           purchased.IncomingDeliveryNumber,
+		UserID:              userId,
+		CompanyID:           material.CompanyID,
+	}); err != nil {
+		return 0, 0, err
+	}
 
-func (mr *MaterialsPostgresRepository) GetPurchasedById(ctx context.Context, id int64) (domain.Material, error) {
-	return mr.getPurchasedById(ctx, id)
-}
+	// 3. уменьшаем остаток в planning
+	remaining := material.TotalQuantity - quantity
 
-func (mr *MaterialsPostgresRepository) getPurchasedById(ctx context.Context, id int64) (domain.Material, error) {
 	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE id = $1
-	`, domain.TablePurchasedMaterials)
-
-	var material domain.Material
-	var otherFieldsJSON []byte
+		UPDATE %s
+		SET item_id = $1, total_quantity = $2, received_quantity = received_quantity + $3, total_without_vat = $4,
+		    last_updated = $5
+		WHERE id = $6`,
+		domain.TablePlanningMaterials)
 
-	if err := mr.psql.QueryRowContext(ctx, query, id).Scan(
-		&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-		pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-		&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-		&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-		&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-		&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection, &material.IncomingDeliveryNumber,
-		&otherFieldsJSON, &material.CompanyID, &material.InternalName, &material.UnitsPerPackage, &material.SupplierName,
-		&material.ContractNumber,
+	if _, err = tx.ExecContext(ctx, query,
+		material.ItemID, remaining, quantity, domain.LineTotal(material.PriceWithoutVAT, remaining), now, material.ID,
 	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Material{}, domain.ErrMaterialNotFound
+		return 0, 0, err
+	}
+
+	// 4. полностью принятую строку переносим в planning archive
+	if remaining == 0 {
+		query = fmt.Sprintf(`
+			INSERT INTO %s (%s)
+			SELECT %s FROM %s WHERE id = $1
+			RETURNING id`,
+			domain.TablePlanningMaterialsArchive, planningMaterialColumns, planningMaterialColumns,
+			domain.TablePlanningMaterials)
+
+		var archiveId int64
+		if err = tx.QueryRowContext(ctx, query, material.ID).Scan(&archiveId); err != nil {
+			return 0, 0, fmt.Errorf("failed to insert planning archive material: %v", err)
 		}
 
-		return domain.Material{}, err
+		if err = copyMaterialCategories(ctx, tx, domain.TablePlanningMaterials, material.ID,
+			domain.TablePlanningMaterialsArchive, archiveId); err != nil {
+			return 0, 0, err
+		}
+
+		query = deleteMaterialQuery(domain.TablePlanningMaterials)
+
+		if _, err = tx.ExecContext(ctx, query, material.ID); err != nil {
+			return 0, 0, err
+		}
 	}
 
-	if err := json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
-		return domain.Material{}, err
+	// 5. принятый материал занимает место на складе
+	if err = checkWarehouseCapacity(ctx, tx, purchased.WarehouseID, purchased.Volume*quantity); err != nil {
+		return 0, 0, err
 	}
 
-	return material, nil
+	return newId, material.ItemID, nil
 }
 
-func (mr *MaterialsPostgresRepository) GetPurchasedList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
-	var totalCount int64
-
-	// Подсчет общего количества записей
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1
-	`, domain.TablePurchasedMaterials)
+func (mr *MaterialsPostgresRepository) CreatePurchased(ctx context.Context, material domain.Material, userId int64) (int64, int64, error) {
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
 
-	err := mr.psql.QueryRowContext(ctx, countQuery, params.CompanyId).Scan(&totalCount)
+	id, itemId, err := createPurchased(ctx, tx, material, userId)
 	if err != nil {
-		return nil, 0, err
+		return 0, 0, err
 	}
 
-	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3
-	`, domain.TablePurchasedMaterials, params.SortField, params.Sort)
+	return id, itemId, tx.Commit()
+}
 
-	rows, err := mr.psql.QueryContext(ctx, query, params.CompanyId, params.Limit, params.Offset)
+func createPurchased(ctx context.Context, tx *sql.Tx, material domain.Material, userId int64) (int64, int64, error) {
+	itemId, err := resolveItemId(ctx, tx, material)
 	if err != nil {
-		return nil, 0, err
+		return 0, 0, err
 	}
-	defer func(rows *sql.Rows) {
-		if err = rows.Close(); err != nil {
-			return
-		}
-	}(rows)
-
-	var materials []domain.Material
 
-	for rows.Next() {
-		var material domain.Material
-		var otherFieldsJSON []byte
+	material.ItemID = itemId
 
-		if err = rows.Scan(
-			&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-			pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-			&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-			&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-			&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-			&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-			&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-			&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-		); err != nil {
-			return nil, 0, err
-		}
+	id, err := insertMaterial(ctx, tx, domain.TablePurchasedMaterials, material)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to insert purchased material: %v", err)
+	}
 
-		if err = json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
-			return nil, 0, err
-		}
+	if _, err = insertStockMovement(ctx, tx, domain.StockMovement{
+		PurchasedMaterialID: id,
+		ItemID:              material.ItemID,
+		WarehouseID:         material.WarehouseID,
+		MovementType:        domain.MovementReceipt,
+		Quantity:            material.TotalQuantity,
+		BalanceAfter:        material.TotalQuantity,
+		Reference:           material.IncomingDeliveryNumber,
+		UserID:              userId,
+		CompanyID:           material.CompanyID,
+	}); err != nil {
+		return 0, 0, err
+	}
 
-		materials = append(materials, material)
+	if err = checkWarehouseCapacity(ctx, tx, material.WarehouseID, material.Volume*material.TotalQuantity); err != nil {
+		return 0, 0, err
 	}
 
-	return materials, totalCount, nil
+	return id, material.ItemID, nil
 }
 
-func (mr *MaterialsPostgresRepository) MovePurchasedToArchive(ctx context.Context, id int64) error {
-	// Удаляем из purchased и переносим сразу в archived
+func (mr *MaterialsPostgresRepository) UpdatePurchased(ctx context.Context, update domain.MaterialUpdate, userId int64) error {
 	tx, err := mr.psql.Begin()
 	if err != nil {
 		return err
@@ -488,366 +461,461 @@ func (mr *MaterialsPostgresRepository) MovePurchasedToArchive(ctx context.Contex
 		}
 	}(tx)
 
-	material, err := mr.getPurchasedById(ctx, id)
-	if err != nil {
+	if _, err = mr.updatePurchased(ctx, tx, update, userId); err != nil {
 		return err
 	}
 
-	otherFieldsJSON, err := json.Marshal(material.OtherFields)
-	if err != nil {
-		return fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
+	return tx.Commit()
+}
+
+// updatePurchased применяет изменение к закупленному материалу, прочитанному под блокировкой строки, и возвращает
+// сохраненное состояние
+func (mr *MaterialsPostgresRepository) updatePurchased(ctx context.Context, tx *sql.Tx, update domain.MaterialUpdate, userId int64) (domain.Material, error) {
+	// блокируем строку, чтобы изменения применялись к актуальному остатку, а корректировка в журнале считалась от него
+	if err := lockMaterial(ctx, tx, domain.TablePurchasedMaterials, update.ID); err != nil {
+		return domain.Material{}, err
 	}
 
-	// 1. удаляем из purchased
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1",
-		domain.TablePurchasedMaterials)
+	previous, err := mr.getPurchasedById(ctx, tx, update.ID)
+	if err != nil {
+		return domain.Material{}, err
+	}
 
-	if _, err = tx.ExecContext(ctx, query, id); err != nil {
-		return err
+	material, err := update.Apply(previous)
+	if err != nil {
+		return domain.Material{}, err
 	}
 
-	// 2. переносим в purchased archive
-	query = fmt.Sprintf(`
-		INSERT INTO %s (warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume, 
-						price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve, 
-						received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost, 
-						warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		                supplier_name, contract_number)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, 
-				$22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32)`,
-		domain.TablePurchasedMaterialsArchive)
+	material.ID = previous.ID
 
-	_, err = tx.ExecContext(ctx, query,
-		material.WarehouseID, material.ItemID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
-		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
-		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
-		material.Reserve, material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
-		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
-		otherFieldsJSON, material.CompanyID, material.InternalName, material.UnitsPerPackage, material.SupplierName,
-		material.ContractNumber,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert purchased material archive: %v", err)
-	}
+	currentQuantity := previous.TotalQuantity
 
-	return tx.Commit()
-}
+	// остаток нельзя уменьшить ниже уже зарезервированного количества
+	if material.TotalQuantity < currentQuantity && material.TotalQuantity < previous.ReservedQuantity {
+		return domain.Material{}, domain.ErrInsufficientStock
+	}
 
-func (mr *MaterialsPostgresRepository) GetPlanningArchiveById(ctx context.Context, id int64) (domain.Material, error) {
-	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE id = $1
-	`, domain.TablePlanningMaterialsArchive)
+	// сохраняем текущее состояние в историю версий
+	if err = insertMaterialRevision(ctx, tx, domain.MaterialTypePurchased, previous, userId); err != nil {
+		return domain.Material{}, err
+	}
 
-	var material domain.Material
-	var otherFieldsJSON []byte
+	if err = updateMaterial(ctx, tx, domain.TablePurchasedMaterials, material); err != nil {
+		return domain.Material{}, err
+	}
 
-	if err := mr.psql.QueryRowContext(ctx, query, id).Scan(
-		&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-		pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-		&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-		&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-		&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-		&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-		&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-		&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Material{}, domain.ErrMaterialNotFound
+	// изменение количества вручную фиксируем как корректировку
+	if delta := material.TotalQuantity - currentQuantity; delta != 0 {
+		if _, err = insertStockMovement(ctx, tx, domain.StockMovement{
+			PurchasedMaterialID: material.ID,
+			ItemID:              material.ItemID,
+			WarehouseID:         material.WarehouseID,
+			MovementType:        domain.MovementAdjustment,
+			Quantity:            delta,
+			BalanceAfter:        material.TotalQuantity,
+			UserID:              userId,
+			CompanyID:           material.CompanyID,
+		}); err != nil {
+			return domain.Material{}, err
 		}
+	}
 
+	// объем, количество или склад могли измениться, пересчитываем прежний и новый склад. При смене склада
+	// материал целиком занимает место на новом складе
+	volume := material.Volume * material.TotalQuantity
+	if previous.WarehouseID == material.WarehouseID {
+		volume -= previous.Volume * previous.TotalQuantity
+	} else if err = recalcWarehouseOccupancy(ctx, tx, previous.WarehouseID); err != nil {
 		return domain.Material{}, err
 	}
 
-	if err := json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
+	if err = checkWarehouseCapacity(ctx, tx, material.WarehouseID, volume); err != nil {
 		return domain.Material{}, err
 	}
 
 	return material, nil
 }
 
-func (mr *MaterialsPostgresRepository) GetPurchasedArchiveById(ctx context.Context, id int64) (domain.Material, error) {
-	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE id = $1
-	`, domain.TablePurchasedMaterialsArchive)
+func (mr *MaterialsPostgresRepository) DeletePurchased(ctx context.Context, id int64) error {
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
 
-	var material domain.Material
-	var otherFieldsJSON []byte
+	if err = deletePurchased(ctx, tx, id); err != nil {
+		return err
+	}
 
-	if err := mr.psql.QueryRowContext(ctx, query, id).Scan(
-		&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-		pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-		&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-		&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-		&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-		&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-		&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-		&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-	); err != nil {
+	return tx.Commit()
+}
+
+func deletePurchased(ctx context.Context, tx *sql.Tx, id int64) error {
+	var warehouseId int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(warehouse_id, 0) FROM %s WHERE id = $1 FOR UPDATE",
+		domain.TablePurchasedMaterials), id).Scan(&warehouseId); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Material{}, domain.ErrMaterialNotFound
+			return nil
 		}
 
-		return domain.Material{}, err
+		return err
+	}
+
+	if err := releaseReservations(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteMaterialQuery(domain.TablePurchasedMaterials), id); err != nil {
+		return err
 	}
 
-	if err := json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
+	return recalcWarehouseOccupancy(ctx, tx, warehouseId)
+}
+
+func (mr *MaterialsPostgresRepository) GetPurchasedById(ctx context.Context, id int64) (domain.Material, error) {
+	return mr.getPurchasedById(ctx, mr.psql, id)
+}
+
+func (mr *MaterialsPostgresRepository) getPurchasedById(ctx context.Context, q querier, id int64) (domain.Material, error) {
+	query := fmt.Sprintf("SELECT id, %s FROM %s p WHERE id = $1", purchasedMaterialColumns, domain.TablePurchasedMaterials)
+
+	material, err := scanPurchasedMaterial(q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Material{}, domain.ErrMaterialNotFound
+		}
+
 		return domain.Material{}, err
 	}
 
-	return material, nil
+	return withCategories(ctx, q, domain.TablePurchasedMaterials, material)
 }
 
-func (mr *MaterialsPostgresRepository) GetPlanningArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
+func (mr *MaterialsPostgresRepository) GetPurchasedList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1
-	`, domain.TablePlanningMaterialsArchive)
+	where, args := materialListConditions(domain.TablePurchasedMaterials, params)
+
+	// при переходе по курсору общее количество не подсчитывается
+	if params.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE %s
+		`, domain.TablePurchasedMaterials, where)
+
+		if err := mr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
 
-	err := mr.psql.QueryRowContext(ctx, countQuery, params.CompanyId).Scan(&totalCount)
+	where, args, offset, err := applyCursor(where, args, params.Cursor, params.Offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3
-	`, domain.TablePlanningMaterialsArchive, params.SortField, params.Sort)
+	SELECT id, %s
+	FROM %s p WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d
+	`, purchasedMaterialColumns, domain.TablePurchasedMaterials, where, listOrder(params.SortField, params.Sort), len(args)+1, len(args)+2)
 
-	rows, err := mr.psql.QueryContext(ctx, query, params.CompanyId, params.Limit, params.Offset)
+	materials, err := queryPurchasedMaterials(ctx, mr.psql, query, append(args, params.Limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer func(rows *sql.Rows) {
-		if err = rows.Close(); err != nil {
+
+	return materials, totalCount, loadMaterialCategories(ctx, mr.psql, domain.TablePurchasedMaterials, materials)
+}
+
+func (mr *MaterialsPostgresRepository) MovePurchasedToArchive(ctx context.Context, id int64) error {
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
 			return
 		}
-	}(rows)
-
-	var materials []domain.Material
+	}(tx)
 
-	for rows.Next() {
-		var material domain.Material
-		var otherFieldsJSON []byte
+	if err = mr.movePurchasedToArchive(ctx, tx, id); err != nil {
+		return err
+	}
 
-		if err = rows.Scan(
-			&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-			pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-			&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-			&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-			&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-			&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-			&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-			&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-		); err != nil {
-			return nil, 0, err
-		}
+	return tx.Commit()
+}
 
-		if err = json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
-			return nil, 0, err
-		}
+// movePurchasedToArchive удаляет материал из purchased и переносит сразу в archived
+func (mr *MaterialsPostgresRepository) movePurchasedToArchive(ctx context.Context, tx *sql.Tx, id int64) error {
+	if err := lockMaterial(ctx, tx, domain.TablePurchasedMaterials, id); err != nil {
+		return err
+	}
 
-		materials = append(materials, material)
+	material, err := mr.getPurchasedById(ctx, tx, id)
+	if err != nil {
+		return err
 	}
 
-	return materials, totalCount, nil
-}
+	// 1. снимаем активные резервы, архивный материал зарезервировать нельзя
+	if err = releaseReservations(ctx, tx, id); err != nil {
+		return err
+	}
 
-func (mr *MaterialsPostgresRepository) GetPurchasedArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
-	var totalCount int64
+	// 2. удаляем из purchased
+	query := deleteMaterialQuery(domain.TablePurchasedMaterials)
 
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1
-	`, domain.TablePurchasedMaterialsArchive)
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
 
-	err := mr.psql.QueryRowContext(ctx, countQuery, params.CompanyId).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, err
+	// 3. переносим в purchased archive
+	if _, err = insertMaterial(ctx, tx, domain.TablePurchasedMaterialsArchive, material); err != nil {
+		return fmt.Errorf("failed to insert purchased material archive: %v", err)
 	}
 
-	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3
-	`, domain.TablePurchasedMaterialsArchive, params.SortField, params.Sort)
+	// 4. архивный материал не занимает место на складе
+	return recalcWarehouseOccupancy(ctx, tx, material.WarehouseID)
+}
 
-	rows, err := mr.psql.QueryContext(ctx, query, params.CompanyId, params.Limit, params.Offset)
+func (mr *MaterialsPostgresRepository) TransferPurchased(ctx context.Context, inp domain.TransferPurchasedMaterial, userId int64) (int64, int64, error) {
+	tx, err := mr.psql.Begin()
 	if err != nil {
-		return nil, 0, err
+		return 0, 0, err
 	}
-	defer func(rows *sql.Rows) {
-		if err = rows.Close(); err != nil {
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
 			return
 		}
-	}(rows)
+	}(tx)
 
-	var materials []domain.Material
+	if err = lockMaterial(ctx, tx, domain.TablePurchasedMaterials, inp.ID); err != nil {
+		return 0, 0, err
+	}
 
-	for rows.Next() {
-		var material domain.Material
-		var otherFieldsJSON []byte
+	material, err := mr.getPurchasedById(ctx, tx, inp.ID)
+	if err != nil {
+		return 0, 0, err
+	}
 
-		if err = rows.Scan(
-			&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-			pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-			&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-			&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-			&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-			&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-			&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-			&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
+	quantity := inp.Quantity
+	if quantity == 0 {
+		quantity = material.TotalQuantity
+	}
+
+	if quantity <= 0 {
+		return 0, 0, domain.ErrInvalidQuantity
+	}
+
+	if quantity > material.TotalQuantity {
+		return 0, 0, domain.ErrInsufficientStock
+	}
+
+	// при полном перемещении резервы переходят вместе с материалом, частично перемещается только свободный остаток
+	if quantity < material.TotalQuantity && quantity > material.AvailableQuantity {
+		return 0, 0, domain.ErrInsufficientStock
+	}
+
+	sourceWarehouseId := material.WarehouseID
+	remaining := material.TotalQuantity - quantity
+	now := time.Now().UTC()
+
+	// 1. переносим материал целиком или отделяем часть в новую строку с тем же item_id
+	// место хранения на складе-отправителе не переносится на склад-получатель
+	placed, err := placeMaterial(ctx, tx, material, inp.LocationID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	targetId := material.ID
+	if remaining == 0 {
+		query := fmt.Sprintf(`
+			UPDATE %s SET warehouse_id = $1, location_id = NULLIF($2, 0), location = $3, last_updated = $4
+			WHERE id = $5`,
+			domain.TablePurchasedMaterials)
+
+		if _, err = tx.ExecContext(ctx, query,
+			inp.WarehouseID, placed.LocationID, placed.Location, now, material.ID,
 		); err != nil {
-			return nil, 0, err
+			return 0, 0, err
 		}
+	} else {
+		query := fmt.Sprintf(`
+			UPDATE %s SET total_quantity = $1, total_without_vat = $2, last_updated = $3 WHERE id = $4`,
+			domain.TablePurchasedMaterials)
 
-		if err = json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
-			return nil, 0, err
+		if _, err = tx.ExecContext(ctx, query,
+			remaining, domain.LineTotal(material.PriceWithoutVAT, remaining), now, material.ID,
+		); err != nil {
+			return 0, 0, err
 		}
 
-		materials = append(materials, material)
+		part := placed
+		part.WarehouseID = inp.WarehouseID
+		part.TotalQuantity = quantity
+		part.TotalWithoutVAT = domain.LineTotal(material.PriceWithoutVAT, quantity)
+		part.LastUpdated = now
+
+		if targetId, err = insertMaterial(ctx, tx, domain.TablePurchasedMaterials, part); err != nil {
+			return 0, 0, err
+		}
 	}
 
-	return materials, totalCount, nil
-}
+	// 2. фиксируем расход на складе-отправителе и приход на складе-получателе
+	if _, err = insertStockMovement(ctx, tx, domain.StockMovement{
+		PurchasedMaterialID: material.ID,
+		ItemID:              material.ItemID,
+		WarehouseID:         sourceWarehouseId,
+		MovementType:        domain.MovementTransfer,
+		Quantity:            -quantity,
+		BalanceAfter:        remaining,
+		TargetWarehouseID:   inp.WarehouseID,
+		Comment:             inp.Comment,
+		UserID:              userId,
+		CompanyID:           material.CompanyID,
+	}); err != nil {
+		return 0, 0, err
+	}
 
-func (mr *MaterialsPostgresRepository) DeletePlanningArchive(ctx context.Context, id int64) error {
-	_, err := mr.psql.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", domain.TablePlanningMaterialsArchive), id)
-	return err
-}
+	if _, err = insertStockMovement(ctx, tx, domain.StockMovement{
+		PurchasedMaterialID: targetId,
+		ItemID:              material.ItemID,
+		WarehouseID:         inp.WarehouseID,
+		MovementType:        domain.MovementTransfer,
+		Quantity:            quantity,
+		BalanceAfter:        quantity,
+		Comment:             inp.Comment,
+		UserID:              userId,
+		CompanyID:           material.CompanyID,
+	}); err != nil {
+		return 0, 0, err
+	}
 
-func (mr *MaterialsPostgresRepository) DeletePurchasedArchive(ctx context.Context, id int64) error {
-	_, err := mr.psql.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", domain.TablePurchasedMaterialsArchive), id)
-	return err
-}
+	// 3. пересчитываем заполняемость обоих складов, перемещенный объем проверяем по вместимости склада-получателя.
+	// Склады блокируются в порядке id, чтобы встречные перемещения не блокировали друг друга
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("SELECT id FROM %s WHERE id = ANY($1) ORDER BY id FOR UPDATE",
+		domain.TableWarehouse), pq.Array([]int64{sourceWarehouseId, inp.WarehouseID})); err != nil {
+		return 0, 0, err
+	}
 
-func (mr *MaterialsPostgresRepository) Search(ctx context.Context, param domain.MaterialParams) ([]domain.Material, int64, error) {
-	countQuery := fmt.Sprintf(`
-        SELECT COUNT(*) FROM (
-            SELECT id FROM %s WHERE name ILIKE $1 AND company_id = $2
-            UNION ALL
-            SELECT id FROM %s WHERE name ILIKE $1 AND company_id = $2
-            UNION ALL
-            SELECT id FROM %s WHERE name ILIKE $1 AND company_id = $2
-            UNION ALL
-            SELECT id FROM %s WHERE name ILIKE $1 AND company_id = $2
-        ) AS total_count;
-    `, domain.TablePlanningMaterials, domain.TablePurchasedMaterials, domain.TablePlanningMaterialsArchive, domain.TablePurchasedMaterialsArchive)
-
-	searchPattern := param.Query + "%"
+	if err = recalcWarehouseOccupancy(ctx, tx, sourceWarehouseId); err != nil {
+		return 0, 0, err
+	}
 
-	var totalCount int64
+	volume := material.Volume * quantity
+	if inp.WarehouseID == sourceWarehouseId {
+		volume = 0
+	}
 
-	if err := mr.psql.QueryRowContext(ctx, countQuery, searchPattern, param.CompanyId).Scan(&totalCount); err != nil {
-		return nil, 0, err
+	if err = checkWarehouseCapacity(ctx, tx, inp.WarehouseID, volume); err != nil {
+		return 0, 0, err
 	}
 
-	sqlQuery := fmt.Sprintf(`
-        SELECT 'planning_materials' AS table_name, id, warehouse_id, name, product_category, unit, total_quantity, status, company_id 
-        FROM %s
-        WHERE name ILIKE $1 AND company_id = $2
+	return targetId, material.ItemID, tx.Commit()
+}
 
-        UNION ALL
+func (mr *MaterialsPostgresRepository) GetPlanningArchiveById(ctx context.Context, id int64) (domain.Material, error) {
+	query := fmt.Sprintf("SELECT id, %s FROM %s WHERE id = $1", planningMaterialColumns, domain.TablePlanningMaterialsArchive)
 
-        SELECT 'purchased_materials' AS table_name, id, warehouse_id, name, product_category, unit, total_quantity, status, company_id
-        FROM %s
-        WHERE name ILIKE $1 AND company_id = $2
+	var material domain.Material
+	if err := scanMaterial(mr.psql.QueryRowContext(ctx, query, id), &material, &material.ReceivedQuantity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Material{}, domain.ErrMaterialNotFound
+		}
 
-        UNION ALL
+		return domain.Material{}, err
+	}
 
-        SELECT 'planning_materials_archive' AS table_name, id, warehouse_id, name, product_category, unit, total_quantity, status, company_id
-        FROM %s
-        WHERE name ILIKE $1 AND company_id = $2
+	return withCategories(ctx, mr.psql, domain.TablePlanningMaterialsArchive, material)
+}
 
-        UNION ALL
+func (mr *MaterialsPostgresRepository) GetPurchasedArchiveById(ctx context.Context, id int64) (domain.Material, error) {
+	return mr.getPurchasedArchiveById(ctx, mr.psql, id)
+}
 
-        SELECT 'purchased_materials_archive' AS table_name, id, warehouse_id, name, product_category, unit, total_quantity, status, company_id
-        FROM %s
-        WHERE name ILIKE $1 AND company_id = $2
+func (mr *MaterialsPostgresRepository) getPurchasedArchiveById(ctx context.Context, q querier, id int64) (domain.Material, error) {
+	query := fmt.Sprintf("SELECT id, %s FROM %s WHERE id = $1", materialColumns, domain.TablePurchasedMaterialsArchive)
 
-        ORDER BY %s %s
-        LIMIT $3 OFFSET $4;
-    `, domain.TablePlanningMaterials, domain.TablePurchasedMaterials, domain.TablePlanningMaterialsArchive, domain.TablePurchasedMaterialsArchive, param.SortField, param.Sort)
+	var material domain.Material
+	if err := scanMaterial(q.QueryRowContext(ctx, query, id), &material); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Material{}, domain.ErrMaterialNotFound
+		}
 
-	rows, err := mr.psql.QueryContext(ctx, sqlQuery, searchPattern, param.CompanyId, param.Limit, param.Offset)
-	if err != nil {
-		return nil, 0, err
+		return domain.Material{}, err
 	}
-	defer func(rows *sql.Rows) {
-		if err = rows.Close(); err != nil {
-			return
-		}
-	}(rows)
 
-	var materials []domain.Material
+	return withCategories(ctx, q, domain.TablePurchasedMaterialsArchive, material)
+}
 
-	for rows.Next() {
-		var tableName string //todo подумать надо этим, может возвращать отдельно если потребуется
-		var m domain.Material
-		err := rows.Scan(&tableName, &m.ID, &m.WarehouseID, &m.Name, &m.ProductCategory, &m.Unit, &m.TotalQuantity, &m.Status, &m.CompanyID)
-		if err != nil {
+func (mr *MaterialsPostgresRepository) GetPlanningArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
+	var totalCount int64
+
+	where, args := materialListConditions(domain.TablePlanningMaterialsArchive, params)
+
+	// при переходе по курсору общее количество не подсчитывается
+	if params.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE %s
+		`, domain.TablePlanningMaterialsArchive, where)
+
+		if err := mr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
 			return nil, 0, err
 		}
+	}
 
-		materials = append(materials, m)
+	where, args, offset, err := applyCursor(where, args, params.Cursor, params.Offset)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if err = rows.Err(); err != nil {
+	query := fmt.Sprintf(`
+	SELECT id, %s
+	FROM %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d
+	`, planningMaterialColumns, domain.TablePlanningMaterialsArchive, where, listOrder(params.SortField, params.Sort), len(args)+1, len(args)+2)
+
+	materials, err := queryPlanningMaterials(ctx, mr.psql, query, append(args, params.Limit, offset)...)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	return materials, totalCount, nil
+	return materials, totalCount, loadMaterialCategories(ctx, mr.psql, domain.TablePlanningMaterialsArchive, materials)
 }
 
-func (mr *MaterialsPostgresRepository) GetIncomeHistoryByWarehouseId(ctx context.Context, id int64, params domain.Param) ([]domain.Material, int64, error) {
+func (mr *MaterialsPostgresRepository) GetPurchasedArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1 AND warehouse_id = $2
-	`, domain.TablePurchasedMaterials)
+	where, args := materialListConditions(domain.TablePurchasedMaterialsArchive, params)
 
-	err := mr.psql.QueryRowContext(ctx, countQuery, params.CompanyId, id).Scan(&totalCount)
+	// при переходе по курсору общее количество не подсчитывается
+	if params.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE %s
+		`, domain.TablePurchasedMaterialsArchive, where)
+
+		if err := mr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	where, args, offset, err := applyCursor(where, args, params.Cursor, params.Offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	query := fmt.Sprintf(`
-	SELECT 
-	    id, warehouse_id, item_id, name, by_invoice, article, product_category, unit, total_quantity, volume,
-		price_without_vat, total_without_vat, supplier_id, location, contract_date, file, status, comments, reserve,
-		received_date, last_updated, min_stock_level, expiration_date, responsible_person, storage_cost,
-		warehouse_section, incoming_delivery_number, other_fields, company_id, internal_name, units_per_package, 
-		supplier_name, contract_number
-	FROM %s WHERE company_id = $1 AND warehouse_id = $2 ORDER BY received_date %s LIMIT $3 OFFSET $4
-	`, domain.TablePurchasedMaterials, params.Sort)
+	SELECT id, %s
+	FROM %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d
+	`, materialColumns, domain.TablePurchasedMaterialsArchive, where, listOrder(params.SortField, params.Sort), len(args)+1, len(args)+2)
 
-	rows, err := mr.psql.QueryContext(ctx, query, params.CompanyId, id, params.Limit, params.Offset)
+	rows, err := mr.psql.QueryContext(ctx, query, append(args, params.Limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -861,27 +929,742 @@ func (mr *MaterialsPostgresRepository) GetIncomeHistoryByWarehouseId(ctx context
 
 	for rows.Next() {
 		var material domain.Material
-		var otherFieldsJSON []byte
-
-		if err = rows.Scan(
-			&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
-			pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
-			&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
-			&material.ContractDate, &material.File, &material.Status, &material.Comments, &material.Reserve,
-			&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
-			&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
-			&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
-			&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber,
-		); err != nil {
-			return nil, 0, err
-		}
-
-		if err = json.Unmarshal(otherFieldsJSON, &material.OtherFields); err != nil {
+		if err = scanMaterial(rows, &material); err != nil {
 			return nil, 0, err
 		}
 
 		materials = append(materials, material)
 	}
 
-	return materials, totalCount, nil
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return materials, totalCount, loadMaterialCategories(ctx, mr.psql, domain.TablePurchasedMaterialsArchive, materials)
+}
+
+func (mr *MaterialsPostgresRepository) DeletePlanningArchive(ctx context.Context, id int64) error {
+	_, err := mr.psql.ExecContext(ctx, deleteMaterialQuery(domain.TablePlanningMaterialsArchive), id)
+	return err
+}
+
+func (mr *MaterialsPostgresRepository) DeletePurchasedArchive(ctx context.Context, id int64) error {
+	_, err := mr.psql.ExecContext(ctx, deleteMaterialQuery(domain.TablePurchasedMaterialsArchive), id)
+	return err
+}
+
+// RestorePlanningArchive возвращает планируемый материал из архива в planning_materials с сохранением item_id
+// и принятого количества. Возвращает новый id материала
+func (mr *MaterialsPostgresRepository) RestorePlanningArchive(ctx context.Context, id int64) (int64, error) {
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockMaterial(ctx, tx, domain.TablePlanningMaterialsArchive, id); err != nil {
+		return 0, err
+	}
+
+	// 1. переносим строку в planning
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		SELECT %s FROM %s WHERE id = $1
+		RETURNING id`,
+		domain.TablePlanningMaterials, planningMaterialColumns, planningMaterialColumns,
+		domain.TablePlanningMaterialsArchive)
+
+	var newId int64
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&newId); err != nil {
+		return 0, fmt.Errorf("failed to restore planning material from archive: %v", err)
+	}
+
+	if err = copyMaterialCategories(ctx, tx, domain.TablePlanningMaterialsArchive, id,
+		domain.TablePlanningMaterials, newId); err != nil {
+		return 0, err
+	}
+
+	// 2. удаляем из planning archive
+	query = deleteMaterialQuery(domain.TablePlanningMaterialsArchive)
+
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return 0, err
+	}
+
+	return newId, tx.Commit()
+}
+
+// RestorePurchasedArchive возвращает закупленный материал из архива в purchased_materials с сохранением item_id.
+// Остаток новой строки фиксируется в журнале движений как поступление. Возвращает новый id материала
+func (mr *MaterialsPostgresRepository) RestorePurchasedArchive(ctx context.Context, id, userId int64) (int64, error) {
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockMaterial(ctx, tx, domain.TablePurchasedMaterialsArchive, id); err != nil {
+		return 0, err
+	}
+
+	material, err := mr.getPurchasedArchiveById(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	material.LastUpdated = time.Now().UTC()
+
+	// 1. переносим строку в purchased
+	newId, err := insertMaterial(ctx, tx, domain.TablePurchasedMaterials, material)
+	if err != nil {
+		return 0, err
+	}
+
+	// 2. удаляем из purchased archive
+	query := deleteMaterialQuery(domain.TablePurchasedMaterialsArchive)
+
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return 0, err
+	}
+
+	// 3. журнал движений ведется по id строки, поэтому остаток восстановленной строки оформляем поступлением
+	if material.TotalQuantity != 0 {
+		if _, err = insertStockMovement(ctx, tx, domain.StockMovement{
+			PurchasedMaterialID: newId,
+			ItemID:              material.ItemID,
+			WarehouseID:         material.WarehouseID,
+			MovementType:        domain.MovementReceipt,
+			Quantity:            material.TotalQuantity,
+			BalanceAfter:        material.TotalQuantity,
+			Comment:             fmt.Sprintf("Восстановление из архива #%d", id),
+			UserID:              userId,
+			CompanyID:           material.CompanyID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	// 4. восстановленный материал снова занимает место на складе
+	if err = checkWarehouseCapacity(ctx, tx, material.WarehouseID, material.Volume*material.TotalQuantity); err != nil {
+		return 0, err
+	}
+
+	return newId, tx.Commit()
+}
+
+func (mr *MaterialsPostgresRepository) GetIncomeHistoryByWarehouseId(ctx context.Context, id int64, params domain.Param) ([]domain.Material, int64, error) {
+	var totalCount int64
+
+	countQuery := fmt.Sprintf(`
+	SELECT COUNT(*)
+	FROM %s
+	WHERE company_id = $1 AND warehouse_id = $2
+	`, domain.TablePurchasedMaterials)
+
+	err := mr.psql.QueryRowContext(ctx, countQuery, params.CompanyId, id).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, %s
+	FROM %s p WHERE company_id = $1 AND warehouse_id = $2 ORDER BY received_date %s LIMIT $3 OFFSET $4
+	`, purchasedMaterialColumns, domain.TablePurchasedMaterials, params.Sort)
+
+	materials, err := queryPurchasedMaterials(ctx, mr.psql, query, params.CompanyId, id, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return materials, totalCount, loadMaterialCategories(ctx, mr.psql, domain.TablePurchasedMaterials, materials)
+}
+
+// GetPurchasedByLocationId возвращает закупленные материалы компании, размещенные в месте хранения и во всех
+// вложенных в него местах хранения
+func (mr *MaterialsPostgresRepository) GetPurchasedByLocationId(ctx context.Context, locationId int64, params domain.Param) ([]domain.Material, int64, error) {
+	var totalCount int64
+
+	where := fmt.Sprintf("company_id = $1 AND location_id IN (%s)", locationSubtreeQuery(2))
+	args := []any{params.CompanyId, locationId}
+
+	// при переходе по курсору общее количество не подсчитывается
+	if params.Cursor == nil {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", domain.TablePurchasedMaterials, where)
+
+		if err := mr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	where, args, offset, err := applyCursor(where, args, params.Cursor, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT id, %s FROM %s p WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d",
+		purchasedMaterialColumns, domain.TablePurchasedMaterials, where, listOrder(params.SortField, params.Sort),
+		len(args)+1, len(args)+2)
+
+	materials, err := queryPurchasedMaterials(ctx, mr.psql, query, append(args, params.Limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return materials, totalCount, loadMaterialCategories(ctx, mr.psql, domain.TablePurchasedMaterials, materials)
+}
+
+func (mr *MaterialsPostgresRepository) GetLowStockByWarehouseId(ctx context.Context, id int64, params domain.Param) ([]domain.LowStockMaterial, int64, error) {
+	lowStock := lowStockQuery("p.company_id = $1 AND p.warehouse_id = $2")
+
+	var totalCount int64
+	if err := mr.psql.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) ls", lowStock),
+		params.CompanyId, id).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM (%s) ls ORDER BY shortage DESC, item_id LIMIT $3 OFFSET $4", lowStock)
+
+	materials, err := mr.queryLowStock(ctx, query, params.CompanyId, id, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return materials, totalCount, nil
+}
+
+func (mr *MaterialsPostgresRepository) GetLowStock(ctx context.Context) ([]domain.LowStockMaterial, error) {
+	query := fmt.Sprintf("SELECT * FROM (%s) ls ORDER BY company_id, warehouse_id, item_id", lowStockQuery("TRUE"))
+
+	return mr.queryLowStock(ctx, query)
+}
+
+func (mr *MaterialsPostgresRepository) queryLowStock(ctx context.Context, query string, args ...any) ([]domain.LowStockMaterial, error) {
+	rows, err := mr.psql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var materials []domain.LowStockMaterial
+
+	for rows.Next() {
+		var m domain.LowStockMaterial
+		if err = rows.Scan(
+			&m.ItemID, &m.WarehouseID, &m.WarehouseName, &m.Name, &m.Article, &m.Unit, &m.UnitID, &m.TotalQuantity,
+			&m.ReservedQuantity, &m.MinStockLevel, &m.Shortage, &m.ResponsiblePerson, &m.CompanyID,
+		); err != nil {
+			return nil, err
+		}
+
+		materials = append(materials, m)
+	}
+
+	return materials, rows.Err()
+}
+
+func (mr *MaterialsPostgresRepository) GetExpiringPurchased(ctx context.Context, companyId, warehouseId int64, before time.Time) ([]domain.ExpiringWarehouse, error) {
+	query := fmt.Sprintf(`
+	SELECT p.warehouse_id, COALESCE(w.name, ''), p.id, p.item_id, COALESCE(p.name, ''), COALESCE(p.article, ''),
+	       COALESCE(p.unit, ''), COALESCE(p.total_quantity, 0),
+	       (SELECT COALESCE(SUM(r.quantity), 0) FROM %s r WHERE r.purchased_material_id = p.id AND %s),
+	       p.expiration_date, p.expiration_date - CURRENT_DATE, COALESCE(p.status, '')
+	FROM %s p
+	JOIN %s w ON w.id = p.warehouse_id
+	WHERE p.company_id = $1 AND ($2 = 0 OR p.warehouse_id = $2) AND %s AND p.expiration_date <= $3
+	ORDER BY p.warehouse_id, p.expiration_date, p.id
+	`, domain.TableReservations, activeReservationCondition, domain.TablePurchasedMaterials, domain.TableWarehouse,
+		expirableLotCondition)
+
+	rows, err := mr.psql.QueryContext(ctx, query, companyId, warehouseId, before)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var warehouses []domain.ExpiringWarehouse
+
+	for rows.Next() {
+		var wh domain.ExpiringWarehouse
+		var lot domain.ExpiringLot
+
+		if err = rows.Scan(
+			&wh.WarehouseID, &wh.WarehouseName, &lot.PurchasedMaterialID, &lot.ItemID, &lot.Name, &lot.Article,
+			&lot.Unit, &lot.TotalQuantity, &lot.ReservedQuantity, &lot.ExpirationDate, &lot.DaysLeft, &lot.Status,
+		); err != nil {
+			return nil, err
+		}
+
+		lot.IsExpired = lot.DaysLeft < 0
+
+		// строки отсортированы по складу, поэтому новая группа начинается при смене склада
+		if len(warehouses) == 0 || warehouses[len(warehouses)-1].WarehouseID != wh.WarehouseID {
+			warehouses = append(warehouses, wh)
+		}
+
+		last := &warehouses[len(warehouses)-1]
+		last.Lots = append(last.Lots, lot)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (mr *MaterialsPostgresRepository) GetFefoCandidates(ctx context.Context, params domain.FefoParams) ([]domain.FefoPick, error) {
+	query := fmt.Sprintf(`
+	SELECT id, warehouse_id, COALESCE(location, ''), COALESCE(warehouse_section, ''), expiration_date, available
+	FROM (
+		SELECT p.id, p.warehouse_id, p.location, p.warehouse_section, p.expiration_date, p.received_date,
+		       COALESCE(p.total_quantity, 0) - (SELECT COALESCE(SUM(r.quantity), 0) FROM %s r
+		                                        WHERE r.purchased_material_id = p.id AND %s) AS available
+		FROM %s p
+		WHERE p.company_id = $1
+		  AND (CASE WHEN $2 <> 0 THEN p.item_id = $2 ELSE p.article = $3 END)
+		  AND ($4 = 0 OR p.warehouse_id = $4)
+		  AND COALESCE(p.status, '') <> '%s'
+		  AND (p.expiration_date IS NULL OR p.expiration_date <= '0001-01-01' OR p.expiration_date >= CURRENT_DATE)
+	) lots
+	WHERE available > 0
+	ORDER BY CASE WHEN expiration_date IS NULL OR expiration_date <= '0001-01-01' THEN 1 ELSE 0 END,
+	         expiration_date, received_date, id
+	`, domain.TableReservations, activeReservationCondition, domain.TablePurchasedMaterials,
+		domain.MaterialStatusWrittenOff)
+
+	rows, err := mr.psql.QueryContext(ctx, query, params.CompanyID, params.ItemID, params.Article, params.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var picks []domain.FefoPick
+
+	for rows.Next() {
+		var pick domain.FefoPick
+		var expirationDate sql.NullTime
+
+		if err = rows.Scan(
+			&pick.PurchasedMaterialID, &pick.WarehouseID, &pick.Location, &pick.WarehouseSection, &expirationDate,
+			&pick.AvailableQuantity,
+		); err != nil {
+			return nil, err
+		}
+
+		pick.ExpirationDate = expirationDate.Time
+		picks = append(picks, pick)
+	}
+
+	return picks, rows.Err()
+}
+
+func (mr *MaterialsPostgresRepository) GetExpiredPurchasedIds(ctx context.Context) ([]int64, error) {
+	query := fmt.Sprintf(`
+	SELECT p.id FROM %s p WHERE %s AND p.expiration_date < CURRENT_DATE ORDER BY p.id
+	`, domain.TablePurchasedMaterials, expirableLotCondition)
+
+	rows, err := mr.psql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (mr *MaterialsPostgresRepository) WriteOffPurchased(ctx context.Context, id, userId int64, comment string) error {
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockMaterial(ctx, tx, domain.TablePurchasedMaterials, id); err != nil {
+		return err
+	}
+
+	material, err := mr.getPurchasedById(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	// 1. списанную партию нельзя отпустить, поэтому резервы по ней снимаются
+	if err = releaseReservations(ctx, tx, id); err != nil {
+		return err
+	}
+
+	// 2. списываем весь остаток с записью в журнал движений
+	if material.TotalQuantity > 0 {
+		if _, err = applyStockMovement(ctx, tx, domain.StockMovement{
+			PurchasedMaterialID: id,
+			MovementType:        domain.MovementWriteOff,
+			Quantity:            -material.TotalQuantity,
+			Comment:             comment,
+			UserID:              userId,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 3. помечаем партию списанной
+	query := fmt.Sprintf("UPDATE %s SET status = $1, total_without_vat = 0, last_updated = $2 WHERE id = $3",
+		domain.TablePurchasedMaterials)
+
+	if _, err = tx.ExecContext(ctx, query, domain.MaterialStatusWrittenOff, time.Now().UTC(), id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lowStockQuery возвращает запрос остатков по товарам на складах, опустившихся ниже минимального уровня запаса.
+// Остаток суммируется по всем партиям товара на складе, количества партий приводятся к базовой единице измерения
+func lowStockQuery(condition string) string {
+	return fmt.Sprintf(`
+	SELECT p.item_id, p.warehouse_id, COALESCE(w.name, ''), MAX(COALESCE(p.name, '')), MAX(COALESCE(p.article, '')),
+	       MAX(COALESCE(bu.abbreviation, p.unit, '')), MAX(COALESCE(bu.id, 0)),
+	       SUM(COALESCE(p.total_quantity, 0) * %[6]s) AS total_quantity,
+	       SUM(COALESCE(res.quantity, 0) * %[6]s) AS reserved_quantity,
+	       MAX(COALESCE(p.min_stock_level, 0) * %[6]s) AS min_stock_level,
+	       MAX(COALESCE(p.min_stock_level, 0) * %[6]s) - SUM(COALESCE(p.total_quantity, 0) * %[6]s) AS shortage,
+	       COALESCE(w.responsible_person, 0), p.company_id
+	FROM %[1]s p
+	JOIN %[2]s w ON w.id = p.warehouse_id
+	LEFT JOIN %[7]s u ON u.id = p.unit_id
+	LEFT JOIN %[7]s bu ON bu.id = COALESCE(u.base_unit_id, u.id)
+	LEFT JOIN (SELECT r.purchased_material_id, SUM(r.quantity) AS quantity
+	           FROM %[3]s r WHERE %[4]s GROUP BY r.purchased_material_id) res ON res.purchased_material_id = p.id
+	WHERE %[5]s
+	GROUP BY p.item_id, p.warehouse_id, w.name, w.responsible_person, p.company_id
+	HAVING MAX(COALESCE(p.min_stock_level, 0)) > 0
+	   AND SUM(COALESCE(p.total_quantity, 0) * %[6]s) < MAX(COALESCE(p.min_stock_level, 0) * %[6]s)`,
+		domain.TablePurchasedMaterials, domain.TableWarehouse, domain.TableReservations, activeReservationCondition,
+		condition, unitFactorExpr, domain.UnitsOfMeasureTable)
+}
+
+// withCategories заполняет CategoryIDs материала, выбранного из указанной таблицы
+func withCategories(ctx context.Context, q querier, table string, material domain.Material) (domain.Material, error) {
+	materials := []domain.Material{material}
+	if err := loadMaterialCategories(ctx, q, table, materials); err != nil {
+		return domain.Material{}, err
+	}
+
+	return materials[0], nil
+}
+
+// deleteMaterialQuery возвращает запрос удаления строки материала $1 из таблицы вместе с ее связями с категориями
+func deleteMaterialQuery(table string) string {
+	return fmt.Sprintf(`
+		WITH deleted AS (DELETE FROM %s WHERE id = $1 RETURNING id)
+		DELETE FROM %s WHERE material_type = '%s' AND material_id IN (SELECT id FROM deleted)`,
+		table, domain.TableMaterialCategoryLinks, table)
+}
+
+// lockMaterial блокирует строку материала до конца транзакции
+func lockMaterial(ctx context.Context, tx *sql.Tx, table string, id int64) error {
+	var lockedId int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT id FROM %s WHERE id = $1 FOR UPDATE", table), id).
+		Scan(&lockedId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrMaterialNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// materialArgs возвращает значения полей материала в порядке materialColumns
+func materialArgs(material domain.Material) ([]any, error) {
+	otherFieldsJSON, err := json.Marshal(material.OtherFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal other_fields to JSON: %v", err)
+	}
+
+	return []any{
+		material.WarehouseID, material.ItemID, material.Name, material.ByInvoice, material.Article, pq.Array(material.ProductCategory),
+		material.Unit, material.TotalQuantity, material.Volume, material.PriceWithoutVAT, material.TotalWithoutVAT,
+		material.SupplierID, material.Location, material.ContractDate, material.File, material.Status, material.Comments,
+		material.ReceivedDate, material.LastUpdated, material.MinStockLevel, material.ExpirationDate,
+		material.ResponsiblePerson, material.StorageCost, material.WarehouseSection, material.IncomingDeliveryNumber,
+		otherFieldsJSON, material.CompanyID, material.InternalName, material.UnitsPerPackage, material.SupplierName,
+		material.ContractNumber, sql.NullInt64{Int64: material.UnitID, Valid: material.UnitID != 0},
+		sql.NullInt64{Int64: material.LocationID, Valid: material.LocationID != 0}, material.VATRate, material.Currency,
+	}, nil
+}
+
+// materialListConditions строит условие WHERE списка материалов компании из таблицы table по фильтрам params.Filter.
+// Все значения фильтров передаются параметрами запроса, в текст запроса попадают только номера параметров
+func materialListConditions(table string, params domain.MaterialParams) (string, []any) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	f := params.Filter
+
+	addCondition("company_id = $%d", params.CompanyId)
+
+	if f.WarehouseId != 0 {
+		addCondition("warehouse_id = $%d", f.WarehouseId)
+	}
+
+	if f.SupplierId != 0 {
+		addCondition("supplier_id = $%d", f.SupplierId)
+	}
+
+	// категория учитывается вместе со всеми вложенными в нее категориями
+	if len(f.Categories) > 0 || len(f.CategoryIds) > 0 {
+		names := make([]string, 0, len(f.Categories))
+		for _, name := range f.Categories {
+			names = append(names, strings.ToLower(name))
+		}
+
+		args = append(args, table, pq.Array(append([]int64{}, f.CategoryIds...)), pq.Array(names))
+		conditions = append(conditions, fmt.Sprintf(
+			"id IN (SELECT l.material_id FROM %s l WHERE l.material_type = $%d AND l.category_id IN (%s))",
+			domain.TableMaterialCategoryLinks, len(args)-2, categorySubtreeQuery(1, len(args)-1, len(args))))
+	}
+
+	if len(f.Statuses) > 0 {
+		addCondition("status = ANY($%d)", pq.Array(f.Statuses))
+	}
+
+	if !f.ReceivedFrom.IsZero() {
+		addCondition("received_date >= $%d", f.ReceivedFrom)
+	}
+
+	if !f.ReceivedTo.IsZero() {
+		addCondition("received_date <= $%d", f.ReceivedTo)
+	}
+
+	if !f.ContractFrom.IsZero() {
+		addCondition("contract_date >= $%d", f.ContractFrom)
+	}
+
+	if !f.ContractTo.IsZero() {
+		addCondition("contract_date <= $%d", f.ContractTo)
+	}
+
+	if !f.ExpirationFrom.IsZero() {
+		addCondition("expiration_date >= $%d", f.ExpirationFrom)
+	}
+
+	if !f.ExpirationTo.IsZero() {
+		addCondition("expiration_date <= $%d", f.ExpirationTo)
+	}
+
+	if f.QuantityMin != nil {
+		addCondition("total_quantity >= $%d", *f.QuantityMin)
+	}
+
+	if f.QuantityMax != nil {
+		addCondition("total_quantity <= $%d", *f.QuantityMax)
+	}
+
+	if f.PriceMin != nil {
+		addCondition("price_without_vat >= $%d", *f.PriceMin)
+	}
+
+	if f.PriceMax != nil {
+		addCondition("price_without_vat <= $%d", *f.PriceMax)
+	}
+
+	// ключи сортируются, чтобы текст запроса не зависел от порядка обхода map
+	keys := make([]string, 0, len(f.OtherFields))
+	for key := range f.OtherFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		args = append(args, key, f.OtherFields[key])
+		conditions = append(conditions, fmt.Sprintf("other_fields ->> $%d = $%d", len(args)-1, len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// scanMaterial считывает строку, выбранную как "id, " + materialColumns, дополнительные колонки читаются в extra
+func scanMaterial(row scanner, material *domain.Material, extra ...any) error {
+	var otherFieldsJSON []byte
+	var unitId, locationId sql.NullInt64
+
+	dest := []any{
+		&material.ID, &material.WarehouseID, &material.ItemID, &material.Name, &material.ByInvoice, &material.Article,
+		pq.Array(&material.ProductCategory), &material.Unit, &material.TotalQuantity, &material.Volume,
+		&material.PriceWithoutVAT, &material.TotalWithoutVAT, &material.SupplierID, &material.Location,
+		&material.ContractDate, &material.File, &material.Status, &material.Comments,
+		&material.ReceivedDate, &material.LastUpdated, &material.MinStockLevel, &material.ExpirationDate,
+		&material.ResponsiblePerson, &material.StorageCost, &material.WarehouseSection,
+		&material.IncomingDeliveryNumber, &otherFieldsJSON, &material.CompanyID, &material.InternalName,
+		&material.UnitsPerPackage, &material.SupplierName, &material.ContractNumber, &unitId, &locationId,
+		&material.VATRate, &material.Currency,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	material.UnitID = unitId.Int64
+	material.LocationID = locationId.Int64
+	material.CalculateDerived()
+
+	return json.Unmarshal(otherFieldsJSON, &material.OtherFields)
+}
+
+// scanPurchasedMaterial считывает строку, выбранную как "id, " + purchasedMaterialColumns
+func scanPurchasedMaterial(row scanner) (domain.Material, error) {
+	var material domain.Material
+	if err := scanMaterial(row, &material, &material.ReservedQuantity); err != nil {
+		return domain.Material{}, err
+	}
+
+	material.AvailableQuantity = material.TotalQuantity - material.ReservedQuantity
+
+	return material, nil
+}
+
+// queryPlanningMaterials выполняет выборку колонок "id, " + planningMaterialColumns
+func queryPlanningMaterials(ctx context.Context, q querier, query string, args ...any) ([]domain.Material, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var materials []domain.Material
+
+	for rows.Next() {
+		var material domain.Material
+		if err = scanMaterial(rows, &material, &material.ReceivedQuantity); err != nil {
+			return nil, err
+		}
+
+		materials = append(materials, material)
+	}
+
+	return materials, rows.Err()
+}
+
+// queryPurchasedMaterials выполняет выборку колонок "id, " + purchasedMaterialColumns
+func queryPurchasedMaterials(ctx context.Context, q querier, query string, args ...any) ([]domain.Material, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var materials []domain.Material
+
+	for rows.Next() {
+		material, err := scanPurchasedMaterial(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		materials = append(materials, material)
+	}
+
+	return materials, rows.Err()
+}
+
+// insertMaterial добавляет материал в указанную таблицу, сохраняя его item_id и категории
+func insertMaterial(ctx context.Context, q querier, table string, material domain.Material) (int64, error) {
+	args, err := materialArgs(material)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING id", table, materialColumns, placeholders(1, len(args)))
+
+	var id int64
+	if err = q.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert material into %s: %v", table, err)
+	}
+
+	if err = saveMaterialCategories(ctx, q, table, id, material.CategoryIDs); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// updateMaterial перезаписывает все колонки и категории материала в указанной таблице
+func updateMaterial(ctx context.Context, q querier, table string, material domain.Material) error {
+	args, err := materialArgs(material)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET (%s) = (%s) WHERE id = $%d",
+		table, materialColumns, placeholders(1, len(args)), len(args)+1)
+
+	if _, err = q.ExecContext(ctx, query, append(args, material.ID)...); err != nil {
+		return err
+	}
+
+	return saveMaterialCategories(ctx, q, table, material.ID, material.CategoryIDs)
+}
+
+// placeholders возвращает список параметров запроса вида "$1, $2, ..., $n"
+func placeholders(from, n int) string {
+	var sb strings.Builder
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		sb.WriteString("$" + strconv.Itoa(from+i))
+	}
+
+	return sb.String()
 }
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type bulkOperation func(tx *sql.Tx, i int) (int64, int64, error)
+
+// runBulk выполняет n операций пакета в одной транзакции. В атомарном режиме первая ошибка откатывает весь пакет,
+// в режиме best effort каждая операция выполняется в своей точке сохранения и ошибка откатывает только ее.
+// Возвращает результаты по операциям и признак сохранения транзакции
+func runBulk(ctx context.Context, psql *sql.DB, n int, atomic bool, op bulkOperation) ([]domain.BulkItemResult, bool, error) {
+	results := make([]domain.BulkItemResult, n)
+	for i := range results {
+		results[i] = domain.BulkItemResult{Index: i, Status: domain.BulkItemSkipped}
+	}
+
+	tx, err := psql.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	for i := 0; i < n; i++ {
+		if !atomic {
+			if _, err = tx.ExecContext(ctx, "SAVEPOINT bulk_item"); err != nil {
+				return nil, false, err
+			}
+		}
+
+		id, itemId, opErr := op(tx, i)
+		if opErr != nil {
+			results[i].Status = domain.BulkItemFailed
+			results[i].Error = opErr.Error()
+
+			if atomic {
+				// уже выполненные операции откатываются вместе с транзакцией
+				for j := 0; j < i; j++ {
+					results[j] = domain.BulkItemResult{Index: j, Status: domain.BulkItemSkipped}
+				}
+
+				return results, false, nil
+			}
+
+			if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_item"); err != nil {
+				return nil, false, err
+			}
+
+			continue
+		}
+
+		if !atomic {
+			if _, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_item"); err != nil {
+				return nil, false, err
+			}
+		}
+
+		results[i] = domain.BulkItemResult{Index: i, ID: id, ItemID: itemId, Status: domain.BulkItemSucceeded}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	return results, true, nil
+}
+
+func (mr *MaterialsPostgresRepository) BulkCreatePlanning(ctx context.Context, materials []domain.Material, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(materials), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		return createPlanning(ctx, tx, materials[i])
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkUpdatePlanning(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(materials), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		if err := mr.updatePlanning(ctx, tx, materials[i], userId); err != nil {
+			return 0, 0, err
+		}
+
+		return materials[i].ID, materials[i].ItemID, nil
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkDeletePlanning(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(ids), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		query := deleteMaterialQuery(domain.TablePlanningMaterials)
+
+		if _, err := tx.ExecContext(ctx, query, ids[i]); err != nil {
+			return 0, 0, err
+		}
+
+		return ids[i], 0, nil
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkMovePlanningToPurchased(ctx context.Context, ids []int64, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(ids), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		return mr.receivePlanning(ctx, tx, domain.ReceivePlanningMaterial{ID: ids[i]}, userId)
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkCreatePurchased(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(materials), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		return createPurchased(ctx, tx, materials[i], userId)
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkUpdatePurchased(ctx context.Context, updates []domain.MaterialUpdate, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(updates), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		material, err := mr.updatePurchased(ctx, tx, updates[i], userId)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return material.ID, material.ItemID, nil
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkDeletePurchased(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(ids), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		if err := deletePurchased(ctx, tx, ids[i]); err != nil {
+			return 0, 0, err
+		}
+
+		return ids[i], 0, nil
+	})
+}
+
+func (mr *MaterialsPostgresRepository) BulkMovePurchasedToArchive(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return runBulk(ctx, mr.psql, len(ids), atomic, func(tx *sql.Tx, i int) (int64, int64, error) {
+		if err := mr.movePurchasedToArchive(ctx, tx, ids[i]); err != nil {
+			return 0, 0, err
+		}
+
+		return ids[i], 0, nil
+	})
+}
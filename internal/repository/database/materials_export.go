@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+// StreamList построчно передает в fn материалы компании из списка или архива с фильтрами и сортировкой params.
+// Материалы не накапливаются в памяти, поэтому метод подходит для выгрузки больших списков
+func (mr *MaterialsPostgresRepository) StreamList(ctx context.Context, materialType string, archive bool, params domain.MaterialParams, fn func(domain.Material) error) error {
+	var (
+		table   string
+		columns string
+		scan    func(row scanner) (domain.Material, error)
+	)
+
+	switch {
+	case materialType == domain.MaterialTypePurchased && archive:
+		table, columns = domain.TablePurchasedMaterialsArchive, materialColumns
+		scan = func(row scanner) (domain.Material, error) {
+			var material domain.Material
+			err := scanMaterial(row, &material)
+			return material, err
+		}
+	case materialType == domain.MaterialTypePurchased:
+		table, columns, scan = domain.TablePurchasedMaterials, purchasedMaterialColumns, scanPurchasedMaterial
+	default:
+		table, columns = domain.TablePlanningMaterials, planningMaterialColumns
+		if archive {
+			table = domain.TablePlanningMaterialsArchive
+		}
+
+		scan = func(row scanner) (domain.Material, error) {
+			var material domain.Material
+			err := scanMaterial(row, &material, &material.ReceivedQuantity)
+			return material, err
+		}
+	}
+
+	where, args := materialListConditions(table, params)
+
+	query := fmt.Sprintf(`
+	SELECT id, %s
+	FROM %s p WHERE %s ORDER BY %s %s, id
+	`, columns, table, where, params.SortField, params.Sort)
+
+	rows, err := mr.psql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	for rows.Next() {
+		material, err := scan(rows)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(material); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
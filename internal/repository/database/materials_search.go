@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"strings"
+)
+
+// materialSearchConfig конфигурация полнотекстового поиска, латиница обрабатывается английским стеммером
+const materialSearchConfig = "russian"
+
+// materialSearchDocument текст материала, по которому выполняется поиск. Выражение совпадает с индексами
+// из миграции 000010_material_search, иначе планировщик не сможет их использовать
+const materialSearchDocument = `(COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+	COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, ''))`
+
+// materialSearchSupplier наименование поставщика из справочника, если в материале оно не заполнено
+const materialSearchSupplier = `COALESCE((SELECT s.name FROM ` + domain.TableSupplier + ` s WHERE s.id = p.supplier_id), '')`
+
+// materialSearchHeadlineOptions параметры выделения совпадений в найденном тексте
+const materialSearchHeadlineOptions = "StartSel=<b>, StopSel=</b>, MaxFragments=2, MinWords=3, MaxWords=15"
+
+// materialSearchSortFields поля, по которым можно отсортировать результаты поиска помимо релевантности
+var materialSearchSortFields = map[string]bool{
+	"id": true, "warehouse_id": true, "item_id": true, "name": true, "article": true, "product_category": true,
+	"total_quantity": true, "volume": true, "price_without_vat": true, "total_without_vat": true, "supplier_id": true,
+	"location": true, "status": true, "received_date": true, "last_updated": true, "min_stock_level": true,
+	"expiration_date": true, "storage_cost": true, "warehouse_section": true, "incoming_delivery_number": true,
+	"vat_rate": true, "currency": true,
+}
+
+// materialSearchQuery объединяет совпадения из всех таблиц материалов компании. Материал подходит, если совпадают
+// лексемы полнотекстового запроса, запрос похож на слово документа (pg_trgm, устойчиво к опечаткам)
+// или совпадает наименование поставщика из справочника. Параметры: $1 - строка поиска, $2 - компания
+func materialSearchQuery() string {
+	document := materialSearchDocument + ` || ' ' || ` + materialSearchSupplier
+	tables := []string{
+		domain.TablePlanningMaterials,
+		domain.TablePurchasedMaterials,
+		domain.TablePlanningMaterialsArchive,
+		domain.TablePurchasedMaterialsArchive,
+	}
+
+	branches := make([]string, 0, len(tables))
+	for _, table := range tables {
+		branches = append(branches, fmt.Sprintf(`
+		SELECT id, %s, '%s' AS source, %s AS document,
+			ts_rank(to_tsvector('%s', %s), q.tsq) + word_similarity($1, %s) AS rank
+		FROM %s p, q
+		WHERE p.company_id = $2 AND (
+			to_tsvector('%s', %s) @@ q.tsq
+			OR $1 <%% %s
+			OR p.supplier_id IN (
+				SELECT s.id FROM %s s
+				WHERE s.company_id = $2 AND (to_tsvector('%s', s.name) @@ q.tsq OR $1 <%% s.name)
+			)
+		)`,
+			materialColumns, table, document,
+			materialSearchConfig, document, document,
+			table,
+			materialSearchConfig, materialSearchDocument,
+			materialSearchDocument,
+			domain.TableSupplier, materialSearchConfig))
+	}
+
+	return fmt.Sprintf(`
+	WITH q AS (SELECT websearch_to_tsquery('%s', $1) AS tsq),
+	found AS (%s
+	)`, materialSearchConfig, strings.Join(branches, "\n\t\tUNION ALL"))
+}
+
+// Search выполняет полнотекстовый и нечеткий поиск материалов компании по наименованию, внутреннему наименованию,
+// артикулу, поставщику и номеру накладной. По умолчанию результаты упорядочены по релевантности
+func (mr *MaterialsPostgresRepository) Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialSearchResult, int64, error) {
+	search := materialSearchQuery()
+
+	var totalCount int64
+	if err := mr.psql.QueryRowContext(ctx, search+" SELECT COUNT(*) FROM found", param.Query, param.CompanyId).
+		Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	order := "rank DESC"
+	if materialSearchSortFields[param.SortField] {
+		order = fmt.Sprintf("%s %s, rank DESC", param.SortField, param.Sort)
+	}
+
+	query := fmt.Sprintf(`%s
+	SELECT id, %s, source, rank, ts_headline('%s', document, q.tsq, '%s')
+	FROM (SELECT * FROM found ORDER BY %s, source, id LIMIT $3 OFFSET $4) f, q
+	ORDER BY %s, source, id`,
+		search, materialColumns, materialSearchConfig, materialSearchHeadlineOptions, order, order)
+
+	rows, err := mr.psql.QueryContext(ctx, query, param.Query, param.CompanyId, param.Limit, param.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var results []domain.MaterialSearchResult
+
+	for rows.Next() {
+		var r domain.MaterialSearchResult
+		if err = scanMaterial(rows, &r.Material, &r.Source, &r.Rank, &r.Headline); err != nil {
+			return nil, 0, err
+		}
+
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// категории подгружаются отдельно для каждой таблицы, из которой найдены материалы
+	for _, table := range materialTables {
+		var materials []domain.Material
+		for _, r := range results {
+			if r.Source == table {
+				materials = append(materials, r.Material)
+			}
+		}
+
+		if err = loadMaterialCategories(ctx, mr.psql, table, materials); err != nil {
+			return nil, 0, err
+		}
+
+		for i, j := 0, 0; i < len(results); i++ {
+			if results[i].Source == table {
+				results[i].CategoryIDs = materials[j].CategoryIDs
+				j++
+			}
+		}
+	}
+
+	return results, totalCount, nil
+}
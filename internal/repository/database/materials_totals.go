@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+// materialTotalExpr рассчитанная общая стоимость без НДС: цена, умноженная на количество, с округлением до копеек
+var materialTotalExpr = fmt.Sprintf("ROUND(COALESCE(price_without_vat, 0) * COALESCE(total_quantity, 0), %d)",
+	domain.MoneyPlaces)
+
+// RecalculateTotals пересчитывает общую стоимость без НДС во всех таблицах материалов, где она не совпадает с ценой,
+// умноженной на количество. Возвращает количество исправленных строк по таблицам. При dryRun строки только
+// подсчитываются, изменения не сохраняются
+func (mr *MaterialsPostgresRepository) RecalculateTotals(ctx context.Context, dryRun bool) (map[string]int64, error) {
+	tables := []string{
+		domain.TablePlanningMaterials,
+		domain.TablePurchasedMaterials,
+		domain.TablePlanningMaterialsArchive,
+		domain.TablePurchasedMaterialsArchive,
+	}
+
+	tx, err := mr.psql.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	result := make(map[string]int64, len(tables))
+
+	for _, table := range tables {
+		where := fmt.Sprintf("total_without_vat IS DISTINCT FROM %s", materialTotalExpr)
+
+		if dryRun {
+			var count int64
+			query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, where)
+
+			if err = tx.QueryRowContext(ctx, query).Scan(&count); err != nil {
+				return nil, err
+			}
+
+			result[table] = count
+			continue
+		}
+
+		query := fmt.Sprintf("UPDATE %s SET total_without_vat = %s WHERE %s", table, materialTotalExpr, where)
+
+		var res sql.Result
+		if res, err = tx.ExecContext(ctx, query); err != nil {
+			return nil, err
+		}
+
+		if result[table], err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	return result, tx.Commit()
+}
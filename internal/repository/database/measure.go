@@ -14,8 +14,16 @@ type UnitOfMeasure interface {
 	Delete(ctx context.Context, id, companyId int64) error
 	GetById(ctx context.Context, id, companyId int64) (domain.UnitOfMeasure, error)
 	List(ctx context.Context, param domain.Param) ([]domain.UnitOfMeasure, int64, error)
+	GetByAbbreviation(ctx context.Context, abbreviation string, companyId int64) (domain.UnitOfMeasure, error)
+	HasDerivedUnits(ctx context.Context, id int64) (bool, error)
 }
 
+// measureColumns колонки единицы измерения. Порядок совпадает с scanMeasure
+const measureColumns = `id, name, name_en, abbreviation, description, company_id, COALESCE(base_unit_id, 0), conversion_factor`
+
+// unitFactorExpr количество базовых единиц в единице измерения (таблица единиц с псевдонимом u)
+const unitFactorExpr = `CASE WHEN u.base_unit_id IS NULL THEN 1 ELSE u.conversion_factor END`
+
 type UnitOfMeasurePostgresRepository struct {
 	psql *sql.DB
 }
@@ -28,13 +36,13 @@ func NewUnitOfMeasurePostgresRepository(psql *sql.DB) *UnitOfMeasurePostgresRepo
 
 func (umr *UnitOfMeasurePostgresRepository) Create(ctx context.Context, m domain.UnitOfMeasure) (int64, error) {
 	query := fmt.Sprintf(`
-		INSERT INTO %s (name, name_en, abbreviation, description, company_id)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		INSERT INTO %s (name, name_en, abbreviation, description, company_id, base_unit_id, conversion_factor)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7) RETURNING id`,
 		domain.UnitsOfMeasureTable)
 
 	var id int64
 	if err := umr.psql.QueryRowContext(ctx, query,
-		m.Name, m.NameEn, m.Abbreviation, m.Description, m.CompanyID,
+		m.Name, m.NameEn, m.Abbreviation, m.Description, m.CompanyID, m.BaseUnitID, m.ConversionFactor,
 	).Scan(&id); err != nil {
 		return 0, fmt.Errorf("failed to insert unit of measure: %v", err)
 	}
@@ -46,12 +54,13 @@ func (umr *UnitOfMeasurePostgresRepository) Update(ctx context.Context, m domain
 	query := fmt.Sprintf(`
 		UPDATE %s 
 		SET
-			name = $1, name_en = $2, abbreviation = $3, description = $4
-		WHERE id = $5 AND company_id = $6`,
+			name = $1, name_en = $2, abbreviation = $3, description = $4, base_unit_id = NULLIF($5, 0),
+			conversion_factor = $6
+		WHERE id = $7 AND company_id = $8`,
 		domain.UnitsOfMeasureTable)
 
 	_, err := umr.psql.ExecContext(ctx, query,
-		m.Name, m.NameEn, m.Abbreviation, m.Description, m.ID, m.CompanyID,
+		m.Name, m.NameEn, m.Abbreviation, m.Description, m.BaseUnitID, m.ConversionFactor, m.ID, m.CompanyID,
 	)
 
 	return err
@@ -65,15 +74,11 @@ func (umr *UnitOfMeasurePostgresRepository) Delete(ctx context.Context, id, comp
 
 func (umr *UnitOfMeasurePostgresRepository) GetById(ctx context.Context, id, companyId int64) (domain.UnitOfMeasure, error) {
 	query := fmt.Sprintf(`
-		SELECT 
-			id, name, name_en, abbreviation, description, company_id
-		FROM %s WHERE id = $1 AND company_id = $2`,
-		domain.UnitsOfMeasureTable)
+		SELECT %s FROM %s WHERE id = $1 AND company_id = $2`,
+		measureColumns, domain.UnitsOfMeasureTable)
 
 	var m domain.UnitOfMeasure
-	if err := umr.psql.QueryRowContext(ctx, query, id, companyId).Scan(
-		&m.ID, &m.Name, &m.NameEn, &m.Abbreviation, &m.Description, &m.CompanyID,
-	); err != nil {
+	if err := scanMeasure(umr.psql.QueryRowContext(ctx, query, id, companyId), &m); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.UnitOfMeasure{}, domain.ErrUnitOfMeasureNotFound
 		}
@@ -99,10 +104,8 @@ func (umr *UnitOfMeasurePostgresRepository) List(ctx context.Context, param doma
 	}
 
 	query := fmt.Sprintf(`
-		SELECT 
-			id, name, name_en, abbreviation, description, company_id
-		FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3`,
-		domain.UnitsOfMeasureTable, param.SortField, param.Sort)
+		SELECT %s FROM %s WHERE company_id = $1 ORDER BY %s %s LIMIT $2 OFFSET $3`,
+		measureColumns, domain.UnitsOfMeasureTable, param.SortField, param.Sort)
 
 	rows, err := umr.psql.QueryContext(ctx, query, param.CompanyId, param.Limit, param.Offset)
 	if err != nil {
@@ -118,9 +121,7 @@ func (umr *UnitOfMeasurePostgresRepository) List(ctx context.Context, param doma
 
 	for rows.Next() {
 		var m domain.UnitOfMeasure
-		if err = rows.Scan(
-			&m.ID, &m.Name, &m.NameEn, &m.Abbreviation, &m.Description, &m.CompanyID,
-		); err != nil {
+		if err = scanMeasure(rows, &m); err != nil {
 			return nil, 0, err
 		}
 
@@ -129,3 +130,40 @@ func (umr *UnitOfMeasurePostgresRepository) List(ctx context.Context, param doma
 
 	return measures, totalCount, nil
 }
+
+// GetByAbbreviation возвращает единицу измерения компании по аббревиатуре без учета регистра
+func (umr *UnitOfMeasurePostgresRepository) GetByAbbreviation(ctx context.Context, abbreviation string, companyId int64) (domain.UnitOfMeasure, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s WHERE LOWER(abbreviation) = LOWER($1) AND company_id = $2 ORDER BY id LIMIT 1`,
+		measureColumns, domain.UnitsOfMeasureTable)
+
+	var m domain.UnitOfMeasure
+	if err := scanMeasure(umr.psql.QueryRowContext(ctx, query, abbreviation, companyId), &m); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.UnitOfMeasure{}, domain.ErrUnitOfMeasureNotFound
+		}
+
+		return domain.UnitOfMeasure{}, err
+	}
+
+	return m, nil
+}
+
+// HasDerivedUnits проверяет, указана ли единица измерения базовой для других единиц
+func (umr *UnitOfMeasurePostgresRepository) HasDerivedUnits(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	if err := umr.psql.QueryRowContext(ctx, fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE base_unit_id = $1)",
+		domain.UnitsOfMeasureTable), id).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// scanMeasure считывает строку, выбранную как measureColumns
+func scanMeasure(row scanner, m *domain.UnitOfMeasure) error {
+	return row.Scan(
+		&m.ID, &m.Name, &m.NameEn, &m.Abbreviation, &m.Description, &m.CompanyID, &m.BaseUnitID, &m.ConversionFactor,
+	)
+}
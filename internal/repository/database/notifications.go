@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
+)
+
+type Notifications interface {
+	CreateIfNotRecent(ctx context.Context, notification domain.Notification, since time.Time) (int64, error)
+	GetListByUserId(ctx context.Context, userId int64, unreadOnly bool, params domain.Param) ([]domain.Notification, int64, error)
+	MarkAsRead(ctx context.Context, id, userId int64) error
+	MarkAllAsRead(ctx context.Context, userId int64) error
+	GetSystemRecipients(ctx context.Context, companyId int64) ([]int64, error)
+}
+
+type NotificationsPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewNotificationsPostgresRepository(psql *sql.DB) *NotificationsPostgresRepository {
+	return &NotificationsPostgresRepository{
+		psql: psql,
+	}
+}
+
+// CreateIfNotRecent создает уведомление, если у пользователя нет непрочитанного уведомления с тем же ключом
+// и такое уведомление не создавалось после since. Возвращает 0, если уведомление пропущено
+func (nr *NotificationsPostgresRepository) CreateIfNotRecent(ctx context.Context, notification domain.Notification, since time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (user_id, company_id, type, title, message, warehouse_id, item_id, dedup_key, is_read, created_at)
+		SELECT $1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, 0), $8, false, $9
+		WHERE NOT EXISTS (
+			SELECT 1 FROM %s WHERE user_id = $1 AND dedup_key = $8 AND (is_read = false OR created_at > $10)
+		)
+		RETURNING id`,
+		domain.TableNotifications, domain.TableNotifications)
+
+	var id int64
+	if err := nr.psql.QueryRowContext(ctx, query,
+		notification.UserID, notification.CompanyID, notification.Type, notification.Title, notification.Message,
+		notification.WarehouseID, notification.ItemID, notification.DedupKey, time.Now().UTC(), since,
+	).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to insert notification: %v", err)
+	}
+
+	return id, nil
+}
+
+func (nr *NotificationsPostgresRepository) GetListByUserId(ctx context.Context, userId int64, unreadOnly bool, params domain.Param) ([]domain.Notification, int64, error) {
+	var totalCount int64
+
+	countQuery := fmt.Sprintf(`
+	SELECT COUNT(*)
+	FROM %s
+	WHERE user_id = $1 AND ($2 = false OR is_read = false)
+	`, domain.TableNotifications)
+
+	err := nr.psql.QueryRowContext(ctx, countQuery, userId, unreadOnly).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT
+	    id, user_id, company_id, type, title, message, COALESCE(warehouse_id, 0), COALESCE(item_id, 0), is_read,
+		read_at, created_at
+	FROM %s WHERE user_id = $1 AND ($2 = false OR is_read = false) ORDER BY %s %s LIMIT $3 OFFSET $4
+	`, domain.TableNotifications, params.SortField, params.Sort)
+
+	rows, err := nr.psql.QueryContext(ctx, query, userId, unreadOnly, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var notifications []domain.Notification
+
+	for rows.Next() {
+		var n domain.Notification
+		var readAt sql.NullTime
+
+		if err = rows.Scan(
+			&n.ID, &n.UserID, &n.CompanyID, &n.Type, &n.Title, &n.Message, &n.WarehouseID, &n.ItemID, &n.IsRead,
+			&readAt, &n.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, totalCount, rows.Err()
+}
+
+func (nr *NotificationsPostgresRepository) MarkAsRead(ctx context.Context, id, userId int64) error {
+	query := fmt.Sprintf(`
+	UPDATE %s SET is_read = true, read_at = COALESCE(read_at, $1) WHERE id = $2 AND user_id = $3
+	`, domain.TableNotifications)
+
+	res, err := nr.psql.ExecContext(ctx, query, time.Now().UTC(), id, userId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (nr *NotificationsPostgresRepository) MarkAllAsRead(ctx context.Context, userId int64) error {
+	query := fmt.Sprintf("UPDATE %s SET is_read = true, read_at = $1 WHERE user_id = $2 AND is_read = false",
+		domain.TableNotifications)
+
+	_, err := nr.psql.ExecContext(ctx, query, time.Now().UTC(), userId)
+	return err
+}
+
+// GetSystemRecipients возвращает активных пользователей компании, подписанных на системные уведомления
+func (nr *NotificationsPostgresRepository) GetSystemRecipients(ctx context.Context, companyId int64) ([]int64, error) {
+	query := fmt.Sprintf(`
+	SELECT id FROM %s WHERE company_id = $1 AND is_send_system_notification = true AND is_active = true
+	`, domain.UsersTable)
+
+	rows, err := nr.psql.QueryContext(ctx, query, companyId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+// keysetCondition возвращает условие выборки записей, следующих за курсором при сортировке по полю курсора и id.
+// NULL при сортировке по возрастанию идут последними, по убыванию - первыми, как принято в PostgreSQL.
+// Параметры условия нумеруются начиная с from
+func keysetCondition(cursor *domain.Cursor, from int) (string, []any, error) {
+	value, isNull, err := cursorValue(cursor.Value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	field := cursor.SortField
+
+	if cursor.Sort == "DESC" {
+		if isNull {
+			return fmt.Sprintf("((%s IS NULL AND id < $%d) OR %s IS NOT NULL)", field, from, field),
+				[]any{cursor.ID}, nil
+		}
+
+		return fmt.Sprintf("(%s < $%d OR (%s = $%d AND id < $%d))", field, from, field, from, from+1),
+			[]any{value, cursor.ID}, nil
+	}
+
+	if isNull {
+		return fmt.Sprintf("(%s IS NULL AND id > $%d)", field, from), []any{cursor.ID}, nil
+	}
+
+	return fmt.Sprintf("(%s > $%d OR (%s = $%d AND id > $%d) OR %s IS NULL)", field, from, field, from, from+1, field),
+		[]any{value, cursor.ID}, nil
+}
+
+// cursorValue преобразует значение поля из JSON-представления записи в параметр запроса.
+// Тип параметра PostgreSQL выводит из типа колонки сортировки
+func cursorValue(raw json.RawMessage) (any, bool, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false, domain.ErrInvalidCursorParam
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, true, nil
+	case string, bool:
+		return v, false, nil
+	case json.Number:
+		return v.String(), false, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false, domain.ErrInvalidCursorParam
+			}
+
+			values = append(values, s)
+		}
+
+		return pq.Array(values), false, nil
+	default:
+		return nil, false, domain.ErrInvalidCursorParam
+	}
+}
+
+// listOrder возвращает сортировку списка с id в качестве второго ключа, чтобы порядок записей был однозначным
+func listOrder(field, sort string) string {
+	if field == "id" {
+		return fmt.Sprintf("id %s", sort)
+	}
+
+	return fmt.Sprintf("%s %s, id %s", field, sort, sort)
+}
+
+// applyCursor дополняет условие выборки позицией курсора. В режиме курсора смещение страницы не используется
+func applyCursor(where string, args []any, cursor *domain.Cursor, offset int64) (string, []any, int64, error) {
+	if cursor == nil {
+		return where, args, offset, nil
+	}
+
+	condition, cursorArgs, err := keysetCondition(cursor, len(args)+1)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	return where + " AND " + condition, append(args, cursorArgs...), 0, nil
+}
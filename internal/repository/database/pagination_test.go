@@ -0,0 +1,110 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"reflect"
+	"testing"
+)
+
+func TestCursorValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		want     any
+		wantNull bool
+		wantErr  error
+	}{
+		{"string", `"Кирпич"`, "Кирпич", false, nil},
+		{"bool", `true`, true, false, nil},
+		{"integer keeps precision", `9007199254740993`, "9007199254740993", false, nil},
+		{"decimal", `150.75`, "150.75", false, nil},
+		{"null", `null`, nil, true, nil},
+		{"string array", `["a","b"]`, pq.Array([]string{"a", "b"}), false, nil},
+		{"mixed array", `["a",1]`, nil, false, domain.ErrInvalidCursorParam},
+		{"object", `{"a":1}`, nil, false, domain.ErrInvalidCursorParam},
+		{"invalid json", `{`, nil, false, domain.ErrInvalidCursorParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isNull, err := cursorValue(json.RawMessage(tt.raw))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("cursorValue() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if isNull != tt.wantNull {
+				t.Errorf("cursorValue() isNull = %v, want %v", isNull, tt.wantNull)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cursorValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name          string
+		cursor        domain.Cursor
+		from          int
+		wantCondition string
+		wantArgs      []any
+		wantErr       error
+	}{
+		{
+			name:          "asc",
+			cursor:        domain.Cursor{ID: 7, SortField: "name", Sort: "ASC", Value: json.RawMessage(`"b"`)},
+			from:          2,
+			wantCondition: "(name > $2 OR (name = $2 AND id > $3) OR name IS NULL)",
+			wantArgs:      []any{"b", int64(7)},
+		},
+		{
+			name:          "desc",
+			cursor:        domain.Cursor{ID: 7, SortField: "total_quantity", Sort: "DESC", Value: json.RawMessage(`10`)},
+			from:          4,
+			wantCondition: "(total_quantity < $4 OR (total_quantity = $4 AND id < $5))",
+			wantArgs:      []any{"10", int64(7)},
+		},
+		{
+			name:          "asc after null",
+			cursor:        domain.Cursor{ID: 7, SortField: "expiration_date", Sort: "ASC", Value: json.RawMessage(`null`)},
+			from:          3,
+			wantCondition: "(expiration_date IS NULL AND id > $3)",
+			wantArgs:      []any{int64(7)},
+		},
+		{
+			name:          "desc after null",
+			cursor:        domain.Cursor{ID: 7, SortField: "expiration_date", Sort: "DESC", Value: json.RawMessage(`null`)},
+			from:          3,
+			wantCondition: "((expiration_date IS NULL AND id < $3) OR expiration_date IS NOT NULL)",
+			wantArgs:      []any{int64(7)},
+		},
+		{
+			name:    "invalid value",
+			cursor:  domain.Cursor{ID: 7, SortField: "name", Sort: "ASC", Value: json.RawMessage(`{}`)},
+			from:    1,
+			wantErr: domain.ErrInvalidCursorParam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, err := keysetCondition(&tt.cursor, tt.from)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("keysetCondition() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if condition != tt.wantCondition {
+				t.Errorf("keysetCondition() condition = %q, want %q", condition, tt.wantCondition)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("keysetCondition() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PurchaseOrders interface {
+	Create(ctx context.Context, order domain.PurchaseOrder) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.PurchaseOrder, error)
+	GetList(ctx context.Context, params domain.PurchaseOrderParams) ([]domain.PurchaseOrder, int64, error)
+	Update(ctx context.Context, order domain.PurchaseOrder) error
+	SetStatus(ctx context.Context, id int64, from []string, to string) error
+	Receive(ctx context.Context, id int64, inp domain.ReceivePurchaseOrder, userId int64) ([]int64, error)
+}
+
+type PurchaseOrdersPostgresRepository struct {
+	psql      *sql.DB
+	materials *MaterialsPostgresRepository
+}
+
+func NewPurchaseOrdersPostgresRepository(psql *sql.DB) *PurchaseOrdersPostgresRepository {
+	return &PurchaseOrdersPostgresRepository{
+		psql:      psql,
+		materials: NewMaterialsPostgresRepository(psql),
+	}
+}
+
+// purchaseOrderColumns колонки заказа поставщику с суммой строк без НДС. Порядок совпадает с scanPurchaseOrder
+var purchaseOrderColumns = fmt.Sprintf(`id, supplier_id, company_id, contract_number, contract_date,
+	expected_delivery_date, status, vat_rate, currency, comment, COALESCE(created_by, 0), created_at, updated_at,
+	(SELECT COALESCE(SUM(price_without_vat * quantity), 0) FROM %s WHERE purchase_order_id = o.id)`,
+	domain.TablePurchaseOrderLines)
+
+func (pr *PurchaseOrdersPostgresRepository) Create(ctx context.Context, order domain.PurchaseOrder) (int64, error) {
+	tx, err := pr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (supplier_id, company_id, contract_number, contract_date, expected_delivery_date, status,
+			vat_rate, currency, comment, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, 0), $11, $12) RETURNING id`,
+		domain.TablePurchaseOrders)
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, query,
+		order.SupplierID, order.CompanyID, order.ContractNumber, order.ContractDate, order.ExpectedDeliveryDate,
+		order.Status, order.VATRate, order.Currency, order.Comment, order.CreatedBy, order.CreatedAt, order.UpdatedAt,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert purchase order: %v", err)
+	}
+
+	if err = insertPurchaseOrderLines(ctx, tx, id, order.Lines); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// GetById возвращает заказ поставщику вместе со строками
+func (pr *PurchaseOrdersPostgresRepository) GetById(ctx context.Context, id int64) (domain.PurchaseOrder, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s o WHERE id = $1", purchaseOrderColumns, domain.TablePurchaseOrders)
+
+	var order domain.PurchaseOrder
+	if err := scanPurchaseOrder(pr.psql.QueryRowContext(ctx, query, id), &order); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PurchaseOrder{}, domain.ErrPurchaseOrderNotFound
+		}
+
+		return domain.PurchaseOrder{}, err
+	}
+
+	lines, err := purchaseOrderLines(ctx, pr.psql, id)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	order.Lines = lines
+
+	return order, nil
+}
+
+// GetList возвращает заказы поставщикам без строк
+func (pr *PurchaseOrdersPostgresRepository) GetList(ctx context.Context, params domain.PurchaseOrderParams) ([]domain.PurchaseOrder, int64, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if params.CompanyId != 0 {
+		addCondition("company_id = $%d", params.CompanyId)
+	}
+
+	if params.SupplierId != 0 {
+		addCondition("supplier_id = $%d", params.SupplierId)
+	}
+
+	if params.Status != "" {
+		addCondition("status = $%d", params.Status)
+	}
+
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int64
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", domain.TablePurchaseOrders, where)
+
+	if err := pr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s o WHERE %s ORDER BY %s %s LIMIT $%d OFFSET $%d",
+		purchaseOrderColumns, domain.TablePurchaseOrders, where, params.SortField, params.Sort, len(args)+1, len(args)+2)
+
+	rows, err := pr.psql.QueryContext(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var orders []domain.PurchaseOrder
+
+	for rows.Next() {
+		var order domain.PurchaseOrder
+		if err = scanPurchaseOrder(rows, &order); err != nil {
+			return nil, 0, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, totalCount, rows.Err()
+}
+
+// Update изменяет черновик заказа. Строки заказа заменяются, только если order.Lines не nil
+func (pr *PurchaseOrdersPostgresRepository) Update(ctx context.Context, order domain.PurchaseOrder) error {
+	tx, err := pr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockPurchaseOrder(ctx, tx, order.ID, domain.PurchaseOrderDraft); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET supplier_id = $1, contract_number = $2, contract_date = $3, expected_delivery_date = $4, vat_rate = $5,
+			currency = $6, comment = $7, updated_at = $8
+		WHERE id = $9`,
+		domain.TablePurchaseOrders)
+
+	if _, err = tx.ExecContext(ctx, query,
+		order.SupplierID, order.ContractNumber, order.ContractDate, order.ExpectedDeliveryDate, order.VATRate,
+		order.Currency, order.Comment, order.UpdatedAt, order.ID,
+	); err != nil {
+		return err
+	}
+
+	if order.Lines != nil {
+		query = fmt.Sprintf("DELETE FROM %s WHERE purchase_order_id = $1", domain.TablePurchaseOrderLines)
+
+		if _, err = tx.ExecContext(ctx, query, order.ID); err != nil {
+			return err
+		}
+
+		if err = insertPurchaseOrderLines(ctx, tx, order.ID, order.Lines); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetStatus переводит заказ в статус to, если текущий статус входит в from
+func (pr *PurchaseOrdersPostgresRepository) SetStatus(ctx context.Context, id int64, from []string, to string) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, updated_at = $2 WHERE id = $3 AND status = ANY($4)",
+		domain.TablePurchaseOrders)
+
+	res, err := pr.psql.ExecContext(ctx, query, to, time.Now().UTC(), id, pq.Array(from))
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrPurchaseOrderStatus
+	}
+
+	return nil
+}
+
+// Receive принимает строки отправленного заказа одной транзакцией: каждая строка принимается так же, как приемка
+// планируемого материала, и переносится в закупленные материалы. Без строк принимается весь непринятый остаток.
+// Возвращает ID созданных закупленных материалов
+func (pr *PurchaseOrdersPostgresRepository) Receive(ctx context.Context, id int64, inp domain.ReceivePurchaseOrder, userId int64) ([]int64, error) {
+	tx, err := pr.psql.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockPurchaseOrder(ctx, tx, id, domain.PurchaseOrderSent, domain.PurchaseOrderPartiallyReceived); err != nil {
+		return nil, err
+	}
+
+	lines, err := purchaseOrderLines(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[int64]int, len(lines))
+	for i, line := range lines {
+		index[line.ID] = i
+	}
+
+	receipts := inp.Lines
+	if len(receipts) == 0 {
+		for _, line := range lines {
+			if line.Quantity > line.ReceivedQuantity {
+				receipts = append(receipts, domain.ReceivePurchaseOrderLine{LineID: line.ID})
+			}
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET received_quantity = received_quantity + $1 WHERE id = $2",
+		domain.TablePurchaseOrderLines)
+
+	var ids []int64
+
+	for _, receipt := range receipts {
+		i, ok := index[receipt.LineID]
+		if !ok {
+			return nil, domain.ErrPurchaseOrderLineNotFound
+		}
+
+		line := lines[i]
+
+		quantity := receipt.Quantity
+		if quantity == 0 {
+			quantity = line.Quantity - line.ReceivedQuantity
+		}
+
+		if quantity <= 0 {
+			return nil, domain.ErrInvalidQuantity
+		}
+
+		if quantity > line.Quantity-line.ReceivedQuantity {
+			return nil, domain.ErrQuantityExceedsPlanned
+		}
+
+		price := receipt.PriceWithoutVAT
+		if price == nil {
+			price = &line.PriceWithoutVAT
+		}
+
+		newId, _, err := pr.materials.receivePlanning(ctx, tx, domain.ReceivePlanningMaterial{
+			ID:                     line.PlanningMaterialID,
+			Quantity:               quantity,
+			PriceWithoutVAT:        price,
+			ByInvoice:              inp.ByInvoice,
+			IncomingDeliveryNumber: inp.IncomingDeliveryNumber,
+			ReceivedDate:           inp.ReceivedDate,
+			LocationID:             receipt.LocationID,
+		}, userId)
+		if err != nil {
+			return nil, fmt.Errorf("purchase order line %d: %w", line.ID, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, query, quantity, line.ID); err != nil {
+			return nil, err
+		}
+
+		lines[i].ReceivedQuantity += quantity
+		ids = append(ids, newId)
+	}
+
+	status := domain.PurchaseOrderReceived
+	for _, line := range lines {
+		if line.ReceivedQuantity < line.Quantity {
+			status = domain.PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET status = $1, updated_at = $2 WHERE id = $3", domain.TablePurchaseOrders)
+
+	if _, err = tx.ExecContext(ctx, query, status, time.Now().UTC(), id); err != nil {
+		return nil, err
+	}
+
+	return ids, tx.Commit()
+}
+
+// lockPurchaseOrder блокирует заказ до конца транзакции и проверяет, что он находится в одном из статусов
+func lockPurchaseOrder(ctx context.Context, tx *sql.Tx, id int64, statuses ...string) error {
+	var status string
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT status FROM %s WHERE id = $1 FOR UPDATE",
+		domain.TablePurchaseOrders), id).Scan(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrPurchaseOrderNotFound
+		}
+
+		return err
+	}
+
+	for _, s := range statuses {
+		if status == s {
+			return nil
+		}
+	}
+
+	return domain.ErrPurchaseOrderStatus
+}
+
+// insertPurchaseOrderLines добавляет строки заказа. Планируемый материал может входить только в один
+// действующий заказ
+func insertPurchaseOrderLines(ctx context.Context, tx *sql.Tx, orderId int64, lines []domain.PurchaseOrderLine) error {
+	// блокируем планируемые материалы до конца транзакции, чтобы параллельно сохраняемые заказы не включили
+	// один материал дважды. Строки блокируются в порядке id, чтобы такие заказы не блокировали друг друга
+	planningIds := make([]int64, 0, len(lines))
+	for _, l := range lines {
+		planningIds = append(planningIds, l.PlanningMaterialID)
+	}
+
+	sort.Slice(planningIds, func(i, j int) bool {
+		return planningIds[i] < planningIds[j]
+	})
+
+	for _, planningId := range planningIds {
+		if err := lockMaterial(ctx, tx, domain.TablePlanningMaterials, planningId); err != nil {
+			return err
+		}
+	}
+
+	checkQuery := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM %s l JOIN %s o ON o.id = l.purchase_order_id
+			WHERE l.planning_material_id = $1 AND o.id <> $2 AND o.status NOT IN ($3, $4)
+		)`,
+		domain.TablePurchaseOrderLines, domain.TablePurchaseOrders)
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (purchase_order_id, planning_material_id, item_id, name, article, unit, quantity,
+			price_without_vat)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8)`,
+		domain.TablePurchaseOrderLines)
+
+	for _, l := range lines {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, checkQuery, l.PlanningMaterialID, orderId, domain.PurchaseOrderCancelled,
+			domain.PurchaseOrderReceived).Scan(&exists); err != nil {
+			return err
+		}
+
+		if exists {
+			return domain.ErrPlanningInPurchaseOrder
+		}
+
+		if _, err := tx.ExecContext(ctx, query,
+			orderId, l.PlanningMaterialID, l.ItemID, l.Name, l.Article, l.Unit, l.Quantity, l.PriceWithoutVAT,
+		); err != nil {
+			return fmt.Errorf("failed to insert purchase order line: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func purchaseOrderLines(ctx context.Context, q querier, id int64) ([]domain.PurchaseOrderLine, error) {
+	query := fmt.Sprintf(`
+		SELECT id, purchase_order_id, planning_material_id, COALESCE(item_id, 0), name, article, unit, quantity,
+			received_quantity, price_without_vat, price_without_vat * quantity
+		FROM %s WHERE purchase_order_id = $1 ORDER BY id`,
+		domain.TablePurchaseOrderLines)
+
+	rows, err := q.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var lines []domain.PurchaseOrderLine
+
+	for rows.Next() {
+		var l domain.PurchaseOrderLine
+		if err = rows.Scan(
+			&l.ID, &l.PurchaseOrderID, &l.PlanningMaterialID, &l.ItemID, &l.Name, &l.Article, &l.Unit, &l.Quantity,
+			&l.ReceivedQuantity, &l.PriceWithoutVAT, &l.TotalWithoutVAT,
+		); err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines, rows.Err()
+}
+
+func scanPurchaseOrder(row scanner, o *domain.PurchaseOrder) error {
+	return row.Scan(
+		&o.ID, &o.SupplierID, &o.CompanyID, &o.ContractNumber, &o.ContractDate, &o.ExpectedDeliveryDate, &o.Status,
+		&o.VATRate, &o.Currency, &o.Comment, &o.CreatedBy, &o.CreatedAt, &o.UpdatedAt, &o.TotalWithoutVAT,
+	)
+}
@@ -0,0 +1,279 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
+)
+
+// activeReservationCondition условие действующего резерва для таблицы reservations с псевдонимом r
+const activeReservationCondition = `r.status = 'active' AND (r.expires_at IS NULL OR r.expires_at > NOW())`
+
+const reservationColumns = `r.id, r.purchased_material_id, COALESCE(r.item_id, 0), COALESCE(r.warehouse_id, 0), r.quantity,
+	COALESCE(r.order_reference, ''), COALESCE(r.reserved_for, 0), r.status, r.expires_at, COALESCE(r.comment, ''),
+	COALESCE(r.created_by, 0), r.company_id, r.created_at, r.updated_at`
+
+type Reservations interface {
+	Create(ctx context.Context, reservation domain.Reservation) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Reservation, error)
+	GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.Reservation, int64, error)
+	Release(ctx context.Context, id int64) error
+	Consume(ctx context.Context, id, userId int64) error
+	ReleaseExpired(ctx context.Context) (int64, error)
+}
+
+type ReservationsPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewReservationsPostgresRepository(psql *sql.DB) *ReservationsPostgresRepository {
+	return &ReservationsPostgresRepository{
+		psql: psql,
+	}
+}
+
+func (rr *ReservationsPostgresRepository) Create(ctx context.Context, reservation domain.Reservation) (int64, error) {
+	tx, err := rr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	// 1. блокируем материал, чтобы параллельные резервы не превысили остаток
+	query := fmt.Sprintf(`
+	SELECT COALESCE(total_quantity, 0), COALESCE(warehouse_id, 0), COALESCE(item_id, 0), company_id
+	FROM %s WHERE id = $1 FOR UPDATE
+	`, domain.TablePurchasedMaterials)
+
+	var totalQuantity int64
+	if err = tx.QueryRowContext(ctx, query, reservation.PurchasedMaterialID).Scan(
+		&totalQuantity, &reservation.WarehouseID, &reservation.ItemID, &reservation.CompanyID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrMaterialNotFound
+		}
+
+		return 0, err
+	}
+
+	// 2. проверяем доступный остаток
+	reserved, err := reservedQuantity(ctx, tx, reservation.PurchasedMaterialID)
+	if err != nil {
+		return 0, err
+	}
+
+	if reservation.Quantity > totalQuantity-reserved {
+		return 0, domain.ErrInsufficientStock
+	}
+
+	// 3. создаем резерв
+	query = fmt.Sprintf(`
+		INSERT INTO %s (purchased_material_id, item_id, warehouse_id, quantity, order_reference, reserved_for, status,
+		                expires_at, comment, created_by, company_id, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, NULLIF($6, 0), $7, $8, $9, NULLIF($10, 0), $11, $12, $12) RETURNING id`,
+		domain.TableReservations)
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, query,
+		reservation.PurchasedMaterialID, reservation.ItemID, reservation.WarehouseID, reservation.Quantity,
+		reservation.OrderReference, reservation.ReservedFor, domain.ReservationActive, reservation.ExpiresAt,
+		reservation.Comment, reservation.CreatedBy, reservation.CompanyID, time.Now().UTC(),
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert reservation: %v", err)
+	}
+
+	return id, tx.Commit()
+}
+
+func (rr *ReservationsPostgresRepository) GetById(ctx context.Context, id int64) (domain.Reservation, error) {
+	return getReservationById(ctx, rr.psql, id, false)
+}
+
+func (rr *ReservationsPostgresRepository) GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.Reservation, int64, error) {
+	var totalCount int64
+
+	countQuery := fmt.Sprintf(`
+	SELECT COUNT(*)
+	FROM %s
+	WHERE purchased_material_id = $1 AND company_id = $2
+	`, domain.TableReservations)
+
+	err := rr.psql.QueryRowContext(ctx, countQuery, id, params.CompanyId).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM %s r WHERE r.purchased_material_id = $1 AND r.company_id = $2 ORDER BY r.%s %s LIMIT $3 OFFSET $4
+	`, reservationColumns, domain.TableReservations, params.SortField, params.Sort)
+
+	rows, err := rr.psql.QueryContext(ctx, query, id, params.CompanyId, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var reservations []domain.Reservation
+
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, totalCount, rows.Err()
+}
+
+func (rr *ReservationsPostgresRepository) Release(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(`
+	UPDATE %s r SET status = $1, updated_at = $2 WHERE r.id = $3 AND %s
+	`, domain.TableReservations, activeReservationCondition)
+
+	res, err := rr.psql.ExecContext(ctx, query, domain.ReservationReleased, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrReservationNotActive
+	}
+
+	return nil
+}
+
+func (rr *ReservationsPostgresRepository) Consume(ctx context.Context, id, userId int64) error {
+	tx, err := rr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	reservation, err := getReservationById(ctx, tx, id, true)
+	if err != nil {
+		return err
+	}
+
+	if reservation.Status != domain.ReservationActive ||
+		(reservation.ExpiresAt != nil && !reservation.ExpiresAt.After(time.Now())) {
+		return domain.ErrReservationNotActive
+	}
+
+	// 1. закрываем резерв до списания, чтобы его количество стало доступно для отпуска
+	query := fmt.Sprintf("UPDATE %s SET status = $1, updated_at = $2 WHERE id = $3", domain.TableReservations)
+
+	if _, err = tx.ExecContext(ctx, query, domain.ReservationConsumed, time.Now().UTC(), id); err != nil {
+		return err
+	}
+
+	// 2. отпускаем зарезервированное количество со склада
+	if _, err = applyStockMovement(ctx, tx, domain.StockMovement{
+		PurchasedMaterialID: reservation.PurchasedMaterialID,
+		MovementType:        domain.MovementIssue,
+		Quantity:            -reservation.Quantity,
+		Reference:           reservation.OrderReference,
+		Comment:             fmt.Sprintf("Отпуск по резерву #%d", reservation.ID),
+		UserID:              userId,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (rr *ReservationsPostgresRepository) ReleaseExpired(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf(`
+	UPDATE %s SET status = $1, updated_at = $2 WHERE status = $3 AND expires_at IS NOT NULL AND expires_at <= $2
+	`, domain.TableReservations)
+
+	res, err := rr.psql.ExecContext(ctx, query, domain.ReservationExpired, time.Now().UTC(), domain.ReservationActive)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func getReservationById(ctx context.Context, q querier, id int64, forUpdate bool) (domain.Reservation, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s r WHERE r.id = $1", reservationColumns, domain.TableReservations)
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	reservation, err := scanReservation(q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Reservation{}, domain.ErrReservationNotFound
+		}
+
+		return domain.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+func scanReservation(row scanner) (domain.Reservation, error) {
+	var reservation domain.Reservation
+	var expiresAt sql.NullTime
+
+	if err := row.Scan(
+		&reservation.ID, &reservation.PurchasedMaterialID, &reservation.ItemID, &reservation.WarehouseID,
+		&reservation.Quantity, &reservation.OrderReference, &reservation.ReservedFor, &reservation.Status, &expiresAt,
+		&reservation.Comment, &reservation.CreatedBy, &reservation.CompanyID, &reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	); err != nil {
+		return domain.Reservation{}, err
+	}
+
+	if expiresAt.Valid {
+		reservation.ExpiresAt = &expiresAt.Time
+	}
+
+	return reservation, nil
+}
+
+// reservedQuantity возвращает количество материала в действующих резервах
+func reservedQuantity(ctx context.Context, q querier, purchasedMaterialId int64) (int64, error) {
+	query := fmt.Sprintf("SELECT COALESCE(SUM(r.quantity), 0) FROM %s r WHERE r.purchased_material_id = $1 AND %s",
+		domain.TableReservations, activeReservationCondition)
+
+	var reserved int64
+	if err := q.QueryRowContext(ctx, query, purchasedMaterialId).Scan(&reserved); err != nil {
+		return 0, err
+	}
+
+	return reserved, nil
+}
+
+// releaseReservations снимает все действующие резервы материала
+func releaseReservations(ctx context.Context, q querier, purchasedMaterialId int64) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, updated_at = $2 WHERE purchased_material_id = $3 AND status = $4",
+		domain.TableReservations)
+
+	_, err := q.ExecContext(ctx, query, domain.ReservationReleased, time.Now().UTC(), purchasedMaterialId,
+		domain.ReservationActive)
+	return err
+}
@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
+)
+
+type StockMovements interface {
+	Create(ctx context.Context, movement domain.StockMovement) (int64, error)
+	GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.StockMovement, int64, error)
+	GetLedgerBalance(ctx context.Context, id int64) (int64, error)
+}
+
+type StockMovementsPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewStockMovementsPostgresRepository(psql *sql.DB) *StockMovementsPostgresRepository {
+	return &StockMovementsPostgresRepository{
+		psql: psql,
+	}
+}
+
+func (smr *StockMovementsPostgresRepository) Create(ctx context.Context, movement domain.StockMovement) (int64, error) {
+	tx, err := smr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	id, err := applyStockMovement(ctx, tx, movement)
+	if err != nil {
+		return 0, err
+	}
+
+	// поступление занимает дополнительное место на складе материала
+	if movement.Quantity > 0 {
+		var warehouseId, volume int64
+		if err = tx.QueryRowContext(ctx, fmt.Sprintf(
+			"SELECT COALESCE(warehouse_id, 0), COALESCE(volume, 0) FROM %s WHERE id = $1",
+			domain.TablePurchasedMaterials), movement.PurchasedMaterialID).Scan(&warehouseId, &volume); err != nil {
+			return 0, err
+		}
+
+		if err = checkWarehouseCapacity(ctx, tx, warehouseId, volume*movement.Quantity); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+func (smr *StockMovementsPostgresRepository) GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.StockMovement, int64, error) {
+	var totalCount int64
+
+	countQuery := fmt.Sprintf(`
+	SELECT COUNT(*)
+	FROM %s
+	WHERE purchased_material_id = $1 AND company_id = $2
+	`, domain.TableStockMovements)
+
+	err := smr.psql.QueryRowContext(ctx, countQuery, id, params.CompanyId).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT
+	    id, purchased_material_id, COALESCE(item_id, 0), COALESCE(warehouse_id, 0), movement_type, quantity, balance_after,
+		COALESCE(target_warehouse_id, 0), COALESCE(reference, ''), COALESCE(comment, ''), COALESCE(user_id, 0),
+		company_id, created_at
+	FROM %s WHERE purchased_material_id = $1 AND company_id = $2 ORDER BY %s %s LIMIT $3 OFFSET $4
+	`, domain.TableStockMovements, params.SortField, params.Sort)
+
+	rows, err := smr.psql.QueryContext(ctx, query, id, params.CompanyId, params.Limit, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var movements []domain.StockMovement
+
+	for rows.Next() {
+		var m domain.StockMovement
+		if err = rows.Scan(
+			&m.ID, &m.PurchasedMaterialID, &m.ItemID, &m.WarehouseID, &m.MovementType, &m.Quantity, &m.BalanceAfter,
+			&m.TargetWarehouseID, &m.Reference, &m.Comment, &m.UserID, &m.CompanyID, &m.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		movements = append(movements, m)
+	}
+
+	return movements, totalCount, nil
+}
+
+func (smr *StockMovementsPostgresRepository) GetLedgerBalance(ctx context.Context, id int64) (int64, error) {
+	query := fmt.Sprintf(`SELECT COALESCE(SUM(quantity), 0) FROM %s WHERE purchased_material_id = $1`,
+		domain.TableStockMovements)
+
+	var balance int64
+	if err := smr.psql.QueryRowContext(ctx, query, id).Scan(&balance); err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// applyStockMovement изменяет остаток закупленного материала на movement.Quantity и записывает движение в журнал.
+// Строка материала блокируется до конца транзакции, поэтому остаток не может уйти в минус при параллельных движениях.
+func applyStockMovement(ctx context.Context, tx *sql.Tx, movement domain.StockMovement) (int64, error) {
+	query := fmt.Sprintf(`
+	SELECT COALESCE(total_quantity, 0), COALESCE(warehouse_id, 0), COALESCE(item_id, 0), company_id
+	FROM %s WHERE id = $1 FOR UPDATE
+	`, domain.TablePurchasedMaterials)
+
+	var balance int64
+	if err := tx.QueryRowContext(ctx, query, movement.PurchasedMaterialID).Scan(
+		&balance, &movement.WarehouseID, &movement.ItemID, &movement.CompanyID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrMaterialNotFound
+		}
+
+		return 0, err
+	}
+
+	balance += movement.Quantity
+	if balance < 0 {
+		return 0, domain.ErrInsufficientStock
+	}
+
+	// расход не может затрагивать зарезервированное количество
+	if movement.Quantity < 0 {
+		reserved, err := reservedQuantity(ctx, tx, movement.PurchasedMaterialID)
+		if err != nil {
+			return 0, err
+		}
+
+		if balance < reserved {
+			return 0, domain.ErrInsufficientStock
+		}
+	}
+
+	// общая стоимость пересчитывается по цене вместе с остатком
+	query = fmt.Sprintf(`
+		UPDATE %s SET total_quantity = $1, total_without_vat = ROUND(price_without_vat * $1, %d), last_updated = $2
+		WHERE id = $3`,
+		domain.TablePurchasedMaterials, domain.MoneyPlaces)
+
+	if _, err := tx.ExecContext(ctx, query, balance, time.Now().UTC(), movement.PurchasedMaterialID); err != nil {
+		return 0, err
+	}
+
+	// изменение остатка меняет занимаемый материалом объем склада
+	if err := recalcWarehouseOccupancy(ctx, tx, movement.WarehouseID); err != nil {
+		return 0, err
+	}
+
+	movement.BalanceAfter = balance
+
+	return insertStockMovement(ctx, tx, movement)
+}
+
+// insertStockMovement записывает движение в журнал без изменения остатка материала
+func insertStockMovement(ctx context.Context, tx *sql.Tx, movement domain.StockMovement) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (purchased_material_id, item_id, warehouse_id, movement_type, quantity, balance_after,
+		                target_warehouse_id, reference, comment, user_id, company_id, created_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, NULLIF($7, 0), $8, $9, NULLIF($10, 0), $11, $12) RETURNING id`,
+		domain.TableStockMovements)
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, query,
+		movement.PurchasedMaterialID, movement.ItemID, movement.WarehouseID, movement.MovementType, movement.Quantity,
+		movement.BalanceAfter, movement.TargetWarehouseID, movement.Reference, movement.Comment, movement.UserID,
+		movement.CompanyID, time.Now().UTC(),
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert stock movement: %v", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"os"
+	"testing"
+)
+
+// testDB подключается к базе из TEST_POSTGRES_DSN с примененными миграциями schema. Без переменной тест пропускается
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if err = db.Ping(); err != nil {
+		t.Fatalf("ping database: %v", err)
+	}
+
+	return db
+}
+
+// TestApplyStockMovementKeepsReserved проверяет, что расход не уменьшает остаток ниже зарезервированного количества.
+// Все данные создаются в транзакции, которая откатывается в конце теста
+func TestApplyStockMovementKeepsReserved(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = tx.Rollback()
+	})
+
+	var companyId, warehouseId, materialId int64
+
+	if err = tx.QueryRowContext(ctx, `INSERT INTO companies (name_ru) VALUES ('test') RETURNING id`).
+		Scan(&companyId); err != nil {
+		t.Fatalf("insert company: %v", err)
+	}
+
+	if err = tx.QueryRowContext(ctx, `INSERT INTO warehouses (name, company_id) VALUES ('test', $1) RETURNING id`,
+		companyId).Scan(&warehouseId); err != nil {
+		t.Fatalf("insert warehouse: %v", err)
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO purchased_materials (warehouse_id, item_id, name, total_quantity, volume, price_without_vat,
+		                                 total_without_vat, company_id)
+		VALUES ($1, NULL, 'test', 10, 1, 1, 10, $2) RETURNING id`,
+		warehouseId, companyId).Scan(&materialId); err != nil {
+		t.Fatalf("insert material: %v", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO reservations (purchased_material_id, warehouse_id, quantity, status, company_id)
+		VALUES ($1, $2, 6, 'active', $3)`,
+		materialId, warehouseId, companyId); err != nil {
+		t.Fatalf("insert reservation: %v", err)
+	}
+
+	issue := func(quantity int64) error {
+		_, err := applyStockMovement(ctx, tx, domain.StockMovement{
+			PurchasedMaterialID: materialId,
+			MovementType:        domain.MovementIssue,
+			Quantity:            -quantity,
+		})
+
+		return err
+	}
+
+	balance := func() int64 {
+		var quantity int64
+		if err := tx.QueryRowContext(ctx, `SELECT total_quantity FROM purchased_materials WHERE id = $1`,
+			materialId).Scan(&quantity); err != nil {
+			t.Fatalf("select balance: %v", err)
+		}
+
+		return quantity
+	}
+
+	if err = issue(5); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("issue into reserved quantity: error = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+
+	if got := balance(); got != 10 {
+		t.Fatalf("balance after rejected issue = %d, want 10", got)
+	}
+
+	if err = issue(4); err != nil {
+		t.Fatalf("issue of free quantity: %v", err)
+	}
+
+	if got := balance(); got != 6 {
+		t.Fatalf("balance after issue = %d, want 6", got)
+	}
+
+	if err = issue(1); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("issue of reserved quantity: error = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+}
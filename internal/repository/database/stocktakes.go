@@ -0,0 +1,374 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Stocktakes interface {
+	Create(ctx context.Context, stocktake domain.Stocktake) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Stocktake, error)
+	GetList(ctx context.Context, params domain.StocktakeParams) ([]domain.Stocktake, int64, error)
+	GetLines(ctx context.Context, id int64) ([]domain.StocktakeLine, error)
+	SaveCounts(ctx context.Context, id int64, lines []domain.StocktakeLine) error
+	Approve(ctx context.Context, stocktake domain.Stocktake) error
+	Cancel(ctx context.Context, stocktake domain.Stocktake) error
+}
+
+type StocktakesPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewStocktakesPostgresRepository(psql *sql.DB) *StocktakesPostgresRepository {
+	return &StocktakesPostgresRepository{
+		psql: psql,
+	}
+}
+
+// stocktakeColumns колонки инвентаризации. Порядок совпадает с scanStocktake
+const stocktakeColumns = `id, warehouse_id, company_id, status, comment, COALESCE(created_by, 0), COALESCE(approved_by, 0),
+	created_at, updated_at, closed_at`
+
+// stocktakeLineColumns колонки строки инвентаризации. Порядок совпадает с scanStocktakeLine
+const stocktakeLineColumns = `id, stocktake_id, COALESCE(purchased_material_id, 0), COALESCE(item_id, 0), name, article,
+	unit, location, expected_quantity, counted_quantity, COALESCE(counted_by, 0), counted_at, comment`
+
+// Create открывает инвентаризацию и фиксирует ожидаемые остатки закупленных материалов склада. Списанные
+// материалы в инвентаризацию не попадают
+func (sr *StocktakesPostgresRepository) Create(ctx context.Context, s domain.Stocktake) (int64, error) {
+	tx, err := sr.psql.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (warehouse_id, company_id, status, comment, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7) RETURNING id`,
+		domain.TableStocktakes)
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, query,
+		s.WarehouseID, s.CompanyID, s.Status, s.Comment, s.CreatedBy, s.CreatedAt, s.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, domain.ErrStocktakeAlreadyOpen
+		}
+
+		return 0, fmt.Errorf("failed to insert stocktake: %v", err)
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s (stocktake_id, purchased_material_id, item_id, name, article, unit, location, expected_quantity)
+		SELECT $1, id, item_id, COALESCE(name, ''), COALESCE(article, ''), COALESCE(unit, ''), COALESCE(location, ''),
+			COALESCE(total_quantity, 0)
+		FROM %s
+		WHERE warehouse_id = $2 AND status IS DISTINCT FROM $3`,
+		domain.TableStocktakeLines, domain.TablePurchasedMaterials)
+
+	if _, err = tx.ExecContext(ctx, query, id, s.WarehouseID, domain.MaterialStatusWrittenOff); err != nil {
+		return 0, fmt.Errorf("failed to insert stocktake lines: %v", err)
+	}
+
+	return id, tx.Commit()
+}
+
+func (sr *StocktakesPostgresRepository) GetById(ctx context.Context, id int64) (domain.Stocktake, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", stocktakeColumns, domain.TableStocktakes)
+
+	var s domain.Stocktake
+	if err := scanStocktake(sr.psql.QueryRowContext(ctx, query, id), &s); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Stocktake{}, domain.ErrStocktakeNotFound
+		}
+
+		return domain.Stocktake{}, err
+	}
+
+	return s, nil
+}
+
+func (sr *StocktakesPostgresRepository) GetList(ctx context.Context, params domain.StocktakeParams) ([]domain.Stocktake, int64, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if params.CompanyId != 0 {
+		addCondition("company_id = $%d", params.CompanyId)
+	}
+
+	if params.WarehouseId != 0 {
+		addCondition("warehouse_id = $%d", params.WarehouseId)
+	}
+
+	if params.Status != "" {
+		addCondition("status = $%d", params.Status)
+	}
+
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int64
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", domain.TableStocktakes, where)
+
+	if err := sr.psql.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s %s LIMIT $%d OFFSET $%d",
+		stocktakeColumns, domain.TableStocktakes, where, params.SortField, params.Sort, len(args)+1, len(args)+2)
+
+	rows, err := sr.psql.QueryContext(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var stocktakes []domain.Stocktake
+
+	for rows.Next() {
+		var s domain.Stocktake
+		if err = scanStocktake(rows, &s); err != nil {
+			return nil, 0, err
+		}
+
+		stocktakes = append(stocktakes, s)
+	}
+
+	return stocktakes, totalCount, rows.Err()
+}
+
+// GetLines возвращает строки инвентаризации, упорядоченные по наименованию материала
+func (sr *StocktakesPostgresRepository) GetLines(ctx context.Context, id int64) ([]domain.StocktakeLine, error) {
+	return stocktakeLines(ctx, sr.psql, id)
+}
+
+// SaveCounts сохраняет подсчеты строк открытой инвентаризации
+func (sr *StocktakesPostgresRepository) SaveCounts(ctx context.Context, id int64, lines []domain.StocktakeLine) error {
+	tx, err := sr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockOpenStocktake(ctx, tx, id); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET counted_quantity = $1, counted_by = NULLIF($2, 0), counted_at = $3, comment = $4
+		WHERE id = $5 AND stocktake_id = $6`,
+		domain.TableStocktakeLines)
+
+	for _, l := range lines {
+		if _, err = tx.ExecContext(ctx, query, l.CountedQuantity, l.CountedBy, l.CountedAt, l.Comment, l.ID, id); err != nil {
+			return err
+		}
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET updated_at = $1 WHERE id = $2", domain.TableStocktakes)
+
+	if _, err = tx.ExecContext(ctx, query, time.Now().UTC(), id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Approve проводит расхождения посчитанных строк корректировками остатков и закрывает инвентаризацию.
+// Корректировка равна разнице посчитанного количества и текущего остатка партии, прочитанного под блокировкой
+// строки, поэтому после утверждения остаток равен посчитанному. Непосчитанные строки и строки удаленных
+// материалов не проводятся
+func (sr *StocktakesPostgresRepository) Approve(ctx context.Context, s domain.Stocktake) error {
+	tx, err := sr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockOpenStocktake(ctx, tx, s.ID); err != nil {
+		return err
+	}
+
+	lines, err := stocktakeLines(ctx, tx, s.ID)
+	if err != nil {
+		return err
+	}
+
+	// партии блокируются в порядке id, как при проведении расходных накладных, чтобы параллельные операции
+	// с одними и теми же партиями не взаимоблокировались
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].PurchasedMaterialID < lines[j].PurchasedMaterialID
+	})
+
+	for _, l := range lines {
+		if l.CountedQuantity == nil || l.PurchasedMaterialID == 0 {
+			continue
+		}
+
+		quantity, err := lockPurchasedQuantity(ctx, tx, l.PurchasedMaterialID)
+		if err != nil {
+			return fmt.Errorf("stocktake line %d: %w", l.ID, err)
+		}
+
+		if *l.CountedQuantity == quantity {
+			continue
+		}
+
+		if _, err = applyStockMovement(ctx, tx, domain.StockMovement{
+			PurchasedMaterialID: l.PurchasedMaterialID,
+			MovementType:        domain.MovementAdjustment,
+			Quantity:            *l.CountedQuantity - quantity,
+			Reference:           fmt.Sprintf("Инвентаризация #%d", s.ID),
+			Comment:             l.Comment,
+			UserID:              s.ApprovedBy,
+		}); err != nil {
+			return fmt.Errorf("stocktake line %d: %w", l.ID, err)
+		}
+	}
+
+	if err = closeStocktake(ctx, tx, s); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel закрывает инвентаризацию без изменения остатков
+func (sr *StocktakesPostgresRepository) Cancel(ctx context.Context, s domain.Stocktake) error {
+	tx, err := sr.psql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		if err = tx.Rollback(); err != nil {
+			return
+		}
+	}(tx)
+
+	if err = lockOpenStocktake(ctx, tx, s.ID); err != nil {
+		return err
+	}
+
+	if err = closeStocktake(ctx, tx, s); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockOpenStocktake блокирует инвентаризацию до конца транзакции и проверяет, что она открыта
+func lockOpenStocktake(ctx context.Context, tx *sql.Tx, id int64) error {
+	var status string
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT status FROM %s WHERE id = $1 FOR UPDATE",
+		domain.TableStocktakes), id).Scan(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrStocktakeNotFound
+		}
+
+		return err
+	}
+
+	if status != domain.StocktakeOpen {
+		return domain.ErrStocktakeNotOpen
+	}
+
+	return nil
+}
+
+// lockPurchasedQuantity блокирует партию закупленного материала до конца транзакции и возвращает ее текущий остаток
+func lockPurchasedQuantity(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	var quantity int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(total_quantity, 0) FROM %s WHERE id = $1 FOR UPDATE",
+		domain.TablePurchasedMaterials), id).Scan(&quantity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrMaterialNotFound
+		}
+
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+func closeStocktake(ctx context.Context, tx *sql.Tx, s domain.Stocktake) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = $1, approved_by = NULLIF($2, 0), closed_at = $3, updated_at = $4 WHERE id = $5`,
+		domain.TableStocktakes)
+
+	_, err := tx.ExecContext(ctx, query, s.Status, s.ApprovedBy, s.ClosedAt, s.UpdatedAt, s.ID)
+
+	return err
+}
+
+func stocktakeLines(ctx context.Context, q querier, id int64) ([]domain.StocktakeLine, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE stocktake_id = $1 ORDER BY name, id",
+		stocktakeLineColumns, domain.TableStocktakeLines)
+
+	rows, err := q.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var lines []domain.StocktakeLine
+
+	for rows.Next() {
+		var l domain.StocktakeLine
+		if err = scanStocktakeLine(rows, &l); err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines, rows.Err()
+}
+
+func scanStocktake(row scanner, s *domain.Stocktake) error {
+	return row.Scan(
+		&s.ID, &s.WarehouseID, &s.CompanyID, &s.Status, &s.Comment, &s.CreatedBy, &s.ApprovedBy, &s.CreatedAt,
+		&s.UpdatedAt, &s.ClosedAt,
+	)
+}
+
+func scanStocktakeLine(row scanner, l *domain.StocktakeLine) error {
+	return row.Scan(
+		&l.ID, &l.StocktakeID, &l.PurchasedMaterialID, &l.ItemID, &l.Name, &l.Article, &l.Unit, &l.Location,
+		&l.ExpectedQuantity, &l.CountedQuantity, &l.CountedBy, &l.CountedAt, &l.Comment,
+	)
+}
@@ -16,6 +16,7 @@ type Suppliers interface {
 	Update(ctx context.Context, supplier domain.Supplier) error
 	Delete(ctx context.Context, id int64) error
 	GetListByCompanyId(ctx context.Context, id int64, param domain.Param) ([]domain.Supplier, int64, error)
+	GetIdByNameOrTaxId(ctx context.Context, companyId int64, value string) (int64, error)
 }
 
 type SuppliersPostgresRepository struct {
@@ -130,13 +131,20 @@ func (sr *SuppliersPostgresRepository) Delete(ctx context.Context, id int64) err
 func (sr *SuppliersPostgresRepository) GetListByCompanyId(ctx context.Context, id int64, param domain.Param) ([]domain.Supplier, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1
-	`, domain.TableSupplier)
+	// при переходе по курсору общее количество не подсчитывается
+	if param.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE company_id = $1
+		`, domain.TableSupplier)
+
+		if err := sr.psql.QueryRowContext(ctx, countQuery, id).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
 
-	err := sr.psql.QueryRowContext(ctx, countQuery, id).Scan(&totalCount)
+	where, args, offset, err := applyCursor("company_id = $1", []any{id}, param.Cursor, param.Offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -150,11 +158,11 @@ func (sr *SuppliersPostgresRepository) GetListByCompanyId(ctx context.Context, i
 		registration_date, payment_terms, is_active, other_fields, company_id, 
 		contract_date, locality
 	FROM %s
-	WHERE company_id = $1 ORDER BY %s %s
-	LIMIT $2 OFFSET $3;
-	`, domain.TableSupplier, param.SortField, param.Sort)
+	WHERE %s ORDER BY %s
+	LIMIT $%d OFFSET $%d;
+	`, domain.TableSupplier, where, listOrder(param.SortField, param.Sort), len(args)+1, len(args)+2)
 
-	rows, err := sr.psql.QueryContext(ctx, query, id, param.Limit, param.Offset)
+	rows, err := sr.psql.QueryContext(ctx, query, append(args, param.Limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -191,3 +199,24 @@ func (sr *SuppliersPostgresRepository) GetListByCompanyId(ctx context.Context, i
 
 	return suppliers, totalCount, nil
 }
+
+// GetIdByNameOrTaxId возвращает id поставщика компании по наименованию (без учета регистра) или ИНН
+func (sr *SuppliersPostgresRepository) GetIdByNameOrTaxId(ctx context.Context, companyId int64, value string) (int64, error) {
+	query := fmt.Sprintf(`
+		SELECT id FROM %s
+		WHERE company_id = $1 AND (LOWER(name) = LOWER($2) OR tax_id = $2)
+		ORDER BY (tax_id = $2) DESC, id
+		LIMIT 1`,
+		domain.TableSupplier)
+
+	var id int64
+	if err := sr.psql.QueryRowContext(ctx, query, companyId, value).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrSupplierNotFound
+		}
+
+		return 0, err
+	}
+
+	return id, nil
+}
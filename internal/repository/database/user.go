@@ -258,13 +258,20 @@ func (udr *UserDatabaseRepository) Delete(ctx context.Context, id int64) error {
 func (udr *UserDatabaseRepository) GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.User, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*)
-		FROM %s
-		WHERE company_id = $1
-	`, domain.UsersTable)
+	// при переходе по курсору общее количество не подсчитывается
+	if param.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM %s
+			WHERE company_id = $1
+		`, domain.UsersTable)
+
+		if err := udr.db.QueryRowContext(ctx, countQuery, companyId).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
 
-	err := udr.db.QueryRowContext(ctx, countQuery, companyId).Scan(&totalCount)
+	where, args, offset, err := applyCursor("company_id = $1", []any{companyId}, param.Cursor, param.Offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -275,13 +282,13 @@ func (udr *UserDatabaseRepository) GetListByCompanyId(ctx context.Context, compa
 		    updated_at, last_login, is_active, role, language, country, 
 		    is_approved, is_send_system_notification, sections, position
 		FROM %s
-		WHERE company_id = $1 ORDER BY %s %s
-		LIMIT $2 OFFSET $3
-		`, domain.UsersTable, param.SortField, param.Sort)
+		WHERE %s ORDER BY %s
+		LIMIT $%d OFFSET $%d
+		`, domain.UsersTable, where, listOrder(param.SortField, param.Sort), len(args)+1, len(args)+2)
 
 	var users []domain.User
 
-	rows, err := udr.db.QueryContext(ctx, query, companyId, param.Limit, param.Offset)
+	rows, err := udr.db.QueryContext(ctx, query, append(args, param.Limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
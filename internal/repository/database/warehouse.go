@@ -36,19 +36,19 @@ func (wpr *WarehousePostgresRepository) Create(ctx context.Context, warehouse do
 	query := fmt.Sprintf(`
     INSERT INTO %s (
         name, address, responsible_person, phone, email,
-        max_capacity, current_occupancy, other_fields, country, region,
+        max_capacity, other_fields, country, region,
         comments, company_id, locality
     ) VALUES (
         $1, $2, $3, $4, $5,
-        $6, $7, $8, $9, $10,
-        $11, $12, $13
+        $6, $7, $8, $9,
+        $10, $11, $12
     ) RETURNING id
     `, domain.TableWarehouse)
 
 	var id int64
 	if err = wpr.db.QueryRowContext(ctx, query,
 		warehouse.Name, warehouse.Address, warehouse.ResponsiblePerson, warehouse.Phone, warehouse.Email,
-		warehouse.MaxCapacity, warehouse.CurrentOccupancy, otherFieldsJSON, warehouse.Country, warehouse.Region,
+		warehouse.MaxCapacity, otherFieldsJSON, warehouse.Country, warehouse.Region,
 		warehouse.Comments, warehouse.CompanyId, warehouse.Locality,
 	).Scan(&id); err != nil {
 		return 0, fmt.Errorf("failed to insert warehouse: %v", err)
@@ -101,14 +101,14 @@ func (wpr *WarehousePostgresRepository) Update(ctx context.Context, warehouse do
 	UPDATE %s
 	SET
 		name = $1, address = $2, responsible_person = $3, phone = $4, email = $5,
-		max_capacity = $6, current_occupancy = $7, other_fields = $8, country = $9,
-		region = $10, comments = $11, locality = $12
-	WHERE id = $13
+		max_capacity = $6, other_fields = $7, country = $8,
+		region = $9, comments = $10, locality = $11
+	WHERE id = $12
 	`, domain.TableWarehouse)
 
 	_, err = wpr.db.ExecContext(ctx, query,
 		warehouse.Name, warehouse.Address, warehouse.ResponsiblePerson, warehouse.Phone, warehouse.Email,
-		warehouse.MaxCapacity, warehouse.CurrentOccupancy, otherFieldsJSON, warehouse.Country,
+		warehouse.MaxCapacity, otherFieldsJSON, warehouse.Country,
 		warehouse.Region, warehouse.Comments, warehouse.Locality,
 		warehouse.ID,
 	)
@@ -127,15 +127,22 @@ func (wpr *WarehousePostgresRepository) Delete(ctx context.Context, id int64) er
 func (wpr *WarehousePostgresRepository) GetListByCompanyId(ctx context.Context, id int64, param domain.Param) ([]domain.Warehouse, int64, error) {
 	var totalCount int64
 
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*)
-	FROM %s
-	WHERE company_id = $1
-	`, domain.TableWarehouse)
+	// при переходе по курсору общее количество не подсчитывается
+	if param.Cursor == nil {
+		countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE company_id = $1
+		`, domain.TableWarehouse)
 
-	err := wpr.db.QueryRowContext(ctx, countQuery, id).Scan(&totalCount)
+		if err := wpr.db.QueryRowContext(ctx, countQuery, id).Scan(&totalCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to count warehouses by company ID: %v", err)
+		}
+	}
+
+	where, args, offset, err := applyCursor("company_id = $1", []any{id}, param.Cursor, param.Offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count warehouses by company ID: %v", err)
+		return nil, 0, err
 	}
 
 	query := fmt.Sprintf(`
@@ -144,11 +151,11 @@ func (wpr *WarehousePostgresRepository) GetListByCompanyId(ctx context.Context,
 		max_capacity, current_occupancy, other_fields, country, region, 
 		comments, created_at, company_id, locality
 	FROM %s
-	WHERE company_id = $1 ORDER BY %s %s
-	LIMIT $2 OFFSET $3;
-	`, domain.TableWarehouse, param.SortField, param.Sort)
+	WHERE %s ORDER BY %s
+	LIMIT $%d OFFSET $%d;
+	`, domain.TableWarehouse, where, listOrder(param.SortField, param.Sort), len(args)+1, len(args)+2)
 
-	rows, err := wpr.db.QueryContext(ctx, query, id, param.Limit, param.Offset)
+	rows, err := wpr.db.QueryContext(ctx, query, append(args, param.Limit, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get warehouses by company ID: %v", err)
 	}
@@ -245,3 +252,80 @@ func (wpr *WarehousePostgresRepository) GetResponsibleUsers(ctx context.Context,
 
 	return users, totalCount, nil
 }
+
+// materialOccupancyExpr объем, занимаемый строкой закупленного материала
+const materialOccupancyExpr = `COALESCE(volume, 0)::BIGINT * COALESCE(total_quantity, 0)`
+
+// recalcWarehouseOccupancy пересчитывает заполняемость складов по объему и количеству размещенных на них
+// закупленных материалов. Вызывается в транзакции, изменяющей закупленные материалы
+func recalcWarehouseOccupancy(ctx context.Context, q querier, warehouseIds ...int64) error {
+	ids := make([]int64, 0, len(warehouseIds))
+	for _, id := range warehouseIds {
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s w
+		SET current_occupancy = (SELECT COALESCE(SUM(%s), 0) FROM %s WHERE warehouse_id = w.id)
+		WHERE w.id = ANY($1)`,
+		domain.TableWarehouse, materialOccupancyExpr, domain.TablePurchasedMaterials)
+
+	if _, err := q.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to recalculate warehouse occupancy: %v", err)
+	}
+
+	return nil
+}
+
+// checkWarehouseCapacity пересчитывает заполняемость склада, на котором транзакция размещает дополнительный объем
+// volume, и проверяет ее по максимальной вместимости склада. Строка склада блокируется до конца транзакции, поэтому
+// параллельные размещения на одном складе проверяются по очереди с учетом уже зафиксированных изменений друг друга.
+// Превышение допускается, если оно разрешено в компании склада. Склад без максимальной вместимости не ограничен
+func checkWarehouseCapacity(ctx context.Context, tx *sql.Tx, warehouseId, volume int64) error {
+	if warehouseId == 0 || volume <= 0 {
+		return recalcWarehouseOccupancy(ctx, tx, warehouseId)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COALESCE(w.max_capacity, 0), COALESCE(c.allow_over_capacity, false)
+		FROM %s w LEFT JOIN %s c ON c.id = w.company_id
+		WHERE w.id = $1 FOR UPDATE OF w`,
+		domain.TableWarehouse, domain.CompaniesTable)
+
+	var maxCapacity int64
+	var allowOverCapacity bool
+	if err := tx.QueryRowContext(ctx, query, warehouseId).Scan(&maxCapacity, &allowOverCapacity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrWarehouseNotFound
+		}
+
+		return err
+	}
+
+	// пересчет после блокировки видит размещения, зафиксированные параллельными транзакциями
+	if err := recalcWarehouseOccupancy(ctx, tx, warehouseId); err != nil {
+		return err
+	}
+
+	if maxCapacity <= 0 || allowOverCapacity {
+		return nil
+	}
+
+	var occupancy int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT current_occupancy FROM %s WHERE id = $1",
+		domain.TableWarehouse), warehouseId).Scan(&occupancy); err != nil {
+		return err
+	}
+
+	if occupancy > maxCapacity {
+		return domain.ErrWarehouseOverCapacity
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type WarehouseLocations interface {
+	Create(ctx context.Context, location domain.WarehouseLocation) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.WarehouseLocation, error)
+	Update(ctx context.Context, location domain.WarehouseLocation) error
+	Delete(ctx context.Context, id int64) error
+	GetByWarehouseId(ctx context.Context, warehouseId int64) ([]domain.WarehouseLocation, error)
+}
+
+type WarehouseLocationsPostgresRepository struct {
+	psql *sql.DB
+}
+
+func NewWarehouseLocationsPostgresRepository(psql *sql.DB) *WarehouseLocationsPostgresRepository {
+	return &WarehouseLocationsPostgresRepository{
+		psql: psql,
+	}
+}
+
+// locationColumns колонки места хранения. Порядок совпадает с scanLocation
+const locationColumns = `id, warehouse_id, company_id, COALESCE(parent_id, 0), type, code, name, capacity, description,
+	is_active, created_at, updated_at`
+
+// locationSubtreeQuery возвращает запрос id места хранения $param вместе со всеми вложенными местами хранения
+func locationSubtreeQuery(param int) string {
+	return fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM %[1]s WHERE id = $%[2]d
+			UNION
+			SELECT l.id FROM %[1]s l JOIN subtree s ON l.parent_id = s.id
+		)
+		SELECT id FROM subtree`,
+		domain.TableWarehouseLocations, param)
+}
+
+func (lr *WarehouseLocationsPostgresRepository) Create(ctx context.Context, l domain.WarehouseLocation) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (warehouse_id, company_id, parent_id, type, code, name, capacity, description, is_active,
+			created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		domain.TableWarehouseLocations)
+
+	var id int64
+	if err := lr.psql.QueryRowContext(ctx, query,
+		l.WarehouseID, l.CompanyID, l.ParentID, l.Type, l.Code, l.Name, l.Capacity, l.Description, l.IsActive,
+		l.CreatedAt, l.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, domain.ErrLocationAlreadyExists
+		}
+
+		return 0, fmt.Errorf("failed to insert warehouse location: %v", err)
+	}
+
+	return id, nil
+}
+
+// GetById возвращает место хранения с заполненностью, включающей материалы вложенных мест хранения
+func (lr *WarehouseLocationsPostgresRepository) GetById(ctx context.Context, id int64) (domain.WarehouseLocation, error) {
+	query := fmt.Sprintf(`
+		SELECT %s,
+			(SELECT COALESCE(SUM(%s), 0) FROM %s WHERE location_id IN (%s))
+		FROM %s WHERE id = $1`,
+		locationColumns, materialOccupancyExpr, domain.TablePurchasedMaterials, locationSubtreeQuery(1),
+		domain.TableWarehouseLocations)
+
+	var l domain.WarehouseLocation
+	if err := scanLocation(lr.psql.QueryRowContext(ctx, query, id), &l, &l.Occupancy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WarehouseLocation{}, domain.ErrLocationNotFound
+		}
+
+		return domain.WarehouseLocation{}, err
+	}
+
+	return l, nil
+}
+
+func (lr *WarehouseLocationsPostgresRepository) Update(ctx context.Context, l domain.WarehouseLocation) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET parent_id = NULLIF($1, 0), code = $2, name = $3, capacity = $4, description = $5, is_active = $6,
+			updated_at = $7
+		WHERE id = $8`,
+		domain.TableWarehouseLocations)
+
+	if _, err := lr.psql.ExecContext(ctx, query,
+		l.ParentID, l.Code, l.Name, l.Capacity, l.Description, l.IsActive, l.UpdatedAt, l.ID,
+	); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrLocationAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет место хранения, если в нем нет вложенных мест хранения и действующих материалов.
+// У архивных материалов ссылка на место хранения очищается
+func (lr *WarehouseLocationsPostgresRepository) Delete(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s l
+		WHERE l.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM %[1]s WHERE parent_id = l.id)
+		  AND NOT EXISTS (SELECT 1 FROM %[2]s WHERE location_id = l.id)
+		  AND NOT EXISTS (SELECT 1 FROM %[3]s WHERE location_id = l.id)`,
+		domain.TableWarehouseLocations, domain.TablePlanningMaterials, domain.TablePurchasedMaterials)
+
+	res, err := lr.psql.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrLocationInUse
+	}
+
+	return nil
+}
+
+// GetByWarehouseId возвращает все места хранения склада, упорядоченные по коду. Заполненность каждого места
+// хранения учитывает только материалы, размещенные непосредственно в нем
+func (lr *WarehouseLocationsPostgresRepository) GetByWarehouseId(ctx context.Context, warehouseId int64) ([]domain.WarehouseLocation, error) {
+	query := fmt.Sprintf(`
+		SELECT %s,
+			(SELECT COALESCE(SUM(%s), 0) FROM %s WHERE location_id = l.id)
+		FROM %s l WHERE warehouse_id = $1 ORDER BY code, id`,
+		locationColumns, materialOccupancyExpr, domain.TablePurchasedMaterials, domain.TableWarehouseLocations)
+
+	rows, err := lr.psql.QueryContext(ctx, query, warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		if err = rows.Close(); err != nil {
+			return
+		}
+	}(rows)
+
+	var locations []domain.WarehouseLocation
+
+	for rows.Next() {
+		var l domain.WarehouseLocation
+		if err = scanLocation(rows, &l, &l.Occupancy); err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, l)
+	}
+
+	return locations, rows.Err()
+}
+
+// placeMaterial размещает материал в месте хранения locationId, заполняя текстовую локацию кодом места хранения.
+// При locationId = 0 ссылка на место хранения снимается, а его код удаляется из текстовой локации
+func placeMaterial(ctx context.Context, q querier, material domain.Material, locationId int64) (domain.Material, error) {
+	if locationId == 0 {
+		if material.LocationID != 0 {
+			material.LocationID = 0
+			material.Location = ""
+		}
+
+		return material, nil
+	}
+
+	if err := q.QueryRowContext(ctx, fmt.Sprintf("SELECT code FROM %s WHERE id = $1", domain.TableWarehouseLocations),
+		locationId).Scan(&material.Location); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Material{}, domain.ErrLocationNotFound
+		}
+
+		return domain.Material{}, err
+	}
+
+	material.LocationID = locationId
+
+	return material, nil
+}
+
+func scanLocation(row scanner, l *domain.WarehouseLocation, extra ...any) error {
+	dest := []any{
+		&l.ID, &l.WarehouseID, &l.CompanyID, &l.ParentID, &l.Type, &l.Code, &l.Name, &l.Capacity, &l.Description,
+		&l.IsActive, &l.CreatedAt, &l.UpdatedAt,
+	}
+
+	return row.Scan(append(dest, extra...)...)
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
+)
+
+type ExchangeRates interface {
+	Create(ctx context.Context, rate domain.ExchangeRate) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.ExchangeRate, error)
+	GetList(ctx context.Context, params domain.ExchangeRateParams) ([]domain.ExchangeRate, int64, error)
+	Update(ctx context.Context, rate domain.ExchangeRate) error
+	Delete(ctx context.Context, id int64) error
+	GetActual(ctx context.Context, companyId int64, from, to string, date time.Time) (domain.ExchangeRate, error)
+}
+
+type ExchangeRatesRepository struct {
+	cfg  *config.Config
+	psql database.ExchangeRates
+}
+
+func NewExchangeRatesRepository(cfg *config.Config, psql *sql.DB) *ExchangeRatesRepository {
+	return &ExchangeRatesRepository{
+		cfg:  cfg,
+		psql: database.NewExchangeRatesPostgresRepository(psql),
+	}
+}
+
+func (er *ExchangeRatesRepository) Create(ctx context.Context, rate domain.ExchangeRate) (int64, error) {
+	return er.psql.Create(ctx, rate)
+}
+
+func (er *ExchangeRatesRepository) GetById(ctx context.Context, id int64) (domain.ExchangeRate, error) {
+	return er.psql.GetById(ctx, id)
+}
+
+func (er *ExchangeRatesRepository) GetList(ctx context.Context, params domain.ExchangeRateParams) ([]domain.ExchangeRate, int64, error) {
+	return er.psql.GetList(ctx, params)
+}
+
+func (er *ExchangeRatesRepository) Update(ctx context.Context, rate domain.ExchangeRate) error {
+	return er.psql.Update(ctx, rate)
+}
+
+func (er *ExchangeRatesRepository) Delete(ctx context.Context, id int64) error {
+	return er.psql.Delete(ctx, id)
+}
+
+func (er *ExchangeRatesRepository) GetActual(ctx context.Context, companyId int64, from, to string, date time.Time) (domain.ExchangeRate, error) {
+	return er.psql.GetActual(ctx, companyId, from, to, date)
+}
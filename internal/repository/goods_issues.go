@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type GoodsIssues interface {
+	Create(ctx context.Context, issue domain.GoodsIssue) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.GoodsIssue, error)
+	GetListByWarehouseId(ctx context.Context, warehouseId int64, params domain.Param) ([]domain.GoodsIssue, int64, error)
+	Post(ctx context.Context, issue domain.GoodsIssue) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type GoodsIssuesRepository struct {
+	cfg  *config.Config
+	psql database.GoodsIssues
+}
+
+func NewGoodsIssuesRepository(cfg *config.Config, psql *sql.DB) *GoodsIssuesRepository {
+	return &GoodsIssuesRepository{
+		cfg:  cfg,
+		psql: database.NewGoodsIssuesPostgresRepository(psql),
+	}
+}
+
+func (gr *GoodsIssuesRepository) Create(ctx context.Context, issue domain.GoodsIssue) (int64, error) {
+	return gr.psql.Create(ctx, issue)
+}
+
+func (gr *GoodsIssuesRepository) GetById(ctx context.Context, id int64) (domain.GoodsIssue, error) {
+	return gr.psql.GetById(ctx, id)
+}
+
+func (gr *GoodsIssuesRepository) GetListByWarehouseId(ctx context.Context, warehouseId int64, params domain.Param) ([]domain.GoodsIssue, int64, error) {
+	return gr.psql.GetListByWarehouseId(ctx, warehouseId, params)
+}
+
+func (gr *GoodsIssuesRepository) Post(ctx context.Context, issue domain.GoodsIssue) error {
+	return gr.psql.Post(ctx, issue)
+}
+
+func (gr *GoodsIssuesRepository) Delete(ctx context.Context, id int64) error {
+	return gr.psql.Delete(ctx, id)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type Items interface {
+	Create(ctx context.Context, item domain.Item) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Item, error)
+	Update(ctx context.Context, item domain.Item) error
+	Delete(ctx context.Context, id int64) error
+	GetList(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error)
+	Search(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error)
+}
+
+type ItemsRepository struct {
+	cfg  *config.Config
+	psql database.Items
+}
+
+func NewItemsRepository(cfg *config.Config, psql *sql.DB) *ItemsRepository {
+	return &ItemsRepository{
+		cfg:  cfg,
+		psql: database.NewItemsPostgresRepository(psql),
+	}
+}
+
+func (ir *ItemsRepository) Create(ctx context.Context, item domain.Item) (int64, error) {
+	return ir.psql.Create(ctx, item)
+}
+
+func (ir *ItemsRepository) GetById(ctx context.Context, id int64) (domain.Item, error) {
+	return ir.psql.GetById(ctx, id)
+}
+
+func (ir *ItemsRepository) Update(ctx context.Context, item domain.Item) error {
+	return ir.psql.Update(ctx, item)
+}
+
+func (ir *ItemsRepository) Delete(ctx context.Context, id int64) error {
+	return ir.psql.Delete(ctx, id)
+}
+
+func (ir *ItemsRepository) GetList(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error) {
+	return ir.psql.GetList(ctx, companyId, params)
+}
+
+func (ir *ItemsRepository) Search(ctx context.Context, companyId int64, params domain.Param) ([]domain.Item, int64, error) {
+	return ir.psql.Search(ctx, companyId, params)
+}
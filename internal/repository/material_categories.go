@@ -15,6 +15,9 @@ type MaterialCategory interface {
 	Delete(ctx context.Context, id, companyId int64) error
 	List(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error)
 	Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error)
+	GetByName(ctx context.Context, name string, companyId int64) (domain.MaterialCategory, error)
+	GetAll(ctx context.Context, companyId int64) ([]domain.MaterialCategory, error)
+	Move(ctx context.Context, id, parentId, companyId int64) error
 }
 
 type MaterialCategoriesRepository struct {
@@ -52,3 +55,15 @@ func (mc *MaterialCategoriesRepository) List(ctx context.Context, param domain.M
 func (mc *MaterialCategoriesRepository) Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error) {
 	return mc.db.Search(ctx, param)
 }
+
+func (mc *MaterialCategoriesRepository) GetByName(ctx context.Context, name string, companyId int64) (domain.MaterialCategory, error) {
+	return mc.db.GetByName(ctx, name, companyId)
+}
+
+func (mc *MaterialCategoriesRepository) GetAll(ctx context.Context, companyId int64) ([]domain.MaterialCategory, error) {
+	return mc.db.GetAll(ctx, companyId)
+}
+
+func (mc *MaterialCategoriesRepository) Move(ctx context.Context, id, parentId, companyId int64) error {
+	return mc.db.Move(ctx, id, parentId, companyId)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type MaterialRevisions interface {
+	GetList(ctx context.Context, materialType string, materialId int64, params domain.Param) ([]domain.MaterialRevision, int64, error)
+	GetByRevision(ctx context.Context, materialType string, materialId, revision int64) (domain.MaterialRevision, error)
+}
+
+type MaterialRevisionsRepository struct {
+	cfg  *config.Config
+	psql database.MaterialRevisions
+}
+
+func NewMaterialRevisionsRepository(cfg *config.Config, psql *sql.DB) *MaterialRevisionsRepository {
+	return &MaterialRevisionsRepository{
+		cfg:  cfg,
+		psql: database.NewMaterialRevisionsPostgresRepository(psql),
+	}
+}
+
+func (mrr *MaterialRevisionsRepository) GetList(ctx context.Context, materialType string, materialId int64, params domain.Param) ([]domain.MaterialRevision, int64, error) {
+	return mrr.psql.GetList(ctx, materialType, materialId, params)
+}
+
+func (mrr *MaterialRevisionsRepository) GetByRevision(ctx context.Context, materialType string, materialId, revision int64) (domain.MaterialRevision, error) {
+	return mrr.psql.GetByRevision(ctx, materialType, materialId, revision)
+}
@@ -6,22 +6,25 @@ import (
 	"github.com/rusystem/crm-api/internal/config"
 	"github.com/rusystem/crm-api/internal/repository/database"
 	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
 )
 
 type Materials interface {
 	CreatePlanning(ctx context.Context, material domain.Material) (int64, error)
-	UpdatePlanning(ctx context.Context, material domain.Material) error
+	UpdatePlanning(ctx context.Context, material domain.Material, userId int64) error
 	DeletePlanning(ctx context.Context, id int64) error
 	GetPlanningById(ctx context.Context, id int64) (domain.Material, error)
 	GetPlanningList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
-	MovePlanningToPurchased(ctx context.Context, id int64) (int64, int64, error)
+	MovePlanningToPurchased(ctx context.Context, id, userId int64) (int64, int64, error)
+	ReceivePlanning(ctx context.Context, inp domain.ReceivePlanningMaterial, userId int64) (int64, int64, error)
 
-	CreatePurchased(ctx context.Context, material domain.Material) (int64, int64, error)
-	UpdatePurchased(ctx context.Context, material domain.Material) error
+	CreatePurchased(ctx context.Context, material domain.Material, userId int64) (int64, int64, error)
+	UpdatePurchased(ctx context.Context, update domain.MaterialUpdate, userId int64) error
 	DeletePurchased(ctx context.Context, id int64) error
 	GetPurchasedById(ctx context.Context, id int64) (domain.Material, error)
 	GetPurchasedList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	MovePurchasedToArchive(ctx context.Context, id int64) error
+	TransferPurchased(ctx context.Context, inp domain.TransferPurchasedMaterial, userId int64) (int64, int64, error)
 
 	GetPlanningArchiveById(ctx context.Context, id int64) (domain.Material, error)
 	GetPurchasedArchiveById(ctx context.Context, id int64) (domain.Material, error)
@@ -29,9 +32,32 @@ type Materials interface {
 	GetPurchasedArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	DeletePlanningArchive(ctx context.Context, id int64) error
 	DeletePurchasedArchive(ctx context.Context, id int64) error
+	RestorePlanningArchive(ctx context.Context, id int64) (int64, error)
+	RestorePurchasedArchive(ctx context.Context, id, userId int64) (int64, error)
 
-	Search(ctx context.Context, param domain.MaterialParams) ([]domain.Material, int64, error)
+	Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialSearchResult, int64, error)
 	GetIncomeHistoryByWarehouseId(ctx context.Context, id int64, param domain.Param) ([]domain.Material, int64, error)
+	GetPurchasedByLocationId(ctx context.Context, locationId int64, params domain.Param) ([]domain.Material, int64, error)
+	GetLowStockByWarehouseId(ctx context.Context, id int64, params domain.Param) ([]domain.LowStockMaterial, int64, error)
+	GetLowStock(ctx context.Context) ([]domain.LowStockMaterial, error)
+
+	GetExpiringPurchased(ctx context.Context, companyId, warehouseId int64, before time.Time) ([]domain.ExpiringWarehouse, error)
+	GetFefoCandidates(ctx context.Context, params domain.FefoParams) ([]domain.FefoPick, error)
+	GetExpiredPurchasedIds(ctx context.Context) ([]int64, error)
+	WriteOffPurchased(ctx context.Context, id, userId int64, comment string) error
+
+	BulkCreatePlanning(ctx context.Context, materials []domain.Material, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkUpdatePlanning(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkDeletePlanning(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkMovePlanningToPurchased(ctx context.Context, ids []int64, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkCreatePurchased(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkUpdatePurchased(ctx context.Context, updates []domain.MaterialUpdate, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkDeletePurchased(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+	BulkMovePurchasedToArchive(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error)
+
+	StreamList(ctx context.Context, materialType string, archive bool, params domain.MaterialParams, fn func(domain.Material) error) error
+
+	RecalculateTotals(ctx context.Context, dryRun bool) (map[string]int64, error)
 }
 
 type MaterialsRepository struct {
@@ -50,8 +76,8 @@ func (mr *MaterialsRepository) CreatePlanning(ctx context.Context, material doma
 	return mr.psql.CreatePlanning(ctx, material)
 }
 
-func (mr *MaterialsRepository) UpdatePlanning(ctx context.Context, material domain.Material) error {
-	return mr.psql.UpdatePlanning(ctx, material)
+func (mr *MaterialsRepository) UpdatePlanning(ctx context.Context, material domain.Material, userId int64) error {
+	return mr.psql.UpdatePlanning(ctx, material, userId)
 }
 
 func (mr *MaterialsRepository) DeletePlanning(ctx context.Context, id int64) error {
@@ -66,16 +92,20 @@ func (mr *MaterialsRepository) GetPlanningList(ctx context.Context, params domai
 	return mr.psql.GetPlanningList(ctx, params)
 }
 
-func (mr *MaterialsRepository) MovePlanningToPurchased(ctx context.Context, id int64) (int64, int64, error) {
-	return mr.psql.MovePlanningToPurchased(ctx, id)
+func (mr *MaterialsRepository) MovePlanningToPurchased(ctx context.Context, id, userId int64) (int64, int64, error) {
+	return mr.psql.MovePlanningToPurchased(ctx, id, userId)
+}
+
+func (mr *MaterialsRepository) ReceivePlanning(ctx context.Context, inp domain.ReceivePlanningMaterial, userId int64) (int64, int64, error) {
+	return mr.psql.ReceivePlanning(ctx, inp, userId)
 }
 
-func (mr *MaterialsRepository) CreatePurchased(ctx context.Context, material domain.Material) (int64, int64, error) {
-	return mr.psql.CreatePurchased(ctx, material)
+func (mr *MaterialsRepository) CreatePurchased(ctx context.Context, material domain.Material, userId int64) (int64, int64, error) {
+	return mr.psql.CreatePurchased(ctx, material, userId)
 }
 
-func (mr *MaterialsRepository) UpdatePurchased(ctx context.Context, material domain.Material) error {
-	return mr.psql.UpdatePurchased(ctx, material)
+func (mr *MaterialsRepository) UpdatePurchased(ctx context.Context, update domain.MaterialUpdate, userId int64) error {
+	return mr.psql.UpdatePurchased(ctx, update, userId)
 }
 
 func (mr *MaterialsRepository) DeletePurchased(ctx context.Context, id int64) error {
@@ -94,6 +124,10 @@ func (mr *MaterialsRepository) MovePurchasedToArchive(ctx context.Context, id in
 	return mr.psql.MovePurchasedToArchive(ctx, id)
 }
 
+func (mr *MaterialsRepository) TransferPurchased(ctx context.Context, inp domain.TransferPurchasedMaterial, userId int64) (int64, int64, error) {
+	return mr.psql.TransferPurchased(ctx, inp, userId)
+}
+
 func (mr *MaterialsRepository) GetPlanningArchiveById(ctx context.Context, id int64) (domain.Material, error) {
 	return mr.psql.GetPlanningArchiveById(ctx, id)
 }
@@ -118,10 +152,86 @@ func (mr *MaterialsRepository) DeletePurchasedArchive(ctx context.Context, id in
 	return mr.psql.DeletePurchasedArchive(ctx, id)
 }
 
-func (mr *MaterialsRepository) Search(ctx context.Context, param domain.MaterialParams) ([]domain.Material, int64, error) {
+func (mr *MaterialsRepository) RestorePlanningArchive(ctx context.Context, id int64) (int64, error) {
+	return mr.psql.RestorePlanningArchive(ctx, id)
+}
+
+func (mr *MaterialsRepository) RestorePurchasedArchive(ctx context.Context, id, userId int64) (int64, error) {
+	return mr.psql.RestorePurchasedArchive(ctx, id, userId)
+}
+
+func (mr *MaterialsRepository) Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialSearchResult, int64, error) {
 	return mr.psql.Search(ctx, param)
 }
 
 func (mr *MaterialsRepository) GetIncomeHistoryByWarehouseId(ctx context.Context, id int64, param domain.Param) ([]domain.Material, int64, error) {
 	return mr.psql.GetIncomeHistoryByWarehouseId(ctx, id, param)
 }
+
+func (mr *MaterialsRepository) GetPurchasedByLocationId(ctx context.Context, locationId int64, params domain.Param) ([]domain.Material, int64, error) {
+	return mr.psql.GetPurchasedByLocationId(ctx, locationId, params)
+}
+
+func (mr *MaterialsRepository) GetLowStockByWarehouseId(ctx context.Context, id int64, params domain.Param) ([]domain.LowStockMaterial, int64, error) {
+	return mr.psql.GetLowStockByWarehouseId(ctx, id, params)
+}
+
+func (mr *MaterialsRepository) GetLowStock(ctx context.Context) ([]domain.LowStockMaterial, error) {
+	return mr.psql.GetLowStock(ctx)
+}
+
+func (mr *MaterialsRepository) GetExpiringPurchased(ctx context.Context, companyId, warehouseId int64, before time.Time) ([]domain.ExpiringWarehouse, error) {
+	return mr.psql.GetExpiringPurchased(ctx, companyId, warehouseId, before)
+}
+
+func (mr *MaterialsRepository) GetFefoCandidates(ctx context.Context, params domain.FefoParams) ([]domain.FefoPick, error) {
+	return mr.psql.GetFefoCandidates(ctx, params)
+}
+
+func (mr *MaterialsRepository) GetExpiredPurchasedIds(ctx context.Context) ([]int64, error) {
+	return mr.psql.GetExpiredPurchasedIds(ctx)
+}
+
+func (mr *MaterialsRepository) WriteOffPurchased(ctx context.Context, id, userId int64, comment string) error {
+	return mr.psql.WriteOffPurchased(ctx, id, userId, comment)
+}
+
+func (mr *MaterialsRepository) BulkCreatePlanning(ctx context.Context, materials []domain.Material, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkCreatePlanning(ctx, materials, atomic)
+}
+
+func (mr *MaterialsRepository) BulkUpdatePlanning(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkUpdatePlanning(ctx, materials, userId, atomic)
+}
+
+func (mr *MaterialsRepository) BulkDeletePlanning(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkDeletePlanning(ctx, ids, atomic)
+}
+
+func (mr *MaterialsRepository) BulkMovePlanningToPurchased(ctx context.Context, ids []int64, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkMovePlanningToPurchased(ctx, ids, userId, atomic)
+}
+
+func (mr *MaterialsRepository) BulkCreatePurchased(ctx context.Context, materials []domain.Material, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkCreatePurchased(ctx, materials, userId, atomic)
+}
+
+func (mr *MaterialsRepository) BulkUpdatePurchased(ctx context.Context, updates []domain.MaterialUpdate, userId int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkUpdatePurchased(ctx, updates, userId, atomic)
+}
+
+func (mr *MaterialsRepository) BulkDeletePurchased(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkDeletePurchased(ctx, ids, atomic)
+}
+
+func (mr *MaterialsRepository) BulkMovePurchasedToArchive(ctx context.Context, ids []int64, atomic bool) ([]domain.BulkItemResult, bool, error) {
+	return mr.psql.BulkMovePurchasedToArchive(ctx, ids, atomic)
+}
+
+func (mr *MaterialsRepository) StreamList(ctx context.Context, materialType string, archive bool, params domain.MaterialParams, fn func(domain.Material) error) error {
+	return mr.psql.StreamList(ctx, materialType, archive, params, fn)
+}
+
+func (mr *MaterialsRepository) RecalculateTotals(ctx context.Context, dryRun bool) (map[string]int64, error) {
+	return mr.psql.RecalculateTotals(ctx, dryRun)
+}
@@ -14,6 +14,8 @@ type UnitOfMeasure interface {
 	Delete(ctx context.Context, id, companyId int64) error
 	GetById(ctx context.Context, id, companyId int64) (domain.UnitOfMeasure, error)
 	List(ctx context.Context, param domain.Param) ([]domain.UnitOfMeasure, int64, error)
+	GetByAbbreviation(ctx context.Context, abbreviation string, companyId int64) (domain.UnitOfMeasure, error)
+	HasDerivedUnits(ctx context.Context, id int64) (bool, error)
 }
 
 type UnitOfMeasureRepository struct {
@@ -47,3 +49,11 @@ func (umr *UnitOfMeasureRepository) GetById(ctx context.Context, id, companyId i
 func (umr *UnitOfMeasureRepository) List(ctx context.Context, param domain.Param) ([]domain.UnitOfMeasure, int64, error) {
 	return umr.db.List(ctx, param)
 }
+
+func (umr *UnitOfMeasureRepository) GetByAbbreviation(ctx context.Context, abbreviation string, companyId int64) (domain.UnitOfMeasure, error) {
+	return umr.db.GetByAbbreviation(ctx, abbreviation, companyId)
+}
+
+func (umr *UnitOfMeasureRepository) HasDerivedUnits(ctx context.Context, id int64) (bool, error) {
+	return umr.db.HasDerivedUnits(ctx, id)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"time"
+)
+
+type Notifications interface {
+	CreateIfNotRecent(ctx context.Context, notification domain.Notification, since time.Time) (int64, error)
+	GetListByUserId(ctx context.Context, userId int64, unreadOnly bool, params domain.Param) ([]domain.Notification, int64, error)
+	MarkAsRead(ctx context.Context, id, userId int64) error
+	MarkAllAsRead(ctx context.Context, userId int64) error
+	GetSystemRecipients(ctx context.Context, companyId int64) ([]int64, error)
+}
+
+type NotificationsRepository struct {
+	cfg  *config.Config
+	psql database.Notifications
+}
+
+func NewNotificationsRepository(cfg *config.Config, psql *sql.DB) *NotificationsRepository {
+	return &NotificationsRepository{
+		cfg:  cfg,
+		psql: database.NewNotificationsPostgresRepository(psql),
+	}
+}
+
+func (nr *NotificationsRepository) CreateIfNotRecent(ctx context.Context, notification domain.Notification, since time.Time) (int64, error) {
+	return nr.psql.CreateIfNotRecent(ctx, notification, since)
+}
+
+func (nr *NotificationsRepository) GetListByUserId(ctx context.Context, userId int64, unreadOnly bool, params domain.Param) ([]domain.Notification, int64, error) {
+	return nr.psql.GetListByUserId(ctx, userId, unreadOnly, params)
+}
+
+func (nr *NotificationsRepository) MarkAsRead(ctx context.Context, id, userId int64) error {
+	return nr.psql.MarkAsRead(ctx, id, userId)
+}
+
+func (nr *NotificationsRepository) MarkAllAsRead(ctx context.Context, userId int64) error {
+	return nr.psql.MarkAllAsRead(ctx, userId)
+}
+
+func (nr *NotificationsRepository) GetSystemRecipients(ctx context.Context, companyId int64) ([]int64, error) {
+	return nr.psql.GetSystemRecipients(ctx, companyId)
+}
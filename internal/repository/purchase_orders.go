@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type PurchaseOrders interface {
+	Create(ctx context.Context, order domain.PurchaseOrder) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.PurchaseOrder, error)
+	GetList(ctx context.Context, params domain.PurchaseOrderParams) ([]domain.PurchaseOrder, int64, error)
+	Update(ctx context.Context, order domain.PurchaseOrder) error
+	SetStatus(ctx context.Context, id int64, from []string, to string) error
+	Receive(ctx context.Context, id int64, inp domain.ReceivePurchaseOrder, userId int64) ([]int64, error)
+}
+
+type PurchaseOrdersRepository struct {
+	cfg  *config.Config
+	psql database.PurchaseOrders
+}
+
+func NewPurchaseOrdersRepository(cfg *config.Config, psql *sql.DB) *PurchaseOrdersRepository {
+	return &PurchaseOrdersRepository{
+		cfg:  cfg,
+		psql: database.NewPurchaseOrdersPostgresRepository(psql),
+	}
+}
+
+func (pr *PurchaseOrdersRepository) Create(ctx context.Context, order domain.PurchaseOrder) (int64, error) {
+	return pr.psql.Create(ctx, order)
+}
+
+func (pr *PurchaseOrdersRepository) GetById(ctx context.Context, id int64) (domain.PurchaseOrder, error) {
+	return pr.psql.GetById(ctx, id)
+}
+
+func (pr *PurchaseOrdersRepository) GetList(ctx context.Context, params domain.PurchaseOrderParams) ([]domain.PurchaseOrder, int64, error) {
+	return pr.psql.GetList(ctx, params)
+}
+
+func (pr *PurchaseOrdersRepository) Update(ctx context.Context, order domain.PurchaseOrder) error {
+	return pr.psql.Update(ctx, order)
+}
+
+func (pr *PurchaseOrdersRepository) SetStatus(ctx context.Context, id int64, from []string, to string) error {
+	return pr.psql.SetStatus(ctx, id, from, to)
+}
+
+func (pr *PurchaseOrdersRepository) Receive(ctx context.Context, id int64, inp domain.ReceivePurchaseOrder, userId int64) ([]int64, error) {
+	return pr.psql.Receive(ctx, id, inp, userId)
+}
@@ -7,27 +7,49 @@ import (
 )
 
 type Repository struct {
-	Auth             Auth
-	User             User
-	Company          Company
-	MaterialCategory MaterialCategory
-	Materials        Materials
-	Sections         Sections
-	Suppliers        Suppliers
-	Warehouse        Warehouse
-	UnitOfMeasure    UnitOfMeasure
+	Auth               Auth
+	User               User
+	Company            Company
+	MaterialCategory   MaterialCategory
+	Materials          Materials
+	Sections           Sections
+	Suppliers          Suppliers
+	Warehouse          Warehouse
+	UnitOfMeasure      UnitOfMeasure
+	StockMovements     StockMovements
+	Reservations       Reservations
+	Notifications      Notifications
+	Audit              Audit
+	MaterialRevisions  MaterialRevisions
+	Items              Items
+	WarehouseLocations WarehouseLocations
+	Stocktakes         Stocktakes
+	GoodsIssues        GoodsIssues
+	PurchaseOrders     PurchaseOrders
+	ExchangeRates      ExchangeRates
 }
 
 func New(cfg *config.Config, cache *cache.MemoryCache, pc *sql.DB) *Repository {
 	return &Repository{
-		Auth:             NewAuthRepository(cfg, cache, pc),
-		User:             NewUserRepository(cfg, cache, pc),
-		Company:          NewCompanyRepository(cfg, cache, pc),
-		MaterialCategory: NewMaterialCategoriesRepository(cfg, pc),
-		Materials:        NewMaterialsRepository(cfg, pc),
-		Sections:         NewSectionsRepository(cfg, pc),
-		Suppliers:        NewSuppliersRepository(cfg, pc),
-		Warehouse:        NewWarehouseRepository(cfg, pc),
-		UnitOfMeasure:    NewUnitOfMeasureRepository(cfg, pc),
+		Auth:               NewAuthRepository(cfg, cache, pc),
+		User:               NewUserRepository(cfg, cache, pc),
+		Company:            NewCompanyRepository(cfg, cache, pc),
+		MaterialCategory:   NewMaterialCategoriesRepository(cfg, pc),
+		Materials:          NewMaterialsRepository(cfg, pc),
+		Sections:           NewSectionsRepository(cfg, pc),
+		Suppliers:          NewSuppliersRepository(cfg, pc),
+		Warehouse:          NewWarehouseRepository(cfg, pc),
+		UnitOfMeasure:      NewUnitOfMeasureRepository(cfg, pc),
+		StockMovements:     NewStockMovementsRepository(cfg, pc),
+		Reservations:       NewReservationsRepository(cfg, pc),
+		Notifications:      NewNotificationsRepository(cfg, pc),
+		Audit:              NewAuditRepository(cfg, pc),
+		MaterialRevisions:  NewMaterialRevisionsRepository(cfg, pc),
+		Items:              NewItemsRepository(cfg, pc),
+		WarehouseLocations: NewWarehouseLocationsRepository(cfg, pc),
+		Stocktakes:         NewStocktakesRepository(cfg, pc),
+		GoodsIssues:        NewGoodsIssuesRepository(cfg, pc),
+		PurchaseOrders:     NewPurchaseOrdersRepository(cfg, pc),
+		ExchangeRates:      NewExchangeRatesRepository(cfg, pc),
 	}
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type Reservations interface {
+	Create(ctx context.Context, reservation domain.Reservation) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Reservation, error)
+	GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.Reservation, int64, error)
+	Release(ctx context.Context, id int64) error
+	Consume(ctx context.Context, id, userId int64) error
+	ReleaseExpired(ctx context.Context) (int64, error)
+}
+
+type ReservationsRepository struct {
+	cfg  *config.Config
+	psql database.Reservations
+}
+
+func NewReservationsRepository(cfg *config.Config, psql *sql.DB) *ReservationsRepository {
+	return &ReservationsRepository{
+		cfg:  cfg,
+		psql: database.NewReservationsPostgresRepository(psql),
+	}
+}
+
+func (rr *ReservationsRepository) Create(ctx context.Context, reservation domain.Reservation) (int64, error) {
+	return rr.psql.Create(ctx, reservation)
+}
+
+func (rr *ReservationsRepository) GetById(ctx context.Context, id int64) (domain.Reservation, error) {
+	return rr.psql.GetById(ctx, id)
+}
+
+func (rr *ReservationsRepository) GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.Reservation, int64, error) {
+	return rr.psql.GetListByPurchasedId(ctx, id, params)
+}
+
+func (rr *ReservationsRepository) Release(ctx context.Context, id int64) error {
+	return rr.psql.Release(ctx, id)
+}
+
+func (rr *ReservationsRepository) Consume(ctx context.Context, id, userId int64) error {
+	return rr.psql.Consume(ctx, id, userId)
+}
+
+func (rr *ReservationsRepository) ReleaseExpired(ctx context.Context) (int64, error) {
+	return rr.psql.ReleaseExpired(ctx)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type StockMovements interface {
+	Create(ctx context.Context, movement domain.StockMovement) (int64, error)
+	GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.StockMovement, int64, error)
+	GetLedgerBalance(ctx context.Context, id int64) (int64, error)
+}
+
+type StockMovementsRepository struct {
+	cfg  *config.Config
+	psql database.StockMovements
+}
+
+func NewStockMovementsRepository(cfg *config.Config, psql *sql.DB) *StockMovementsRepository {
+	return &StockMovementsRepository{
+		cfg:  cfg,
+		psql: database.NewStockMovementsPostgresRepository(psql),
+	}
+}
+
+func (smr *StockMovementsRepository) Create(ctx context.Context, movement domain.StockMovement) (int64, error) {
+	return smr.psql.Create(ctx, movement)
+}
+
+func (smr *StockMovementsRepository) GetListByPurchasedId(ctx context.Context, id int64, params domain.Param) ([]domain.StockMovement, int64, error) {
+	return smr.psql.GetListByPurchasedId(ctx, id, params)
+}
+
+func (smr *StockMovementsRepository) GetLedgerBalance(ctx context.Context, id int64) (int64, error) {
+	return smr.psql.GetLedgerBalance(ctx, id)
+}
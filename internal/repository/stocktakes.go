@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type Stocktakes interface {
+	Create(ctx context.Context, stocktake domain.Stocktake) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.Stocktake, error)
+	GetList(ctx context.Context, params domain.StocktakeParams) ([]domain.Stocktake, int64, error)
+	GetLines(ctx context.Context, id int64) ([]domain.StocktakeLine, error)
+	SaveCounts(ctx context.Context, id int64, lines []domain.StocktakeLine) error
+	Approve(ctx context.Context, stocktake domain.Stocktake) error
+	Cancel(ctx context.Context, stocktake domain.Stocktake) error
+}
+
+type StocktakesRepository struct {
+	cfg  *config.Config
+	psql database.Stocktakes
+}
+
+func NewStocktakesRepository(cfg *config.Config, psql *sql.DB) *StocktakesRepository {
+	return &StocktakesRepository{
+		cfg:  cfg,
+		psql: database.NewStocktakesPostgresRepository(psql),
+	}
+}
+
+func (sr *StocktakesRepository) Create(ctx context.Context, stocktake domain.Stocktake) (int64, error) {
+	return sr.psql.Create(ctx, stocktake)
+}
+
+func (sr *StocktakesRepository) GetById(ctx context.Context, id int64) (domain.Stocktake, error) {
+	return sr.psql.GetById(ctx, id)
+}
+
+func (sr *StocktakesRepository) GetList(ctx context.Context, params domain.StocktakeParams) ([]domain.Stocktake, int64, error) {
+	return sr.psql.GetList(ctx, params)
+}
+
+func (sr *StocktakesRepository) GetLines(ctx context.Context, id int64) ([]domain.StocktakeLine, error) {
+	return sr.psql.GetLines(ctx, id)
+}
+
+func (sr *StocktakesRepository) SaveCounts(ctx context.Context, id int64, lines []domain.StocktakeLine) error {
+	return sr.psql.SaveCounts(ctx, id, lines)
+}
+
+func (sr *StocktakesRepository) Approve(ctx context.Context, stocktake domain.Stocktake) error {
+	return sr.psql.Approve(ctx, stocktake)
+}
+
+func (sr *StocktakesRepository) Cancel(ctx context.Context, stocktake domain.Stocktake) error {
+	return sr.psql.Cancel(ctx, stocktake)
+}
@@ -14,6 +14,7 @@ type Suppliers interface {
 	Update(ctx context.Context, supplier domain.Supplier) error
 	Delete(ctx context.Context, id int64) error
 	GetListByCompanyId(ctx context.Context, id int64, param domain.Param) ([]domain.Supplier, int64, error)
+	GetIdByNameOrTaxId(ctx context.Context, companyId int64, value string) (int64, error)
 }
 
 type SuppliersRepository struct {
@@ -47,3 +48,7 @@ func (sr *SuppliersRepository) Delete(ctx context.Context, id int64) error {
 func (sr *SuppliersRepository) GetListByCompanyId(ctx context.Context, id int64, param domain.Param) ([]domain.Supplier, int64, error) {
 	return sr.psql.GetListByCompanyId(ctx, id, param)
 }
+
+func (sr *SuppliersRepository) GetIdByNameOrTaxId(ctx context.Context, companyId int64, value string) (int64, error) {
+	return sr.psql.GetIdByNameOrTaxId(ctx, companyId, value)
+}
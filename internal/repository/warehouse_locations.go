@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository/database"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+type WarehouseLocations interface {
+	Create(ctx context.Context, location domain.WarehouseLocation) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.WarehouseLocation, error)
+	Update(ctx context.Context, location domain.WarehouseLocation) error
+	Delete(ctx context.Context, id int64) error
+	GetByWarehouseId(ctx context.Context, warehouseId int64) ([]domain.WarehouseLocation, error)
+}
+
+type WarehouseLocationsRepository struct {
+	cfg  *config.Config
+	psql database.WarehouseLocations
+}
+
+func NewWarehouseLocationsRepository(cfg *config.Config, psql *sql.DB) *WarehouseLocationsRepository {
+	return &WarehouseLocationsRepository{
+		cfg:  cfg,
+		psql: database.NewWarehouseLocationsPostgresRepository(psql),
+	}
+}
+
+func (lr *WarehouseLocationsRepository) Create(ctx context.Context, location domain.WarehouseLocation) (int64, error) {
+	return lr.psql.Create(ctx, location)
+}
+
+func (lr *WarehouseLocationsRepository) GetById(ctx context.Context, id int64) (domain.WarehouseLocation, error) {
+	return lr.psql.GetById(ctx, id)
+}
+
+func (lr *WarehouseLocationsRepository) Update(ctx context.Context, location domain.WarehouseLocation) error {
+	return lr.psql.Update(ctx, location)
+}
+
+func (lr *WarehouseLocationsRepository) Delete(ctx context.Context, id int64) error {
+	return lr.psql.Delete(ctx, id)
+}
+
+func (lr *WarehouseLocationsRepository) GetByWarehouseId(ctx context.Context, warehouseId int64) ([]domain.WarehouseLocation, error) {
+	return lr.psql.GetByWarehouseId(ctx, warehouseId)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"github.com/rusystem/crm-api/tools"
+	"reflect"
+)
+
+// auditRedactedFields поля, значения которых не попадают в журнал аудита, фиксируется только факт изменения
+var auditRedactedFields = map[string]bool{
+	"password_hash": true,
+	"password":      true,
+}
+
+// auditIgnoredFields поля, которые меняются при каждом сохранении и не несут информации об изменении
+var auditIgnoredFields = map[string]bool{
+	"last_updated": true,
+	"updated_at":   true,
+}
+
+const auditRedactedValue = "[redacted]"
+
+type Audit interface {
+	GetList(ctx context.Context, info domain.JWTInfo, params domain.AuditParams) ([]domain.AuditLog, int64, error)
+}
+
+type AuditService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewAuditService(cfg *config.Config, repo *repository.Repository) *AuditService {
+	return &AuditService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *AuditService) GetList(ctx context.Context, info domain.JWTInfo, params domain.AuditParams) ([]domain.AuditLog, int64, error) {
+	// журнал других компаний доступен только с полным доступом
+	if params.CompanyId == 0 || !tools.IsFullAccessSection(info.Sections) {
+		params.CompanyId = info.CompanyId
+	}
+
+	return s.repo.Audit.GetList(ctx, params)
+}
+
+// recordAudit записывает действие пользователя в журнал аудита компании companyId, которой принадлежит сущность.
+// before и after - состояние сущности до и после действия, nil для создания и удаления соответственно.
+// Ошибка записи не прерывает основную операцию
+func recordAudit(ctx context.Context, repo *repository.Repository, info domain.JWTInfo, companyId int64, entityType string,
+	entityId int64, action string, before, after interface{}) {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		logger.Error(fmt.Sprintf("audit: failed to build diff for %s %d: %v", entityType, entityId, err))
+		return
+	}
+
+	if action == domain.AuditActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	if err = repo.Audit.Create(ctx, domain.AuditLog{
+		UserID:     info.UserId,
+		CompanyID:  companyId,
+		EntityType: entityType,
+		EntityID:   entityId,
+		Action:     action,
+		Changes:    changes,
+	}); err != nil {
+		logger.Error(fmt.Sprintf("audit: failed to record %s of %s %d: %v", action, entityType, entityId, err))
+	}
+}
+
+// auditDiff сравнивает JSON-представления сущности до и после изменения и возвращает измененные поля
+func auditDiff(before, after interface{}) (map[string]domain.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]domain.AuditChange)
+
+	for key := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			afterFields[key] = nil
+		}
+	}
+
+	for key, afterValue := range afterFields {
+		beforeValue := beforeFields[key]
+
+		if auditIgnoredFields[key] || reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		if auditRedactedFields[key] {
+			change := domain.AuditChange{}
+			if beforeValue != nil {
+				change.Before = auditRedactedValue
+			}
+
+			if afterValue != nil {
+				change.After = auditRedactedValue
+			}
+
+			changes[key] = change
+			continue
+		}
+
+		changes[key] = domain.AuditChange{Before: beforeValue, After: afterValue}
+	}
+
+	return changes, nil
+}
+
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if entity == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
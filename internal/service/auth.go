@@ -139,7 +139,7 @@ func (as *AuthServices) SignUp(c *gin.Context, input domain.SignUp, info domain.
 		return 0, false, domain.ErrSectionsNotAllowed
 	}
 
-	id, err := as.repo.User.Create(c.Request.Context(), domain.User{
+	user := domain.User{
 		CompanyID:                input.CompanyId,
 		Username:                 input.Username,
 		Name:                     input.Name,
@@ -155,7 +155,9 @@ func (as *AuthServices) SignUp(c *gin.Context, input domain.SignUp, info domain.
 		IsSendSystemNotification: input.IsSendSystemNotification,
 		Sections:                 input.Sections,
 		Position:                 input.Position,
-	})
+	}
+
+	id, err := as.repo.User.Create(c.Request.Context(), user)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserAlreadyExists) {
 			return 0, false, domain.ErrUserAlreadyExists
@@ -164,6 +166,9 @@ func (as *AuthServices) SignUp(c *gin.Context, input domain.SignUp, info domain.
 		return 0, false, domain.ErrCreateUser
 	}
 
+	user.ID = id
+	recordAudit(c.Request.Context(), as.repo, info, user.CompanyID, domain.AuditEntityUser, id, domain.AuditActionCreate, nil, user)
+
 	return id, false, nil
 }
 
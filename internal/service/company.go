@@ -12,9 +12,9 @@ import (
 
 type Company interface {
 	GetById(ctx context.Context, id int64) (domain.Company, error)
-	Create(ctx context.Context, company domain.Company) (int64, error)
+	Create(ctx context.Context, company domain.Company, info domain.JWTInfo) (int64, error)
 	Update(ctx context.Context, company domain.CompanyUpdate, info domain.JWTInfo) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, info domain.JWTInfo) error
 	IsExist(ctx context.Context, id int64) (bool, error)
 	List(ctx context.Context, param domain.Param) ([]domain.Company, int64, error)
 }
@@ -35,8 +35,24 @@ func (c *CompanyService) GetById(ctx context.Context, id int64) (domain.Company,
 	return c.repo.Company.GetById(ctx, id)
 }
 
-func (c *CompanyService) Create(ctx context.Context, company domain.Company) (int64, error) {
-	return c.repo.Company.Create(ctx, company)
+func (c *CompanyService) Create(ctx context.Context, company domain.Company, info domain.JWTInfo) (int64, error) {
+	if company.DefaultCurrency == "" {
+		company.DefaultCurrency = domain.DefaultCurrency
+	}
+
+	if !domain.IsValidCurrency(company.DefaultCurrency) {
+		return 0, domain.ErrInvalidCurrency
+	}
+
+	id, err := c.repo.Company.Create(ctx, company)
+	if err != nil {
+		return 0, err
+	}
+
+	company.ID = id
+	recordAudit(ctx, c.repo, info, id, domain.AuditEntityCompany, id, domain.AuditActionCreate, nil, company)
+
+	return id, nil
 }
 
 func (c *CompanyService) Update(ctx context.Context, req domain.CompanyUpdate, info domain.JWTInfo) error {
@@ -53,6 +69,8 @@ func (c *CompanyService) Update(ctx context.Context, req domain.CompanyUpdate, i
 		return domain.ErrNotAllowed
 	}
 
+	before := company
+
 	if req.NameRu != nil {
 		company.NameRu = *req.NameRu
 	}
@@ -90,6 +108,18 @@ func (c *CompanyService) Update(ctx context.Context, req domain.CompanyUpdate, i
 		}
 	}
 
+	if req.AllowOverCapacity != nil {
+		company.AllowOverCapacity = *req.AllowOverCapacity
+	}
+
+	if req.DefaultCurrency != nil {
+		if !domain.IsValidCurrency(*req.DefaultCurrency) {
+			return domain.ErrInvalidCurrency
+		}
+
+		company.DefaultCurrency = *req.DefaultCurrency
+	}
+
 	if (req.IsApproved != nil || req.IsActive != nil) && !tools.IsFullAccessSection(info.Sections) {
 		return domain.ErrNotAllowed
 	}
@@ -102,11 +132,28 @@ func (c *CompanyService) Update(ctx context.Context, req domain.CompanyUpdate, i
 		company.IsActive = *req.IsActive
 	}
 
-	return c.repo.Company.Update(ctx, company)
+	if err = c.repo.Company.Update(ctx, company); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, c.repo, info, company.ID, domain.AuditEntityCompany, company.ID, domain.AuditActionUpdate, before, company)
+
+	return nil
 }
 
-func (c *CompanyService) Delete(ctx context.Context, id int64) error {
-	return c.repo.Company.Delete(ctx, id)
+func (c *CompanyService) Delete(ctx context.Context, id int64, info domain.JWTInfo) error {
+	company, err := c.repo.Company.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = c.repo.Company.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, c.repo, info, id, domain.AuditEntityCompany, id, domain.AuditActionDelete, company, nil)
+
+	return nil
 }
 
 func (c *CompanyService) IsExist(ctx context.Context, id int64) (bool, error) {
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/tools"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+// ratePrecision количество знаков после запятой при расчете обратного и кросс-курса
+const ratePrecision = 10
+
+type ExchangeRates interface {
+	Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateExchangeRate) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.ExchangeRate, error)
+	GetList(ctx context.Context, info domain.JWTInfo, params domain.ExchangeRateParams) ([]domain.ExchangeRate, int64, error)
+	Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdateExchangeRate) error
+	Delete(ctx context.Context, info domain.JWTInfo, id int64) error
+	Convert(ctx context.Context, info domain.JWTInfo, amount decimal.Decimal, from, to string, date *time.Time) (domain.CurrencyConversion, error)
+}
+
+type ExchangeRatesService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewExchangeRatesService(cfg *config.Config, repo *repository.Repository) *ExchangeRatesService {
+	return &ExchangeRatesService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *ExchangeRatesService) Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateExchangeRate) (int64, error) {
+	from, to, err := currencyPair(inp.FromCurrency, inp.ToCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	if !inp.Rate.IsPositive() {
+		return 0, domain.ErrInvalidExchangeRate
+	}
+
+	now := time.Now().UTC()
+
+	rateDate := now
+	if inp.RateDate != nil {
+		rateDate = *inp.RateDate
+	}
+
+	rate := domain.ExchangeRate{
+		CompanyID:    info.CompanyId,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         inp.Rate,
+		RateDate:     rateDate.UTC().Truncate(24 * time.Hour),
+		CreatedBy:    info.UserId,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	id, err := s.repo.ExchangeRates.Create(ctx, rate)
+	if err != nil {
+		return 0, err
+	}
+
+	rate.ID = id
+	recordAudit(ctx, s.repo, info, rate.CompanyID, domain.AuditEntityExchangeRate, id, domain.AuditActionCreate, nil, rate)
+
+	return id, nil
+}
+
+func (s *ExchangeRatesService) GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.ExchangeRate, error) {
+	rate, err := s.repo.ExchangeRates.GetById(ctx, id)
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+
+	if rate.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.ExchangeRate{}, domain.ErrNotAllowed
+	}
+
+	return rate, nil
+}
+
+func (s *ExchangeRatesService) GetList(ctx context.Context, info domain.JWTInfo, params domain.ExchangeRateParams) ([]domain.ExchangeRate, int64, error) {
+	params.CompanyId = info.CompanyId
+	params.FromCurrency = strings.ToUpper(strings.TrimSpace(params.FromCurrency))
+	params.ToCurrency = strings.ToUpper(strings.TrimSpace(params.ToCurrency))
+
+	return s.repo.ExchangeRates.GetList(ctx, params)
+}
+
+// Update изменяет курс и дату курса. Валютная пара курса не меняется
+func (s *ExchangeRatesService) Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdateExchangeRate) error {
+	rate, err := s.GetById(ctx, info, inp.ID)
+	if err != nil {
+		return err
+	}
+
+	before := rate
+
+	if inp.Rate != nil {
+		if !inp.Rate.IsPositive() {
+			return domain.ErrInvalidExchangeRate
+		}
+
+		rate.Rate = *inp.Rate
+	}
+
+	if inp.RateDate != nil {
+		rate.RateDate = inp.RateDate.UTC().Truncate(24 * time.Hour)
+	}
+
+	rate.UpdatedAt = time.Now().UTC()
+
+	if err = s.repo.ExchangeRates.Update(ctx, rate); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyID, domain.AuditEntityExchangeRate, rate.ID, domain.AuditActionUpdate, before, rate)
+
+	return nil
+}
+
+func (s *ExchangeRatesService) Delete(ctx context.Context, info domain.JWTInfo, id int64) error {
+	rate, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.ExchangeRates.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, rate.CompanyID, domain.AuditEntityExchangeRate, id, domain.AuditActionDelete, rate, nil)
+
+	return nil
+}
+
+// Convert пересчитывает сумму из одной валюты в другую по курсу компании, действующему на дату date.
+// Если дата не указана, используется текущий курс
+func (s *ExchangeRatesService) Convert(ctx context.Context, info domain.JWTInfo, amount decimal.Decimal, from, to string, date *time.Time) (domain.CurrencyConversion, error) {
+	from, to, err := currencyPair(from, to)
+	if err != nil && !errors.Is(err, domain.ErrSameCurrency) {
+		return domain.CurrencyConversion{}, err
+	}
+
+	at := time.Now().UTC()
+	if date != nil {
+		at = date.UTC()
+	}
+
+	rate, err := exchangeRate(ctx, s.repo, info.CompanyId, from, to, at)
+	if err != nil {
+		return domain.CurrencyConversion{}, err
+	}
+
+	return domain.CurrencyConversion{
+		Amount:       amount,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         rate,
+		Result:       amount.Mul(rate).Round(domain.MoneyPlaces),
+		Date:         at,
+	}, nil
+}
+
+// currencyPair нормализует коды валютной пары и проверяет, что обе валюты поддерживаются и различаются
+func currencyPair(from, to string) (string, string, error) {
+	from = strings.ToUpper(strings.TrimSpace(from))
+	to = strings.ToUpper(strings.TrimSpace(to))
+
+	if !domain.IsValidCurrency(from) || !domain.IsValidCurrency(to) {
+		return "", "", domain.ErrInvalidCurrency
+	}
+
+	if from == to {
+		return from, to, domain.ErrSameCurrency
+	}
+
+	return from, to, nil
+}
+
+// companyCurrency возвращает валюту компании по умолчанию
+func companyCurrency(ctx context.Context, repo *repository.Repository, companyId int64) (string, error) {
+	company, err := repo.Company.GetById(ctx, companyId)
+	if err != nil {
+		return "", err
+	}
+
+	if company.DefaultCurrency == "" {
+		return domain.DefaultCurrency, nil
+	}
+
+	return company.DefaultCurrency, nil
+}
+
+// exchangeRate возвращает курс from -> to компании на дату date. Если прямого курса нет, используется обратный курс,
+// а затем кросс-курс через валюту компании по умолчанию
+func exchangeRate(ctx context.Context, repo *repository.Repository, companyId int64, from, to string, date time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	rate, err := pairRate(ctx, repo, companyId, from, to, date)
+	if err == nil || !errors.Is(err, domain.ErrExchangeRateNotFound) {
+		return rate, err
+	}
+
+	base, err := companyCurrency(ctx, repo, companyId)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	if base == from || base == to {
+		return decimal.Decimal{}, domain.ErrExchangeRateNotFound
+	}
+
+	fromBase, err := pairRate(ctx, repo, companyId, from, base, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	baseTo, err := pairRate(ctx, repo, companyId, base, to, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return fromBase.Mul(baseTo).Round(ratePrecision), nil
+}
+
+// pairRate возвращает прямой курс from -> to, а при его отсутствии - величину, обратную курсу to -> from
+func pairRate(ctx context.Context, repo *repository.Repository, companyId int64, from, to string, date time.Time) (decimal.Decimal, error) {
+	rate, err := repo.ExchangeRates.GetActual(ctx, companyId, from, to, date)
+	if err == nil {
+		return rate.Rate, nil
+	}
+
+	if !errors.Is(err, domain.ErrExchangeRateNotFound) {
+		return decimal.Decimal{}, err
+	}
+
+	rate, err = repo.ExchangeRates.GetActual(ctx, companyId, to, from, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return decimal.NewFromInt(1).DivRound(rate.Rate, ratePrecision), nil
+}
+
+// currencyConverter пересчитывает суммы в одну валюту, запоминая уже найденные курсы
+type currencyConverter struct {
+	repo      *repository.Repository
+	companyId int64
+	currency  string
+	date      time.Time
+	rates     map[string]decimal.Decimal
+}
+
+func newCurrencyConverter(repo *repository.Repository, companyId int64, currency string, date time.Time) *currencyConverter {
+	return &currencyConverter{
+		repo:      repo,
+		companyId: companyId,
+		currency:  currency,
+		date:      date,
+		rates:     make(map[string]decimal.Decimal),
+	}
+}
+
+// rate возвращает курс валюты from к валюте конвертера
+func (c *currencyConverter) rate(ctx context.Context, from string) (decimal.Decimal, error) {
+	if rate, ok := c.rates[from]; ok {
+		return rate, nil
+	}
+
+	rate, err := exchangeRate(ctx, c.repo, c.companyId, from, c.currency, c.date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	c.rates[from] = rate
+
+	return rate, nil
+}
+
+// material пересчитывает цены и стоимости материала в валюту конвертера
+func (c *currencyConverter) material(ctx context.Context, m domain.Material) (domain.Material, error) {
+	from := m.Currency
+	if from == "" {
+		from = domain.DefaultCurrency
+	}
+
+	rate, err := c.rate(ctx, from)
+	if err != nil {
+		return domain.Material{}, err
+	}
+
+	m.PriceWithoutVAT = m.PriceWithoutVAT.Mul(rate).Round(domain.MoneyPlaces)
+	m.TotalWithoutVAT = m.TotalWithoutVAT.Mul(rate).Round(domain.MoneyPlaces)
+	m.PriceWithVAT = m.PriceWithVAT.Mul(rate).Round(domain.MoneyPlaces)
+	m.TotalWithVAT = m.TotalWithVAT.Mul(rate).Round(domain.MoneyPlaces)
+	m.Currency = c.currency
+
+	return m, nil
+}
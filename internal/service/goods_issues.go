@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/tools"
+	"strconv"
+	"time"
+)
+
+type GoodsIssues interface {
+	Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateGoodsIssue) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.GoodsIssue, error)
+	GetListByWarehouseId(ctx context.Context, info domain.JWTInfo, warehouseId int64, params domain.Param) ([]domain.GoodsIssue, int64, error)
+	Post(ctx context.Context, info domain.JWTInfo, id int64) error
+	Delete(ctx context.Context, info domain.JWTInfo, id int64) error
+	GeneratePdf(ctx context.Context, info domain.JWTInfo, id int64) (*gofpdf.Fpdf, error)
+}
+
+type GoodsIssuesService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewGoodsIssuesService(cfg *config.Config, repo *repository.Repository) *GoodsIssuesService {
+	return &GoodsIssuesService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+// Create создает черновик документа отпуска. Материалы строк должны находиться на складе документа,
+// остаток проверяется предварительно и повторно при проведении
+func (s *GoodsIssuesService) Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateGoodsIssue) (int64, error) {
+	wh, err := s.repo.Warehouse.GetById(ctx, inp.WarehouseID)
+	if err != nil {
+		return 0, err
+	}
+
+	if wh.CompanyId != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return 0, domain.ErrNotAllowed
+	}
+
+	responsibleUserId := inp.ResponsibleUserID
+	if responsibleUserId == 0 {
+		responsibleUserId = info.UserId
+	}
+
+	user, err := s.repo.User.GetById(ctx, responsibleUserId)
+	if err != nil {
+		return 0, err
+	}
+
+	if user.CompanyID != wh.CompanyId {
+		return 0, domain.ErrNotAllowed
+	}
+
+	now := time.Now().UTC()
+
+	issue := domain.GoodsIssue{
+		WarehouseID:       wh.ID,
+		CompanyID:         wh.CompanyId,
+		ResponsibleUserID: responsibleUserId,
+		Status:            domain.GoodsIssueDraft,
+		Comment:           inp.Comment,
+		CreatedBy:         info.UserId,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	requested := make(map[int64]int64)
+
+	for _, l := range inp.Lines {
+		if l.Quantity <= 0 {
+			return 0, domain.ErrInvalidQuantity
+		}
+
+		material, err := s.repo.Materials.GetPurchasedById(ctx, l.PurchasedMaterialID)
+		if err != nil {
+			return 0, err
+		}
+
+		if material.CompanyID != wh.CompanyId {
+			return 0, domain.ErrNotAllowed
+		}
+
+		if material.WarehouseID != wh.ID {
+			return 0, domain.ErrGoodsIssueMaterialWarehouse
+		}
+
+		requested[material.ID] += l.Quantity
+		if requested[material.ID] > material.TotalQuantity {
+			return 0, domain.ErrInsufficientStock
+		}
+
+		issue.Lines = append(issue.Lines, domain.GoodsIssueLine{
+			PurchasedMaterialID: material.ID,
+			ItemID:              material.ItemID,
+			Name:                material.Name,
+			Article:             material.Article,
+			Unit:                material.Unit,
+			Quantity:            l.Quantity,
+			Comment:             l.Comment,
+		})
+	}
+
+	id, err := s.repo.GoodsIssues.Create(ctx, issue)
+	if err != nil {
+		return 0, err
+	}
+
+	issue.ID = id
+	recordAudit(ctx, s.repo, info, issue.CompanyID, domain.AuditEntityGoodsIssue, id, domain.AuditActionCreate, nil, issue)
+
+	return id, nil
+}
+
+func (s *GoodsIssuesService) GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.GoodsIssue, error) {
+	issue, err := s.repo.GoodsIssues.GetById(ctx, id)
+	if err != nil {
+		return domain.GoodsIssue{}, err
+	}
+
+	if issue.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.GoodsIssue{}, domain.ErrNotAllowed
+	}
+
+	return issue, nil
+}
+
+func (s *GoodsIssuesService) GetListByWarehouseId(ctx context.Context, info domain.JWTInfo, warehouseId int64, params domain.Param) ([]domain.GoodsIssue, int64, error) {
+	wh, err := s.repo.Warehouse.GetById(ctx, warehouseId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if wh.CompanyId != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return nil, 0, domain.ErrNotAllowed
+	}
+
+	params.CompanyId = wh.CompanyId
+
+	return s.repo.GoodsIssues.GetListByWarehouseId(ctx, warehouseId, params)
+}
+
+// Post проводит документ отпуска, уменьшая остатки материалов. Материал, перемещенный после создания документа
+// на другой склад, не отпускается
+func (s *GoodsIssuesService) Post(ctx context.Context, info domain.JWTInfo, id int64) error {
+	issue, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	if issue.Status != domain.GoodsIssueDraft {
+		return domain.ErrGoodsIssueNotDraft
+	}
+
+	for _, l := range issue.Lines {
+		material, err := s.repo.Materials.GetPurchasedById(ctx, l.PurchasedMaterialID)
+		if err != nil {
+			return err
+		}
+
+		if material.WarehouseID != issue.WarehouseID {
+			return domain.ErrGoodsIssueMaterialWarehouse
+		}
+	}
+
+	before := issue
+	now := time.Now().UTC()
+
+	issue.Status = domain.GoodsIssuePosted
+	issue.PostedBy = info.UserId
+	issue.PostedAt = &now
+	issue.UpdatedAt = now
+
+	if err = s.repo.GoodsIssues.Post(ctx, issue); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyID, domain.AuditEntityGoodsIssue, id, domain.AuditActionPost, before, issue)
+
+	return nil
+}
+
+// Delete удаляет черновик документа отпуска. Проведенный документ удалить нельзя
+func (s *GoodsIssuesService) Delete(ctx context.Context, info domain.JWTInfo, id int64) error {
+	issue, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.GoodsIssues.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, issue.CompanyID, domain.AuditEntityGoodsIssue, id, domain.AuditActionDelete, issue, nil)
+
+	return nil
+}
+
+// GeneratePdf формирует печатную форму документа отпуска с местами для подписей
+func (s *GoodsIssuesService) GeneratePdf(ctx context.Context, info domain.JWTInfo, id int64) (*gofpdf.Fpdf, error) {
+	issue, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wh, err := s.repo.Warehouse.GetById(ctx, issue.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responsibleName string
+	if issue.ResponsibleUserID != 0 {
+		user, err := s.repo.User.GetById(ctx, issue.ResponsibleUserID)
+		if err != nil {
+			return nil, err
+		}
+
+		responsibleName = user.Name
+	}
+
+	status := "Черновик"
+	if issue.Status == domain.GoodsIssuePosted {
+		status = "Проведен " + issue.PostedAt.Format("02.01.2006 15:04")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8Font("Arial", "", pdfFontPath)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "", 16)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Требование-накладная №%d от %s", issue.ID,
+		issue.CreatedAt.Format("02.01.2006")), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont("Arial", "", 11)
+	fields := []struct {
+		Label string
+		Value string
+	}{
+		{"Склад", wh.Name},
+		{"Получатель", responsibleName},
+		{"Статус", status},
+		{"Назначение", issue.Comment},
+	}
+
+	for _, field := range fields {
+		pdf.CellFormat(30, 7, field.Label+":", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, fitPdfText(pdf, field.Value, 158), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	columns := []struct {
+		Title string
+		Width float64
+	}{
+		{"№", 10}, {"Наименование", 70}, {"Артикул", 30}, {"Ед.", 15}, {"Количество", 25}, {"Комментарий", 40},
+	}
+
+	pdf.SetFont("Arial", "", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range columns {
+		pdf.CellFormat(column.Width, 7, column.Title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	var total int64
+	for i, l := range issue.Lines {
+		values := []string{
+			strconv.Itoa(i + 1), l.Name, l.Article, l.Unit, strconv.FormatInt(l.Quantity, 10), l.Comment,
+		}
+
+		for j, column := range columns {
+			align := "L"
+			if j == 0 || j == 4 {
+				align = "R"
+			}
+
+			pdf.CellFormat(column.Width, 6, fitPdfText(pdf, values[j], column.Width-2), "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		total += l.Quantity
+	}
+
+	pdf.Ln(2)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Всего строк: %d, общее количество: %d", len(issue.Lines), total),
+		"", 1, "L", false, 0, "")
+	pdf.Ln(12)
+
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(95, 7, "Отпустил: ____________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(95, 7, "Получил: ____________________", "", 1, "L", false, 0, "")
+
+	return pdf, pdf.Error()
+}
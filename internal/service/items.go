@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/tools"
+	"strings"
+	"time"
+)
+
+type Items interface {
+	Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateItem) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.Item, error)
+	Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdateItem) error
+	Delete(ctx context.Context, info domain.JWTInfo, id int64) error
+	GetList(ctx context.Context, info domain.JWTInfo, params domain.Param) ([]domain.Item, int64, error)
+	Search(ctx context.Context, info domain.JWTInfo, params domain.Param) ([]domain.Item, int64, error)
+}
+
+type ItemsService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewItemsService(cfg *config.Config, repo *repository.Repository) *ItemsService {
+	return &ItemsService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *ItemsService) Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateItem) (int64, error) {
+	now := time.Now().UTC()
+
+	item := domain.Item{
+		CompanyID:         info.CompanyId,
+		Name:              strings.TrimSpace(inp.Name),
+		Article:           strings.TrimSpace(inp.Article),
+		DefaultUnitID:     inp.DefaultUnitID,
+		CategoryID:        inp.CategoryID,
+		Barcode:           strings.TrimSpace(inp.Barcode),
+		DefaultSupplierID: inp.DefaultSupplierID,
+		Description:       inp.Description,
+		IsActive:          true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.validateReferences(ctx, item); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Items.Create(ctx, item)
+	if err != nil {
+		return 0, err
+	}
+
+	item.ID = id
+	recordAudit(ctx, s.repo, info, item.CompanyID, domain.AuditEntityItem, id, domain.AuditActionCreate, nil, item)
+
+	return id, nil
+}
+
+func (s *ItemsService) GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.Item, error) {
+	item, err := s.repo.Items.GetById(ctx, id)
+	if err != nil {
+		return domain.Item{}, err
+	}
+
+	if item.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.Item{}, domain.ErrNotAllowed
+	}
+
+	return item, nil
+}
+
+func (s *ItemsService) Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdateItem) error {
+	item, err := s.GetById(ctx, info, inp.ID)
+	if err != nil {
+		return err
+	}
+
+	before := item
+
+	if inp.Name != nil {
+		item.Name = strings.TrimSpace(*inp.Name)
+	}
+
+	if inp.Article != nil {
+		item.Article = strings.TrimSpace(*inp.Article)
+	}
+
+	if inp.DefaultUnitID != nil {
+		item.DefaultUnitID = *inp.DefaultUnitID
+	}
+
+	if inp.CategoryID != nil {
+		item.CategoryID = *inp.CategoryID
+	}
+
+	if inp.Barcode != nil {
+		item.Barcode = strings.TrimSpace(*inp.Barcode)
+	}
+
+	if inp.DefaultSupplierID != nil {
+		item.DefaultSupplierID = *inp.DefaultSupplierID
+	}
+
+	if inp.Description != nil {
+		item.Description = *inp.Description
+	}
+
+	if inp.IsActive != nil {
+		item.IsActive = *inp.IsActive
+	}
+
+	if item.Name == "" {
+		return domain.ErrSmallName
+	}
+
+	if err = s.validateReferences(ctx, item); err != nil {
+		return err
+	}
+
+	item.UpdatedAt = time.Now().UTC()
+
+	if err = s.repo.Items.Update(ctx, item); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyID, domain.AuditEntityItem, item.ID, domain.AuditActionUpdate, before, item)
+
+	return nil
+}
+
+// Delete удаляет товар из каталога. Товар, на который ссылаются материалы, удалить нельзя, его можно только
+// сделать неактивным
+func (s *ItemsService) Delete(ctx context.Context, info domain.JWTInfo, id int64) error {
+	item, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.Items.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, item.CompanyID, domain.AuditEntityItem, id, domain.AuditActionDelete, item, nil)
+
+	return nil
+}
+
+func (s *ItemsService) GetList(ctx context.Context, info domain.JWTInfo, params domain.Param) ([]domain.Item, int64, error) {
+	return s.repo.Items.GetList(ctx, info.CompanyId, params)
+}
+
+func (s *ItemsService) Search(ctx context.Context, info domain.JWTInfo, params domain.Param) ([]domain.Item, int64, error) {
+	return s.repo.Items.Search(ctx, info.CompanyId, params)
+}
+
+// validateReferences проверяет, что единица измерения, категория и поставщик товара принадлежат компании товара
+func (s *ItemsService) validateReferences(ctx context.Context, item domain.Item) error {
+	if item.DefaultUnitID != 0 {
+		if _, err := s.repo.UnitOfMeasure.GetById(ctx, item.DefaultUnitID, item.CompanyID); err != nil {
+			return err
+		}
+	}
+
+	if item.CategoryID != 0 {
+		if _, err := s.repo.MaterialCategory.GetById(ctx, item.CategoryID, item.CompanyID); err != nil {
+			return err
+		}
+	}
+
+	if item.DefaultSupplierID != 0 {
+		supplier, err := s.repo.Suppliers.GetById(ctx, item.DefaultSupplierID)
+		if err != nil {
+			return err
+		}
+
+		if supplier.CompanyId != item.CompanyID {
+			return domain.ErrNotAllowed
+		}
+	}
+
+	return nil
+}
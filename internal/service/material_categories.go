@@ -15,6 +15,8 @@ type Category interface {
 	Delete(ctx context.Context, id, companyId int64) error
 	List(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error)
 	Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error)
+	Tree(ctx context.Context, companyId int64) ([]domain.MaterialCategoryNode, error)
+	Move(ctx context.Context, inp domain.MoveMaterialCategory) error
 }
 
 type MaterialCategoriesService struct {
@@ -30,6 +32,12 @@ func NewMaterialCategoriesService(cfg *config.Config, repo *repository.Repositor
 }
 
 func (s *MaterialCategoriesService) Create(ctx context.Context, category domain.MaterialCategory) (int64, error) {
+	if category.ParentID != 0 {
+		if _, err := s.repo.MaterialCategory.GetById(ctx, category.ParentID, category.CompanyID); err != nil {
+			return 0, err
+		}
+	}
+
 	return s.repo.MaterialCategory.Create(ctx, category)
 }
 
@@ -79,3 +87,53 @@ func (s *MaterialCategoriesService) List(ctx context.Context, param domain.Mater
 func (s *MaterialCategoriesService) Search(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialCategory, int64, error) {
 	return s.repo.MaterialCategory.Search(ctx, param)
 }
+
+// Tree возвращает все категории компании в виде дерева
+func (s *MaterialCategoriesService) Tree(ctx context.Context, companyId int64) ([]domain.MaterialCategoryNode, error) {
+	categories, err := s.repo.MaterialCategory.GetAll(ctx, companyId)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+
+	children := make(map[int64][]domain.MaterialCategory, len(categories))
+	for _, c := range categories {
+		parentId := c.ParentID
+		if !known[parentId] {
+			parentId = 0
+		}
+
+		children[parentId] = append(children[parentId], c)
+	}
+
+	var build func(parentId int64) []domain.MaterialCategoryNode
+	build = func(parentId int64) []domain.MaterialCategoryNode {
+		nodes := make([]domain.MaterialCategoryNode, 0, len(children[parentId]))
+		for _, c := range children[parentId] {
+			nodes = append(nodes, domain.MaterialCategoryNode{MaterialCategory: c, Children: build(c.ID)})
+		}
+
+		return nodes
+	}
+
+	return build(0), nil
+}
+
+// Move переносит категорию вместе с дочерними категориями под другую категорию компании
+func (s *MaterialCategoriesService) Move(ctx context.Context, inp domain.MoveMaterialCategory) error {
+	if _, err := s.repo.MaterialCategory.GetById(ctx, inp.ID, inp.CompanyID); err != nil {
+		return err
+	}
+
+	if inp.ParentID != 0 {
+		if _, err := s.repo.MaterialCategory.GetById(ctx, inp.ParentID, inp.CompanyID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.MaterialCategory.Move(ctx, inp.ID, inp.ParentID, inp.CompanyID)
+}
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/rusystem/crm-api/internal/config"
 	"github.com/rusystem/crm-api/internal/repository"
 	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/pkg/logger"
 	"github.com/rusystem/crm-api/tools"
+	"io"
 	"time"
 )
 
@@ -16,6 +20,7 @@ type Materials interface {
 	DeletePlanningById(ctx context.Context, id int64, info domain.JWTInfo) error
 	GetPlanningList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	MovePlanningToPurchased(ctx context.Context, id int64, info domain.JWTInfo) (int64, int64, error)
+	ReceivePlanning(ctx context.Context, inp domain.ReceivePlanningMaterial, info domain.JWTInfo) (int64, int64, error)
 
 	CreatePurchased(ctx context.Context, info domain.JWTInfo, material domain.Material) (int64, int64, error)
 	GetPurchasedById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Material, error)
@@ -23,6 +28,7 @@ type Materials interface {
 	DeletePurchasedById(ctx context.Context, id int64, info domain.JWTInfo) error
 	GetPurchasedList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	MovePurchasedToArchive(ctx context.Context, id int64, info domain.JWTInfo) error
+	TransferPurchased(ctx context.Context, inp domain.TransferPurchasedMaterial, info domain.JWTInfo) (int64, int64, error)
 
 	GetPlanningArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Material, error)
 	GetPurchasedArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Material, error)
@@ -30,8 +36,32 @@ type Materials interface {
 	GetPurchasedArchiveList(ctx context.Context, params domain.MaterialParams) ([]domain.Material, int64, error)
 	DeletePlanningArchiveById(ctx context.Context, id int64, info domain.JWTInfo) error
 	DeletePurchasedArchiveById(ctx context.Context, id int64, info domain.JWTInfo) error
-
-	MaterialSearch(ctx context.Context, param domain.MaterialParams) ([]domain.Material, int64, error)
+	RestorePlanningArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (int64, int64, error)
+	RestorePurchasedArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (int64, int64, error)
+
+	MaterialSearch(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialSearchResult, int64, error)
+
+	GetExpiringPurchased(ctx context.Context, info domain.JWTInfo, days, warehouseId int64) ([]domain.ExpiringWarehouse, error)
+	SuggestFefo(ctx context.Context, info domain.JWTInfo, params domain.FefoParams) (domain.FefoSuggestion, error)
+	WriteOffExpired(ctx context.Context) error
+	RecalculateTotals(ctx context.Context, dryRun bool) (map[string]int64, error)
+
+	GetPlanningHistory(ctx context.Context, id int64, info domain.JWTInfo, params domain.Param) ([]domain.MaterialRevision, int64, error)
+	GetPurchasedHistory(ctx context.Context, id int64, info domain.JWTInfo, params domain.Param) ([]domain.MaterialRevision, int64, error)
+	RestorePlanningRevision(ctx context.Context, id, revision int64, info domain.JWTInfo) error
+	RestorePurchasedRevision(ctx context.Context, id, revision int64, info domain.JWTInfo) error
+
+	BulkCreatePlanning(ctx context.Context, info domain.JWTInfo, mode string, materials []domain.Material) (domain.BulkResult, error)
+	BulkUpdatePlanning(ctx context.Context, info domain.JWTInfo, mode string, items []domain.UpdatePlanningMaterial) (domain.BulkResult, error)
+	BulkDeletePlanning(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error)
+	BulkMovePlanningToPurchased(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error)
+	BulkCreatePurchased(ctx context.Context, info domain.JWTInfo, mode string, materials []domain.Material) (domain.BulkResult, error)
+	BulkUpdatePurchased(ctx context.Context, info domain.JWTInfo, mode string, items []domain.UpdatePurchasedMaterial) (domain.BulkResult, error)
+	BulkDeletePurchased(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error)
+	BulkMovePurchasedToArchive(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error)
+
+	ImportMaterials(ctx context.Context, info domain.JWTInfo, materialType string, r io.Reader, params domain.MaterialImportParams) (domain.ImportResult, error)
+	ExportMaterials(ctx context.Context, info domain.JWTInfo, params domain.MaterialExportParams, w io.Writer) error
 }
 
 type MaterialsService struct {
@@ -47,45 +77,106 @@ func NewMaterialsService(cfg *config.Config, repo *repository.Repository) *Mater
 }
 
 func (s *MaterialsService) CreatePlanning(ctx context.Context, info domain.JWTInfo, material domain.Material) (int64, error) {
-	wh, err := s.repo.Warehouse.GetById(ctx, material.WarehouseID)
+	material, err := s.prepareNewMaterial(ctx, info, material)
 	if err != nil {
 		return 0, err
 	}
 
+	id, err := s.repo.Materials.CreatePlanning(ctx, material)
+	if err != nil {
+		return 0, err
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, id, domain.AuditActionCreate, nil)
+
+	return id, nil
+}
+
+// prepareNewMaterial проверяет доступ к складу, поставщику и товару каталога создаваемого материала, заполняет имя
+// поставщика и валюту, рассчитывает стоимость, связывает материал с категориями, местом хранения и единицей
+// измерения из справочников
+func (s *MaterialsService) prepareNewMaterial(ctx context.Context, info domain.JWTInfo, material domain.Material) (domain.Material, error) {
+	wh, err := s.repo.Warehouse.GetById(ctx, material.WarehouseID)
+	if err != nil {
+		return domain.Material{}, err
+	}
+
 	if wh.CompanyId != material.CompanyID && !tools.IsFullAccessSection(info.Sections) {
-		return 0, domain.ErrNotAllowed
+		return domain.Material{}, domain.ErrNotAllowed
 	}
 
 	supplier, err := s.repo.Suppliers.GetById(ctx, material.SupplierID)
 	if err != nil {
-		return 0, err
+		return domain.Material{}, err
 	}
 
 	if supplier.CompanyId != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
-		return 0, domain.ErrNotAllowed
+		return domain.Material{}, domain.ErrNotAllowed
 	}
 
 	material.SupplierName = supplier.Name
 
-	return s.repo.Materials.CreatePlanning(ctx, material)
+	if material.ItemID != 0 {
+		item, err := s.repo.Items.GetById(ctx, material.ItemID)
+		if err != nil {
+			return domain.Material{}, err
+		}
+
+		if item.CompanyID != material.CompanyID {
+			return domain.Material{}, domain.ErrNotAllowed
+		}
+	}
+
+	if material, err = s.prepareMaterialCategories(ctx, material); err != nil {
+		return domain.Material{}, err
+	}
+
+	if material, err = s.prepareMaterialLocation(ctx, material); err != nil {
+		return domain.Material{}, err
+	}
+
+	if material, err = s.prepareMaterialPrice(ctx, material, material.InputTotalWithoutVAT); err != nil {
+		return domain.Material{}, err
+	}
+
+	return s.prepareMaterialUnit(ctx, material)
 }
 
 func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.UpdatePlanningMaterial, info domain.JWTInfo) error {
-	material, err := s.repo.Materials.GetPlanningById(ctx, inp.ID)
+	before, material, err := s.applyPlanningUpdate(ctx, inp, info)
 	if err != nil {
 		return err
 	}
 
+	if err = s.repo.Materials.UpdatePlanning(ctx, material, info.UserId); err != nil {
+		return err
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, material.ID, domain.AuditActionUpdate, before)
+
+	return nil
+}
+
+// applyPlanningUpdate загружает материал, проверяет доступ и применяет к нему переданные поля.
+// Возвращает состояние материала до и после изменения
+func (s *MaterialsService) applyPlanningUpdate(ctx context.Context, inp domain.UpdatePlanningMaterial, info domain.JWTInfo) (domain.Material, domain.Material, error) {
+	material, err := s.repo.Materials.GetPlanningById(ctx, inp.ID)
+	if err != nil {
+		return domain.Material{}, domain.Material{}, err
+	}
+
 	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
-		return domain.ErrNotAllowed
+		return domain.Material{}, domain.Material{}, domain.ErrNotAllowed
 	}
 
+	before := material
+
 	if inp.WarehouseID != nil {
 		material.WarehouseID = *inp.WarehouseID
 
 		_, err = s.repo.Warehouse.GetById(ctx, material.WarehouseID)
 		if err != nil {
-			return err
+			return domain.Material{}, domain.Material{}, err
 		}
 	}
 
@@ -103,10 +194,32 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 
 	if inp.ProductCategory != nil {
 		material.ProductCategory = *inp.ProductCategory
+		material.CategoryIDs = nil
+	}
+
+	if inp.CategoryIDs != nil {
+		material.CategoryIDs = *inp.CategoryIDs
+	}
+
+	if inp.ProductCategory != nil || inp.CategoryIDs != nil {
+		if material, err = s.prepareMaterialCategories(ctx, material); err != nil {
+			return domain.Material{}, domain.Material{}, err
+		}
 	}
 
 	if inp.Unit != nil {
 		material.Unit = *inp.Unit
+		material.UnitID = 0
+	}
+
+	if inp.UnitID != nil {
+		material.UnitID = *inp.UnitID
+	}
+
+	if inp.Unit != nil || inp.UnitID != nil {
+		if material, err = s.prepareMaterialUnit(ctx, material); err != nil {
+			return domain.Material{}, domain.Material{}, err
+		}
 	}
 
 	if inp.TotalQuantity != nil {
@@ -121,8 +234,12 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 		material.PriceWithoutVAT = *inp.PriceWithoutVAT
 	}
 
-	if inp.TotalWithoutVAT != nil {
-		material.TotalWithoutVAT = *inp.TotalWithoutVAT
+	if inp.VATRate != nil {
+		material.VATRate = *inp.VATRate
+	}
+
+	if inp.Currency != nil {
+		material.Currency = *inp.Currency
 	}
 
 	if inp.SupplierID != nil {
@@ -130,7 +247,7 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 
 		supplier, err := s.repo.Suppliers.GetById(ctx, material.SupplierID)
 		if err != nil {
-			return err
+			return domain.Material{}, domain.Material{}, err
 		}
 
 		material.SupplierName = supplier.Name
@@ -138,6 +255,11 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 
 	if inp.Location != nil {
 		material.Location = *inp.Location
+		material.LocationID = 0
+	}
+
+	if inp.LocationID != nil {
+		material.LocationID = *inp.LocationID
 	}
 
 	if inp.ContractDate != nil {
@@ -156,10 +278,6 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 		material.Comments = *inp.Comments
 	}
 
-	if inp.Reserve != nil {
-		material.Reserve = *inp.Reserve
-	}
-
 	if inp.ReceivedDate != nil {
 		material.ReceivedDate = *inp.ReceivedDate
 	}
@@ -199,6 +317,10 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 	}
 
 	if inp.UnitsPerPackage != nil {
+		if *inp.UnitsPerPackage < 0 {
+			return domain.Material{}, domain.Material{}, domain.ErrInvalidQuantity
+		}
+
 		material.UnitsPerPackage = *inp.UnitsPerPackage
 	}
 
@@ -206,7 +328,23 @@ func (s *MaterialsService) UpdatePlanningById(ctx context.Context, inp domain.Up
 		material.ContractNumber = *inp.ContractNumber
 	}
 
-	return s.repo.Materials.UpdatePlanning(ctx, material)
+	// место хранения прежнего склада не переносится вместе с материалом на другой склад
+	if material.WarehouseID != before.WarehouseID && inp.LocationID == nil && material.LocationID != 0 {
+		material.LocationID = 0
+		material.Location = ""
+	}
+
+	if inp.LocationID != nil {
+		if material, err = s.prepareMaterialLocation(ctx, material); err != nil {
+			return domain.Material{}, domain.Material{}, err
+		}
+	}
+
+	if material, err = s.prepareMaterialPrice(ctx, material, inp.TotalWithoutVAT); err != nil {
+		return domain.Material{}, domain.Material{}, err
+	}
+
+	return before, material, nil
 }
 
 func (s *MaterialsService) DeletePlanningById(ctx context.Context, id int64, info domain.JWTInfo) error {
@@ -219,7 +357,13 @@ func (s *MaterialsService) DeletePlanningById(ctx context.Context, id int64, inf
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Materials.DeletePlanning(ctx, id)
+	if err = s.repo.Materials.DeletePlanning(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPlanningMaterial, id, domain.AuditActionDelete, material, nil)
+
+	return nil
 }
 
 func (s *MaterialsService) GetPlanningById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Material, error) {
@@ -249,42 +393,162 @@ func (s *MaterialsService) MovePlanningToPurchased(ctx context.Context, id int64
 		return 0, 0, domain.ErrNotAllowed
 	}
 
-	return s.repo.Materials.MovePlanningToPurchased(ctx, id)
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, material.WarehouseID,
+		material.Volume*material.TotalQuantity)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	newId, itemId, err := s.repo.Materials.MovePlanningToPurchased(ctx, id, info.UserId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, material.WarehouseID)
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, id, domain.AuditActionMove, material)
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, newId, domain.AuditActionCreate, nil)
+
+	return newId, itemId, nil
 }
 
-func (s *MaterialsService) CreatePurchased(ctx context.Context, info domain.JWTInfo, material domain.Material) (int64, int64, error) {
-	wh, err := s.repo.Warehouse.GetById(ctx, material.WarehouseID)
+func (s *MaterialsService) ReceivePlanning(ctx context.Context, inp domain.ReceivePlanningMaterial, info domain.JWTInfo) (int64, int64, error) {
+	material, err := s.repo.Materials.GetPlanningById(ctx, inp.ID)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	if wh.CompanyId != material.CompanyID && !tools.IsFullAccessSection(info.Sections) {
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
 		return 0, 0, domain.ErrNotAllowed
 	}
 
-	supplier, err := s.repo.Suppliers.GetById(ctx, material.SupplierID)
+	if inp.Quantity < 0 || (inp.PriceWithoutVAT != nil && inp.PriceWithoutVAT.IsNegative()) {
+		return 0, 0, domain.ErrInvalidQuantity
+	}
+
+	if inp.LocationID != nil && *inp.LocationID != 0 {
+		if _, err = s.warehouseLocation(ctx, *inp.LocationID, material.WarehouseID); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	quantity := inp.Quantity
+	if quantity == 0 {
+		quantity = material.TotalQuantity
+	}
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, material.WarehouseID, material.Volume*quantity)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	if supplier.CompanyId != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
-		return 0, 0, domain.ErrNotAllowed
+	newId, itemId, err := s.repo.Materials.ReceivePlanning(ctx, inp, info.UserId)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	material.SupplierName = supplier.Name
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, material.WarehouseID)
+	}
 
-	return s.repo.Materials.CreatePurchased(ctx, material)
+	s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, inp.ID, domain.AuditActionReceive, material)
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, newId, domain.AuditActionCreate, nil)
+
+	return newId, itemId, nil
+}
+
+func (s *MaterialsService) CreatePurchased(ctx context.Context, info domain.JWTInfo, material domain.Material) (int64, int64, error) {
+	material, err := s.prepareNewMaterial(ctx, info, material)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, material.WarehouseID,
+		material.Volume*material.TotalQuantity)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	id, itemId, err := s.repo.Materials.CreatePurchased(ctx, material, info.UserId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, material.WarehouseID)
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, id, domain.AuditActionCreate, nil)
+
+	return id, itemId, nil
 }
 
 func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.UpdatePurchasedMaterial, info domain.JWTInfo) error {
-	material, err := s.repo.Materials.GetPurchasedById(ctx, inp.ID)
+	before, material, err := s.applyPurchasedUpdate(ctx, inp, info)
+	if err != nil {
+		return err
+	}
+
+	warehouseId, volume := occupancyChange(before, material)
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, warehouseId, volume)
 	if err != nil {
 		return err
 	}
 
+	if err = s.repo.Materials.UpdatePurchased(ctx, s.purchasedUpdate(ctx, inp, &before), info.UserId); err != nil {
+		return err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, warehouseId)
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, material.ID, domain.AuditActionUpdate, before)
+
+	return nil
+}
+
+// applyPurchasedUpdate загружает материал, проверяет доступ и применяет к нему переданные поля.
+// Возвращает состояние материала до и после изменения
+func (s *MaterialsService) applyPurchasedUpdate(ctx context.Context, inp domain.UpdatePurchasedMaterial, info domain.JWTInfo) (domain.Material, domain.Material, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, inp.ID)
+	if err != nil {
+		return domain.Material{}, domain.Material{}, err
+	}
+
 	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
-		return domain.ErrNotAllowed
+		return domain.Material{}, domain.Material{}, domain.ErrNotAllowed
+	}
+
+	updated, err := s.applyPurchasedChanges(ctx, material, inp)
+	if err != nil {
+		return domain.Material{}, domain.Material{}, err
+	}
+
+	return material, updated, nil
+}
+
+// purchasedUpdate возвращает изменение закупленного материала, которое репозиторий применяет к состоянию,
+// прочитанному под блокировкой строки. Это состояние записывается в before
+func (s *MaterialsService) purchasedUpdate(ctx context.Context, inp domain.UpdatePurchasedMaterial, before *domain.Material) domain.MaterialUpdate {
+	return domain.MaterialUpdate{
+		ID: inp.ID,
+		Apply: func(current domain.Material) (domain.Material, error) {
+			*before = current
+
+			return s.applyPurchasedChanges(ctx, current, inp)
+		},
 	}
+}
+
+// applyPurchasedChanges применяет к закупленному материалу переданные поля
+func (s *MaterialsService) applyPurchasedChanges(ctx context.Context, material domain.Material, inp domain.UpdatePurchasedMaterial) (domain.Material, error) {
+	var err error
+
+	before := material
 
 	if inp.WarehouseID != nil {
 		material.WarehouseID = *inp.WarehouseID
@@ -304,10 +568,32 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 
 	if inp.ProductCategory != nil {
 		material.ProductCategory = *inp.ProductCategory
+		material.CategoryIDs = nil
+	}
+
+	if inp.CategoryIDs != nil {
+		material.CategoryIDs = *inp.CategoryIDs
+	}
+
+	if inp.ProductCategory != nil || inp.CategoryIDs != nil {
+		if material, err = s.prepareMaterialCategories(ctx, material); err != nil {
+			return domain.Material{}, err
+		}
 	}
 
 	if inp.Unit != nil {
 		material.Unit = *inp.Unit
+		material.UnitID = 0
+	}
+
+	if inp.UnitID != nil {
+		material.UnitID = *inp.UnitID
+	}
+
+	if inp.Unit != nil || inp.UnitID != nil {
+		if material, err = s.prepareMaterialUnit(ctx, material); err != nil {
+			return domain.Material{}, err
+		}
 	}
 
 	if inp.TotalQuantity != nil {
@@ -322,8 +608,12 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 		material.PriceWithoutVAT = *inp.PriceWithoutVAT
 	}
 
-	if inp.TotalWithoutVAT != nil {
-		material.TotalWithoutVAT = *inp.TotalWithoutVAT
+	if inp.VATRate != nil {
+		material.VATRate = *inp.VATRate
+	}
+
+	if inp.Currency != nil {
+		material.Currency = *inp.Currency
 	}
 
 	if inp.SupplierID != nil {
@@ -331,7 +621,7 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 
 		supplier, err := s.repo.Suppliers.GetById(ctx, material.SupplierID)
 		if err != nil {
-			return err
+			return domain.Material{}, err
 		}
 
 		material.SupplierName = supplier.Name
@@ -339,6 +629,11 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 
 	if inp.Location != nil {
 		material.Location = *inp.Location
+		material.LocationID = 0
+	}
+
+	if inp.LocationID != nil {
+		material.LocationID = *inp.LocationID
 	}
 
 	if inp.ContractDate != nil {
@@ -357,10 +652,6 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 		material.Comments = *inp.Comments
 	}
 
-	if inp.Reserve != nil {
-		material.Reserve = *inp.Reserve
-	}
-
 	if inp.ReceivedDate != nil {
 		material.ReceivedDate = *inp.ReceivedDate
 	}
@@ -400,6 +691,10 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 	}
 
 	if inp.UnitsPerPackage != nil {
+		if *inp.UnitsPerPackage < 0 {
+			return domain.Material{}, domain.ErrInvalidQuantity
+		}
+
 		material.UnitsPerPackage = *inp.UnitsPerPackage
 	}
 
@@ -407,7 +702,23 @@ func (s *MaterialsService) UpdatePurchasedById(ctx context.Context, inp domain.U
 		material.ContractNumber = *inp.ContractNumber
 	}
 
-	return s.repo.Materials.UpdatePurchased(ctx, material)
+	// место хранения прежнего склада не переносится вместе с материалом на другой склад
+	if material.WarehouseID != before.WarehouseID && inp.LocationID == nil && material.LocationID != 0 {
+		material.LocationID = 0
+		material.Location = ""
+	}
+
+	if inp.LocationID != nil {
+		if material, err = s.prepareMaterialLocation(ctx, material); err != nil {
+			return domain.Material{}, err
+		}
+	}
+
+	if material, err = s.prepareMaterialPrice(ctx, material, inp.TotalWithoutVAT); err != nil {
+		return domain.Material{}, err
+	}
+
+	return material, nil
 }
 
 func (s *MaterialsService) DeletePurchasedById(ctx context.Context, id int64, info domain.JWTInfo) error {
@@ -420,7 +731,13 @@ func (s *MaterialsService) DeletePurchasedById(ctx context.Context, id int64, in
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Materials.DeletePurchased(ctx, id)
+	if err = s.repo.Materials.DeletePurchased(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPurchasedMaterial, id, domain.AuditActionDelete, material, nil)
+
+	return nil
 }
 
 func (s *MaterialsService) GetPurchasedById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Material, error) {
@@ -450,7 +767,82 @@ func (s *MaterialsService) MovePurchasedToArchive(ctx context.Context, id int64,
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Materials.MovePurchasedToArchive(ctx, id)
+	if err = s.repo.Materials.MovePurchasedToArchive(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPurchasedMaterial, id, domain.AuditActionMove, material, nil)
+
+	return nil
+}
+
+func (s *MaterialsService) TransferPurchased(ctx context.Context, inp domain.TransferPurchasedMaterial, info domain.JWTInfo) (int64, int64, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, inp.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return 0, 0, domain.ErrNotAllowed
+	}
+
+	if material.WarehouseID == inp.WarehouseID {
+		return 0, 0, domain.ErrSameWarehouse
+	}
+
+	// количество в другой единице измерения или в упаковках приводится к единице материала
+	if inp.Quantity, err = s.transferQuantity(ctx, material, inp); err != nil {
+		return 0, 0, err
+	}
+
+	source, err := s.repo.Warehouse.GetById(ctx, material.WarehouseID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	target, err := s.repo.Warehouse.GetById(ctx, inp.WarehouseID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// перемещать можно только между складами компании, которой принадлежит материал
+	if source.CompanyId != material.CompanyID || target.CompanyId != material.CompanyID {
+		return 0, 0, domain.ErrNotAllowed
+	}
+
+	if inp.LocationID != 0 {
+		if _, err = s.warehouseLocation(ctx, inp.LocationID, inp.WarehouseID); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	quantity := inp.Quantity
+	if quantity == 0 {
+		quantity = material.TotalQuantity
+	}
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, inp.WarehouseID, material.Volume*quantity)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	targetId, itemId, err := s.repo.Materials.TransferPurchased(ctx, inp, info.UserId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, inp.WarehouseID)
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, inp.ID, domain.AuditActionTransfer, material)
+
+	// при частичном перемещении на складе-получателе создается новая строка
+	if targetId != inp.ID {
+		s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, targetId, domain.AuditActionCreate, nil)
+	}
+
+	return targetId, itemId, nil
 }
 
 func (s *MaterialsService) GetPlanningArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Material, error) {
@@ -497,7 +889,13 @@ func (s *MaterialsService) DeletePlanningArchiveById(ctx context.Context, id int
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Materials.DeletePlanningArchive(ctx, id)
+	if err = s.repo.Materials.DeletePlanningArchive(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPlanningMaterialArchive, id, domain.AuditActionDelete, material, nil)
+
+	return nil
 }
 
 func (s *MaterialsService) DeletePurchasedArchiveById(ctx context.Context, id int64, info domain.JWTInfo) error {
@@ -510,9 +908,302 @@ func (s *MaterialsService) DeletePurchasedArchiveById(ctx context.Context, id in
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Materials.DeletePurchasedArchive(ctx, id)
+	if err = s.repo.Materials.DeletePurchasedArchive(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPurchasedMaterialArchive, id, domain.AuditActionDelete, material, nil)
+
+	return nil
+}
+
+func (s *MaterialsService) RestorePlanningArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (int64, int64, error) {
+	material, err := s.GetPlanningArchiveById(ctx, id, info)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err = s.checkRestoreWarehouse(ctx, material); err != nil {
+		return 0, 0, err
+	}
+
+	newId, err := s.repo.Materials.RestorePlanningArchive(ctx, id)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPlanningMaterialArchive, id, domain.AuditActionRestore, material, nil)
+	s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, newId, domain.AuditActionCreate, nil)
+
+	return newId, material.ItemID, nil
+}
+
+func (s *MaterialsService) RestorePurchasedArchiveById(ctx context.Context, id int64, info domain.JWTInfo) (int64, int64, error) {
+	material, err := s.GetPurchasedArchiveById(ctx, id, info)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err = s.checkRestoreWarehouse(ctx, material); err != nil {
+		return 0, 0, err
+	}
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, material.WarehouseID,
+		material.Volume*material.TotalQuantity)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	newId, err := s.repo.Materials.RestorePurchasedArchive(ctx, id, info.UserId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, material.WarehouseID)
+	}
+
+	recordAudit(ctx, s.repo, info, material.CompanyID, domain.AuditEntityPurchasedMaterialArchive, id, domain.AuditActionRestore, material, nil)
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, newId, domain.AuditActionCreate, nil)
+
+	return newId, material.ItemID, nil
+}
+
+// checkRestoreWarehouse проверяет, что склад архивного материала еще существует и принадлежит компании материала
+func (s *MaterialsService) checkRestoreWarehouse(ctx context.Context, material domain.Material) error {
+	wh, err := s.repo.Warehouse.GetById(ctx, material.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	if wh.CompanyId != material.CompanyID {
+		return domain.ErrNotAllowed
+	}
+
+	return nil
 }
 
-func (s *MaterialsService) MaterialSearch(ctx context.Context, param domain.MaterialParams) ([]domain.Material, int64, error) {
+func (s *MaterialsService) MaterialSearch(ctx context.Context, param domain.MaterialParams) ([]domain.MaterialSearchResult, int64, error) {
 	return s.repo.Materials.Search(ctx, param)
 }
+
+func (s *MaterialsService) GetExpiringPurchased(ctx context.Context, info domain.JWTInfo, days, warehouseId int64) ([]domain.ExpiringWarehouse, error) {
+	if days < 0 {
+		return nil, domain.ErrInvalidQueryParam
+	}
+
+	before := time.Now().UTC().AddDate(0, 0, int(days))
+
+	return s.repo.Materials.GetExpiringPurchased(ctx, info.CompanyId, warehouseId, before)
+}
+
+// SuggestFefo подбирает партии для отбора по принципу FEFO: сначала партии с ближайшим сроком годности,
+// затем партии без срока. Просроченные и списанные партии не участвуют в подборе
+func (s *MaterialsService) SuggestFefo(ctx context.Context, info domain.JWTInfo, params domain.FefoParams) (domain.FefoSuggestion, error) {
+	if params.ItemID == 0 && params.Article == "" {
+		return domain.FefoSuggestion{}, domain.ErrFefoTarget
+	}
+
+	if params.Quantity <= 0 {
+		return domain.FefoSuggestion{}, domain.ErrInvalidQuantity
+	}
+
+	params.CompanyID = info.CompanyId
+
+	candidates, err := s.repo.Materials.GetFefoCandidates(ctx, params)
+	if err != nil {
+		return domain.FefoSuggestion{}, err
+	}
+
+	suggestion := domain.FefoSuggestion{
+		RequestedQuantity: params.Quantity,
+		Picks:             []domain.FefoPick{},
+	}
+
+	rest := params.Quantity
+	for _, pick := range candidates {
+		if rest == 0 {
+			break
+		}
+
+		pick.Quantity = min(pick.AvailableQuantity, rest)
+		rest -= pick.Quantity
+
+		suggestion.Picks = append(suggestion.Picks, pick)
+	}
+
+	suggestion.AllocatedQuantity = params.Quantity - rest
+	suggestion.Shortage = rest
+
+	return suggestion, nil
+}
+
+// WriteOffExpired списывает остатки просроченных партий и переводит их в статус written_off
+func (s *MaterialsService) WriteOffExpired(ctx context.Context) error {
+	ids, err := s.repo.Materials.GetExpiredPurchasedIds(ctx)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, id := range ids {
+		if err = s.repo.Materials.WriteOffPurchased(ctx, id, 0, "Списание по истечении срока годности"); err != nil {
+			logger.Error(fmt.Sprintf("failed to write off expired purchased material %d: %v", id, err))
+			continue
+		}
+
+		count++
+	}
+
+	if count > 0 {
+		logger.Info(fmt.Sprintf("written off %d expired purchased materials", count))
+	}
+
+	return nil
+}
+
+// RecalculateTotals исправляет общую стоимость материалов, не совпадающую с ценой, умноженной на количество.
+// Используется разовой командой cmd/recalc для строк, сохраненных до расчета стоимости на сервере
+func (s *MaterialsService) RecalculateTotals(ctx context.Context, dryRun bool) (map[string]int64, error) {
+	return s.repo.Materials.RecalculateTotals(ctx, dryRun)
+}
+
+func (s *MaterialsService) GetPlanningHistory(ctx context.Context, id int64, info domain.JWTInfo, params domain.Param) ([]domain.MaterialRevision, int64, error) {
+	if _, err := s.GetPlanningById(ctx, id, info); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.MaterialRevisions.GetList(ctx, domain.MaterialTypePlanning, id, params)
+}
+
+func (s *MaterialsService) GetPurchasedHistory(ctx context.Context, id int64, info domain.JWTInfo, params domain.Param) ([]domain.MaterialRevision, int64, error) {
+	if _, err := s.GetPurchasedById(ctx, id, info); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.MaterialRevisions.GetList(ctx, domain.MaterialTypePurchased, id, params)
+}
+
+// RestorePlanningRevision возвращает планируемый материал к состоянию выбранной версии.
+// Текущее состояние при этом также сохраняется в историю, поэтому восстановление можно отменить
+func (s *MaterialsService) RestorePlanningRevision(ctx context.Context, id, revision int64, info domain.JWTInfo) error {
+	material, err := s.GetPlanningById(ctx, id, info)
+	if err != nil {
+		return err
+	}
+
+	restored, err := s.revisionSnapshot(ctx, domain.MaterialTypePlanning, material, revision)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.Materials.UpdatePlanning(ctx, restored, info.UserId); err != nil {
+		return err
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, id, domain.AuditActionRestore, material)
+
+	return nil
+}
+
+// RestorePurchasedRevision возвращает закупленный материал к состоянию выбранной версии.
+// Изменение количества фиксируется в журнале движений как корректировка
+func (s *MaterialsService) RestorePurchasedRevision(ctx context.Context, id, revision int64, info domain.JWTInfo) error {
+	material, err := s.GetPurchasedById(ctx, id, info)
+	if err != nil {
+		return err
+	}
+
+	restored, err := s.revisionSnapshot(ctx, domain.MaterialTypePurchased, material, revision)
+	if err != nil {
+		return err
+	}
+
+	warehouseId, volume := occupancyChange(material, restored)
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, warehouseId, volume)
+	if err != nil {
+		return err
+	}
+
+	// версия восстанавливается целиком, включая количество
+	update := domain.MaterialUpdate{
+		ID: id,
+		Apply: func(current domain.Material) (domain.Material, error) {
+			material = current
+
+			return restored, nil
+		},
+	}
+
+	if err = s.repo.Materials.UpdatePurchased(ctx, update, info.UserId); err != nil {
+		return err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, warehouseId)
+	}
+
+	s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, id, domain.AuditActionRestore, material)
+
+	return nil
+}
+
+// revisionSnapshot возвращает состояние материала из версии, пригодное для записи поверх текущей строки.
+// Идентификаторы строки, товара и компании сохраняются текущими
+func (s *MaterialsService) revisionSnapshot(ctx context.Context, materialType string, current domain.Material, revision int64) (domain.Material, error) {
+	rev, err := s.repo.MaterialRevisions.GetByRevision(ctx, materialType, current.ID, revision)
+	if err != nil {
+		return domain.Material{}, err
+	}
+
+	restored := rev.Snapshot
+	restored.ID = current.ID
+	restored.ItemID = current.ItemID
+	restored.CompanyID = current.CompanyID
+	restored.LastUpdated = time.Now().UTC()
+
+	// склад из версии мог быть удален или передан другой компании
+	if restored.WarehouseID != current.WarehouseID {
+		wh, err := s.repo.Warehouse.GetById(ctx, restored.WarehouseID)
+		if err != nil {
+			return domain.Material{}, err
+		}
+
+		if wh.CompanyId != current.CompanyID {
+			return domain.Material{}, domain.ErrNotAllowed
+		}
+	}
+
+	return s.restoreMaterialCategories(ctx, restored)
+}
+
+// auditMaterial записывает в журнал аудита действие над материалом, состояние после действия перечитывается из базы.
+// Если материала больше нет в таблице (например, полностью принят и перенесен в архив), after остается пустым
+func (s *MaterialsService) auditMaterial(ctx context.Context, info domain.JWTInfo, entityType string, id int64, action string, before interface{}) {
+	var material domain.Material
+	var err error
+
+	switch entityType {
+	case domain.AuditEntityPlanningMaterial:
+		material, err = s.repo.Materials.GetPlanningById(ctx, id)
+	default:
+		material, err = s.repo.Materials.GetPurchasedById(ctx, id)
+	}
+
+	var after interface{}
+	if err == nil {
+		after = material
+	} else if !errors.Is(err, domain.ErrMaterialNotFound) {
+		logger.Error(fmt.Sprintf("audit: failed to get %s %d: %v", entityType, id, err))
+		return
+	}
+
+	// запись относится к компании материала; если материала уже нет, компания берется из прежнего состояния
+	companyId := material.CompanyID
+	if previous, ok := before.(domain.Material); ok && after == nil {
+		companyId = previous.CompanyID
+	}
+
+	recordAudit(ctx, s.repo, info, companyId, entityType, id, action, before, after)
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+func (s *MaterialsService) BulkCreatePlanning(ctx context.Context, info domain.JWTInfo, mode string, materials []domain.Material) (domain.BulkResult, error) {
+	prepared := make([]domain.Material, 0, len(materials))
+
+	result, err := runBulkPlan(mode, len(materials), func(i int) error {
+		material, err := s.prepareNewMaterial(ctx, info, materials[i])
+		if err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material)
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkCreatePlanning(ctx, prepared, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	for _, item := range succeededItems(result) {
+		s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, item.ID, domain.AuditActionCreate, nil)
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkUpdatePlanning(ctx context.Context, info domain.JWTInfo, mode string, items []domain.UpdatePlanningMaterial) (domain.BulkResult, error) {
+	prepared := make([]domain.Material, 0, len(items))
+	before := make(map[int64]domain.Material, len(items))
+
+	result, err := runBulkPlan(mode, len(items), func(i int) error {
+		previous, material, err := s.applyPlanningUpdate(ctx, items[i], info)
+		if err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material)
+		before[material.ID] = previous
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkUpdatePlanning(ctx, prepared, info.UserId, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	for _, item := range succeededItems(result) {
+		s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, item.ID, domain.AuditActionUpdate, before[item.ID])
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkDeletePlanning(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error) {
+	prepared := make([]int64, 0, len(ids))
+	before := make(map[int64]domain.Material, len(ids))
+
+	result, err := runBulkPlan(mode, len(ids), func(i int) error {
+		material, err := s.GetPlanningById(ctx, ids[i], info)
+		if err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material.ID)
+		before[material.ID] = material
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkDeletePlanning(ctx, prepared, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	for _, item := range succeededItems(result) {
+		recordAudit(ctx, s.repo, info, before[item.ID].CompanyID, domain.AuditEntityPlanningMaterial, item.ID, domain.AuditActionDelete, before[item.ID], nil)
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkMovePlanningToPurchased(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error) {
+	prepared := make([]int64, 0, len(ids))
+	before := make(map[int]domain.Material, len(ids))
+	capacity := newCapacityPlan()
+
+	result, err := runBulkPlan(mode, len(ids), func(i int) error {
+		material, err := s.GetPlanningById(ctx, ids[i], info)
+		if err != nil {
+			return err
+		}
+
+		if err = capacity.check(ctx, s.repo, material.WarehouseID, material.Volume*material.TotalQuantity); err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material.ID)
+		before[i] = material
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkMovePlanningToPurchased(ctx, prepared, info.UserId, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	if len(succeededItems(result)) > 0 {
+		capacity.notify(ctx, s.repo, info)
+	}
+
+	// в результате возвращается id созданного закупленного материала
+	for _, item := range succeededItems(result) {
+		s.auditMaterial(ctx, info, domain.AuditEntityPlanningMaterial, ids[item.Index], domain.AuditActionMove, before[item.Index])
+		s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, item.ID, domain.AuditActionCreate, nil)
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkCreatePurchased(ctx context.Context, info domain.JWTInfo, mode string, materials []domain.Material) (domain.BulkResult, error) {
+	prepared := make([]domain.Material, 0, len(materials))
+	capacity := newCapacityPlan()
+
+	result, err := runBulkPlan(mode, len(materials), func(i int) error {
+		material, err := s.prepareNewMaterial(ctx, info, materials[i])
+		if err != nil {
+			return err
+		}
+
+		if err = capacity.check(ctx, s.repo, material.WarehouseID, material.Volume*material.TotalQuantity); err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material)
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkCreatePurchased(ctx, prepared, info.UserId, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	if len(succeededItems(result)) > 0 {
+		capacity.notify(ctx, s.repo, info)
+	}
+
+	for _, item := range succeededItems(result) {
+		s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, item.ID, domain.AuditActionCreate, nil)
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkUpdatePurchased(ctx context.Context, info domain.JWTInfo, mode string, items []domain.UpdatePurchasedMaterial) (domain.BulkResult, error) {
+	prepared := make([]domain.MaterialUpdate, 0, len(items))
+	before := make([]domain.Material, len(items))
+	capacity := newCapacityPlan()
+
+	result, err := runBulkPlan(mode, len(items), func(i int) error {
+		previous, material, err := s.applyPurchasedUpdate(ctx, items[i], info)
+		if err != nil {
+			return err
+		}
+
+		warehouseId, volume := occupancyChange(previous, material)
+		if err = capacity.check(ctx, s.repo, warehouseId, volume); err != nil {
+			return err
+		}
+
+		// изменения применяются повторно к состоянию материала, прочитанному под блокировкой
+		prepared = append(prepared, s.purchasedUpdate(ctx, items[i], &before[i]))
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkUpdatePurchased(ctx, prepared, info.UserId, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	if len(succeededItems(result)) > 0 {
+		capacity.notify(ctx, s.repo, info)
+	}
+
+	for _, item := range succeededItems(result) {
+		s.auditMaterial(ctx, info, domain.AuditEntityPurchasedMaterial, item.ID, domain.AuditActionUpdate, before[item.Index])
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkDeletePurchased(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error) {
+	prepared := make([]int64, 0, len(ids))
+	before := make(map[int64]domain.Material, len(ids))
+
+	result, err := runBulkPlan(mode, len(ids), func(i int) error {
+		material, err := s.GetPurchasedById(ctx, ids[i], info)
+		if err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material.ID)
+		before[material.ID] = material
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkDeletePurchased(ctx, prepared, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	for _, item := range succeededItems(result) {
+		recordAudit(ctx, s.repo, info, before[item.ID].CompanyID, domain.AuditEntityPurchasedMaterial, item.ID, domain.AuditActionDelete, before[item.ID], nil)
+	}
+
+	return result, nil
+}
+
+func (s *MaterialsService) BulkMovePurchasedToArchive(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error) {
+	prepared := make([]int64, 0, len(ids))
+	before := make(map[int64]domain.Material, len(ids))
+
+	result, err := runBulkPlan(mode, len(ids), func(i int) error {
+		material, err := s.GetPurchasedById(ctx, ids[i], info)
+		if err != nil {
+			return err
+		}
+
+		prepared = append(prepared, material.ID)
+		before[material.ID] = material
+		return nil
+	}, func(atomic bool) ([]domain.BulkItemResult, bool, error) {
+		return s.repo.Materials.BulkMovePurchasedToArchive(ctx, prepared, atomic)
+	})
+	if err != nil {
+		return domain.BulkResult{}, err
+	}
+
+	for _, item := range succeededItems(result) {
+		recordAudit(ctx, s.repo, info, before[item.ID].CompanyID, domain.AuditEntityPurchasedMaterial, item.ID, domain.AuditActionMove, before[item.ID], nil)
+	}
+
+	return result, nil
+}
+
+// runBulkPlan проверяет каждую операцию пакета через prepare и выполняет прошедшие проверку одной транзакцией
+// через execute. В атомарном режиме ошибка проверки любой операции отменяет весь пакет. Результаты execute
+// приходят в порядке подготовленных операций и сопоставляются с их номерами в запросе
+func runBulkPlan(mode string, n int, prepare func(i int) error,
+	execute func(atomic bool) ([]domain.BulkItemResult, bool, error)) (domain.BulkResult, error) {
+	if mode == "" {
+		mode = domain.BulkModeAtomic
+	}
+
+	atomic := mode == domain.BulkModeAtomic
+
+	result := domain.BulkResult{
+		Mode:  mode,
+		Items: make([]domain.BulkItemResult, n),
+	}
+
+	var positions []int
+	for i := 0; i < n; i++ {
+		result.Items[i] = domain.BulkItemResult{Index: i, Status: domain.BulkItemSkipped}
+
+		if err := prepare(i); err != nil {
+			result.Items[i].Status = domain.BulkItemFailed
+			result.Items[i].Error = err.Error()
+
+			var verr *domain.ValidationError
+			if errors.As(err, &verr) {
+				result.Items[i].Fields = verr.Fields
+			}
+
+			continue
+		}
+
+		positions = append(positions, i)
+	}
+
+	if len(positions) > 0 && (!atomic || len(positions) == n) {
+		items, committed, err := execute(atomic)
+		if err != nil {
+			return domain.BulkResult{}, err
+		}
+
+		for k, item := range items {
+			item.Index = positions[k]
+			result.Items[positions[k]] = item
+		}
+
+		result.Committed = committed
+	}
+
+	for _, item := range result.Items {
+		switch item.Status {
+		case domain.BulkItemSucceeded:
+			result.Succeeded++
+		case domain.BulkItemFailed:
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// succeededItems возвращает операции пакета, изменения которых сохранены
+func succeededItems(result domain.BulkResult) []domain.BulkItemResult {
+	if !result.Committed {
+		return nil
+	}
+
+	var items []domain.BulkItemResult
+	for _, item := range result.Items {
+		if item.Status == domain.BulkItemSucceeded {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
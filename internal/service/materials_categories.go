@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"strings"
+)
+
+// prepareMaterialCategories связывает материал с категориями компании материала. Если category_ids указаны,
+// по ним заполняются названия категорий, иначе категории ищутся по названиям product_category
+func (s *MaterialsService) prepareMaterialCategories(ctx context.Context, material domain.Material) (domain.Material, error) {
+	categories, err := s.materialCategories(ctx, material)
+	if err != nil {
+		return domain.Material{}, err
+	}
+
+	return withMaterialCategories(material, categories), nil
+}
+
+// restoreMaterialCategories связывает материал из версии с категориями, которые существуют до сих пор.
+// Удаленные после сохранения версии категории отбрасываются
+func (s *MaterialsService) restoreMaterialCategories(ctx context.Context, material domain.Material) (domain.Material, error) {
+	var categories []domain.MaterialCategory
+
+	// версии, сохраненные до появления category_ids, содержат только названия категорий
+	for _, c := range categoryRefs(material) {
+		category, err := s.materialCategory(ctx, c, material.CompanyID)
+		if err != nil {
+			if errors.Is(err, domain.ErrMaterialCategoryNotFound) {
+				continue
+			}
+
+			return domain.Material{}, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return withMaterialCategories(material, categories), nil
+}
+
+func (s *MaterialsService) materialCategories(ctx context.Context, material domain.Material) ([]domain.MaterialCategory, error) {
+	var categories []domain.MaterialCategory
+
+	for _, c := range categoryRefs(material) {
+		category, err := s.materialCategory(ctx, c, material.CompanyID)
+		if err != nil {
+			if errors.Is(err, domain.ErrMaterialCategoryNotFound) && c.id == 0 {
+				return nil, fmt.Errorf("%w: %s", err, c.name)
+			}
+
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+// categoryRef ссылка на категорию материала по id или по названию
+type categoryRef struct {
+	id   int64
+	name string
+}
+
+func categoryRefs(material domain.Material) []categoryRef {
+	var refs []categoryRef
+
+	if len(material.CategoryIDs) > 0 {
+		for _, id := range material.CategoryIDs {
+			refs = append(refs, categoryRef{id: id})
+		}
+
+		return refs
+	}
+
+	for _, name := range material.ProductCategory {
+		if name = strings.TrimSpace(name); name != "" {
+			refs = append(refs, categoryRef{name: name})
+		}
+	}
+
+	return refs
+}
+
+func (s *MaterialsService) materialCategory(ctx context.Context, ref categoryRef, companyId int64) (domain.MaterialCategory, error) {
+	if ref.id != 0 {
+		return s.repo.MaterialCategory.GetById(ctx, ref.id, companyId)
+	}
+
+	return s.repo.MaterialCategory.GetByName(ctx, ref.name, companyId)
+}
+
+// withMaterialCategories записывает в материал id и названия категорий без повторов
+func withMaterialCategories(material domain.Material, categories []domain.MaterialCategory) domain.Material {
+	material.CategoryIDs = make([]int64, 0, len(categories))
+	material.ProductCategory = make([]string, 0, len(categories))
+
+	seen := make(map[int64]bool, len(categories))
+	for _, c := range categories {
+		if seen[c.ID] {
+			continue
+		}
+
+		seen[c.ID] = true
+		material.CategoryIDs = append(material.CategoryIDs, c.ID)
+		material.ProductCategory = append(material.ProductCategory, c.Name)
+	}
+
+	return material
+}
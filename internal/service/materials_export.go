@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pdfFontPath шрифт с поддержкой кириллицы для PDF документов
+const pdfFontPath = "assets/fonts/arial/arialmt.ttf"
+
+// csvFlushRows через какое количество строк CSV выгрузка отправляется клиенту
+const csvFlushRows = 500
+
+// exportColumn описывает колонку выгрузки списка материалов. pdfWidth - ширина колонки в PDF в мм,
+// колонки с нулевой шириной в PDF не выводятся
+type exportColumn struct {
+	title    string
+	pdfWidth float64
+	value    func(m domain.Material) interface{}
+}
+
+var exportCommonColumns = []exportColumn{
+	{"ID", 14, func(m domain.Material) interface{} { return m.ID }},
+	{"ID товара", 0, func(m domain.Material) interface{} { return m.ItemID }},
+	{"Склад", 0, func(m domain.Material) interface{} { return m.WarehouseID }},
+	{"Наименование", 58, func(m domain.Material) interface{} { return m.Name }},
+	{"Внутреннее наименование", 0, func(m domain.Material) interface{} { return m.InternalName }},
+	{"Артикул", 25, func(m domain.Material) interface{} { return m.Article }},
+	{"Категории", 0, func(m domain.Material) interface{} { return strings.Join(m.ProductCategory, ", ") }},
+	{"Ед. изм.", 14, func(m domain.Material) interface{} { return m.Unit }},
+	{"Количество", 20, func(m domain.Material) interface{} { return m.TotalQuantity }},
+	{"Объем", 0, func(m domain.Material) interface{} { return m.Volume }},
+	{"Цена без НДС", 22, func(m domain.Material) interface{} { return m.PriceWithoutVAT }},
+	{"Сумма без НДС", 25, func(m domain.Material) interface{} { return m.TotalWithoutVAT }},
+	{"Ставка НДС", 0, func(m domain.Material) interface{} { return m.VATRate }},
+	{"Цена с НДС", 0, func(m domain.Material) interface{} { return m.PriceWithVAT }},
+	{"Сумма с НДС", 0, func(m domain.Material) interface{} { return m.TotalWithVAT }},
+	{"Валюта", 0, func(m domain.Material) interface{} { return m.Currency }},
+	{"Поставщик", 40, func(m domain.Material) interface{} { return m.SupplierName }},
+	{"Номер накладной", 0, func(m domain.Material) interface{} { return m.ByInvoice }},
+	{"Номер договора", 0, func(m domain.Material) interface{} { return m.ContractNumber }},
+	{"Дата договора", 0, func(m domain.Material) interface{} { return m.ContractDate }},
+	{"Статус", 22, func(m domain.Material) interface{} { return m.Status }},
+	{"Дата поступления", 0, func(m domain.Material) interface{} { return m.ReceivedDate }},
+	{"Срок годности", 0, func(m domain.Material) interface{} { return m.ExpirationDate }},
+	{"Мин. запас", 0, func(m domain.Material) interface{} { return m.MinStockLevel }},
+	{"Секция хранения", 0, func(m domain.Material) interface{} { return m.WarehouseSection }},
+	{"Входящий номер поставки", 0, func(m domain.Material) interface{} { return m.IncomingDeliveryNumber }},
+	{"Комментарии", 0, func(m domain.Material) interface{} { return m.Comments }},
+	{"Обновлено", 0, func(m domain.Material) interface{} { return m.LastUpdated }},
+}
+
+var exportPlanningColumns = []exportColumn{
+	{"Принято", 18, func(m domain.Material) interface{} { return m.ReceivedQuantity }},
+}
+
+var exportPurchasedColumns = []exportColumn{
+	{"Локация", 0, func(m domain.Material) interface{} { return m.Location }},
+	{"В резерве", 18, func(m domain.Material) interface{} { return m.ReservedQuantity }},
+	{"Доступно", 18, func(m domain.Material) interface{} { return m.AvailableQuantity }},
+}
+
+func exportColumns(params domain.MaterialExportParams) []exportColumn {
+	columns := append([]exportColumn{}, exportCommonColumns...)
+
+	switch {
+	case params.MaterialType == domain.MaterialTypePlanning:
+		columns = append(columns, exportPlanningColumns...)
+	case !params.Archive:
+		columns = append(columns, exportPurchasedColumns...)
+	default:
+		// в архиве закупленных материалов резервов нет
+		columns = append(columns, exportPurchasedColumns[0])
+	}
+
+	return columns
+}
+
+// ExportMaterials выгружает список материалов компании в w в формате xlsx, csv или pdf. Материалы читаются из базы
+// построчно: CSV отправляется клиенту по мере чтения, XLSX собирается потоковой записью excelize. Если указана
+// валюта, цены и стоимости пересчитываются в нее по текущему курсу компании
+func (s *MaterialsService) ExportMaterials(ctx context.Context, info domain.JWTInfo, params domain.MaterialExportParams, w io.Writer) error {
+	params.Currency = strings.ToUpper(strings.TrimSpace(params.Currency))
+	if params.Currency != "" && !domain.IsValidCurrency(params.Currency) {
+		return domain.ErrInvalidCurrency
+	}
+
+	columns := exportColumns(params)
+	listParams := domain.MaterialParams{
+		CompanyId: info.CompanyId,
+		Sort:      params.Sort,
+		SortField: params.SortField,
+		Filter:    params.Filter,
+	}
+
+	switch params.Format {
+	case domain.ExportFormatCSV:
+		return s.exportCSV(ctx, params, listParams, columns, w)
+	case domain.ExportFormatXLSX:
+		return s.exportXLSX(ctx, params, listParams, columns, w)
+	case domain.ExportFormatPDF:
+		return s.exportPDF(ctx, params, listParams, columns, w)
+	default:
+		return domain.ErrInvalidExportFormat
+	}
+}
+
+func (s *MaterialsService) exportCSV(ctx context.Context, params domain.MaterialExportParams, listParams domain.MaterialParams,
+	columns []exportColumn, w io.Writer) error {
+	// BOM нужен, чтобы Excel открывал файл в UTF-8
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.title
+	}
+
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	var count int
+	err := s.streamExport(ctx, params, listParams, func(m domain.Material) error {
+		for i, column := range columns {
+			record[i] = formatExportValue(column.value(m))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+
+		if count++; count%csvFlushRows == 0 {
+			writer.Flush()
+			return writer.Error()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *MaterialsService) exportXLSX(ctx context.Context, params domain.MaterialExportParams, listParams domain.MaterialParams,
+	columns []exportColumn, w io.Writer) (err error) {
+	f := excelize.NewFile()
+	defer func(f *excelize.File) {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}(f)
+
+	sheetName := exportTitle(params)
+	if err = f.SetSheetName("Sheet1", sheetName); err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = excelize.Cell{Value: column.title}
+	}
+
+	if err = sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	row := 1
+	err = s.streamExport(ctx, params, listParams, func(m domain.Material) error {
+		row++
+
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			value := column.value(m)
+			switch v := value.(type) {
+			case time.Time:
+				value = formatExportValue(v)
+			case decimal.Decimal:
+				value = v.InexactFloat64()
+			}
+
+			values[i] = value
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+
+		return sw.SetRow(cell, values)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = sw.Flush(); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+func (s *MaterialsService) exportPDF(ctx context.Context, params domain.MaterialExportParams, listParams domain.MaterialParams,
+	columns []exportColumn, w io.Writer) error {
+	var pdfColumns []exportColumn
+	for _, column := range columns {
+		if column.pdfWidth > 0 {
+			pdfColumns = append(pdfColumns, column)
+		}
+	}
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8Font("Arial", "", pdfFontPath)
+	pdf.SetAutoPageBreak(true, 10)
+
+	title := fmt.Sprintf("%s на %s", exportTitle(params), time.Now().Format("02.01.2006 15:04"))
+	if params.Currency != "" {
+		title += fmt.Sprintf(", цены в %s", params.Currency)
+	}
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Arial", "", 12)
+		pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Arial", "", 8)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range pdfColumns {
+			pdf.CellFormat(column.pdfWidth, 7, column.title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+	})
+
+	pdf.AddPage()
+
+	var count int
+	err := s.streamExport(ctx, params, listParams, func(m domain.Material) error {
+		if count++; count > domain.MaxPdfExportRows {
+			return nil
+		}
+
+		for _, column := range pdfColumns {
+			align := "L"
+			value := column.value(m)
+			switch value.(type) {
+			case int64, float64, decimal.Decimal:
+				align = "R"
+			}
+
+			text := fitPdfText(pdf, formatExportValue(value), column.pdfWidth-2)
+			pdf.CellFormat(column.pdfWidth, 6, text, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		return pdf.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	pdf.Ln(2)
+	if count > domain.MaxPdfExportRows {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Показаны первые %d из %d строк, полный список доступен в XLSX и CSV",
+			domain.MaxPdfExportRows, count), "", 1, "L", false, 0, "")
+	} else {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Всего строк: %d", count), "", 1, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+// streamExport читает материалы выгрузки и при выбранной валюте выгрузки пересчитывает их цены в эту валюту
+func (s *MaterialsService) streamExport(ctx context.Context, params domain.MaterialExportParams, listParams domain.MaterialParams,
+	fn func(m domain.Material) error) error {
+	if params.Currency == "" {
+		return s.repo.Materials.StreamList(ctx, params.MaterialType, params.Archive, listParams, fn)
+	}
+
+	converter := newCurrencyConverter(s.repo, listParams.CompanyId, params.Currency, time.Now().UTC())
+
+	return s.repo.Materials.StreamList(ctx, params.MaterialType, params.Archive, listParams, func(m domain.Material) error {
+		m, err := converter.material(ctx, m)
+		if err != nil {
+			return err
+		}
+
+		return fn(m)
+	})
+}
+
+func exportTitle(params domain.MaterialExportParams) string {
+	title := "Планируемые материалы"
+	if params.MaterialType == domain.MaterialTypePurchased {
+		title = "Закупленные материалы"
+	}
+
+	if params.Archive {
+		title += " (архив)"
+	}
+
+	return title
+}
+
+// formatExportValue приводит значение колонки к строке. Незаполненные даты выводятся пустой строкой
+func formatExportValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case decimal.Decimal:
+		return v.StringFixed(domain.MoneyPlaces)
+	case time.Time:
+		if v.IsZero() || v.Year() <= 1 {
+			return ""
+		}
+
+		return v.Format("02.01.2006")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fitPdfText обрезает текст, чтобы он поместился в колонку указанной ширины
+func fitPdfText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
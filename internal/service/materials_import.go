@@ -0,0 +1,651 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importFields поля материала, доступные для импорта, по типу материала
+var importFields = map[string]map[string]bool{
+	domain.MaterialTypePlanning:  importFieldSet(),
+	domain.MaterialTypePurchased: importFieldSet(domain.ImportFieldLocation),
+}
+
+// importDateLayouts форматы дат, которые принимаются в файле импорта
+var importDateLayouts = []string{
+	time.RFC3339,
+	time.DateTime,
+	time.DateOnly,
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+func importFieldSet(extra ...string) map[string]bool {
+	fields := map[string]bool{
+		domain.ImportFieldWarehouseID:            true,
+		domain.ImportFieldName:                   true,
+		domain.ImportFieldByInvoice:              true,
+		domain.ImportFieldArticle:                true,
+		domain.ImportFieldProductCategory:        true,
+		domain.ImportFieldUnit:                   true,
+		domain.ImportFieldTotalQuantity:          true,
+		domain.ImportFieldVolume:                 true,
+		domain.ImportFieldPriceWithoutVAT:        true,
+		domain.ImportFieldTotalWithoutVAT:        true,
+		domain.ImportFieldVATRate:                true,
+		domain.ImportFieldCurrency:               true,
+		domain.ImportFieldSupplierID:             true,
+		domain.ImportFieldSupplier:               true,
+		domain.ImportFieldContractDate:           true,
+		domain.ImportFieldFile:                   true,
+		domain.ImportFieldStatus:                 true,
+		domain.ImportFieldComments:               true,
+		domain.ImportFieldReceivedDate:           true,
+		domain.ImportFieldMinStockLevel:          true,
+		domain.ImportFieldExpirationDate:         true,
+		domain.ImportFieldResponsiblePerson:      true,
+		domain.ImportFieldStorageCost:            true,
+		domain.ImportFieldWarehouseSection:       true,
+		domain.ImportFieldIncomingDeliveryNumber: true,
+		domain.ImportFieldInternalName:           true,
+		domain.ImportFieldUnitsPerPackage:        true,
+		domain.ImportFieldContractNumber:         true,
+	}
+
+	for _, field := range extra {
+		fields[field] = true
+	}
+
+	return fields
+}
+
+type importColumn struct {
+	index int
+	field string
+}
+
+// ImportMaterials загружает материалы из файла XLSX или CSV. Первая строка файла - заголовки колонок. Каждая строка
+// проверяется отдельно, материалы сохраняются одной транзакцией только если ни одна строка не содержит ошибок.
+// При пробном импорте возвращается только результат проверки строк
+func (s *MaterialsService) ImportMaterials(ctx context.Context, info domain.JWTInfo, materialType string, r io.Reader,
+	params domain.MaterialImportParams) (domain.ImportResult, error) {
+	records, err := readImportRecords(r, params.Format)
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	if len(records) < 2 {
+		return domain.ImportResult{}, domain.ErrImportEmptyFile
+	}
+
+	if len(records)-1 > domain.MaxImportRows {
+		return domain.ImportResult{}, domain.ErrImportTooManyRows
+	}
+
+	columns, mapping, err := importColumns(records[0], params.Mapping, importFields[materialType])
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	result := domain.ImportResult{
+		DryRun:  params.DryRun,
+		Columns: mapping,
+		Rows:    make([]domain.ImportRowResult, 0, len(records)-1),
+	}
+
+	resolver := newImportResolver(s.repo, info.CompanyId)
+	capacity := newCapacityPlan()
+	prepared := make([]domain.Material, 0, len(records)-1)
+	positions := make([]int, 0, len(records)-1)
+
+	for i, record := range records[1:] {
+		if isEmptyImportRecord(record) {
+			continue
+		}
+
+		row := domain.ImportRowResult{Row: i + 2, Status: domain.ImportRowValid}
+
+		material, rowErrors, err := s.parseImportRow(ctx, info, resolver, columns, record, params)
+		if err != nil {
+			return domain.ImportResult{}, err
+		}
+
+		if len(rowErrors) == 0 {
+			material, err = s.prepareNewMaterial(ctx, info, material)
+			if err != nil {
+				if !isImportRowError(err) {
+					return domain.ImportResult{}, err
+				}
+
+				rowErrors = append(rowErrors, importRowErrors(err)...)
+			}
+		}
+
+		// закупленные материалы занимают место на складе
+		if len(rowErrors) == 0 && materialType == domain.MaterialTypePurchased {
+			if err = capacity.check(ctx, s.repo, material.WarehouseID, material.Volume*material.TotalQuantity); err != nil {
+				if !isImportRowError(err) {
+					return domain.ImportResult{}, err
+				}
+
+				rowErrors = append(rowErrors, err.Error())
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			row.Status = domain.ImportRowInvalid
+			row.Errors = rowErrors
+		} else {
+			prepared = append(prepared, material)
+			positions = append(positions, len(result.Rows))
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	if len(result.Rows) == 0 {
+		return domain.ImportResult{}, domain.ErrImportEmptyFile
+	}
+
+	if !params.DryRun {
+		if err = s.commitImport(ctx, info, materialType, &result, prepared, positions); err != nil {
+			return domain.ImportResult{}, err
+		}
+
+		if result.Committed {
+			capacity.notify(ctx, s.repo, info)
+		}
+	}
+
+	result.Total = len(result.Rows)
+	for _, row := range result.Rows {
+		if row.Status == domain.ImportRowInvalid {
+			result.Invalid++
+		} else {
+			result.Valid++
+		}
+	}
+
+	return result, nil
+}
+
+// commitImport сохраняет проверенные строки одной транзакцией. Если в файле есть строки с ошибками,
+// ничего не сохраняется
+func (s *MaterialsService) commitImport(ctx context.Context, info domain.JWTInfo, materialType string,
+	result *domain.ImportResult, prepared []domain.Material, positions []int) error {
+	if len(prepared) != len(result.Rows) {
+		for _, position := range positions {
+			result.Rows[position].Status = domain.ImportRowSkipped
+		}
+
+		return nil
+	}
+
+	var (
+		items     []domain.BulkItemResult
+		committed bool
+		err       error
+	)
+
+	entityType := domain.AuditEntityPlanningMaterial
+	if materialType == domain.MaterialTypePurchased {
+		entityType = domain.AuditEntityPurchasedMaterial
+		items, committed, err = s.repo.Materials.BulkCreatePurchased(ctx, prepared, info.UserId, true)
+	} else {
+		items, committed, err = s.repo.Materials.BulkCreatePlanning(ctx, prepared, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	result.Committed = committed
+
+	for k, item := range items {
+		row := &result.Rows[positions[k]]
+
+		switch item.Status {
+		case domain.BulkItemSucceeded:
+			row.Status = domain.ImportRowCreated
+			row.ID = item.ID
+			row.ItemID = item.ItemID
+		case domain.BulkItemFailed:
+			row.Status = domain.ImportRowInvalid
+			row.Errors = []string{item.Error}
+		default:
+			row.Status = domain.ImportRowSkipped
+		}
+	}
+
+	if committed {
+		for _, item := range items {
+			s.auditMaterial(ctx, info, entityType, item.ID, domain.AuditActionCreate, nil)
+		}
+	}
+
+	return nil
+}
+
+// parseImportRow заполняет материал значениями строки файла. Ошибки в значениях возвращаются списком для строки,
+// ошибка выполнения запроса прерывает импорт
+func (s *MaterialsService) parseImportRow(ctx context.Context, info domain.JWTInfo, resolver *importResolver,
+	columns []importColumn, record []string, params domain.MaterialImportParams) (domain.Material, []string, error) {
+	material := domain.Material{
+		WarehouseID: params.WarehouseID,
+		CompanyID:   info.CompanyId,
+		LastUpdated: time.Now().UTC(),
+	}
+
+	var rowErrors []string
+
+	for _, column := range columns {
+		if column.index >= len(record) {
+			continue
+		}
+
+		value := strings.TrimSpace(record[column.index])
+		if value == "" {
+			continue
+		}
+
+		if err := setImportField(ctx, resolver, &material, column.field, value); err != nil {
+			if !isImportRowError(err) {
+				return domain.Material{}, nil, err
+			}
+
+			rowErrors = append(rowErrors, fmt.Sprintf("%s %q: %v", column.field, value, err))
+		}
+	}
+
+	if material.Name == "" {
+		rowErrors = append(rowErrors, fmt.Sprintf("%s: value is required", domain.ImportFieldName))
+	}
+
+	if material.WarehouseID == 0 {
+		rowErrors = append(rowErrors, fmt.Sprintf("%s: value is required", domain.ImportFieldWarehouseID))
+	}
+
+	if material.SupplierID == 0 {
+		rowErrors = append(rowErrors, fmt.Sprintf("%s: value is required", domain.ImportFieldSupplier))
+	}
+
+	return material, rowErrors, nil
+}
+
+func setImportField(ctx context.Context, resolver *importResolver, m *domain.Material, field, value string) error {
+	var err error
+
+	switch field {
+	case domain.ImportFieldWarehouseID:
+		m.WarehouseID, err = parseImportInt(value)
+	case domain.ImportFieldName:
+		m.Name = value
+	case domain.ImportFieldByInvoice:
+		m.ByInvoice = value
+	case domain.ImportFieldArticle:
+		m.Article = value
+	case domain.ImportFieldProductCategory:
+		m.ProductCategory, err = resolver.categories(ctx, value)
+	case domain.ImportFieldUnit:
+		m.Unit, err = resolver.unit(ctx, value)
+	case domain.ImportFieldTotalQuantity:
+		m.TotalQuantity, err = parseImportInt(value)
+	case domain.ImportFieldVolume:
+		m.Volume, err = parseImportInt(value)
+	case domain.ImportFieldPriceWithoutVAT:
+		m.PriceWithoutVAT, err = parseImportDecimal(value)
+	case domain.ImportFieldTotalWithoutVAT:
+		var total decimal.Decimal
+		if total, err = parseImportDecimal(value); err == nil {
+			m.InputTotalWithoutVAT = &total
+		}
+	case domain.ImportFieldVATRate:
+		m.VATRate, err = parseImportDecimal(strings.TrimSuffix(value, "%"))
+	case domain.ImportFieldCurrency:
+		m.Currency = strings.ToUpper(value)
+	case domain.ImportFieldSupplierID:
+		m.SupplierID, err = parseImportInt(value)
+	case domain.ImportFieldSupplier:
+		m.SupplierID, err = resolver.supplier(ctx, value)
+	case domain.ImportFieldLocation:
+		m.Location = value
+	case domain.ImportFieldContractDate:
+		m.ContractDate, err = parseImportDate(value)
+	case domain.ImportFieldFile:
+		m.File = value
+	case domain.ImportFieldStatus:
+		m.Status = value
+	case domain.ImportFieldComments:
+		m.Comments = value
+	case domain.ImportFieldReceivedDate:
+		m.ReceivedDate, err = parseImportDate(value)
+	case domain.ImportFieldMinStockLevel:
+		m.MinStockLevel, err = parseImportInt(value)
+	case domain.ImportFieldExpirationDate:
+		m.ExpirationDate, err = parseImportDate(value)
+	case domain.ImportFieldResponsiblePerson:
+		m.ResponsiblePerson, err = parseImportInt(value)
+	case domain.ImportFieldStorageCost:
+		m.StorageCost, err = parseImportDecimal(value)
+	case domain.ImportFieldWarehouseSection:
+		m.WarehouseSection = value
+	case domain.ImportFieldIncomingDeliveryNumber:
+		m.IncomingDeliveryNumber = value
+	case domain.ImportFieldInternalName:
+		m.InternalName = value
+	case domain.ImportFieldUnitsPerPackage:
+		m.UnitsPerPackage, err = parseImportInt(value)
+	case domain.ImportFieldContractNumber:
+		m.ContractNumber = value
+	}
+
+	return err
+}
+
+// isImportRowError возвращает true для ошибок, которые относятся к данным строки, а не к выполнению импорта
+func isImportRowError(err error) bool {
+	return errors.Is(err, domain.ErrImportInvalidValue) || errors.Is(err, domain.ErrWarehouseNotFound) ||
+		errors.Is(err, domain.ErrSupplierNotFound) || errors.Is(err, domain.ErrUnitOfMeasureNotFound) ||
+		errors.Is(err, domain.ErrMaterialCategoryNotFound) || errors.Is(err, domain.ErrNotAllowed) ||
+		errors.Is(err, domain.ErrWarehouseOverCapacity) || errors.Is(err, domain.ErrInvalidVATRate) ||
+		errors.Is(err, domain.ErrInvalidCurrency) || errors.As(err, new(*domain.ValidationError))
+}
+
+// importRowErrors возвращает тексты ошибок строки импорта, ошибка проверки полей раскладывается по полям
+func importRowErrors(err error) []string {
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) {
+		return []string{err.Error()}
+	}
+
+	messages := make([]string, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		messages = append(messages, f.Message)
+	}
+
+	return messages
+}
+
+// importColumns сопоставляет колонки файла с полями материала. Сначала применяется явное сопоставление из запроса,
+// остальные колонки сопоставляются по совпадению заголовка с названием поля. Колонки без поля пропускаются
+func importColumns(header []string, mapping map[string]string, fields map[string]bool) ([]importColumn, map[string]string, error) {
+	explicit := make(map[string]string, len(mapping))
+	for column, field := range mapping {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !fields[field] {
+			return nil, nil, fmt.Errorf("%w: unknown field %q", domain.ErrImportInvalidMapping, field)
+		}
+
+		explicit[normalizeImportHeader(column)] = field
+	}
+
+	var columns []importColumn
+	used := make(map[string]bool)
+	found := make(map[string]bool)
+	result := make(map[string]string)
+
+	for i, title := range header {
+		key := normalizeImportHeader(title)
+
+		field, ok := explicit[key]
+		if ok {
+			found[key] = true
+		} else if fields[key] {
+			field = key
+		} else {
+			continue
+		}
+
+		if used[field] {
+			return nil, nil, fmt.Errorf("%w: several columns for field %q", domain.ErrImportInvalidMapping, field)
+		}
+
+		used[field] = true
+		result[title] = field
+		columns = append(columns, importColumn{index: i, field: field})
+	}
+
+	for column := range explicit {
+		if !found[column] {
+			return nil, nil, fmt.Errorf("%w: column %q not found in file", domain.ErrImportInvalidMapping, column)
+		}
+	}
+
+	return columns, result, nil
+}
+
+func normalizeImportHeader(title string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(title, "\ufeff")))
+}
+
+func isEmptyImportRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readImportRecords читает строки первого листа XLSX или CSV файла. Для CSV разделитель (запятая, точка с запятой
+// или табуляция) определяется по строке заголовков
+func readImportRecords(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case domain.ImportFormatXLSX:
+		f, err := excelize.OpenReader(r, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalidFile, err)
+		}
+		defer func(f *excelize.File) {
+			if err = f.Close(); err != nil {
+				return
+			}
+		}(f)
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, domain.ErrImportEmptyFile
+		}
+
+		rows, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalidFile, err)
+		}
+
+		return rows, nil
+	case domain.ImportFormatCSV:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.Comma = detectCSVDelimiter(data)
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalidFile, err)
+		}
+
+		return records, nil
+	default:
+		return nil, domain.ErrImportUnsupportedFormat
+	}
+}
+
+func detectCSVDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+
+	delimiter, count := ',', bytes.Count(line, []byte{','})
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(candidate))); n > count {
+			delimiter, count = candidate, n
+		}
+	}
+
+	return delimiter
+}
+
+// parseImportFloat разбирает число, допускает запятую как десятичный разделитель и пробелы между разрядами
+func parseImportFloat(value string) (float64, error) {
+	value = strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(value)
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, domain.ErrImportInvalidValue
+	}
+
+	return number, nil
+}
+
+// parseImportDecimal разбирает денежное значение без потери точности. Допускаются пробелы между разрядами
+// и запятая в качестве десятичного разделителя
+func parseImportDecimal(value string) (decimal.Decimal, error) {
+	value = strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(value)
+
+	number, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, domain.ErrImportInvalidValue
+	}
+
+	return number, nil
+}
+
+func parseImportInt(value string) (int64, error) {
+	number, err := parseImportFloat(value)
+	if err != nil || number != math.Trunc(number) || math.Abs(number) > math.MaxInt64 {
+		return 0, domain.ErrImportInvalidValue
+	}
+
+	return int64(number), nil
+}
+
+// parseImportDate разбирает дату в одном из форматов importDateLayouts или числовую дату Excel
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC(), nil
+		}
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		date, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			return date.UTC(), nil
+		}
+	}
+
+	return time.Time{}, domain.ErrImportInvalidValue
+}
+
+// importResolver находит поставщиков, единицы измерения и категории компании по значениям из файла.
+// Результаты поиска кэшируются на время импорта, так как значения в строках обычно повторяются
+type importResolver struct {
+	repo          *repository.Repository
+	companyId     int64
+	supplierCache map[string]importLookup
+	unitCache     map[string]importLookup
+	categoryCache map[string]importLookup
+}
+
+type importLookup struct {
+	id   int64
+	name string
+	err  error
+}
+
+func newImportResolver(repo *repository.Repository, companyId int64) *importResolver {
+	return &importResolver{
+		repo:          repo,
+		companyId:     companyId,
+		supplierCache: make(map[string]importLookup),
+		unitCache:     make(map[string]importLookup),
+		categoryCache: make(map[string]importLookup),
+	}
+}
+
+// supplier возвращает id поставщика по наименованию или ИНН
+func (r *importResolver) supplier(ctx context.Context, value string) (int64, error) {
+	key := strings.ToLower(value)
+	if cached, ok := r.supplierCache[key]; ok {
+		return cached.id, cached.err
+	}
+
+	id, err := r.repo.Suppliers.GetIdByNameOrTaxId(ctx, r.companyId, value)
+	if err != nil && !errors.Is(err, domain.ErrSupplierNotFound) {
+		return 0, err
+	}
+
+	r.supplierCache[key] = importLookup{id: id, err: err}
+	return id, err
+}
+
+// unit возвращает аббревиатуру единицы измерения в том виде, в котором она заведена в справочнике
+func (r *importResolver) unit(ctx context.Context, value string) (string, error) {
+	key := strings.ToLower(value)
+	if cached, ok := r.unitCache[key]; ok {
+		return cached.name, cached.err
+	}
+
+	unit, err := r.repo.UnitOfMeasure.GetByAbbreviation(ctx, value, r.companyId)
+	if err != nil && !errors.Is(err, domain.ErrUnitOfMeasureNotFound) {
+		return "", err
+	}
+
+	r.unitCache[key] = importLookup{id: unit.ID, name: unit.Abbreviation, err: err}
+	return unit.Abbreviation, err
+}
+
+// categories возвращает названия категорий из справочника для списка через запятую или точку с запятой
+func (r *importResolver) categories(ctx context.Context, value string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.FieldsFunc(value, func(c rune) bool { return c == ',' || c == ';' }) {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+
+		cached, ok := r.categoryCache[key]
+		if !ok {
+			category, err := r.repo.MaterialCategory.GetByName(ctx, name, r.companyId)
+			if err != nil && !errors.Is(err, domain.ErrMaterialCategoryNotFound) {
+				return nil, err
+			}
+
+			cached = importLookup{id: category.ID, name: category.Name, err: err}
+			r.categoryCache[key] = cached
+		}
+
+		if cached.err != nil {
+			return nil, fmt.Errorf("%w: %s", cached.err, name)
+		}
+
+		seen[key] = true
+		names = append(names, cached.name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportColumns(t *testing.T) {
+	fields := map[string]bool{
+		domain.ImportFieldName:          true,
+		domain.ImportFieldWarehouseID:   true,
+		domain.ImportFieldTotalQuantity: true,
+	}
+
+	tests := []struct {
+		name        string
+		header      []string
+		mapping     map[string]string
+		wantColumns []importColumn
+		wantMapping map[string]string
+		wantErr     error
+	}{
+		{
+			name:        "by header",
+			header:      []string{"\ufeffName", " warehouse_id ", "unknown"},
+			wantColumns: []importColumn{{index: 0, field: "name"}, {index: 1, field: "warehouse_id"}},
+			wantMapping: map[string]string{"\ufeffName": "name", " warehouse_id ": "warehouse_id"},
+		},
+		{
+			name:        "explicit mapping",
+			header:      []string{"Наименование", "Кол-во", "warehouse_id"},
+			mapping:     map[string]string{"наименование": "name", "Кол-во": " Total_Quantity "},
+			wantColumns: []importColumn{{index: 0, field: "name"}, {index: 1, field: "total_quantity"}, {index: 2, field: "warehouse_id"}},
+			wantMapping: map[string]string{"Наименование": "name", "Кол-во": "total_quantity", "warehouse_id": "warehouse_id"},
+		},
+		{
+			name:    "unknown field in mapping",
+			header:  []string{"Наименование"},
+			mapping: map[string]string{"Наименование": "title"},
+			wantErr: domain.ErrImportInvalidMapping,
+		},
+		{
+			name:    "mapped column not in file",
+			header:  []string{"name"},
+			mapping: map[string]string{"Склад": "warehouse_id"},
+			wantErr: domain.ErrImportInvalidMapping,
+		},
+		{
+			name:    "several columns for one field",
+			header:  []string{"Наименование", "name"},
+			mapping: map[string]string{"Наименование": "name"},
+			wantErr: domain.ErrImportInvalidMapping,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, mapping, err := importColumns(tt.header, tt.mapping, fields)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("importColumns() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("importColumns() columns = %v, want %v", columns, tt.wantColumns)
+			}
+
+			if !reflect.DeepEqual(mapping, tt.wantMapping) {
+				t.Errorf("importColumns() mapping = %v, want %v", mapping, tt.wantMapping)
+			}
+		})
+	}
+}
+
+func TestParseImportInt(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"42", 42, false},
+		{"-7", -7, false},
+		{"1 000", 1000, false},
+		{"1\u00a0000", 1000, false},
+		{"15,0", 15, false},
+		{"15.5", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+		{"NaN", 0, true},
+		{"1e30", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseImportInt(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImportInt(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseImportInt(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-03-15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), false},
+		{"2024-03-15 10:30:00", time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC), false},
+		{"2024-03-15T10:30:00+03:00", time.Date(2024, 3, 15, 7, 30, 0, 0, time.UTC), false},
+		{"15.03.2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), false},
+		{"15.03.2024 10:30", time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC), false},
+		{"15.03.2024 10:30:15", time.Date(2024, 3, 15, 10, 30, 15, 0, time.UTC), false},
+		{"45366", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), false},
+		{"45366.5", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC), false},
+		{"0", time.Time{}, true},
+		{"15/03/2024", time.Time{}, true},
+		{"tomorrow", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseImportDate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImportDate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("parseImportDate(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want rune
+	}{
+		{"comma", "name,warehouse_id,total_quantity\nКирпич,1,10", ','},
+		{"semicolon", "name;warehouse_id;total_quantity\nКирпич;1;10,5", ';'},
+		{"tab", "name\twarehouse_id\ttotal_quantity\n", '\t'},
+		{"only header line is used", "name;warehouse_id\n1,2,3,4,5", ';'},
+		{"comma inside semicolon header", "name;price, KZT;total_quantity", ';'},
+		{"no delimiter", "name", ','},
+		{"empty", "", ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCSVDelimiter([]byte(tt.data)); got != tt.want {
+				t.Errorf("detectCSVDelimiter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"github.com/rusystem/crm-api/pkg/domain"
+)
+
+// prepareMaterialLocation проверяет, что место хранения материала находится на складе материала, и заполняет
+// текстовую локацию кодом места хранения
+func (s *MaterialsService) prepareMaterialLocation(ctx context.Context, material domain.Material) (domain.Material, error) {
+	if material.LocationID == 0 {
+		return material, nil
+	}
+
+	location, err := s.warehouseLocation(ctx, material.LocationID, material.WarehouseID)
+	if err != nil {
+		return domain.Material{}, err
+	}
+
+	material.Location = location.Code
+
+	return material, nil
+}
+
+// warehouseLocation возвращает место хранения, если оно находится на указанном складе
+func (s *MaterialsService) warehouseLocation(ctx context.Context, id, warehouseId int64) (domain.WarehouseLocation, error) {
+	location, err := s.repo.WarehouseLocations.GetById(ctx, id)
+	if err != nil {
+		return domain.WarehouseLocation{}, err
+	}
+
+	if location.WarehouseID != warehouseId {
+		return domain.WarehouseLocation{}, domain.ErrLocationWarehouse
+	}
+
+	return location, nil
+}
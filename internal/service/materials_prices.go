@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// prepareMaterialPrice проверяет количество, цены, ставку НДС и валюту материала и рассчитывает общую стоимость
+// и производные суммы. Материал без валюты получает валюту компании по умолчанию. total - переданная клиентом общая
+// стоимость без НДС, она должна совпадать с рассчитанной
+func (s *MaterialsService) prepareMaterialPrice(ctx context.Context, material domain.Material, total *decimal.Decimal) (domain.Material, error) {
+	if err := validateMaterialAmounts(material, total); err != nil {
+		return domain.Material{}, err
+	}
+
+	if !domain.IsValidVATRate(material.VATRate) {
+		return domain.Material{}, domain.ErrInvalidVATRate
+	}
+
+	material.Currency = strings.ToUpper(strings.TrimSpace(material.Currency))
+	if material.Currency == "" {
+		currency, err := companyCurrency(ctx, s.repo, material.CompanyID)
+		if err != nil {
+			return domain.Material{}, err
+		}
+
+		material.Currency = currency
+	}
+
+	if !domain.IsValidCurrency(material.Currency) {
+		return domain.Material{}, domain.ErrInvalidCurrency
+	}
+
+	material.CalculateTotals()
+
+	return material, nil
+}
+
+// validateMaterialAmounts проверяет, что количество и цены материала неотрицательны, а переданная общая стоимость
+// равна цене, умноженной на количество. Возвращает *domain.ValidationError со всеми некорректными полями
+func validateMaterialAmounts(material domain.Material, total *decimal.Decimal) error {
+	var verr domain.ValidationError
+
+	if material.TotalQuantity < 0 {
+		verr.Add("total_quantity", "total_quantity must not be negative")
+	}
+
+	if material.Volume < 0 {
+		verr.Add("volume", "volume must not be negative")
+	}
+
+	if material.PriceWithoutVAT.IsNegative() {
+		verr.Add("price_without_vat", "price_without_vat must not be negative")
+	}
+
+	if material.StorageCost.IsNegative() {
+		verr.Add("storage_cost", "storage_cost must not be negative")
+	}
+
+	if total != nil && len(verr.Fields) == 0 {
+		expected := domain.LineTotal(material.PriceWithoutVAT, material.TotalQuantity)
+		if !total.Round(domain.MoneyPlaces).Equal(expected) {
+			verr.Add("total_without_vat", fmt.Sprintf("total_without_vat must equal price_without_vat × total_quantity: %s",
+				expected.StringFixed(domain.MoneyPlaces)))
+		}
+	}
+
+	return verr.Err()
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/shopspring/decimal"
+	"reflect"
+	"testing"
+)
+
+func TestValidateMaterialAmounts(t *testing.T) {
+	total := func(value string) *decimal.Decimal {
+		d := decimal.RequireFromString(value)
+		return &d
+	}
+
+	valid := domain.Material{
+		TotalQuantity:   500,
+		Volume:          25,
+		PriceWithoutVAT: decimal.RequireFromString("150.75"),
+		StorageCost:     decimal.RequireFromString("2"),
+	}
+
+	tests := []struct {
+		name       string
+		material   func(m domain.Material) domain.Material
+		total      *decimal.Decimal
+		wantFields []string
+	}{
+		{"total not sent", nil, nil, nil},
+		{"total matches", nil, total("75375"), nil},
+		{"total matches after rounding", nil, total("75375.004"), nil},
+		{"total differs", nil, total("75000"), []string{"total_without_vat"}},
+		{"explicit zero total", nil, total("0"), []string{"total_without_vat"}},
+		{
+			name: "zero total of zero quantity",
+			material: func(m domain.Material) domain.Material {
+				m.TotalQuantity = 0
+				return m
+			},
+			total: total("0"),
+		},
+		{
+			name: "negative amounts",
+			material: func(m domain.Material) domain.Material {
+				m.TotalQuantity = -1
+				m.Volume = -1
+				m.PriceWithoutVAT = decimal.RequireFromString("-1")
+				m.StorageCost = decimal.RequireFromString("-1")
+				return m
+			},
+			wantFields: []string{"total_quantity", "volume", "price_without_vat", "storage_cost"},
+		},
+		{
+			name: "total is not checked when amounts are invalid",
+			material: func(m domain.Material) domain.Material {
+				m.TotalQuantity = -1
+				return m
+			},
+			total:      total("1"),
+			wantFields: []string{"total_quantity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			material := valid
+			if tt.material != nil {
+				material = tt.material(material)
+			}
+
+			err := validateMaterialAmounts(material, tt.total)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("validateMaterialAmounts() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var verr *domain.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("validateMaterialAmounts() error = %v, want *domain.ValidationError", err)
+			}
+
+			fields := make([]string, 0, len(verr.Fields))
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("validateMaterialAmounts() fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"math"
+	"strings"
+)
+
+// prepareMaterialUnit связывает материал с единицей измерения из справочника компании материала.
+// Если unit_id не указан, единица ищется по аббревиатуре unit, не найденная в справочнике единица остается текстом
+func (s *MaterialsService) prepareMaterialUnit(ctx context.Context, material domain.Material) (domain.Material, error) {
+	if material.UnitsPerPackage < 0 {
+		return domain.Material{}, domain.ErrInvalidQuantity
+	}
+
+	if material.UnitID != 0 {
+		unit, err := s.repo.UnitOfMeasure.GetById(ctx, material.UnitID, material.CompanyID)
+		if err != nil {
+			return domain.Material{}, err
+		}
+
+		material.Unit = unit.Abbreviation
+		return material, nil
+	}
+
+	abbreviation := strings.TrimSpace(material.Unit)
+	if abbreviation == "" {
+		return material, nil
+	}
+
+	unit, err := s.repo.UnitOfMeasure.GetByAbbreviation(ctx, abbreviation, material.CompanyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnitOfMeasureNotFound) {
+			return material, nil
+		}
+
+		return domain.Material{}, err
+	}
+
+	material.UnitID = unit.ID
+	material.Unit = unit.Abbreviation
+
+	return material, nil
+}
+
+// transferQuantity приводит перемещаемое количество к единице измерения материала.
+// quantity задается в единице unit_id (по умолчанию в единице материала), упаковки пересчитываются через
+// units_per_package материала и добавляются к quantity. Результат должен быть целым числом единиц материала
+func (s *MaterialsService) transferQuantity(ctx context.Context, material domain.Material, inp domain.TransferPurchasedMaterial) (int64, error) {
+	if inp.Quantity < 0 || inp.Packages < 0 {
+		return 0, domain.ErrInvalidQuantity
+	}
+
+	quantity := float64(inp.Quantity)
+
+	if inp.UnitID != 0 && inp.UnitID != material.UnitID && inp.Quantity != 0 {
+		if material.UnitID == 0 {
+			return 0, domain.ErrUnitsNotConvertible
+		}
+
+		from, err := s.repo.UnitOfMeasure.GetById(ctx, inp.UnitID, material.CompanyID)
+		if err != nil {
+			return 0, err
+		}
+
+		to, err := s.repo.UnitOfMeasure.GetById(ctx, material.UnitID, material.CompanyID)
+		if err != nil {
+			return 0, err
+		}
+
+		if quantity, err = convertUnits(quantity, from, to); err != nil {
+			return 0, err
+		}
+	}
+
+	if inp.Packages != 0 {
+		if material.UnitsPerPackage <= 0 {
+			return 0, domain.ErrUnitsPerPackageEmpty
+		}
+
+		quantity += float64(inp.Packages * material.UnitsPerPackage)
+	}
+
+	if quantity != math.Trunc(quantity) {
+		return 0, domain.ErrFractionalQuantity
+	}
+
+	return int64(quantity), nil
+}
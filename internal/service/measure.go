@@ -2,17 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/rusystem/crm-api/internal/config"
 	"github.com/rusystem/crm-api/internal/repository"
 	"github.com/rusystem/crm-api/pkg/domain"
+	"math"
 )
 
 type UnitOfMeasure interface {
-	Create(ctx context.Context, measure domain.UnitOfMeasure) (int64, error)
-	Update(ctx context.Context, measure domain.UpdateUnitOfMeasure) error
-	Delete(ctx context.Context, id, companyId int64) error
+	Create(ctx context.Context, measure domain.UnitOfMeasure, info domain.JWTInfo) (int64, error)
+	Update(ctx context.Context, measure domain.UpdateUnitOfMeasure, info domain.JWTInfo) error
+	Delete(ctx context.Context, id int64, info domain.JWTInfo) error
 	GetById(ctx context.Context, id, companyId int64) (domain.UnitOfMeasure, error)
 	List(ctx context.Context, param domain.Param) ([]domain.UnitOfMeasure, int64, error)
+	Convert(ctx context.Context, quantity float64, fromUnitId, toUnitId, companyId int64) (domain.UnitConversion, error)
 }
 
 type UnitOfMeasureService struct {
@@ -27,16 +30,31 @@ func NewUnitOfMeasureService(cfg *config.Config, repo *repository.Repository) *U
 	}
 }
 
-func (ums *UnitOfMeasureService) Create(ctx context.Context, measure domain.UnitOfMeasure) (int64, error) {
-	return ums.repo.UnitOfMeasure.Create(ctx, measure)
+func (ums *UnitOfMeasureService) Create(ctx context.Context, measure domain.UnitOfMeasure, info domain.JWTInfo) (int64, error) {
+	measure, err := ums.prepareBaseUnit(ctx, measure)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := ums.repo.UnitOfMeasure.Create(ctx, measure)
+	if err != nil {
+		return 0, err
+	}
+
+	measure.ID = id
+	recordAudit(ctx, ums.repo, info, measure.CompanyID, domain.AuditEntityUnitOfMeasure, id, domain.AuditActionCreate, nil, measure)
+
+	return id, nil
 }
 
-func (ums *UnitOfMeasureService) Update(ctx context.Context, inp domain.UpdateUnitOfMeasure) error {
+func (ums *UnitOfMeasureService) Update(ctx context.Context, inp domain.UpdateUnitOfMeasure, info domain.JWTInfo) error {
 	measure, err := ums.repo.UnitOfMeasure.GetById(ctx, inp.ID, inp.CompanyID)
 	if err != nil {
 		return err
 	}
 
+	before := measure
+
 	if inp.Name != nil {
 		measure.Name = *inp.Name
 	}
@@ -53,11 +71,61 @@ func (ums *UnitOfMeasureService) Update(ctx context.Context, inp domain.UpdateUn
 		measure.Description = *inp.Description
 	}
 
-	return ums.repo.UnitOfMeasure.Update(ctx, measure)
+	if inp.BaseUnitID != nil {
+		measure.BaseUnitID = *inp.BaseUnitID
+	}
+
+	if inp.ConversionFactor != nil {
+		measure.ConversionFactor = *inp.ConversionFactor
+	}
+
+	// единица, от которой пересчитываются другие единицы, должна оставаться базовой
+	if measure.BaseUnitID != 0 {
+		derived, err := ums.repo.UnitOfMeasure.HasDerivedUnits(ctx, measure.ID)
+		if err != nil {
+			return err
+		}
+
+		if derived {
+			return domain.ErrUnitOfMeasureInUse
+		}
+	}
+
+	if measure, err = ums.prepareBaseUnit(ctx, measure); err != nil {
+		return err
+	}
+
+	if err = ums.repo.UnitOfMeasure.Update(ctx, measure); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, ums.repo, info, before.CompanyID, domain.AuditEntityUnitOfMeasure, measure.ID, domain.AuditActionUpdate, before, measure)
+
+	return nil
 }
 
-func (ums *UnitOfMeasureService) Delete(ctx context.Context, id, companyId int64) error {
-	return ums.repo.UnitOfMeasure.Delete(ctx, id, companyId)
+func (ums *UnitOfMeasureService) Delete(ctx context.Context, id int64, info domain.JWTInfo) error {
+	measure, err := ums.repo.UnitOfMeasure.GetById(ctx, id, info.CompanyId)
+	if err != nil {
+		return err
+	}
+
+	derived, err := ums.repo.UnitOfMeasure.HasDerivedUnits(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if derived {
+		return domain.ErrUnitOfMeasureInUse
+	}
+
+	if err = ums.repo.UnitOfMeasure.Delete(ctx, id, info.CompanyId); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, ums.repo, info, measure.CompanyID, domain.AuditEntityUnitOfMeasure, id, domain.AuditActionDelete, measure, nil)
+
+	return nil
 }
 
 func (ums *UnitOfMeasureService) GetById(ctx context.Context, id, companyId int64) (domain.UnitOfMeasure, error) {
@@ -67,3 +135,97 @@ func (ums *UnitOfMeasureService) GetById(ctx context.Context, id, companyId int6
 func (ums *UnitOfMeasureService) List(ctx context.Context, param domain.Param) ([]domain.UnitOfMeasure, int64, error) {
 	return ums.repo.UnitOfMeasure.List(ctx, param)
 }
+
+// Convert пересчитывает количество из одной единицы измерения компании в другую через их общую базовую единицу
+func (ums *UnitOfMeasureService) Convert(ctx context.Context, quantity float64, fromUnitId, toUnitId, companyId int64) (domain.UnitConversion, error) {
+	from, err := ums.repo.UnitOfMeasure.GetById(ctx, fromUnitId, companyId)
+	if err != nil {
+		return domain.UnitConversion{}, err
+	}
+
+	to, err := ums.repo.UnitOfMeasure.GetById(ctx, toUnitId, companyId)
+	if err != nil {
+		return domain.UnitConversion{}, err
+	}
+
+	result, err := convertUnits(quantity, from, to)
+	if err != nil {
+		return domain.UnitConversion{}, err
+	}
+
+	return domain.UnitConversion{
+		Quantity:   quantity,
+		FromUnitID: from.ID,
+		ToUnitID:   to.ID,
+		Result:     result,
+		BaseUnitID: baseUnitId(from),
+	}, nil
+}
+
+// prepareBaseUnit проверяет базовую единицу измерения и коэффициент пересчета.
+// Базовая единица должна принадлежать той же компании и сама быть базовой, у базовой единицы коэффициент равен 1
+func (ums *UnitOfMeasureService) prepareBaseUnit(ctx context.Context, measure domain.UnitOfMeasure) (domain.UnitOfMeasure, error) {
+	if measure.BaseUnitID == 0 {
+		measure.ConversionFactor = 1
+		return measure, nil
+	}
+
+	if measure.BaseUnitID == measure.ID {
+		return domain.UnitOfMeasure{}, domain.ErrInvalidBaseUnit
+	}
+
+	if measure.ConversionFactor <= 0 {
+		return domain.UnitOfMeasure{}, domain.ErrInvalidQuantity
+	}
+
+	base, err := ums.repo.UnitOfMeasure.GetById(ctx, measure.BaseUnitID, measure.CompanyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnitOfMeasureNotFound) {
+			return domain.UnitOfMeasure{}, domain.ErrInvalidBaseUnit
+		}
+
+		return domain.UnitOfMeasure{}, err
+	}
+
+	if base.BaseUnitID != 0 {
+		return domain.UnitOfMeasure{}, domain.ErrInvalidBaseUnit
+	}
+
+	return measure, nil
+}
+
+// baseUnitId возвращает базовую единицу измерения, для базовой единицы - ее саму
+func baseUnitId(unit domain.UnitOfMeasure) int64 {
+	if unit.BaseUnitID != 0 {
+		return unit.BaseUnitID
+	}
+
+	return unit.ID
+}
+
+// unitFactor возвращает количество базовых единиц в одной единице измерения
+func unitFactor(unit domain.UnitOfMeasure) float64 {
+	if unit.BaseUnitID == 0 || unit.ConversionFactor <= 0 {
+		return 1
+	}
+
+	return unit.ConversionFactor
+}
+
+// convertUnits пересчитывает количество между единицами измерения с общей базовой единицей
+func convertUnits(quantity float64, from, to domain.UnitOfMeasure) (float64, error) {
+	if from.ID == to.ID {
+		return quantity, nil
+	}
+
+	if baseUnitId(from) != baseUnitId(to) {
+		return 0, domain.ErrUnitsNotConvertible
+	}
+
+	return roundQuantity(quantity * unitFactor(from) / unitFactor(to)), nil
+}
+
+// roundQuantity округляет количество до точности коэффициента пересчета, убирая погрешность деления
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1e6) / 1e6
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"time"
+)
+
+type Notifications interface {
+	GetList(ctx context.Context, info domain.JWTInfo, unreadOnly bool, params domain.Param) ([]domain.Notification, int64, error)
+	MarkAsRead(ctx context.Context, info domain.JWTInfo, id int64) error
+	MarkAllAsRead(ctx context.Context, info domain.JWTInfo) error
+	CheckLowStock(ctx context.Context) error
+}
+
+type NotificationsService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewNotificationsService(cfg *config.Config, repo *repository.Repository) *NotificationsService {
+	return &NotificationsService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *NotificationsService) GetList(ctx context.Context, info domain.JWTInfo, unreadOnly bool, params domain.Param) ([]domain.Notification, int64, error) {
+	return s.repo.Notifications.GetListByUserId(ctx, info.UserId, unreadOnly, params)
+}
+
+func (s *NotificationsService) MarkAsRead(ctx context.Context, info domain.JWTInfo, id int64) error {
+	return s.repo.Notifications.MarkAsRead(ctx, id, info.UserId)
+}
+
+func (s *NotificationsService) MarkAllAsRead(ctx context.Context, info domain.JWTInfo) error {
+	return s.repo.Notifications.MarkAllAsRead(ctx, info.UserId)
+}
+
+// CheckLowStock создает уведомления о товарах с остатком ниже минимального уровня запаса для ответственного
+// за склад и пользователей компании, подписанных на системные уведомления
+func (s *NotificationsService) CheckLowStock(ctx context.Context) error {
+	materials, err := s.repo.Materials.GetLowStock(ctx)
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().UTC().Add(-s.cfg.Workers.LowStockRepeat)
+	recipientsByCompany := make(map[int64][]int64)
+
+	var created int64
+	for _, m := range materials {
+		recipients, ok := recipientsByCompany[m.CompanyID]
+		if !ok {
+			if recipients, err = s.repo.Notifications.GetSystemRecipients(ctx, m.CompanyID); err != nil {
+				return err
+			}
+
+			recipientsByCompany[m.CompanyID] = recipients
+		}
+
+		for _, userId := range uniqueIds(append([]int64{m.ResponsiblePerson}, recipients...)) {
+			id, err := s.repo.Notifications.CreateIfNotRecent(ctx, domain.Notification{
+				UserID:      userId,
+				CompanyID:   m.CompanyID,
+				Type:        domain.NotificationLowStock,
+				Title:       "Низкий остаток товара",
+				Message:     lowStockMessage(m),
+				WarehouseID: m.WarehouseID,
+				ItemID:      m.ItemID,
+				DedupKey:    fmt.Sprintf("%s:%d:%d", domain.NotificationLowStock, m.WarehouseID, m.ItemID),
+			}, since)
+			if err != nil {
+				return err
+			}
+
+			if id != 0 {
+				created++
+			}
+		}
+	}
+
+	if created > 0 {
+		logger.Info(fmt.Sprintf("created %d low stock notifications", created))
+	}
+
+	return nil
+}
+
+func lowStockMessage(m domain.LowStockMaterial) string {
+	return fmt.Sprintf("Остаток «%s» на складе «%s»: %g %s при минимальном уровне запаса %g",
+		m.Name, m.WarehouseName, m.TotalQuantity, m.Unit, m.MinStockLevel)
+}
+
+// uniqueIds возвращает ненулевые id без повторов, сохраняя порядок
+func uniqueIds(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		result = append(result, id)
+	}
+
+	return result
+}
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/tools"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"time"
+)
+
+type PurchaseOrders interface {
+	Create(ctx context.Context, info domain.JWTInfo, inp domain.CreatePurchaseOrder) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.PurchaseOrder, error)
+	GetList(ctx context.Context, info domain.JWTInfo, params domain.PurchaseOrderParams) ([]domain.PurchaseOrder, int64, error)
+	Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdatePurchaseOrder) error
+	Send(ctx context.Context, info domain.JWTInfo, id int64) error
+	Cancel(ctx context.Context, info domain.JWTInfo, id int64) error
+	Receive(ctx context.Context, info domain.JWTInfo, id int64, inp domain.ReceivePurchaseOrder) ([]int64, error)
+	GeneratePdf(ctx context.Context, info domain.JWTInfo, id int64) (*gofpdf.Fpdf, error)
+}
+
+type PurchaseOrdersService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewPurchaseOrdersService(cfg *config.Config, repo *repository.Repository) *PurchaseOrdersService {
+	return &PurchaseOrdersService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+// Create создает черновик заказа поставщику. Строки заказа формируются из оставшегося количества и цены
+// планируемых материалов
+func (s *PurchaseOrdersService) Create(ctx context.Context, info domain.JWTInfo, inp domain.CreatePurchaseOrder) (int64, error) {
+	if !domain.IsValidVATRate(inp.VATRate) {
+		return 0, domain.ErrInvalidVATRate
+	}
+
+	supplier, err := s.supplier(ctx, info, inp.SupplierID)
+	if err != nil {
+		return 0, err
+	}
+
+	lines, currency, err := s.buildLines(ctx, supplier, inp.PlanningMaterialIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+
+	order := domain.PurchaseOrder{
+		SupplierID:           supplier.ID,
+		CompanyID:            supplier.CompanyId,
+		ContractNumber:       inp.ContractNumber,
+		ContractDate:         inp.ContractDate,
+		ExpectedDeliveryDate: inp.ExpectedDeliveryDate,
+		Status:               domain.PurchaseOrderDraft,
+		VATRate:              inp.VATRate,
+		Currency:             currency,
+		Comment:              inp.Comment,
+		CreatedBy:            info.UserId,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		Lines:                lines,
+	}
+
+	id, err := s.repo.PurchaseOrders.Create(ctx, order)
+	if err != nil {
+		return 0, err
+	}
+
+	order.ID = id
+	recordAudit(ctx, s.repo, info, order.CompanyID, domain.AuditEntityPurchaseOrder, id, domain.AuditActionCreate, nil, order)
+
+	return id, nil
+}
+
+func (s *PurchaseOrdersService) GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.PurchaseOrder, error) {
+	order, err := s.repo.PurchaseOrders.GetById(ctx, id)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	if order.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.PurchaseOrder{}, domain.ErrNotAllowed
+	}
+
+	return withPurchaseOrderTotals(order), nil
+}
+
+func (s *PurchaseOrdersService) GetList(ctx context.Context, info domain.JWTInfo, params domain.PurchaseOrderParams) ([]domain.PurchaseOrder, int64, error) {
+	params.CompanyId = info.CompanyId
+
+	orders, count, err := s.repo.PurchaseOrders.GetList(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range orders {
+		orders[i] = withPurchaseOrderTotals(orders[i])
+	}
+
+	return orders, count, nil
+}
+
+// Update изменяет черновик заказа. При передаче планируемых материалов строки заказа формируются заново
+func (s *PurchaseOrdersService) Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdatePurchaseOrder) error {
+	order, err := s.GetById(ctx, info, inp.ID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != domain.PurchaseOrderDraft {
+		return domain.ErrPurchaseOrderStatus
+	}
+
+	before := order
+
+	if inp.SupplierID != nil {
+		order.SupplierID = *inp.SupplierID
+	}
+
+	if inp.ContractNumber != nil {
+		order.ContractNumber = *inp.ContractNumber
+	}
+
+	if inp.ContractDate != nil {
+		order.ContractDate = inp.ContractDate
+	}
+
+	if inp.ExpectedDeliveryDate != nil {
+		order.ExpectedDeliveryDate = inp.ExpectedDeliveryDate
+	}
+
+	if inp.VATRate != nil {
+		if !domain.IsValidVATRate(*inp.VATRate) {
+			return domain.ErrInvalidVATRate
+		}
+
+		order.VATRate = *inp.VATRate
+	}
+
+	if inp.Comment != nil {
+		order.Comment = *inp.Comment
+	}
+
+	supplier, err := s.supplier(ctx, info, order.SupplierID)
+	if err != nil {
+		return err
+	}
+
+	planningIds := make([]int64, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		planningIds = append(planningIds, line.PlanningMaterialID)
+	}
+
+	// строки перестраиваются при замене материалов и при смене поставщика, чтобы проверить материалы заново
+	replaceLines := inp.PlanningMaterialIDs != nil || order.SupplierID != before.SupplierID
+	if inp.PlanningMaterialIDs != nil {
+		planningIds = *inp.PlanningMaterialIDs
+	}
+
+	order.Lines = nil
+	if replaceLines {
+		lines, currency, err := s.buildLines(ctx, supplier, planningIds)
+		if err != nil {
+			return err
+		}
+
+		order.Lines = lines
+
+		if currency != "" {
+			order.Currency = currency
+		}
+	}
+
+	order.UpdatedAt = time.Now().UTC()
+
+	if err = s.repo.PurchaseOrders.Update(ctx, order); err != nil {
+		return err
+	}
+
+	after, err := s.repo.PurchaseOrders.GetById(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyID, domain.AuditEntityPurchaseOrder, order.ID, domain.AuditActionUpdate, before,
+		withPurchaseOrderTotals(after))
+
+	return nil
+}
+
+// Send отмечает черновик заказа отправленным поставщику. После отправки строки заказа не изменяются
+func (s *PurchaseOrdersService) Send(ctx context.Context, info domain.JWTInfo, id int64) error {
+	return s.setStatus(ctx, info, id, domain.AuditActionSend, domain.PurchaseOrderSent, domain.PurchaseOrderDraft)
+}
+
+// Cancel отменяет заказ, по которому еще ничего не принято
+func (s *PurchaseOrdersService) Cancel(ctx context.Context, info domain.JWTInfo, id int64) error {
+	return s.setStatus(ctx, info, id, domain.AuditActionCancel, domain.PurchaseOrderCancelled,
+		domain.PurchaseOrderDraft, domain.PurchaseOrderSent)
+}
+
+func (s *PurchaseOrdersService) setStatus(ctx context.Context, info domain.JWTInfo, id int64, action, to string, from ...string) error {
+	order, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	// поставщику нельзя отправить заказ без строк
+	if to == domain.PurchaseOrderSent && len(order.Lines) == 0 {
+		return domain.ErrPurchaseOrderEmpty
+	}
+
+	if err = s.repo.PurchaseOrders.SetStatus(ctx, id, from, to); err != nil {
+		return err
+	}
+
+	after := order
+	after.Status = to
+
+	recordAudit(ctx, s.repo, info, order.CompanyID, domain.AuditEntityPurchaseOrder, id, action, order, after)
+
+	return nil
+}
+
+// Receive принимает строки отправленного заказа на склад через приемку планируемых материалов. Принятое
+// количество переносится в закупленные материалы, заказ становится частично или полностью принятым.
+// Возвращает ID созданных закупленных материалов
+func (s *PurchaseOrdersService) Receive(ctx context.Context, info domain.JWTInfo, id int64, inp domain.ReceivePurchaseOrder) ([]int64, error) {
+	order, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != domain.PurchaseOrderSent && order.Status != domain.PurchaseOrderPartiallyReceived {
+		return nil, domain.ErrPurchaseOrderStatus
+	}
+
+	if len(order.Lines) == 0 {
+		return nil, domain.ErrPurchaseOrderEmpty
+	}
+
+	lines := make(map[int64]domain.PurchaseOrderLine, len(order.Lines))
+	for _, line := range order.Lines {
+		lines[line.ID] = line
+	}
+
+	receipts := inp.Lines
+	if len(receipts) == 0 {
+		for _, line := range order.Lines {
+			if line.Quantity > line.ReceivedQuantity {
+				receipts = append(receipts, domain.ReceivePurchaseOrderLine{LineID: line.ID})
+			}
+		}
+	}
+
+	capacity := newCapacityPlan()
+
+	for _, receipt := range receipts {
+		line, ok := lines[receipt.LineID]
+		if !ok {
+			return nil, domain.ErrPurchaseOrderLineNotFound
+		}
+
+		if receipt.Quantity < 0 || (receipt.PriceWithoutVAT != nil && receipt.PriceWithoutVAT.IsNegative()) {
+			return nil, domain.ErrInvalidQuantity
+		}
+
+		quantity := receipt.Quantity
+		if quantity == 0 {
+			quantity = line.Quantity - line.ReceivedQuantity
+		}
+
+		material, err := s.repo.Materials.GetPlanningById(ctx, line.PlanningMaterialID)
+		if err != nil {
+			return nil, err
+		}
+
+		if receipt.LocationID != nil && *receipt.LocationID != 0 {
+			location, err := s.repo.WarehouseLocations.GetById(ctx, *receipt.LocationID)
+			if err != nil {
+				return nil, err
+			}
+
+			if location.WarehouseID != material.WarehouseID {
+				return nil, domain.ErrLocationWarehouse
+			}
+		}
+
+		if err = capacity.check(ctx, s.repo, material.WarehouseID, material.Volume*quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err := s.repo.PurchaseOrders.Receive(ctx, id, inp, info.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity.notify(ctx, s.repo, info)
+
+	after, err := s.repo.PurchaseOrders.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.repo, info, order.CompanyID, domain.AuditEntityPurchaseOrder, id, domain.AuditActionReceive, order,
+		withPurchaseOrderTotals(after))
+
+	return ids, nil
+}
+
+// GeneratePdf формирует документ заказа поставщику с суммами без НДС и с НДС
+func (s *PurchaseOrdersService) GeneratePdf(ctx context.Context, info domain.JWTInfo, id int64) (*gofpdf.Fpdf, error) {
+	order, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier, err := s.repo.Suppliers.GetById(ctx, order.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8Font("Arial", "", pdfFontPath)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "", 16)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Заказ поставщику №%d от %s", order.ID, order.CreatedAt.Format("02.01.2006")),
+		"", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	contract := order.ContractNumber
+	if order.ContractDate != nil {
+		contract = fmt.Sprintf("%s от %s", contract, order.ContractDate.Format("02.01.2006"))
+	}
+
+	var expected string
+	if order.ExpectedDeliveryDate != nil {
+		expected = order.ExpectedDeliveryDate.Format("02.01.2006")
+	}
+
+	pdf.SetFont("Arial", "", 11)
+	fields := []struct {
+		Label string
+		Value string
+	}{
+		{"Поставщик", supplier.Name},
+		{"ИНН", supplier.TaxID},
+		{"Адрес", supplier.LegalAddress},
+		{"Договор", contract},
+		{"Дата поставки", expected},
+		{"Комментарий", order.Comment},
+	}
+
+	for _, field := range fields {
+		pdf.CellFormat(35, 7, field.Label+":", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, fitPdfText(pdf, field.Value, 153), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	columns := []struct {
+		Title string
+		Width float64
+	}{
+		{"№", 10}, {"Наименование", 62}, {"Артикул", 28}, {"Ед.", 14}, {"Кол-во", 20}, {"Цена без НДС", 28},
+		{"Сумма без НДС", 28},
+	}
+
+	pdf.SetFont("Arial", "", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range columns {
+		pdf.CellFormat(column.Width, 7, column.Title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for i, l := range order.Lines {
+		values := []string{
+			strconv.Itoa(i + 1), l.Name, l.Article, l.Unit, strconv.FormatInt(l.Quantity, 10),
+			formatMoney(l.PriceWithoutVAT), formatMoney(l.TotalWithoutVAT),
+		}
+
+		for j, column := range columns {
+			align := "L"
+			if j == 0 || j >= 4 {
+				align = "R"
+			}
+
+			pdf.CellFormat(column.Width, 6, fitPdfText(pdf, values[j], column.Width-2), "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.Ln(2)
+	pdf.SetFont("Arial", "", 11)
+	totals := []struct {
+		Label string
+		Value decimal.Decimal
+	}{
+		{"Итого без НДС", order.TotalWithoutVAT},
+		{fmt.Sprintf("НДС %s%%", order.VATRate.String()), order.VATAmount},
+		{"Всего с НДС", order.TotalWithVAT},
+	}
+
+	for _, total := range totals {
+		pdf.CellFormat(152, 7, total.Label+":", "", 0, "R", false, 0, "")
+		pdf.CellFormat(38, 7, formatMoney(total.Value)+" "+order.Currency, "", 1, "R", false, 0, "")
+	}
+
+	return pdf, pdf.Error()
+}
+
+// buildLines формирует строки заказа из планируемых материалов поставщика и возвращает их общую валюту. Материал
+// с указанным поставщиком может войти только в заказ этого поставщика
+func (s *PurchaseOrdersService) buildLines(ctx context.Context, supplier domain.Supplier, planningIds []int64) ([]domain.PurchaseOrderLine, string, error) {
+	if len(planningIds) == 0 {
+		return nil, "", domain.ErrPurchaseOrderEmpty
+	}
+
+	lines := make([]domain.PurchaseOrderLine, 0, len(planningIds))
+	var currency string
+
+	for _, planningId := range uniqueIds(planningIds) {
+		material, err := s.repo.Materials.GetPlanningById(ctx, planningId)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if material.CompanyID != supplier.CompanyId {
+			return nil, "", domain.ErrNotAllowed
+		}
+
+		if material.SupplierID != 0 && material.SupplierID != supplier.ID {
+			return nil, "", domain.ErrPurchaseOrderSupplier
+		}
+
+		if material.TotalQuantity <= 0 {
+			return nil, "", domain.ErrInvalidQuantity
+		}
+
+		if currency != "" && material.Currency != currency {
+			return nil, "", domain.ErrPurchaseOrderCurrency
+		}
+
+		currency = material.Currency
+
+		lines = append(lines, domain.PurchaseOrderLine{
+			PlanningMaterialID: material.ID,
+			ItemID:             material.ItemID,
+			Name:               material.Name,
+			Article:            material.Article,
+			Unit:               material.Unit,
+			Quantity:           material.TotalQuantity,
+			PriceWithoutVAT:    material.PriceWithoutVAT,
+			TotalWithoutVAT:    domain.LineTotal(material.PriceWithoutVAT, material.TotalQuantity),
+		})
+	}
+
+	return lines, currency, nil
+}
+
+func (s *PurchaseOrdersService) supplier(ctx context.Context, info domain.JWTInfo, id int64) (domain.Supplier, error) {
+	supplier, err := s.repo.Suppliers.GetById(ctx, id)
+	if err != nil {
+		return domain.Supplier{}, err
+	}
+
+	if supplier.CompanyId != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.Supplier{}, domain.ErrNotAllowed
+	}
+
+	return supplier, nil
+}
+
+// withPurchaseOrderTotals рассчитывает НДС и сумму заказа с НДС по сумме строк без НДС. Суммы округляются
+// до копеек
+func withPurchaseOrderTotals(order domain.PurchaseOrder) domain.PurchaseOrder {
+	order.VATAmount = domain.VATAmount(order.TotalWithoutVAT, order.VATRate)
+	order.TotalWithVAT = domain.WithVAT(order.TotalWithoutVAT, order.VATRate)
+	order.TotalWithoutVAT = order.TotalWithoutVAT.Round(domain.MoneyPlaces)
+
+	return order
+}
+
+func formatMoney(value decimal.Decimal) string {
+	return value.StringFixed(domain.MoneyPlaces)
+}
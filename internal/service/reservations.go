@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"github.com/rusystem/crm-api/tools"
+	"time"
+)
+
+type Reservations interface {
+	Create(ctx context.Context, info domain.JWTInfo, purchasedId int64, inp domain.CreateReservation) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.Reservation, error)
+	GetListByPurchasedId(ctx context.Context, info domain.JWTInfo, purchasedId int64, params domain.Param) ([]domain.Reservation, int64, error)
+	Release(ctx context.Context, info domain.JWTInfo, id int64) error
+	Consume(ctx context.Context, info domain.JWTInfo, id int64) error
+	ReleaseExpired(ctx context.Context) error
+}
+
+type ReservationsService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewReservationsService(cfg *config.Config, repo *repository.Repository) *ReservationsService {
+	return &ReservationsService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *ReservationsService) Create(ctx context.Context, info domain.JWTInfo, purchasedId int64, inp domain.CreateReservation) (int64, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, purchasedId)
+	if err != nil {
+		return 0, err
+	}
+
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return 0, domain.ErrNotAllowed
+	}
+
+	if inp.Quantity <= 0 {
+		return 0, domain.ErrInvalidQuantity
+	}
+
+	if inp.OrderReference == "" && inp.ReservedFor == 0 {
+		return 0, domain.ErrReservationTarget
+	}
+
+	if inp.ReservedFor != 0 {
+		user, err := s.repo.User.GetById(ctx, inp.ReservedFor)
+		if err != nil {
+			return 0, err
+		}
+
+		if user.CompanyID != material.CompanyID {
+			return 0, domain.ErrNotAllowed
+		}
+	}
+
+	if inp.ExpiresAt != nil && !inp.ExpiresAt.After(time.Now()) {
+		return 0, domain.ErrReservationNotActive
+	}
+
+	return s.repo.Reservations.Create(ctx, domain.Reservation{
+		PurchasedMaterialID: purchasedId,
+		Quantity:            inp.Quantity,
+		OrderReference:      inp.OrderReference,
+		ReservedFor:         inp.ReservedFor,
+		ExpiresAt:           inp.ExpiresAt,
+		Comment:             inp.Comment,
+		CreatedBy:           info.UserId,
+	})
+}
+
+func (s *ReservationsService) GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.Reservation, error) {
+	reservation, err := s.repo.Reservations.GetById(ctx, id)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+
+	if reservation.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.Reservation{}, domain.ErrNotAllowed
+	}
+
+	return reservation, nil
+}
+
+func (s *ReservationsService) GetListByPurchasedId(ctx context.Context, info domain.JWTInfo, purchasedId int64, params domain.Param) ([]domain.Reservation, int64, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, purchasedId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return nil, 0, domain.ErrNotAllowed
+	}
+
+	params.CompanyId = material.CompanyID
+
+	return s.repo.Reservations.GetListByPurchasedId(ctx, purchasedId, params)
+}
+
+func (s *ReservationsService) Release(ctx context.Context, info domain.JWTInfo, id int64) error {
+	if _, err := s.GetById(ctx, info, id); err != nil {
+		return err
+	}
+
+	return s.repo.Reservations.Release(ctx, id)
+}
+
+func (s *ReservationsService) Consume(ctx context.Context, info domain.JWTInfo, id int64) error {
+	if _, err := s.GetById(ctx, info, id); err != nil {
+		return err
+	}
+
+	return s.repo.Reservations.Consume(ctx, id, info.UserId)
+}
+
+func (s *ReservationsService) ReleaseExpired(ctx context.Context) error {
+	count, err := s.repo.Reservations.ReleaseExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		logger.Info(fmt.Sprintf("released %d expired reservations", count))
+	}
+
+	return nil
+}
@@ -15,29 +15,49 @@ type Config struct {
 }
 
 type Service struct {
-	Auth          Auth
-	Supplier      Supplier
-	Warehouse     Warehouse
-	User          User
-	Company       Company
-	Sections      Sections
-	Materials     Materials
-	Category      Category
-	Geo           Geo
-	UnitOfMeasure UnitOfMeasure
+	Auth               Auth
+	Supplier           Supplier
+	Warehouse          Warehouse
+	User               User
+	Company            Company
+	Sections           Sections
+	Materials          Materials
+	Category           Category
+	Geo                Geo
+	UnitOfMeasure      UnitOfMeasure
+	StockMovements     StockMovements
+	Reservations       Reservations
+	Notifications      Notifications
+	Audit              Audit
+	Items              Items
+	WarehouseLocations WarehouseLocations
+	Stocktakes         Stocktakes
+	GoodsIssues        GoodsIssues
+	PurchaseOrders     PurchaseOrders
+	ExchangeRates      ExchangeRates
 }
 
 func New(cfg Config, gc *geonames.Client, cache *cache.MemoryCache) *Service {
 	return &Service{
-		Auth:          NewAuthServices(cfg.Config, cfg.Repo, cfg.TokenManager),
-		Supplier:      NewSupplierService(cfg.Config, cfg.Repo),
-		Warehouse:     NewWarehouseServices(cfg.Config, cfg.Repo),
-		User:          NewUserServices(cfg.Config, cfg.Repo),
-		Company:       NewCompanyService(cfg.Config, cfg.Repo),
-		Sections:      NewSectionsService(cfg.Config, cfg.Repo),
-		Materials:     NewMaterialsService(cfg.Config, cfg.Repo),
-		Category:      NewMaterialCategoriesService(cfg.Config, cfg.Repo),
-		Geo:           NewGeoService(cfg.Config, gc, cache),
-		UnitOfMeasure: NewUnitOfMeasureService(cfg.Config, cfg.Repo),
+		Auth:               NewAuthServices(cfg.Config, cfg.Repo, cfg.TokenManager),
+		Supplier:           NewSupplierService(cfg.Config, cfg.Repo),
+		Warehouse:          NewWarehouseServices(cfg.Config, cfg.Repo),
+		User:               NewUserServices(cfg.Config, cfg.Repo),
+		Company:            NewCompanyService(cfg.Config, cfg.Repo),
+		Sections:           NewSectionsService(cfg.Config, cfg.Repo),
+		Materials:          NewMaterialsService(cfg.Config, cfg.Repo),
+		Category:           NewMaterialCategoriesService(cfg.Config, cfg.Repo),
+		Geo:                NewGeoService(cfg.Config, gc, cache),
+		UnitOfMeasure:      NewUnitOfMeasureService(cfg.Config, cfg.Repo),
+		StockMovements:     NewStockMovementsService(cfg.Config, cfg.Repo),
+		Reservations:       NewReservationsService(cfg.Config, cfg.Repo),
+		Notifications:      NewNotificationsService(cfg.Config, cfg.Repo),
+		Audit:              NewAuditService(cfg.Config, cfg.Repo),
+		Items:              NewItemsService(cfg.Config, cfg.Repo),
+		WarehouseLocations: NewWarehouseLocationsService(cfg.Config, cfg.Repo),
+		Stocktakes:         NewStocktakesService(cfg.Config, cfg.Repo),
+		GoodsIssues:        NewGoodsIssuesService(cfg.Config, cfg.Repo),
+		PurchaseOrders:     NewPurchaseOrdersService(cfg.Config, cfg.Repo),
+		ExchangeRates:      NewExchangeRatesService(cfg.Config, cfg.Repo),
 	}
 }
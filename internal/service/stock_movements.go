@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/tools"
+)
+
+type StockMovements interface {
+	Create(ctx context.Context, info domain.JWTInfo, purchasedId int64, inp domain.CreateStockMovement) (int64, error)
+	GetListByPurchasedId(ctx context.Context, info domain.JWTInfo, purchasedId int64, params domain.Param) ([]domain.StockMovement, int64, error)
+	Reconcile(ctx context.Context, info domain.JWTInfo, purchasedId int64) (domain.StockReconciliation, error)
+}
+
+type StockMovementsService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewStockMovementsService(cfg *config.Config, repo *repository.Repository) *StockMovementsService {
+	return &StockMovementsService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *StockMovementsService) Create(ctx context.Context, info domain.JWTInfo, purchasedId int64, inp domain.CreateStockMovement) (int64, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, purchasedId)
+	if err != nil {
+		return 0, err
+	}
+
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return 0, domain.ErrNotAllowed
+	}
+
+	quantity, err := movementQuantity(inp.MovementType, inp.Quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	overCapacity, err := checkWarehouseCapacity(ctx, s.repo, material.WarehouseID, material.Volume*quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.StockMovements.Create(ctx, domain.StockMovement{
+		PurchasedMaterialID: purchasedId,
+		MovementType:        inp.MovementType,
+		Quantity:            quantity,
+		Reference:           inp.Reference,
+		Comment:             inp.Comment,
+		UserID:              info.UserId,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if overCapacity {
+		notifyOverCapacity(ctx, s.repo, info, material.WarehouseID)
+	}
+
+	return id, nil
+}
+
+func (s *StockMovementsService) GetListByPurchasedId(ctx context.Context, info domain.JWTInfo, purchasedId int64, params domain.Param) ([]domain.StockMovement, int64, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, purchasedId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return nil, 0, domain.ErrNotAllowed
+	}
+
+	params.CompanyId = material.CompanyID
+
+	return s.repo.StockMovements.GetListByPurchasedId(ctx, purchasedId, params)
+}
+
+func (s *StockMovementsService) Reconcile(ctx context.Context, info domain.JWTInfo, purchasedId int64) (domain.StockReconciliation, error) {
+	material, err := s.repo.Materials.GetPurchasedById(ctx, purchasedId)
+	if err != nil {
+		return domain.StockReconciliation{}, err
+	}
+
+	if material.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.StockReconciliation{}, domain.ErrNotAllowed
+	}
+
+	balance, err := s.repo.StockMovements.GetLedgerBalance(ctx, purchasedId)
+	if err != nil {
+		return domain.StockReconciliation{}, err
+	}
+
+	return domain.StockReconciliation{
+		PurchasedMaterialID: purchasedId,
+		TotalQuantity:       material.TotalQuantity,
+		LedgerQuantity:      balance,
+		Difference:          material.TotalQuantity - balance,
+	}, nil
+}
+
+// movementQuantity возвращает изменение остатка со знаком в зависимости от типа движения
+func movementQuantity(movementType string, quantity int64) (int64, error) {
+	switch movementType {
+	case domain.MovementReceipt:
+		if quantity <= 0 {
+			return 0, domain.ErrInvalidQuantity
+		}
+
+		return quantity, nil
+	case domain.MovementIssue, domain.MovementWriteOff:
+		if quantity <= 0 {
+			return 0, domain.ErrInvalidQuantity
+		}
+
+		return -quantity, nil
+	case domain.MovementAdjustment:
+		if quantity == 0 {
+			return 0, domain.ErrInvalidQuantity
+		}
+
+		return quantity, nil
+	default:
+		// перемещения создаются только операцией перемещения между складами
+		return 0, domain.ErrInvalidMovementType
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"testing"
+)
+
+func TestMovementQuantity(t *testing.T) {
+	tests := []struct {
+		name         string
+		movementType string
+		quantity     int64
+		want         int64
+		wantErr      error
+	}{
+		{"receipt", domain.MovementReceipt, 5, 5, nil},
+		{"receipt zero", domain.MovementReceipt, 0, 0, domain.ErrInvalidQuantity},
+		{"receipt negative", domain.MovementReceipt, -5, 0, domain.ErrInvalidQuantity},
+		{"issue", domain.MovementIssue, 5, -5, nil},
+		{"issue negative", domain.MovementIssue, -5, 0, domain.ErrInvalidQuantity},
+		{"write off", domain.MovementWriteOff, 3, -3, nil},
+		{"write off zero", domain.MovementWriteOff, 0, 0, domain.ErrInvalidQuantity},
+		{"adjustment up", domain.MovementAdjustment, 4, 4, nil},
+		{"adjustment down", domain.MovementAdjustment, -4, -4, nil},
+		{"adjustment zero", domain.MovementAdjustment, 0, 0, domain.ErrInvalidQuantity},
+		{"transfer", domain.MovementTransfer, 5, 0, domain.ErrInvalidMovementType},
+		{"unknown", "unknown", 5, 0, domain.ErrInvalidMovementType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := movementQuantity(tt.movementType, tt.quantity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("movementQuantity() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("movementQuantity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/tools"
+	"sort"
+	"time"
+)
+
+type Stocktakes interface {
+	Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateStocktake) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.Stocktake, error)
+	GetList(ctx context.Context, info domain.JWTInfo, params domain.StocktakeParams) ([]domain.Stocktake, int64, error)
+	GetLines(ctx context.Context, info domain.JWTInfo, id int64) ([]domain.StocktakeLine, error)
+	SubmitCounts(ctx context.Context, info domain.JWTInfo, id int64, inp domain.SubmitStocktakeCounts) error
+	Variance(ctx context.Context, info domain.JWTInfo, id int64) (domain.StocktakeVarianceReport, error)
+	Approve(ctx context.Context, info domain.JWTInfo, id int64) error
+	Cancel(ctx context.Context, info domain.JWTInfo, id int64) error
+}
+
+type StocktakesService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewStocktakesService(cfg *config.Config, repo *repository.Repository) *StocktakesService {
+	return &StocktakesService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+// Create открывает инвентаризацию склада. На складе может быть открыта только одна инвентаризация
+func (s *StocktakesService) Create(ctx context.Context, info domain.JWTInfo, inp domain.CreateStocktake) (int64, error) {
+	wh, err := s.repo.Warehouse.GetById(ctx, inp.WarehouseID)
+	if err != nil {
+		return 0, err
+	}
+
+	if wh.CompanyId != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return 0, domain.ErrNotAllowed
+	}
+
+	now := time.Now().UTC()
+
+	stocktake := domain.Stocktake{
+		WarehouseID: wh.ID,
+		CompanyID:   wh.CompanyId,
+		Status:      domain.StocktakeOpen,
+		Comment:     inp.Comment,
+		CreatedBy:   info.UserId,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	id, err := s.repo.Stocktakes.Create(ctx, stocktake)
+	if err != nil {
+		return 0, err
+	}
+
+	stocktake.ID = id
+	recordAudit(ctx, s.repo, info, stocktake.CompanyID, domain.AuditEntityStocktake, id, domain.AuditActionCreate, nil, stocktake)
+
+	return id, nil
+}
+
+func (s *StocktakesService) GetById(ctx context.Context, info domain.JWTInfo, id int64) (domain.Stocktake, error) {
+	stocktake, err := s.repo.Stocktakes.GetById(ctx, id)
+	if err != nil {
+		return domain.Stocktake{}, err
+	}
+
+	if stocktake.CompanyID != info.CompanyId && !tools.IsFullAccessSection(info.Sections) {
+		return domain.Stocktake{}, domain.ErrNotAllowed
+	}
+
+	return stocktake, nil
+}
+
+func (s *StocktakesService) GetList(ctx context.Context, info domain.JWTInfo, params domain.StocktakeParams) ([]domain.Stocktake, int64, error) {
+	params.CompanyId = info.CompanyId
+
+	return s.repo.Stocktakes.GetList(ctx, params)
+}
+
+func (s *StocktakesService) GetLines(ctx context.Context, info domain.JWTInfo, id int64) ([]domain.StocktakeLine, error) {
+	if _, err := s.GetById(ctx, info, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Stocktakes.GetLines(ctx, id)
+}
+
+// SubmitCounts вносит подсчеты в открытую инвентаризацию. Подсчет по QR-коду относится к партии из кода,
+// подсчет по item_id распределяется по партиям товара: каждая партия в порядке открытия заполняется до
+// ожидаемого количества, остаток относится к последней партии
+func (s *StocktakesService) SubmitCounts(ctx context.Context, info domain.JWTInfo, id int64, inp domain.SubmitStocktakeCounts) error {
+	stocktake, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	if stocktake.Status != domain.StocktakeOpen {
+		return domain.ErrStocktakeNotOpen
+	}
+
+	lines, err := s.repo.Stocktakes.GetLines(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ID < lines[j].ID })
+
+	now := time.Now().UTC()
+	counted := make(map[int64]domain.StocktakeLine)
+	var order []int64
+
+	for _, count := range inp.Counts {
+		targets, err := stocktakeCountTargets(lines, count)
+		if err != nil {
+			return err
+		}
+
+		remaining := count.Quantity
+		for i, line := range targets {
+			quantity := remaining
+			if i < len(targets)-1 && quantity > line.ExpectedQuantity {
+				quantity = line.ExpectedQuantity
+			}
+
+			remaining -= quantity
+
+			line.CountedQuantity = &quantity
+			line.CountedBy = info.UserId
+			line.CountedAt = &now
+			line.Comment = count.Comment
+
+			if _, ok := counted[line.ID]; !ok {
+				order = append(order, line.ID)
+			}
+
+			counted[line.ID] = line
+		}
+	}
+
+	result := make([]domain.StocktakeLine, 0, len(order))
+	for _, lineId := range order {
+		result = append(result, counted[lineId])
+	}
+
+	return s.repo.Stocktakes.SaveCounts(ctx, id, result)
+}
+
+// stocktakeCountTargets возвращает строки инвентаризации, к которым относится подсчет
+func stocktakeCountTargets(lines []domain.StocktakeLine, count domain.StocktakeCount) ([]domain.StocktakeLine, error) {
+	var targets []domain.StocktakeLine
+
+	switch {
+	case count.Code != nil:
+		for _, line := range lines {
+			if line.PurchasedMaterialID == count.Code.Id && (count.Code.ItemId == 0 || line.ItemID == count.Code.ItemId) {
+				targets = append(targets, line)
+				break
+			}
+		}
+	case count.ItemID != 0:
+		for _, line := range lines {
+			if line.ItemID == count.ItemID {
+				targets = append(targets, line)
+			}
+		}
+	default:
+		return nil, domain.ErrStocktakeCountTarget
+	}
+
+	if len(targets) == 0 {
+		return nil, domain.ErrStocktakeLineNotFound
+	}
+
+	return targets, nil
+}
+
+// Variance возвращает отчет о расхождениях: строки, посчитанное количество которых отличается от ожидаемого,
+// и непосчитанные строки
+func (s *StocktakesService) Variance(ctx context.Context, info domain.JWTInfo, id int64) (domain.StocktakeVarianceReport, error) {
+	stocktake, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return domain.StocktakeVarianceReport{}, err
+	}
+
+	lines, err := s.repo.Stocktakes.GetLines(ctx, id)
+	if err != nil {
+		return domain.StocktakeVarianceReport{}, err
+	}
+
+	report := domain.StocktakeVarianceReport{
+		Stocktake:  stocktake,
+		Lines:      make([]domain.StocktakeVariance, 0),
+		TotalLines: int64(len(lines)),
+	}
+
+	for _, line := range lines {
+		if line.CountedQuantity == nil {
+			report.UncountedLines++
+			report.Lines = append(report.Lines, domain.StocktakeVariance{StocktakeLine: line})
+			continue
+		}
+
+		report.CountedLines++
+
+		difference := *line.CountedQuantity - line.ExpectedQuantity
+		if difference == 0 {
+			continue
+		}
+
+		if difference < 0 {
+			report.Shortage -= difference
+		} else {
+			report.Surplus += difference
+		}
+
+		report.Lines = append(report.Lines, domain.StocktakeVariance{StocktakeLine: line, Difference: difference})
+	}
+
+	return report, nil
+}
+
+// Approve проводит расхождения инвентаризации корректировками остатков и закрывает ее
+func (s *StocktakesService) Approve(ctx context.Context, info domain.JWTInfo, id int64) error {
+	return s.close(ctx, info, id, domain.StocktakeApproved)
+}
+
+// Cancel закрывает инвентаризацию без изменения остатков
+func (s *StocktakesService) Cancel(ctx context.Context, info domain.JWTInfo, id int64) error {
+	return s.close(ctx, info, id, domain.StocktakeCancelled)
+}
+
+func (s *StocktakesService) close(ctx context.Context, info domain.JWTInfo, id int64, status string) error {
+	stocktake, err := s.GetById(ctx, info, id)
+	if err != nil {
+		return err
+	}
+
+	if stocktake.Status != domain.StocktakeOpen {
+		return domain.ErrStocktakeNotOpen
+	}
+
+	before := stocktake
+	now := time.Now().UTC()
+
+	stocktake.Status = status
+	stocktake.ApprovedBy = info.UserId
+	stocktake.ClosedAt = &now
+	stocktake.UpdatedAt = now
+
+	action := domain.AuditActionApprove
+	if status == domain.StocktakeApproved {
+		err = s.repo.Stocktakes.Approve(ctx, stocktake)
+	} else {
+		action = domain.AuditActionCancel
+		err = s.repo.Stocktakes.Cancel(ctx, stocktake)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyID, domain.AuditEntityStocktake, id, action, before, stocktake)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"testing"
+)
+
+func TestStocktakeCountTargets(t *testing.T) {
+	lines := []domain.StocktakeLine{
+		{ID: 1, PurchasedMaterialID: 10, ItemID: 100},
+		{ID: 2, PurchasedMaterialID: 11, ItemID: 100},
+		{ID: 3, PurchasedMaterialID: 12, ItemID: 200},
+	}
+
+	tests := []struct {
+		name    string
+		count   domain.StocktakeCount
+		wantIds []int64
+		wantErr error
+	}{
+		{
+			name:    "all lots of item",
+			count:   domain.StocktakeCount{ItemID: 100},
+			wantIds: []int64{1, 2},
+		},
+		{
+			name:    "single lot of item",
+			count:   domain.StocktakeCount{ItemID: 200},
+			wantIds: []int64{3},
+		},
+		{
+			name:    "code selects one lot",
+			count:   domain.StocktakeCount{Code: &domain.CodeInfo{Id: 11, ItemId: 100}},
+			wantIds: []int64{2},
+		},
+		{
+			name:    "code without item",
+			count:   domain.StocktakeCount{Code: &domain.CodeInfo{Id: 12}},
+			wantIds: []int64{3},
+		},
+		{
+			name:    "code takes precedence over item",
+			count:   domain.StocktakeCount{ItemID: 100, Code: &domain.CodeInfo{Id: 12}},
+			wantIds: []int64{3},
+		},
+		{
+			name:    "code of another item",
+			count:   domain.StocktakeCount{Code: &domain.CodeInfo{Id: 10, ItemId: 200}},
+			wantErr: domain.ErrStocktakeLineNotFound,
+		},
+		{
+			name:    "item not in stocktake",
+			count:   domain.StocktakeCount{ItemID: 300},
+			wantErr: domain.ErrStocktakeLineNotFound,
+		},
+		{
+			name:    "no target",
+			count:   domain.StocktakeCount{Quantity: 5},
+			wantErr: domain.ErrStocktakeCountTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := stocktakeCountTargets(lines, tt.count)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("stocktakeCountTargets() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(targets) != len(tt.wantIds) {
+				t.Fatalf("stocktakeCountTargets() returned %d lines, want %d", len(targets), len(tt.wantIds))
+			}
+
+			for i, line := range targets {
+				if line.ID != tt.wantIds[i] {
+					t.Errorf("stocktakeCountTargets()[%d].ID = %d, want %d", i, line.ID, tt.wantIds[i])
+				}
+			}
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 type Supplier interface {
 	GetById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Supplier, error)
-	Create(ctx context.Context, spl domain.Supplier) (int64, error)
+	Create(ctx context.Context, spl domain.Supplier, info domain.JWTInfo) (int64, error)
 	Update(ctx context.Context, inp domain.UpdateSupplier, info domain.JWTInfo) error
 	Delete(ctx context.Context, id int64, info domain.JWTInfo) error
 	GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.Supplier, int64, error)
@@ -42,8 +42,16 @@ func (s *SupplierService) GetById(ctx context.Context, id int64, info domain.JWT
 	return supplier, nil
 }
 
-func (s *SupplierService) Create(ctx context.Context, spl domain.Supplier) (int64, error) {
-	return s.repo.Suppliers.Create(ctx, spl)
+func (s *SupplierService) Create(ctx context.Context, spl domain.Supplier, info domain.JWTInfo) (int64, error) {
+	id, err := s.repo.Suppliers.Create(ctx, spl)
+	if err != nil {
+		return 0, err
+	}
+
+	spl.ID = id
+	recordAudit(ctx, s.repo, info, spl.CompanyId, domain.AuditEntitySupplier, id, domain.AuditActionCreate, nil, spl)
+
+	return id, nil
 }
 
 func (s *SupplierService) Update(ctx context.Context, inp domain.UpdateSupplier, info domain.JWTInfo) error {
@@ -60,6 +68,8 @@ func (s *SupplierService) Update(ctx context.Context, inp domain.UpdateSupplier,
 		return domain.ErrNotAllowed
 	}
 
+	before := supplier
+
 	if inp.Name != nil {
 		supplier.Name = *inp.Name
 	}
@@ -152,7 +162,13 @@ func (s *SupplierService) Update(ctx context.Context, inp domain.UpdateSupplier,
 		supplier.Locality = *inp.Locality
 	}
 
-	return s.repo.Suppliers.Update(ctx, supplier)
+	if err = s.repo.Suppliers.Update(ctx, supplier); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyId, domain.AuditEntitySupplier, supplier.ID, domain.AuditActionUpdate, before, supplier)
+
+	return nil
 }
 
 func (s *SupplierService) Delete(ctx context.Context, id int64, info domain.JWTInfo) error {
@@ -169,7 +185,13 @@ func (s *SupplierService) Delete(ctx context.Context, id int64, info domain.JWTI
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Suppliers.Delete(ctx, id)
+	if err = s.repo.Suppliers.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, spl.CompanyId, domain.AuditEntitySupplier, id, domain.AuditActionDelete, spl, nil)
+
+	return nil
 }
 
 func (s *SupplierService) GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.Supplier, int64, error) {
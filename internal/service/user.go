@@ -13,7 +13,7 @@ import (
 type User interface {
 	GetById(ctx context.Context, id int64, info domain.JWTInfo) (domain.User, error)
 	UpdateProfile(ctx context.Context, user domain.UserProfileUpdate, info domain.JWTInfo) error
-	Create(ctx context.Context, user domain.User) (int64, error)
+	Create(ctx context.Context, user domain.User, info domain.JWTInfo) (int64, error)
 	Update(ctx context.Context, user domain.UserUpdate, info domain.JWTInfo) error
 	Delete(ctx context.Context, id int64, info domain.JWTInfo) error
 	GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.UserResponse, int64, error)
@@ -58,6 +58,8 @@ func (su *UserService) UpdateProfile(ctx context.Context, req domain.UserProfile
 		return domain.ErrNotAllowed
 	}
 
+	before := user
+
 	if req.Name != nil {
 		user.Name = *req.Name
 	}
@@ -83,11 +85,25 @@ func (su *UserService) UpdateProfile(ctx context.Context, req domain.UserProfile
 		user.Country = *req.Country
 	}
 
-	return su.repo.User.Update(ctx, user)
+	if err = su.repo.User.Update(ctx, user); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, su.repo, info, before.CompanyID, domain.AuditEntityUser, user.ID, domain.AuditActionUpdate, before, user)
+
+	return nil
 }
 
-func (su *UserService) Create(ctx context.Context, user domain.User) (int64, error) {
-	return su.repo.User.Create(ctx, user)
+func (su *UserService) Create(ctx context.Context, user domain.User, info domain.JWTInfo) (int64, error) {
+	id, err := su.repo.User.Create(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+
+	user.ID = id
+	recordAudit(ctx, su.repo, info, user.CompanyID, domain.AuditEntityUser, id, domain.AuditActionCreate, nil, user)
+
+	return id, nil
 }
 
 func (su *UserService) Update(ctx context.Context, req domain.UserUpdate, info domain.JWTInfo) error {
@@ -100,6 +116,8 @@ func (su *UserService) Update(ctx context.Context, req domain.UserUpdate, info d
 		return domain.ErrNotAllowed
 	}
 
+	before := user
+
 	if req.Name != nil {
 		user.Name = *req.Name
 	}
@@ -161,7 +179,13 @@ func (su *UserService) Update(ctx context.Context, req domain.UserUpdate, info d
 		user.IsApproved = *req.IsApproved
 	}
 
-	return su.repo.User.Update(ctx, user)
+	if err = su.repo.User.Update(ctx, user); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, su.repo, info, before.CompanyID, domain.AuditEntityUser, user.ID, domain.AuditActionUpdate, before, user)
+
+	return nil
 }
 
 func (su *UserService) Delete(ctx context.Context, id int64, info domain.JWTInfo) error {
@@ -174,7 +198,13 @@ func (su *UserService) Delete(ctx context.Context, id int64, info domain.JWTInfo
 		return domain.ErrNotAllowed
 	}
 
-	return su.repo.User.Delete(ctx, id)
+	if err = su.repo.User.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, su.repo, info, user.CompanyID, domain.AuditEntityUser, id, domain.AuditActionDelete, user, nil)
+
+	return nil
 }
 
 func (su *UserService) GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.UserResponse, int64, error) {
@@ -15,12 +15,13 @@ import (
 
 type Warehouse interface {
 	GetById(ctx context.Context, id int64, info domain.JWTInfo) (domain.Warehouse, error)
-	Create(ctx context.Context, wh domain.Warehouse) (int64, error)
+	Create(ctx context.Context, wh domain.Warehouse, info domain.JWTInfo) (int64, error)
 	Update(ctx context.Context, wh domain.WarehouseUpdate, info domain.JWTInfo) error
 	Delete(ctx context.Context, id int64, info domain.JWTInfo) error
 	GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.Warehouse, int64, error)
 	GetResponsibleUsers(ctx context.Context, companyId int64, param domain.Param) ([]domain.UserResponse, int64, error)
 	GetIncomeHistoryByWarehouseId(ctx context.Context, id int64, param domain.Param) ([]domain.Material, int64, error)
+	GetLowStock(ctx context.Context, id int64, info domain.JWTInfo, param domain.Param) ([]domain.LowStockMaterial, int64, error)
 	GenerateWarehouseInfoReportXls(ctx context.Context, id int64, info domain.JWTInfo) (*excelize.File, error)
 	GenerateWarehouseInfoReportPdf(ctx context.Context, id int64, info domain.JWTInfo) (*gofpdf.Fpdf, error)
 }
@@ -50,8 +51,16 @@ func (s *WarehouseServices) GetById(ctx context.Context, id int64, info domain.J
 	return wh, nil
 }
 
-func (s *WarehouseServices) Create(ctx context.Context, wh domain.Warehouse) (int64, error) {
-	return s.repo.Warehouse.Create(ctx, wh)
+func (s *WarehouseServices) Create(ctx context.Context, wh domain.Warehouse, info domain.JWTInfo) (int64, error) {
+	id, err := s.repo.Warehouse.Create(ctx, wh)
+	if err != nil {
+		return 0, err
+	}
+
+	wh.ID = id
+	recordAudit(ctx, s.repo, info, wh.CompanyId, domain.AuditEntityWarehouse, id, domain.AuditActionCreate, nil, wh)
+
+	return id, nil
 }
 
 func (s *WarehouseServices) Update(ctx context.Context, inp domain.WarehouseUpdate, info domain.JWTInfo) error {
@@ -64,6 +73,8 @@ func (s *WarehouseServices) Update(ctx context.Context, inp domain.WarehouseUpda
 		return domain.ErrNotAllowed
 	}
 
+	before := wh
+
 	if inp.Name != nil {
 		wh.Name = *inp.Name
 	}
@@ -88,10 +99,6 @@ func (s *WarehouseServices) Update(ctx context.Context, inp domain.WarehouseUpda
 		wh.MaxCapacity = *inp.MaxCapacity
 	}
 
-	if inp.CurrentOccupancy != nil {
-		wh.CurrentOccupancy = *inp.CurrentOccupancy
-	}
-
 	if inp.OtherFields != nil {
 		wh.OtherFields = *inp.OtherFields
 	}
@@ -112,7 +119,13 @@ func (s *WarehouseServices) Update(ctx context.Context, inp domain.WarehouseUpda
 		wh.Comments = *inp.Comments
 	}
 
-	return s.repo.Warehouse.Update(ctx, wh)
+	if err = s.repo.Warehouse.Update(ctx, wh); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, before.CompanyId, domain.AuditEntityWarehouse, wh.ID, domain.AuditActionUpdate, before, wh)
+
+	return nil
 }
 
 func (s *WarehouseServices) Delete(ctx context.Context, id int64, info domain.JWTInfo) error {
@@ -129,7 +142,13 @@ func (s *WarehouseServices) Delete(ctx context.Context, id int64, info domain.JW
 		return domain.ErrNotAllowed
 	}
 
-	return s.repo.Warehouse.Delete(ctx, id)
+	if err = s.repo.Warehouse.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, wh.CompanyId, domain.AuditEntityWarehouse, id, domain.AuditActionDelete, wh, nil)
+
+	return nil
 }
 
 func (s *WarehouseServices) GetListByCompanyId(ctx context.Context, companyId int64, param domain.Param) ([]domain.Warehouse, int64, error) {
@@ -173,6 +192,17 @@ func (s *WarehouseServices) GetIncomeHistoryByWarehouseId(ctx context.Context, i
 	return s.repo.Materials.GetIncomeHistoryByWarehouseId(ctx, id, param)
 }
 
+func (s *WarehouseServices) GetLowStock(ctx context.Context, id int64, info domain.JWTInfo, param domain.Param) ([]domain.LowStockMaterial, int64, error) {
+	wh, err := s.GetById(ctx, id, info)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	param.CompanyId = wh.CompanyId
+
+	return s.repo.Materials.GetLowStockByWarehouseId(ctx, id, param)
+}
+
 func (s *WarehouseServices) GenerateWarehouseInfoReportXls(ctx context.Context, id int64, info domain.JWTInfo) (*excelize.File, error) {
 	wh, err := s.repo.Warehouse.GetById(ctx, id)
 	if err != nil {
@@ -235,7 +265,7 @@ func (s *WarehouseServices) GenerateWarehouseInfoReportPdf(ctx context.Context,
 	}
 
 	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddUTF8Font("Arial", "", "assets/fonts/arial/arialmt.ttf")
+	pdf.AddUTF8Font("Arial", "", pdfFontPath)
 	pdf.AddPage()
 	pdf.SetFont("Arial", "", 16)
 
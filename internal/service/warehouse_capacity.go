@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"time"
+)
+
+// checkWarehouseCapacity предварительно проверяет размещение на складе дополнительного объема volume. Если
+// заполняемость превысит максимальную вместимость склада, возвращает ErrWarehouseOverCapacity, а при разрешенном
+// в компании превышении - признак того, что после размещения нужно отправить предупреждение через notifyOverCapacity.
+// Склад без указанной максимальной вместимости не ограничен. Окончательная проверка с учетом параллельных
+// размещений выполняется репозиторием в транзакции, изменяющей остаток
+func checkWarehouseCapacity(ctx context.Context, repo *repository.Repository, warehouseId, volume int64) (bool, error) {
+	if warehouseId == 0 || volume <= 0 {
+		return false, nil
+	}
+
+	wh, err := repo.Warehouse.GetById(ctx, warehouseId)
+	if err != nil {
+		return false, err
+	}
+
+	if wh.MaxCapacity <= 0 || wh.CurrentOccupancy+volume <= wh.MaxCapacity {
+		return false, nil
+	}
+
+	company, err := repo.Company.GetById(ctx, wh.CompanyId)
+	if err != nil {
+		return false, err
+	}
+
+	if !company.AllowOverCapacity {
+		return false, domain.ErrWarehouseOverCapacity
+	}
+
+	return true, nil
+}
+
+// notifyOverCapacity предупреждает пользователя, ответственного за склад, и пользователей компании, подписанных
+// на системные уведомления, о превышении вместимости склада. Ошибка отправки не прерывает основную операцию
+func notifyOverCapacity(ctx context.Context, repo *repository.Repository, info domain.JWTInfo, warehouseId int64) {
+	wh, err := repo.Warehouse.GetById(ctx, warehouseId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("over capacity: failed to get warehouse %d: %v", warehouseId, err))
+		return
+	}
+
+	recipients, err := repo.Notifications.GetSystemRecipients(ctx, wh.CompanyId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("over capacity: failed to get recipients for company %d: %v", wh.CompanyId, err))
+		return
+	}
+
+	// повторное предупреждение не создается, пока предыдущее не прочитано
+	now := time.Now().UTC()
+
+	for _, userId := range uniqueIds(append([]int64{info.UserId, wh.ResponsiblePerson}, recipients...)) {
+		if _, err = repo.Notifications.CreateIfNotRecent(ctx, domain.Notification{
+			UserID:      userId,
+			CompanyID:   wh.CompanyId,
+			Type:        domain.NotificationOverCapacity,
+			Title:       "Превышена вместимость склада",
+			Message:     overCapacityMessage(wh),
+			WarehouseID: wh.ID,
+			DedupKey:    fmt.Sprintf("%s:%d", domain.NotificationOverCapacity, wh.ID),
+		}, now); err != nil {
+			logger.Error(fmt.Sprintf("over capacity: failed to notify user %d: %v", userId, err))
+		}
+	}
+}
+
+func overCapacityMessage(wh domain.Warehouse) string {
+	return fmt.Sprintf("Заполняемость склада «%s» составляет %d при максимальной вместимости %d",
+		wh.Name, wh.CurrentOccupancy, wh.MaxCapacity)
+}
+
+// occupancyChange возвращает склад, на котором изменение закупленного материала занимает дополнительный объем,
+// и величину этого объема. При смене склада материал целиком занимает место на новом складе
+func occupancyChange(before, after domain.Material) (int64, int64) {
+	volume := after.Volume * after.TotalQuantity
+	if before.WarehouseID == after.WarehouseID {
+		volume -= before.Volume * before.TotalQuantity
+	}
+
+	return after.WarehouseID, volume
+}
+
+// capacityPlan накапливает объем, размещаемый на складах пакетной операцией, чтобы каждая строка пакета
+// проверялась с учетом подготовленных перед ней строк
+type capacityPlan struct {
+	volumes      map[int64]int64
+	overCapacity map[int64]bool
+}
+
+func newCapacityPlan() *capacityPlan {
+	return &capacityPlan{
+		volumes:      make(map[int64]int64),
+		overCapacity: make(map[int64]bool),
+	}
+}
+
+// check проверяет размещение объема volume на складе вместе с ранее запланированным объемом пакета
+func (p *capacityPlan) check(ctx context.Context, repo *repository.Repository, warehouseId, volume int64) error {
+	if volume <= 0 {
+		return nil
+	}
+
+	overCapacity, err := checkWarehouseCapacity(ctx, repo, warehouseId, p.volumes[warehouseId]+volume)
+	if err != nil {
+		return err
+	}
+
+	p.volumes[warehouseId] += volume
+
+	if overCapacity {
+		p.overCapacity[warehouseId] = true
+	}
+
+	return nil
+}
+
+// notify отправляет предупреждения по складам, вместимость которых превышена пакетом. Вызывается после
+// сохранения пакета
+func (p *capacityPlan) notify(ctx context.Context, repo *repository.Repository, info domain.JWTInfo) {
+	for warehouseId := range p.overCapacity {
+		notifyOverCapacity(ctx, repo, info, warehouseId)
+	}
+}
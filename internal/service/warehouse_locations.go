@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"github.com/rusystem/crm-api/internal/config"
+	"github.com/rusystem/crm-api/internal/repository"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"strings"
+	"time"
+)
+
+type WarehouseLocations interface {
+	Create(ctx context.Context, info domain.JWTInfo, warehouseId int64, inp domain.CreateWarehouseLocation) (int64, error)
+	GetById(ctx context.Context, info domain.JWTInfo, warehouseId, id int64) (domain.WarehouseLocation, error)
+	Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdateWarehouseLocation) error
+	Delete(ctx context.Context, info domain.JWTInfo, warehouseId, id int64) error
+	Tree(ctx context.Context, info domain.JWTInfo, warehouseId int64) ([]domain.WarehouseLocationNode, error)
+	GetContents(ctx context.Context, info domain.JWTInfo, warehouseId, id int64, params domain.Param) ([]domain.Material, int64, error)
+}
+
+type WarehouseLocationsService struct {
+	cfg  *config.Config
+	repo *repository.Repository
+}
+
+func NewWarehouseLocationsService(cfg *config.Config, repo *repository.Repository) *WarehouseLocationsService {
+	return &WarehouseLocationsService{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
+
+func (s *WarehouseLocationsService) Create(ctx context.Context, info domain.JWTInfo, warehouseId int64, inp domain.CreateWarehouseLocation) (int64, error) {
+	wh, err := s.warehouse(ctx, info, warehouseId)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+
+	location := domain.WarehouseLocation{
+		WarehouseID: wh.ID,
+		CompanyID:   wh.CompanyId,
+		ParentID:    inp.ParentID,
+		Type:        inp.Type,
+		Code:        strings.TrimSpace(inp.Code),
+		Name:        strings.TrimSpace(inp.Name),
+		Capacity:    inp.Capacity,
+		Description: inp.Description,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err = s.validateLocation(ctx, location); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.WarehouseLocations.Create(ctx, location)
+	if err != nil {
+		return 0, err
+	}
+
+	location.ID = id
+	recordAudit(ctx, s.repo, info, location.CompanyID, domain.AuditEntityWarehouseLocation, id, domain.AuditActionCreate, nil, location)
+
+	return id, nil
+}
+
+func (s *WarehouseLocationsService) GetById(ctx context.Context, info domain.JWTInfo, warehouseId, id int64) (domain.WarehouseLocation, error) {
+	if _, err := s.warehouse(ctx, info, warehouseId); err != nil {
+		return domain.WarehouseLocation{}, err
+	}
+
+	location, err := s.repo.WarehouseLocations.GetById(ctx, id)
+	if err != nil {
+		return domain.WarehouseLocation{}, err
+	}
+
+	if location.WarehouseID != warehouseId {
+		return domain.WarehouseLocation{}, domain.ErrLocationNotFound
+	}
+
+	return location, nil
+}
+
+// Update изменяет место хранения. Уровень места хранения не меняется, родителя можно заменить только на место
+// хранения того же уровня на том же складе
+func (s *WarehouseLocationsService) Update(ctx context.Context, info domain.JWTInfo, inp domain.UpdateWarehouseLocation) error {
+	location, err := s.GetById(ctx, info, inp.WarehouseID, inp.ID)
+	if err != nil {
+		return err
+	}
+
+	before := location
+
+	if inp.ParentID != nil {
+		location.ParentID = *inp.ParentID
+	}
+
+	if inp.Code != nil {
+		location.Code = strings.TrimSpace(*inp.Code)
+	}
+
+	if inp.Name != nil {
+		location.Name = strings.TrimSpace(*inp.Name)
+	}
+
+	if inp.Capacity != nil {
+		location.Capacity = *inp.Capacity
+	}
+
+	if inp.Description != nil {
+		location.Description = *inp.Description
+	}
+
+	if inp.IsActive != nil {
+		location.IsActive = *inp.IsActive
+	}
+
+	if err = s.validateLocation(ctx, location); err != nil {
+		return err
+	}
+
+	location.UpdatedAt = time.Now().UTC()
+
+	if err = s.repo.WarehouseLocations.Update(ctx, location); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, location.CompanyID, domain.AuditEntityWarehouseLocation, location.ID, domain.AuditActionUpdate,
+		before, location)
+
+	return nil
+}
+
+// Delete удаляет место хранения без вложенных мест хранения и размещенных материалов
+func (s *WarehouseLocationsService) Delete(ctx context.Context, info domain.JWTInfo, warehouseId, id int64) error {
+	location, err := s.GetById(ctx, info, warehouseId, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.WarehouseLocations.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.repo, info, location.CompanyID, domain.AuditEntityWarehouseLocation, id, domain.AuditActionDelete, location, nil)
+
+	return nil
+}
+
+// Tree возвращает места хранения склада в виде дерева. Заполненность каждого узла включает заполненность
+// вложенных мест хранения
+func (s *WarehouseLocationsService) Tree(ctx context.Context, info domain.JWTInfo, warehouseId int64) ([]domain.WarehouseLocationNode, error) {
+	if _, err := s.warehouse(ctx, info, warehouseId); err != nil {
+		return nil, err
+	}
+
+	locations, err := s.repo.WarehouseLocations.GetByWarehouseId(ctx, warehouseId)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]domain.WarehouseLocation, len(locations))
+	for _, l := range locations {
+		children[l.ParentID] = append(children[l.ParentID], l)
+	}
+
+	var build func(parentId int64) []domain.WarehouseLocationNode
+	build = func(parentId int64) []domain.WarehouseLocationNode {
+		nodes := make([]domain.WarehouseLocationNode, 0, len(children[parentId]))
+		for _, l := range children[parentId] {
+			node := domain.WarehouseLocationNode{WarehouseLocation: l, Children: build(l.ID)}
+			for _, child := range node.Children {
+				node.Occupancy += child.Occupancy
+			}
+
+			nodes = append(nodes, node)
+		}
+
+		return nodes
+	}
+
+	return build(0), nil
+}
+
+// GetContents возвращает закупленные материалы, размещенные в месте хранения и во вложенных в него местах хранения
+func (s *WarehouseLocationsService) GetContents(ctx context.Context, info domain.JWTInfo, warehouseId, id int64, params domain.Param) ([]domain.Material, int64, error) {
+	location, err := s.GetById(ctx, info, warehouseId, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params.CompanyId = location.CompanyID
+
+	return s.repo.Materials.GetPurchasedByLocationId(ctx, id, params)
+}
+
+func (s *WarehouseLocationsService) warehouse(ctx context.Context, info domain.JWTInfo, id int64) (domain.Warehouse, error) {
+	wh, err := s.repo.Warehouse.GetById(ctx, id)
+	if err != nil {
+		return domain.Warehouse{}, err
+	}
+
+	if wh.CompanyId != info.CompanyId {
+		return domain.Warehouse{}, domain.ErrNotAllowed
+	}
+
+	return wh, nil
+}
+
+// validateLocation проверяет код и уровень места хранения: зона располагается в корне склада, стеллаж - в зоне,
+// полка - на стеллаже, ячейка - на полке того же склада
+func (s *WarehouseLocationsService) validateLocation(ctx context.Context, location domain.WarehouseLocation) error {
+	if location.Code == "" {
+		return domain.ErrLocationCodeEmpty
+	}
+
+	if location.Capacity < 0 {
+		return domain.ErrInvalidQuantity
+	}
+
+	parentType, ok := domain.LocationParentTypes[location.Type]
+	if !ok {
+		return domain.ErrInvalidLocationType
+	}
+
+	if parentType == "" {
+		if location.ParentID != 0 {
+			return domain.ErrInvalidLocationParent
+		}
+
+		return nil
+	}
+
+	if location.ParentID == 0 {
+		return domain.ErrInvalidLocationParent
+	}
+
+	parent, err := s.repo.WarehouseLocations.GetById(ctx, location.ParentID)
+	if err != nil {
+		return err
+	}
+
+	if parent.WarehouseID != location.WarehouseID || parent.Type != parentType {
+		return domain.ErrInvalidLocationParent
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// auditSortFields поля сортировки журнала аудита
+var auditSortFields = sortFields("id", "created_at", "entity_type", "action")
+
+func (h *Handler) initAuditRoutes(api *gin.RouterGroup) {
+	audit := api.Group("/audit", h.adminIdentity)
+	{
+		audit.GET("/", h.getAuditLog)
+	}
+}
+
+// @Summary Get audit log
+// @Security ApiKeyAuth
+// @Tags audit
+// @Description Журнал аудита изменений: кто, когда и что изменил.
+// @Description Доступен только администраторам, журнал других компаний - только super admin
+// @ID get-audit-log
+// @Accept json
+// @Produce json
+// @Param entity_type query string false "Тип сущности" Enums(planning_material, purchased_material, planning_material_archive, purchased_material_archive, warehouse, supplier, user, company, unit_of_measure)
+// @Param entity_id query int false "ID сущности"
+// @Param user_id query int false "ID пользователя, выполнившего действие"
+// @Param company_id query int false "ID компании, только для super admin"
+// @Param from query string false "Начало периода, RFC3339 или 2006-01-02"
+// @Param to query string false "Конец периода, RFC3339 или 2006-01-02"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, created_at, entity_type, action) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /audit [GET]
+func (h *Handler) getAuditLog(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	entityId, err := parseInt64QueryParam(c, "entity_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userId, err := parseInt64QueryParam(c, "user_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	companyId, err := parseInt64QueryParam(c, "company_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	from, err := parseTimeQueryParam(c, "from", false)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	to, err := parseTimeQueryParam(c, "to", true)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, auditSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	logs, count, err := h.services.Audit.GetList(c, info, domain.AuditParams{
+		Limit:      limit,
+		Offset:     offset,
+		CompanyId:  companyId,
+		UserId:     userId,
+		EntityType: c.Query("entity_type"),
+		EntityId:   entityId,
+		From:       from,
+		To:         to,
+		Sort:       sort,
+		SortField:  field,
+	})
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       logs,
+		TotalCount: count,
+	})
+}
@@ -61,6 +61,8 @@ func (h *Handler) getCompanyInfo(c *gin.Context) {
 // @Description Обновление компании.
 // @Description Только super admin может обновлять active & approve компании
 // @Description Для обновления указывать только необходимые поля.
+// @Description allow_over_capacity разрешает размещать материалы сверх вместимости склада с уведомлением вместо отказа
+// @Description default_currency - валюта цен материалов по умолчанию: KZT, RUB или USD
 // @ID update-company
 // @Accept  json
 // @Produce  json
@@ -91,7 +93,7 @@ func (h *Handler) updateCompany(c *gin.Context) {
 	}
 
 	if err = h.services.Company.Update(c.Request.Context(), req, info); err != nil {
-		if errors.Is(err, domain.ErrInvalidTimezone) {
+		if errors.Is(err, domain.ErrInvalidTimezone) || errors.Is(err, domain.ErrInvalidCurrency) {
 			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 			return
 		}
@@ -134,21 +136,34 @@ func (h *Handler) createCompany(c *gin.Context) {
 		return
 	}
 
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	id, err := h.services.Company.Create(c.Request.Context(), domain.Company{
-		NameRu:     req.NameRu,
-		NameEn:     req.NameEn,
-		Country:    req.Country,
-		Address:    req.Address,
-		Phone:      req.Phone,
-		Email:      req.Email,
-		Website:    req.Website,
-		IsActive:   req.IsActive,
-		CreatedAt:  time.Now().UTC(),
-		UpdatedAt:  time.Now().UTC(),
-		IsApproved: req.IsApproved,
-		Timezone:   req.Timezone,
-	})
+		NameRu:            req.NameRu,
+		NameEn:            req.NameEn,
+		Country:           req.Country,
+		Address:           req.Address,
+		Phone:             req.Phone,
+		Email:             req.Email,
+		Website:           req.Website,
+		IsActive:          req.IsActive,
+		CreatedAt:         time.Now().UTC(),
+		UpdatedAt:         time.Now().UTC(),
+		IsApproved:        req.IsApproved,
+		Timezone:          req.Timezone,
+		AllowOverCapacity: req.AllowOverCapacity,
+		DefaultCurrency:   req.DefaultCurrency,
+	}, info)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCurrency) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -177,7 +192,18 @@ func (h *Handler) deleteCompany(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Company.Delete(c.Request.Context(), id); err != nil {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Company.Delete(c.Request.Context(), id, info); err != nil {
+		if errors.Is(err, domain.ErrCompanyNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,315 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"time"
+)
+
+// exchangeRateSortFields поля сортировки списка курсов валют
+var exchangeRateSortFields = sortFields("id", "from_currency", "to_currency", "rate_date", "created_at", "updated_at")
+
+func (h *Handler) initExchangeRateRoutes(api *gin.RouterGroup) {
+	rates := api.Group("/exchange-rates", h.userIdentity)
+	{
+		rates.POST("/", h.createExchangeRate)
+		rates.GET("/", h.getExchangeRates)
+		rates.GET("/convert", h.convertCurrency)
+		rates.GET("/:id", h.getExchangeRate)
+		rates.PUT("/:id", h.updateExchangeRate)
+		rates.DELETE("/:id", h.deleteExchangeRate)
+	}
+}
+
+// @Summary Create exchange rate
+// @Security ApiKeyAuth
+// @Tags exchange rates
+// @Description Добавление курса валюты компании на дату. Курс задает стоимость единицы исходной валюты в целевой
+// @ID create-exchange-rate
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateExchangeRate true "Необходимо указать валютную пару и курс"
+// @Success 201 {object} domain.IdResponse
+// @Failure 400,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /exchange-rates [POST]
+func (h *Handler) createExchangeRate(c *gin.Context) {
+	var inp domain.CreateExchangeRate
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.services.ExchangeRates.Create(c, info, inp)
+	if err != nil {
+		exchangeRateErrorResponse(c, err)
+		return
+	}
+
+	newCreateSuccessIdResponse(c, id)
+}
+
+// @Summary Get exchange rates
+// @Security ApiKeyAuth
+// @Tags exchange rates
+// @Description Получение списка курсов валют компании
+// @ID get-exchange-rates
+// @Accept json
+// @Produce json
+// @Param from_currency query string false "Исходная валюта" Enums(KZT, RUB, USD)
+// @Param to_currency query string false "Целевая валюта" Enums(KZT, RUB, USD)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, from_currency, to_currency, rate_date, created_at) default(rate_date)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /exchange-rates [GET]
+func (h *Handler) getExchangeRates(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, exchangeRateSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	rates, count, err := h.services.ExchangeRates.GetList(c, info, domain.ExchangeRateParams{
+		Limit:        limit,
+		Offset:       offset,
+		FromCurrency: c.Query("from_currency"),
+		ToCurrency:   c.Query("to_currency"),
+		Sort:         sort,
+		SortField:    field,
+	})
+	if err != nil {
+		exchangeRateErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       rates,
+		TotalCount: count,
+	})
+}
+
+// @Summary Get exchange rate
+// @Security ApiKeyAuth
+// @Tags exchange rates
+// @Description Получение курса валюты по id
+// @ID get-exchange-rate
+// @Accept json
+// @Produce json
+// @Param id path int true "ID курса"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /exchange-rates/{id} [GET]
+func (h *Handler) getExchangeRate(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rate, err := h.services.ExchangeRates.GetById(c, info, id)
+	if err != nil {
+		exchangeRateErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       rate,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Update exchange rate
+// @Security ApiKeyAuth
+// @Tags exchange rates
+// @Description Изменение курса валюты и даты курса. Валютная пара не меняется
+// @ID update-exchange-rate
+// @Accept json
+// @Produce json
+// @Param id path int true "ID курса"
+// @Param input body domain.UpdateExchangeRate true "Изменяемые поля курса"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /exchange-rates/{id} [PUT]
+func (h *Handler) updateExchangeRate(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.UpdateExchangeRate
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+
+	if err = h.services.ExchangeRates.Update(c, info, inp); err != nil {
+		exchangeRateErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Delete exchange rate
+// @Security ApiKeyAuth
+// @Tags exchange rates
+// @Description Удаление курса валюты
+// @ID delete-exchange-rate
+// @Accept json
+// @Produce json
+// @Param id path int true "ID курса"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /exchange-rates/{id} [DELETE]
+func (h *Handler) deleteExchangeRate(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.ExchangeRates.Delete(c, info, id); err != nil {
+		exchangeRateErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Convert currency
+// @Security ApiKeyAuth
+// @Tags exchange rates
+// @Description Пересчет суммы из одной валюты в другую по курсу компании на дату. Если прямого курса нет,
+// @Description используется обратный курс или кросс-курс через валюту компании по умолчанию
+// @ID convert-currency
+// @Accept json
+// @Produce json
+// @Param amount query number true "Сумма"
+// @Param from query string true "Исходная валюта" Enums(KZT, RUB, USD)
+// @Param to query string true "Целевая валюта" Enums(KZT, RUB, USD)
+// @Param date query string false "Дата курса (RFC3339 или 2006-01-02), по умолчанию текущая"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /exchange-rates/convert [GET]
+func (h *Handler) convertCurrency(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	amount, err := decimal.NewFromString(c.Query("amount"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidQueryParam.Error())
+		return
+	}
+
+	date, err := parseTimeQueryParam(c, "date", true)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var at *time.Time
+	if !date.IsZero() {
+		at = &date
+	}
+
+	conversion, err := h.services.ExchangeRates.Convert(c, info, amount, c.Query("from"), c.Query("to"), at)
+	if err != nil {
+		exchangeRateErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       conversion,
+		TotalCount: 1,
+	})
+}
+
+func exchangeRateErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrExchangeRateNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrExchangeRateAlreadyExists) {
+		newErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidCurrency) || errors.Is(err, domain.ErrSameCurrency) ||
+		errors.Is(err, domain.ErrInvalidExchangeRate) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
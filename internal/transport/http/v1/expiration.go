@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// @Summary Get expiring purchased materials
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Получение партий с истекающим в ближайшие N дней сроком годности, сгруппированных по складам. Просроченные партии помечаются is_expired
+// @ID get-expiring-purchased
+// @Accept json
+// @Produce json
+// @Param days query int false "Количество дней до истечения срока" default(30)
+// @Param warehouse_id query int false "ID склада"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/expiring [GET]
+func (h *Handler) getExpiringPurchased(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	days, err := parseInt64QueryParam(c, "days", 30)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	warehouseId, err := parseInt64QueryParam(c, "warehouse_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	warehouses, err := h.services.Materials.GetExpiringPurchased(c, info, days, warehouseId)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidQueryParam) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       warehouses,
+		TotalCount: int64(len(warehouses)),
+	})
+}
+
+// @Summary Get FEFO picking suggestion
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Подбор партий для отбора товара по принципу FEFO (первым истекает - первым отпускается)
+// @ID get-fefo-suggestion
+// @Accept json
+// @Produce json
+// @Param item_id query int false "Идентификатор товара"
+// @Param article query string false "Артикул материала, если не указан item_id"
+// @Param quantity query int true "Требуемое количество"
+// @Param warehouse_id query int false "ID склада"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/fefo [GET]
+func (h *Handler) getFefoSuggestion(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	itemId, err := parseInt64QueryParam(c, "item_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quantity, err := parseInt64QueryParam(c, "quantity", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	warehouseId, err := parseInt64QueryParam(c, "warehouse_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	suggestion, err := h.services.Materials.SuggestFefo(c, info, domain.FefoParams{
+		ItemID:      itemId,
+		Article:     c.Query("article"),
+		Quantity:    quantity,
+		WarehouseID: warehouseId,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrFefoTarget) || errors.Is(err, domain.ErrInvalidQuantity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       suggestion,
+		TotalCount: int64(len(suggestion.Picks)),
+	})
+}
@@ -0,0 +1,296 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// goodsIssueSortFields поля сортировки списка расходных накладных
+var goodsIssueSortFields = sortFields("id", "warehouse_id", "status", "created_at", "updated_at")
+
+func (h *Handler) initGoodsIssueRoutes(api *gin.RouterGroup) {
+	issues := api.Group("/goods-issues", h.userIdentity)
+	{
+		issues.POST("/", h.createGoodsIssue)
+		issues.GET("/:id", h.getGoodsIssue)
+		issues.GET("/:id/pdf", h.getGoodsIssuePdf)
+		issues.PUT("/:id/post", h.postGoodsIssue)
+		issues.DELETE("/:id", h.deleteGoodsIssue)
+	}
+}
+
+// @Summary Create goods issue
+// @Security ApiKeyAuth
+// @Tags goods issues
+// @Description Создание черновика документа отпуска материалов со склада в производство. Остатки изменяются
+// @Description только при проведении документа
+// @ID create-goods-issue
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateGoodsIssue true "Необходимо указать склад и строки документа"
+// @Success 201 {object} domain.IdResponse
+// @Failure 400,403,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /goods-issues [POST]
+func (h *Handler) createGoodsIssue(c *gin.Context) {
+	var inp domain.CreateGoodsIssue
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.services.GoodsIssues.Create(c, info, inp)
+	if err != nil {
+		goodsIssueErrorResponse(c, err)
+		return
+	}
+
+	newCreateSuccessIdResponse(c, id)
+}
+
+// @Summary Get goods issue
+// @Security ApiKeyAuth
+// @Tags goods issues
+// @Description Получение документа отпуска материалов со строками
+// @ID get-goods-issue
+// @Accept json
+// @Produce json
+// @Param id path int true "ID документа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /goods-issues/{id} [GET]
+func (h *Handler) getGoodsIssue(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	issue, err := h.services.GoodsIssues.GetById(c, info, id)
+	if err != nil {
+		goodsIssueErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       issue,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Post goods issue
+// @Security ApiKeyAuth
+// @Tags goods issues
+// @Description Проведение документа отпуска: остатки всех строк уменьшаются одной транзакцией.
+// @Description Если по одной из строк не хватает незарезервированного остатка, документ не проводится
+// @ID post-goods-issue
+// @Accept json
+// @Produce json
+// @Param id path int true "ID документа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /goods-issues/{id}/post [PUT]
+func (h *Handler) postGoodsIssue(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.GoodsIssues.Post(c, info, id); err != nil {
+		goodsIssueErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Delete goods issue
+// @Security ApiKeyAuth
+// @Tags goods issues
+// @Description Удаление черновика документа отпуска. Проведенный документ удалить нельзя
+// @ID delete-goods-issue
+// @Accept json
+// @Produce json
+// @Param id path int true "ID документа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /goods-issues/{id} [DELETE]
+func (h *Handler) deleteGoodsIssue(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.GoodsIssues.Delete(c, info, id); err != nil {
+		goodsIssueErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary      Goods issue pdf
+// @Security 	 ApiKeyAuth
+// @Tags         goods issues
+// @Description  Возвращает печатную форму документа отпуска материалов в PDF
+// @ID           goods-issue-pdf
+// @Accept       json
+// @Produce      application/pdf
+// @Param 		 id path int true "ID документа"
+// @Success      200 {file} file "PDF файл документа"
+// @Failure 	 403,404,422 {object} domain.ErrorResponse
+// @Failure 	 500 {object} domain.ErrorResponse
+// @Failure 	 default {object} domain.ErrorResponse
+// @Router       /goods-issues/{id}/pdf [GET]
+func (h *Handler) getGoodsIssuePdf(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	pdf, err := h.services.GoodsIssues.GeneratePdf(c, info, id)
+	if err != nil {
+		goodsIssueErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=goods_issue_%d.pdf", id))
+	if err = pdf.Output(c.Writer); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary Get warehouse goods issues
+// @Security ApiKeyAuth
+// @Tags warehouse
+// @Description Получение документов отпуска материалов со склада
+// @ID get-warehouse-goods-issues
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, status, created_at, updated_at) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/goods-issues [GET]
+func (h *Handler) getWarehouseGoodsIssues(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, goodsIssueSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	issues, count, err := h.services.GoodsIssues.GetListByWarehouseId(c, info, id, domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+	})
+	if err != nil {
+		goodsIssueErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       issues,
+		TotalCount: count,
+	})
+}
+
+func goodsIssueErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrGoodsIssueNotFound) || errors.Is(err, domain.ErrWarehouseNotFound) ||
+		errors.Is(err, domain.ErrMaterialNotFound) || errors.Is(err, domain.ErrUserNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrGoodsIssueNotDraft) {
+		newErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInsufficientStock) || errors.Is(err, domain.ErrInvalidQuantity) ||
+		errors.Is(err, domain.ErrGoodsIssueMaterialWarehouse) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/rusystem/crm-api/pkg/auth"
 	"github.com/rusystem/crm-api/pkg/domain"
 	"github.com/rusystem/crm-api/tools"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Handler struct {
@@ -34,6 +36,9 @@ func (h *Handler) Init(api *gin.RouterGroup) {
 		h.initSupplierRoutes(v1)
 		h.initWarehouseRoutes(v1)
 		h.initMaterialsRoutes(v1)
+		h.initStocktakeRoutes(v1)
+		h.initGoodsIssueRoutes(v1)
+		h.initPurchaseOrderRoutes(v1)
 
 		// accounts routes
 		h.initCompanyRoutes(v1)
@@ -45,6 +50,15 @@ func (h *Handler) Init(api *gin.RouterGroup) {
 
 		// unit of measure route
 		h.initUnitOfMeasureRoutes(v1)
+
+		// exchange rates route
+		h.initExchangeRateRoutes(v1)
+
+		// notifications route
+		h.initNotificationsRoutes(v1)
+
+		// audit route
+		h.initAuditRoutes(v1)
 	}
 }
 
@@ -86,63 +100,18 @@ func parseLimitQueryParam(c *gin.Context) (int64, error) {
 	return int64(limit), nil
 }
 
-var allowedFields = map[string]bool{
-	"id":                       true,
-	"username":                 true,
-	"name":                     true,
-	"address":                  true,
-	"responsible_person":       true,
-	"phone":                    true,
-	"email":                    true,
-	"max_capacity":             true,
-	"current_occupancy":        true,
-	"country":                  true,
-	"region":                   true,
-	"created_at":               true,
-	"updated_at":               true,
-	"position":                 true,
-	"warehouse_id":             true,
-	"item_id":                  true,
-	"article":                  true,
-	"product_category":         true,
-	"total_quantity":           true,
-	"volume":                   true,
-	"price_without_vat":        true,
-	"total_without_vat":        true,
-	"supplier_id":              true,
-	"location":                 true,
-	"status":                   true,
-	"received_date":            true,
-	"last_updated":             true,
-	"min_stock_level":          true,
-	"expiration_date":          true,
-	"storage_cost":             true,
-	"warehouse_section":        true,
-	"incoming_delivery_number": true,
-	"slug":                     true,
-	"is_active":                true,
-	"name_ru":                  true,
-	"name_en":                  true,
-	"website":                  true,
-	"is_approved":              true,
-	"legal_address":            true,
-	"actual_address":           true,
-	"warehouse_address":        true,
-	"contact_person":           true,
-	"contract_number":          true,
-	"product_categories":       true,
-	"purchase_amount":          true,
-	"balance":                  true,
-	"product_types":            true,
-	"tax_id":                   true,
-	"registration_date":        true,
-	"last_login":               true,
-	"role":                     true,
-	"abbreviation":             true,
-	"description≈":             true,
-}
-
-func parseSortParam(c *gin.Context) (string, string, error) {
+// sortFields возвращает множество полей, по которым можно отсортировать список. Каждый список задает свои поля,
+// чтобы в сортировку не попадали колонки других таблиц
+func sortFields(fields ...string) map[string]bool {
+	allowed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = true
+	}
+
+	return allowed
+}
+
+func parseSortParam(c *gin.Context, allowed map[string]bool) (string, string, error) {
 	sortParam := c.Query("sort")
 	if sortParam == "" {
 		sortParam = "asc"
@@ -158,7 +127,7 @@ func parseSortParam(c *gin.Context) (string, string, error) {
 	}
 
 	// Проверяем, что поле сортировки разрешено
-	if _, ok := allowedFields[sortField]; !ok {
+	if !allowed[sortField] {
 		return "", "", domain.ErrInvalidSortFieldParam
 	}
 
@@ -183,6 +152,24 @@ func parseIdIntPathParam(c *gin.Context) (int64, error) {
 	return int64(id), nil
 }
 
+func parseRevisionPathParam(c *gin.Context) (int64, error) {
+	revision, err := strconv.ParseInt(c.Param("rev"), 10, 64)
+	if err != nil || revision <= 0 {
+		return 0, domain.ErrInvalidRevisionParam
+	}
+
+	return revision, nil
+}
+
+func parseLocationIdPathParam(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("location_id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, domain.ErrInvalidLocationIdParam
+	}
+
+	return id, nil
+}
+
 func parseNameQueryParam(c *gin.Context) (string, error) {
 	queryParam := c.Query("name")
 	if queryParam == "" {
@@ -237,3 +224,114 @@ func parseRegionStringPathParam(c *gin.Context) (string, error) {
 
 	return code, nil
 }
+
+func parseBoolQueryParam(c *gin.Context, name string) (bool, error) {
+	param := c.Query(name)
+	if param == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, domain.ErrInvalidQueryParam
+	}
+
+	return value, nil
+}
+
+func parseInt64QueryParam(c *gin.Context, name string, defaultValue int64) (int64, error) {
+	param := c.Query(name)
+	if param == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, domain.ErrInvalidQueryParam
+	}
+
+	return value, nil
+}
+
+// parseTimeQueryParam разбирает дату в формате RFC3339 или 2006-01-02. Для даты без времени при endOfDay
+// возвращается конец дня, чтобы граница периода включала весь день
+func parseTimeQueryParam(c *gin.Context, name string, endOfDay bool) (time.Time, error) {
+	param := c.Query(name)
+	if param == "" {
+		return time.Time{}, nil
+	}
+
+	if value, err := time.Parse(time.RFC3339, param); err == nil {
+		return value, nil
+	}
+
+	value, err := time.Parse(time.DateOnly, param)
+	if err != nil {
+		return time.Time{}, domain.ErrInvalidQueryParam
+	}
+
+	if endOfDay {
+		value = value.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return value, nil
+}
+
+// parseListQueryParam собирает значения повторяющегося параметра, каждое значение может содержать список через запятую
+func parseListQueryParam(c *gin.Context, name string) []string {
+	var values []string
+
+	for _, param := range c.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
+
+// parseInt64ListQueryParam разбирает список целых чисел, переданный повторяющимися параметрами или через запятую
+func parseInt64ListQueryParam(c *gin.Context, name string) ([]int64, error) {
+	var values []int64
+
+	for _, param := range parseListQueryParam(c, name) {
+		value, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, domain.ErrInvalidQueryParam
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func parseOptionalInt64QueryParam(c *gin.Context, name string) (*int64, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return nil, domain.ErrInvalidQueryParam
+	}
+
+	return &value, nil
+}
+
+func parseOptionalFloatQueryParam(c *gin.Context, name string) (*float64, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, domain.ErrInvalidQueryParam
+	}
+
+	return &value, nil
+}
@@ -0,0 +1,296 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// itemSortFields поля сортировки каталога товаров
+var itemSortFields = sortFields("id", "name", "article", "barcode", "is_active", "created_at", "updated_at")
+
+// @Summary Create item
+// @Security ApiKeyAuth
+// @Tags materials items
+// @Description Создание товара в каталоге компании
+// @ID create-item
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateItem true "Необходимо указать данные товара"
+// @Success 201 {object} domain.IdResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/items [POST]
+func (h *Handler) createItem(c *gin.Context) {
+	var inp domain.CreateItem
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.services.Items.Create(c, info, inp)
+	if err != nil {
+		itemErrorResponse(c, err)
+		return
+	}
+
+	newCreateSuccessIdResponse(c, id)
+}
+
+// @Summary Get item by id
+// @Security ApiKeyAuth
+// @Tags materials items
+// @Description Получение товара каталога по id
+// @ID get-item-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "ID товара"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/items/{id} [GET]
+func (h *Handler) getItemById(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	item, err := h.services.Items.GetById(c, info, id)
+	if err != nil {
+		itemErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       item,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Update item
+// @Security ApiKeyAuth
+// @Tags materials items
+// @Description Обновление товара каталога
+// @ID update-item
+// @Accept json
+// @Produce json
+// @Param id path int true "ID товара"
+// @Param input body domain.UpdateItem true "Необходимо указать изменяемые поля товара"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/items/{id} [PUT]
+func (h *Handler) updateItem(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.UpdateItem
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+
+	if err = h.services.Items.Update(c, info, inp); err != nil {
+		itemErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Delete item
+// @Security ApiKeyAuth
+// @Tags materials items
+// @Description Удаление товара из каталога. Товар, на который ссылаются материалы, удалить нельзя
+// @ID delete-item
+// @Accept json
+// @Produce json
+// @Param id path int true "ID товара"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/items/{id} [DELETE]
+func (h *Handler) deleteItem(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Items.Delete(c, info, id); err != nil {
+		itemErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Get items
+// @Security ApiKeyAuth
+// @Tags materials items
+// @Description Получение каталога товаров компании
+// @ID get-items
+// @Accept json
+// @Produce json
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, name, article, barcode, is_active, created_at, updated_at) default(name)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/items [GET]
+func (h *Handler) getItems(c *gin.Context) {
+	h.listItems(c, false)
+}
+
+// @Summary Search items
+// @Security ApiKeyAuth
+// @Tags materials items
+// @Description Поиск товаров каталога по наименованию, артикулу или штрихкоду
+// @ID search-items
+// @Accept json
+// @Produce json
+// @Param name query string true "Строка поиска"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, name, article, barcode, is_active, created_at, updated_at) default(name)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/items/search [GET]
+func (h *Handler) searchItems(c *gin.Context) {
+	h.listItems(c, true)
+}
+
+func (h *Handler) listItems(c *gin.Context, search bool) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, itemSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+		Cursor:    cursor,
+	}
+
+	var (
+		items []domain.Item
+		count int64
+	)
+
+	if search {
+		if params.Query, err = parseNameQueryParam(c); err != nil {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		items, count, err = h.services.Items.Search(c, info, params)
+	} else {
+		items, count, err = h.services.Items.GetList(c, info, params)
+	}
+
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       items,
+		TotalCount: count,
+		NextCursor: nextCursor(items, limit, sort, field),
+	})
+}
+
+func itemErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrItemNotFound) || errors.Is(err, domain.ErrUnitOfMeasureNotFound) ||
+		errors.Is(err, domain.ErrMaterialCategoryNotFound) || errors.Is(err, domain.ErrSupplierNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrItemAlreadyExists) || errors.Is(err, domain.ErrItemInUse) {
+		newErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrSmallName) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// materialRevisionSortFields поля сортировки истории изменений материала
+var materialRevisionSortFields = sortFields("id", "revision", "created_at")
+
+// @Summary Get planning material history
+// @Security ApiKeyAuth
+// @Tags materials planning
+// @Description Получение истории версий планируемого материала. Каждая версия содержит состояние материала до изменения
+// @ID get-planning-history
+// @Accept json
+// @Produce json
+// @Param id path int true "ID планируемого материала"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, revision, created_at) default(revision)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/{id}/history [GET]
+func (h *Handler) getPlanningHistory(c *gin.Context) {
+	h.getMaterialHistory(c, h.services.Materials.GetPlanningHistory)
+}
+
+// @Summary Get purchased material history
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Получение истории версий закупленного материала. Каждая версия содержит состояние материала до изменения
+// @ID get-purchased-history
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, revision, created_at) default(revision)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/history [GET]
+func (h *Handler) getPurchasedHistory(c *gin.Context) {
+	h.getMaterialHistory(c, h.services.Materials.GetPurchasedHistory)
+}
+
+func (h *Handler) getMaterialHistory(c *gin.Context,
+	getHistory func(ctx context.Context, id int64, info domain.JWTInfo, params domain.Param) ([]domain.MaterialRevision, int64, error)) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, materialRevisionSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	revisions, count, err := getHistory(c, id, info, domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       revisions,
+		TotalCount: count,
+	})
+}
+
+// @Summary Restore planning material revision
+// @Security ApiKeyAuth
+// @Tags materials planning
+// @Description Восстановление планируемого материала из выбранной версии.
+// @Description Текущее состояние сохраняется в историю как новая версия
+// @ID restore-planning-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "ID планируемого материала"
+// @Param rev path int true "Номер версии"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/{id}/history/{rev}/restore [POST]
+func (h *Handler) restorePlanningRevision(c *gin.Context) {
+	h.restoreMaterialRevision(c, h.services.Materials.RestorePlanningRevision)
+}
+
+// @Summary Restore purchased material revision
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Восстановление закупленного материала из выбранной версии.
+// @Description Текущее состояние сохраняется в историю как новая версия, изменение количества фиксируется корректировкой
+// @ID restore-purchased-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param rev path int true "Номер версии"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/history/{rev}/restore [POST]
+func (h *Handler) restorePurchasedRevision(c *gin.Context) {
+	h.restoreMaterialRevision(c, h.services.Materials.RestorePurchasedRevision)
+}
+
+func (h *Handler) restoreMaterialRevision(c *gin.Context,
+	restore func(ctx context.Context, id, revision int64, info domain.JWTInfo) error) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	revision, err := parseRevisionPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = restore(c, id, revision, info); err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) || errors.Is(err, domain.ErrRevisionNotFound) ||
+			errors.Is(err, domain.ErrWarehouseNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrInsufficientStock) || errors.Is(err, domain.ErrWarehouseOverCapacity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
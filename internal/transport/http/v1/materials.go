@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/rusystem/crm-api/pkg/domain"
@@ -9,6 +10,21 @@ import (
 	"time"
 )
 
+// materialSortFields поля сортировки списков материалов
+var materialSortFields = sortFields("id", "warehouse_id", "item_id", "name", "article", "product_category",
+	"total_quantity", "volume", "price_without_vat", "total_without_vat", "vat_rate", "currency", "supplier_id", "location",
+	"location_id", "status", "received_date", "last_updated", "min_stock_level", "expiration_date", "storage_cost",
+	"warehouse_section", "incoming_delivery_number", "responsible_person", "contract_number")
+
+// materialSearchSortFields поля сортировки результатов поиска материалов, rank - по релевантности
+var materialSearchSortFields = sortFields("rank", "id", "warehouse_id", "item_id", "name", "article",
+	"product_category", "total_quantity", "volume", "price_without_vat", "total_without_vat", "vat_rate", "currency",
+	"supplier_id", "location", "status", "received_date", "last_updated", "min_stock_level", "expiration_date",
+	"storage_cost", "warehouse_section", "incoming_delivery_number")
+
+// materialCategorySortFields поля сортировки списков категорий материалов
+var materialCategorySortFields = sortFields("id", "name", "slug", "created_at", "updated_at", "is_active")
+
 func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 	materials := api.Group("/materials", h.userIdentity) //todo добавить секции в мидлвейер
 	{
@@ -20,6 +36,15 @@ func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 			planning.DELETE("/:id", h.deletePlanningById)
 			planning.GET("/", h.getPlanningList)
 			planning.PUT("/move-to-purchased/:id", h.movePlanningToPurchased)
+			planning.PUT("/receive/:id", h.receivePlanning)
+			planning.GET("/:id/history", h.getPlanningHistory)
+			planning.POST("/:id/history/:rev/restore", h.restorePlanningRevision)
+			planning.POST("/bulk", h.bulkCreatePlanning)
+			planning.PUT("/bulk", h.bulkUpdatePlanning)
+			planning.POST("/bulk/delete", h.bulkDeletePlanning)
+			planning.POST("/bulk/move-to-purchased", h.bulkMovePlanningToPurchased)
+			planning.POST("/import", h.importPlanning)
+			planning.GET("/export", h.exportPlanning)
 		}
 
 		purchased := materials.Group("/purchased")
@@ -32,6 +57,29 @@ func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 			purchased.GET("/:id/qr-code", h.getPurchasedQrCode)
 			purchased.GET("/:id/barcode", h.getPurchasedBarcode)
 			purchased.PUT("/move-to-archive/:id", h.movePurchasedToArchive)
+			purchased.PUT("/transfer/:id", h.transferPurchased)
+			purchased.GET("/expiring", h.getExpiringPurchased)
+			purchased.GET("/fefo", h.getFefoSuggestion)
+			purchased.GET("/:id/movements", h.getStockMovements)
+			purchased.POST("/:id/movements", h.createStockMovement)
+			purchased.GET("/:id/movements/reconcile", h.reconcileStockMovements)
+			purchased.GET("/:id/reservations", h.getReservations)
+			purchased.POST("/:id/reservations", h.createReservation)
+			purchased.GET("/:id/history", h.getPurchasedHistory)
+			purchased.POST("/:id/history/:rev/restore", h.restorePurchasedRevision)
+			purchased.POST("/bulk", h.bulkCreatePurchased)
+			purchased.PUT("/bulk", h.bulkUpdatePurchased)
+			purchased.POST("/bulk/delete", h.bulkDeletePurchased)
+			purchased.POST("/bulk/move-to-archive", h.bulkMovePurchasedToArchive)
+			purchased.POST("/import", h.importPurchased)
+			purchased.GET("/export", h.exportPurchased)
+		}
+
+		reservations := materials.Group("/reservations")
+		{
+			reservations.GET("/:id", h.getReservationById)
+			reservations.PUT("/:id/release", h.releaseReservation)
+			reservations.PUT("/:id/consume", h.consumeReservation)
 		}
 
 		archive := materials.Group("/archive")
@@ -41,6 +89,8 @@ func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 				planning.GET("/:id", h.getPlanningArchiveById)
 				planning.GET("/", h.getPlanningArchiveList)
 				planning.DELETE("/:id", h.deletePlanningArchiveById)
+				planning.PUT("/:id/restore", h.restorePlanningArchiveById)
+				planning.GET("/export", h.exportPlanningArchive)
 			}
 
 			purchased := archive.Group("/purchased")
@@ -48,6 +98,8 @@ func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 				purchased.GET("/:id", h.getPurchasedArchiveById)
 				purchased.GET("/", h.getPurchasedArchiveList)
 				purchased.DELETE("/:id", h.deletePurchasedArchiveById)
+				purchased.PUT("/:id/restore", h.restorePurchasedArchiveById)
+				purchased.GET("/export", h.exportPurchasedArchive)
 			}
 		}
 
@@ -64,6 +116,18 @@ func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 			category.DELETE("/:id", h.deleteCategory)
 			category.GET("/", h.getCategoryList)
 			category.GET("/search", h.searchCategory)
+			category.GET("/tree", h.getCategoryTree)
+			category.PUT("/move/:id", h.moveCategory)
+		}
+
+		items := materials.Group("/items")
+		{
+			items.POST("/", h.createItem)
+			items.GET("/:id", h.getItemById)
+			items.PUT("/:id", h.updateItem)
+			items.DELETE("/:id", h.deleteItem)
+			items.GET("/", h.getItems)
+			items.GET("/search", h.searchItems)
 		}
 	}
 }
@@ -71,13 +135,14 @@ func (h *Handler) initMaterialsRoutes(api *gin.RouterGroup) {
 // @Summary Create planning material
 // @Security ApiKeyAuth
 // @Tags materials planning
-// @Description Создание планируемого материала
+// @Description Создание планируемого материала. Общая стоимость без НДС рассчитывается по цене
+// @Description и количеству, при расхождении с переданной стоимостью возвращается 422 со списком некорректных полей в data
 // @ID create-planning-material
 // @Accept json
 // @Produce json
 // @Param input body domain.CreatePlanningMaterial true "Необходимо указать данные планируемого материала"
 // @Success 200 {object} domain.SuccessResponse
-// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 400,404,422 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Failure default {object} domain.ErrorResponse
 // @Router /materials/planning [POST]
@@ -94,43 +159,27 @@ func (h *Handler) createPlanning(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.Materials.CreatePlanning(c, info, domain.Material{
-		WarehouseID:            inp.WarehouseID,
-		Name:                   inp.Name,
-		ByInvoice:              inp.ByInvoice,
-		Article:                inp.Article,
-		ProductCategory:        inp.ProductCategory,
-		Unit:                   inp.Unit,
-		TotalQuantity:          inp.TotalQuantity,
-		Volume:                 inp.Volume,
-		PriceWithoutVAT:        inp.PriceWithoutVAT,
-		TotalWithoutVAT:        inp.TotalWithoutVAT,
-		SupplierID:             inp.SupplierID,
-		ContractDate:           inp.ContractDate,
-		File:                   inp.File,
-		Status:                 inp.Status,
-		Comments:               inp.Comments,
-		Reserve:                inp.Reserve,
-		ReceivedDate:           inp.ReceivedDate,
-		LastUpdated:            time.Now().UTC(),
-		MinStockLevel:          inp.MinStockLevel,
-		ExpirationDate:         inp.ExpirationDate,
-		ResponsiblePerson:      inp.ResponsiblePerson,
-		StorageCost:            inp.StorageCost,
-		WarehouseSection:       inp.WarehouseSection,
-		IncomingDeliveryNumber: inp.IncomingDeliveryNumber,
-		OtherFields:            inp.OtherFields,
-		CompanyID:              info.CompanyId,
-		InternalName:           inp.InternalName,
-		UnitsPerPackage:        inp.UnitsPerPackage,
-		ContractNumber:         inp.ContractNumber,
-	})
+	id, err := h.services.Materials.CreatePlanning(c, info, newPlanningMaterial(inp, info.CompanyId))
 	if err != nil {
-		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			newValidationErrorResponse(c, verr)
+			return
+		}
+
+		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) ||
+			errors.Is(err, domain.ErrItemNotFound) || errors.Is(err, domain.ErrUnitOfMeasureNotFound) ||
+			errors.Is(err, domain.ErrMaterialCategoryNotFound) {
 			newErrorResponse(c, http.StatusNotFound, err.Error())
 			return
 		}
 
+		if errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrInvalidVATRate) ||
+			errors.Is(err, domain.ErrInvalidCurrency) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -189,14 +238,15 @@ func (h *Handler) getPlanningById(c *gin.Context) {
 // @Summary Update planning material
 // @Security ApiKeyAuth
 // @Tags materials planning
-// @Description Обновление планируемого материала
+// @Description Обновление планируемого материала. Общая стоимость без НДС рассчитывается по цене
+// @Description и количеству, при расхождении с переданной стоимостью возвращается 422 со списком некорректных полей в data
 // @ID update-planning-material
 // @Accept json
 // @Produce json
 // @Param id path int true "ID планируемого материала"
 // @Param input body domain.UpdatePlanningMaterial true "Необходимо указать данные планируемого материала"
 // @Success 200 {object} domain.SuccessResponse
-// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 400,404,422 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Failure default {object} domain.ErrorResponse
 // @Router /materials/planning/{id} [PUT]
@@ -222,6 +272,12 @@ func (h *Handler) updatePlanningById(c *gin.Context) {
 	inp.ID = id
 
 	if err = h.services.Materials.UpdatePlanningById(c, inp, info); err != nil {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			newValidationErrorResponse(c, verr)
+			return
+		}
+
 		if errors.Is(err, domain.ErrMaterialNotFound) {
 			newErrorResponse(c, http.StatusNotFound, err.Error())
 			return
@@ -232,7 +288,11 @@ func (h *Handler) updatePlanningById(c *gin.Context) {
 			return
 		}
 
-		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) {
+		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) ||
+			errors.Is(err, domain.ErrUnitOfMeasureNotFound) || errors.Is(err, domain.ErrInvalidQuantity) ||
+			errors.Is(err, domain.ErrMaterialCategoryNotFound) || errors.Is(err, domain.ErrLocationNotFound) ||
+			errors.Is(err, domain.ErrLocationWarehouse) || errors.Is(err, domain.ErrInvalidVATRate) ||
+			errors.Is(err, domain.ErrInvalidCurrency) {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -299,6 +359,23 @@ func (h *Handler) deletePlanningById(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, warehouse_id, item_id, name, article, product_category, total_quantity, volume, price_without_vat, total_without_vat, supplier_id, location, status, received_date, last_updated, min_stock_level, expiration_date, storage_cost, warehouse_section, incoming_delivery_number) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param supplier_id query int false "Фильтр по поставщику"
+// @Param category query []string false "Фильтр по названиям категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param received_from query string false "Дата поступления с (RFC3339 или 2006-01-02)"
+// @Param received_to query string false "Дата поступления по (RFC3339 или 2006-01-02)"
+// @Param contract_from query string false "Дата договора с (RFC3339 или 2006-01-02)"
+// @Param contract_to query string false "Дата договора по (RFC3339 или 2006-01-02)"
+// @Param expiration_from query string false "Срок годности с (RFC3339 или 2006-01-02)"
+// @Param expiration_to query string false "Срок годности по (RFC3339 или 2006-01-02)"
+// @Param quantity_min query int false "Минимальное количество"
+// @Param quantity_max query int false "Максимальное количество"
+// @Param price_min query number false "Минимальная цена без НДС"
+// @Param price_max query number false "Максимальная цена без НДС"
+// @Param other_fields query object false "Значения дополнительных полей в виде other_fields[ключ]=значение"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -311,7 +388,7 @@ func (h *Handler) getPlanningList(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -329,12 +406,26 @@ func (h *Handler) getPlanningList(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseMaterialFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	mtrls, count, err := h.services.Materials.GetPlanningList(c.Request.Context(), domain.MaterialParams{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 		CompanyId: info.CompanyId,
+		Filter:    filter,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -344,6 +435,7 @@ func (h *Handler) getPlanningList(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       mtrls,
 		TotalCount: count,
+		NextCursor: nextCursor(mtrls, limit, sort, field),
 	})
 }
 
@@ -385,6 +477,74 @@ func (h *Handler) movePlanningToPurchased(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrWarehouseOverCapacity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data: domain.PurchasedIdResponse{ID: newId, ItemId: itemId},
+	})
+}
+
+// @Summary Receive planning material
+// @Security ApiKeyAuth
+// @Tags materials planning
+// @Description Приемка планируемого материала на склад, в том числе частичная. Полностью принятый материал переносится в архив
+// @ID receive-planning
+// @Accept json
+// @Produce json
+// @Param id path int true "ID планируемого материала"
+// @Param input body domain.ReceivePlanningMaterial true "Необходимо указать данные поставки"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/receive/{id} [PUT]
+func (h *Handler) receivePlanning(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.ReceivePlanningMaterial
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+
+	newId, itemId, err := h.services.Materials.ReceivePlanning(c, inp, info)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrQuantityExceedsPlanned) ||
+			errors.Is(err, domain.ErrLocationNotFound) || errors.Is(err, domain.ErrLocationWarehouse) ||
+			errors.Is(err, domain.ErrWarehouseOverCapacity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -397,13 +557,17 @@ func (h *Handler) movePlanningToPurchased(c *gin.Context) {
 // @Summary Create purchased material
 // @Security ApiKeyAuth
 // @Tags materials purchased
-// @Description Создание закупленного материала
+// @Description Создание закупленного материала.
+// @Description Если объем материала превышает свободную вместимость склада, материал не создается, а при разрешенном
+// @Description в компании превышении (allow_over_capacity) создается с уведомлением о превышении вместимости.
+// @Description Общая стоимость без НДС рассчитывается по цене и количеству, при расхождении с переданной стоимостью
+// @Description возвращается 422 со списком некорректных полей в data
 // @ID create-purchased-material
 // @Accept json
 // @Produce json
 // @Param input body domain.CreatePurchasedMaterial true "Необходимо указать данные закупленного материала"
 // @Success 200 {object} domain.SuccessResponse
-// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 400,404,422 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Failure default {object} domain.ErrorResponse
 // @Router /materials/purchased [POST]
@@ -420,40 +584,20 @@ func (h *Handler) createPurchased(c *gin.Context) {
 		return
 	}
 
-	id, itemId, err := h.services.Materials.CreatePurchased(c, info, domain.Material{
-		WarehouseID:            inp.WarehouseID,
-		Name:                   inp.Name,
-		ByInvoice:              inp.ByInvoice,
-		Article:                inp.Article,
-		ProductCategory:        inp.ProductCategory,
-		Unit:                   inp.Unit,
-		TotalQuantity:          inp.TotalQuantity,
-		Volume:                 inp.Volume,
-		PriceWithoutVAT:        inp.PriceWithoutVAT,
-		TotalWithoutVAT:        inp.TotalWithoutVAT,
-		SupplierID:             inp.SupplierID,
-		Location:               inp.Location,
-		ContractDate:           inp.ContractDate,
-		File:                   inp.File,
-		Status:                 inp.Status,
-		Comments:               inp.Comments,
-		Reserve:                inp.Reserve,
-		ReceivedDate:           inp.ReceivedDate,
-		LastUpdated:            time.Now().UTC(),
-		MinStockLevel:          inp.MinStockLevel,
-		ExpirationDate:         inp.ExpirationDate,
-		ResponsiblePerson:      inp.ResponsiblePerson,
-		StorageCost:            inp.StorageCost,
-		WarehouseSection:       inp.WarehouseSection,
-		IncomingDeliveryNumber: inp.IncomingDeliveryNumber,
-		OtherFields:            inp.OtherFields,
-		CompanyID:              info.CompanyId,
-		InternalName:           inp.InternalName,
-		UnitsPerPackage:        inp.UnitsPerPackage,
-		ContractNumber:         inp.ContractNumber,
-	})
+	id, itemId, err := h.services.Materials.CreatePurchased(c, info, newPurchasedMaterial(inp, info.CompanyId))
 	if err != nil {
-		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			newValidationErrorResponse(c, verr)
+			return
+		}
+
+		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) ||
+			errors.Is(err, domain.ErrItemNotFound) || errors.Is(err, domain.ErrUnitOfMeasureNotFound) ||
+			errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrMaterialCategoryNotFound) ||
+			errors.Is(err, domain.ErrLocationNotFound) || errors.Is(err, domain.ErrLocationWarehouse) ||
+			errors.Is(err, domain.ErrWarehouseOverCapacity) || errors.Is(err, domain.ErrInvalidVATRate) ||
+			errors.Is(err, domain.ErrInvalidCurrency) {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -518,14 +662,15 @@ func (h *Handler) getPurchasedById(c *gin.Context) {
 // @Summary Update purchased material
 // @Security ApiKeyAuth
 // @Tags materials purchased
-// @Description Обновление закупленного материала
+// @Description Обновление закупленного материала. Общая стоимость без НДС рассчитывается по цене
+// @Description и количеству, при расхождении с переданной стоимостью возвращается 422 со списком некорректных полей в data
 // @ID update-purchased-material
 // @Accept json
 // @Produce json
 // @Param id path int true "ID закупленного материала"
 // @Param input body domain.UpdatePurchasedMaterial true "Необходимо указать данные закупленного материала"
 // @Success 200 {object} domain.SuccessResponse
-// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 400,404,422 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Failure default {object} domain.ErrorResponse
 // @Router /materials/purchased/{id} [PUT]
@@ -551,6 +696,12 @@ func (h *Handler) updatePurchasedById(c *gin.Context) {
 	inp.ID = id
 
 	if err = h.services.Materials.UpdatePurchasedById(c, inp, info); err != nil {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			newValidationErrorResponse(c, verr)
+			return
+		}
+
 		if errors.Is(err, domain.ErrMaterialNotFound) {
 			newErrorResponse(c, http.StatusNotFound, err.Error())
 			return
@@ -561,7 +712,12 @@ func (h *Handler) updatePurchasedById(c *gin.Context) {
 			return
 		}
 
-		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) {
+		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSupplierNotFound) ||
+			errors.Is(err, domain.ErrInsufficientStock) || errors.Is(err, domain.ErrUnitOfMeasureNotFound) ||
+			errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrMaterialCategoryNotFound) ||
+			errors.Is(err, domain.ErrLocationNotFound) || errors.Is(err, domain.ErrLocationWarehouse) ||
+			errors.Is(err, domain.ErrWarehouseOverCapacity) || errors.Is(err, domain.ErrInvalidVATRate) ||
+			errors.Is(err, domain.ErrInvalidCurrency) {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -628,6 +784,23 @@ func (h *Handler) deletePurchasedById(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, warehouse_id, item_id, name, article, product_category, total_quantity, volume, price_without_vat, total_without_vat, supplier_id, location, status, received_date, last_updated, min_stock_level, expiration_date, storage_cost, warehouse_section, incoming_delivery_number) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param supplier_id query int false "Фильтр по поставщику"
+// @Param category query []string false "Фильтр по названиям категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param received_from query string false "Дата поступления с (RFC3339 или 2006-01-02)"
+// @Param received_to query string false "Дата поступления по (RFC3339 или 2006-01-02)"
+// @Param contract_from query string false "Дата договора с (RFC3339 или 2006-01-02)"
+// @Param contract_to query string false "Дата договора по (RFC3339 или 2006-01-02)"
+// @Param expiration_from query string false "Срок годности с (RFC3339 или 2006-01-02)"
+// @Param expiration_to query string false "Срок годности по (RFC3339 или 2006-01-02)"
+// @Param quantity_min query int false "Минимальное количество"
+// @Param quantity_max query int false "Максимальное количество"
+// @Param price_min query number false "Минимальная цена без НДС"
+// @Param price_max query number false "Максимальная цена без НДС"
+// @Param other_fields query object false "Значения дополнительных полей в виде other_fields[ключ]=значение"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -640,7 +813,7 @@ func (h *Handler) getPurchasedList(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -658,12 +831,26 @@ func (h *Handler) getPurchasedList(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseMaterialFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	mtrls, count, err := h.services.Materials.GetPurchasedList(c.Request.Context(), domain.MaterialParams{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 		CompanyId: info.CompanyId,
+		Filter:    filter,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -673,6 +860,7 @@ func (h *Handler) getPurchasedList(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       mtrls,
 		TotalCount: count,
+		NextCursor: nextCursor(mtrls, limit, sort, field),
 	})
 }
 
@@ -833,6 +1021,73 @@ func (h *Handler) movePurchasedToArchive(c *gin.Context) {
 	newSuccessOkResponse(c)
 }
 
+// @Summary Transfer purchased material
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Перемещение закупленного материала (полностью или частично) на другой склад компании
+// @Description Перемещение сверх вместимости склада-получателя отклоняется, если компания не разрешает превышение вместимости
+// @ID transfer-purchased
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param input body domain.TransferPurchasedMaterial true "Необходимо указать склад назначения и количество"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/transfer/{id} [PUT]
+func (h *Handler) transferPurchased(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.TransferPurchasedMaterial
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+
+	newId, itemId, err := h.services.Materials.TransferPurchased(c, inp, info)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrSameWarehouse) ||
+			errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrInsufficientStock) ||
+			errors.Is(err, domain.ErrUnitOfMeasureNotFound) || errors.Is(err, domain.ErrUnitsNotConvertible) ||
+			errors.Is(err, domain.ErrFractionalQuantity) || errors.Is(err, domain.ErrUnitsPerPackageEmpty) ||
+			errors.Is(err, domain.ErrLocationNotFound) || errors.Is(err, domain.ErrLocationWarehouse) ||
+			errors.Is(err, domain.ErrWarehouseOverCapacity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data: domain.PurchasedIdResponse{ID: newId, ItemId: itemId},
+	})
+}
+
 // @Summary Get planning archive by id
 // @Security ApiKeyAuth
 // @Tags materials archive
@@ -892,6 +1147,23 @@ func (h *Handler) getPlanningArchiveById(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, warehouse_id, item_id, name, article, product_category, total_quantity, volume, price_without_vat, total_without_vat, supplier_id, location, status, received_date, last_updated, min_stock_level, expiration_date, storage_cost, warehouse_section, incoming_delivery_number) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param supplier_id query int false "Фильтр по поставщику"
+// @Param category query []string false "Фильтр по названиям категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param received_from query string false "Дата поступления с (RFC3339 или 2006-01-02)"
+// @Param received_to query string false "Дата поступления по (RFC3339 или 2006-01-02)"
+// @Param contract_from query string false "Дата договора с (RFC3339 или 2006-01-02)"
+// @Param contract_to query string false "Дата договора по (RFC3339 или 2006-01-02)"
+// @Param expiration_from query string false "Срок годности с (RFC3339 или 2006-01-02)"
+// @Param expiration_to query string false "Срок годности по (RFC3339 или 2006-01-02)"
+// @Param quantity_min query int false "Минимальное количество"
+// @Param quantity_max query int false "Максимальное количество"
+// @Param price_min query number false "Минимальная цена без НДС"
+// @Param price_max query number false "Максимальная цена без НДС"
+// @Param other_fields query object false "Значения дополнительных полей в виде other_fields[ключ]=значение"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -904,7 +1176,7 @@ func (h *Handler) getPlanningArchiveList(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -922,12 +1194,26 @@ func (h *Handler) getPlanningArchiveList(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseMaterialFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	mtrls, count, err := h.services.Materials.GetPlanningArchiveList(c.Request.Context(), domain.MaterialParams{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 		CompanyId: info.CompanyId,
+		Filter:    filter,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -937,6 +1223,7 @@ func (h *Handler) getPlanningArchiveList(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       mtrls,
 		TotalCount: count,
+		NextCursor: nextCursor(mtrls, limit, sort, field),
 	})
 }
 
@@ -1043,6 +1330,23 @@ func (h *Handler) getPurchasedArchiveById(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, warehouse_id, item_id, name, article, product_category, total_quantity, volume, price_without_vat, total_without_vat, supplier_id, location, status, received_date, last_updated, min_stock_level, expiration_date, storage_cost, warehouse_section, incoming_delivery_number) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param supplier_id query int false "Фильтр по поставщику"
+// @Param category query []string false "Фильтр по названиям категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий с учетом вложенных, материал относится хотя бы к одной из них" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param received_from query string false "Дата поступления с (RFC3339 или 2006-01-02)"
+// @Param received_to query string false "Дата поступления по (RFC3339 или 2006-01-02)"
+// @Param contract_from query string false "Дата договора с (RFC3339 или 2006-01-02)"
+// @Param contract_to query string false "Дата договора по (RFC3339 или 2006-01-02)"
+// @Param expiration_from query string false "Срок годности с (RFC3339 или 2006-01-02)"
+// @Param expiration_to query string false "Срок годности по (RFC3339 или 2006-01-02)"
+// @Param quantity_min query int false "Минимальное количество"
+// @Param quantity_max query int false "Максимальное количество"
+// @Param price_min query number false "Минимальная цена без НДС"
+// @Param price_max query number false "Максимальная цена без НДС"
+// @Param other_fields query object false "Значения дополнительных полей в виде other_fields[ключ]=значение"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -1055,7 +1359,7 @@ func (h *Handler) getPurchasedArchiveList(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -1073,12 +1377,26 @@ func (h *Handler) getPurchasedArchiveList(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, err := parseMaterialFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	mtrls, count, err := h.services.Materials.GetPurchasedArchiveList(c.Request.Context(), domain.MaterialParams{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 		CompanyId: info.CompanyId,
+		Filter:    filter,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -1088,6 +1406,7 @@ func (h *Handler) getPurchasedArchiveList(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       mtrls,
 		TotalCount: count,
+		NextCursor: nextCursor(mtrls, limit, sort, field),
 	})
 }
 
@@ -1135,18 +1454,95 @@ func (h *Handler) deletePurchasedArchiveById(c *gin.Context) {
 	newSuccessOkResponse(c)
 }
 
+// @Summary Restore planning material from archive
+// @Security ApiKeyAuth
+// @Tags materials archive
+// @Description Возврат планируемого материала из архива в активные с сохранением item_id
+// @ID restore-planning-archive
+// @Accept json
+// @Produce json
+// @Param id path int true "ID планируемого материала в архиве"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/archive/planning/{id}/restore [PUT]
+func (h *Handler) restorePlanningArchiveById(c *gin.Context) {
+	h.restoreArchiveMaterial(c, h.services.Materials.RestorePlanningArchiveById)
+}
+
+// @Summary Restore purchased material from archive
+// @Security ApiKeyAuth
+// @Tags materials archive
+// @Description Возврат закупленного материала из архива в активные с сохранением item_id.
+// @Description Остаток восстановленного материала фиксируется в журнале движений как поступление
+// @ID restore-purchased-archive
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала в архиве"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/archive/purchased/{id}/restore [PUT]
+func (h *Handler) restorePurchasedArchiveById(c *gin.Context) {
+	h.restoreArchiveMaterial(c, h.services.Materials.RestorePurchasedArchiveById)
+}
+
+func (h *Handler) restoreArchiveMaterial(c *gin.Context,
+	restore func(ctx context.Context, id int64, info domain.JWTInfo) (int64, int64, error)) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newId, itemId, err := restore(c, id, info)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) || errors.Is(err, domain.ErrWarehouseNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrWarehouseOverCapacity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data: domain.PurchasedIdResponse{ID: newId, ItemId: itemId},
+	})
+}
+
 // @Summary Search materials
 // @Security ApiKeyAuth
 // @Tags materials
-// @Description Поиск товара по наименованию
+// @Description Полнотекстовый и нечеткий поиск материалов компании по наименованию, внутреннему наименованию, артикулу,
+// @Description поставщику и номеру накладной во всех таблицах материалов. Результат содержит таблицу материала (source),
+// @Description релевантность (rank) и фрагмент с выделенными совпадениями (headline)
 // @ID search-material
 // @Accept json
 // @Produce json
 // @Param sort query string true "Sort order" Enums(asc, desc)
-// @Param sort_field query string true "Field to sort by" Enums(id, warehouse_id, item_id, name, article, product_category, total_quantity, volume, price_without_vat, total_without_vat, supplier_id, location, status, received_date, last_updated, min_stock_level, expiration_date, storage_cost, warehouse_section, incoming_delivery_number) default(name)
+// @Param sort_field query string true "Field to sort by, rank - по релевантности" Enums(rank, id, warehouse_id, item_id, name, article, product_category, total_quantity, volume, price_without_vat, total_without_vat, supplier_id, location, status, received_date, last_updated, min_stock_level, expiration_date, storage_cost, warehouse_section, incoming_delivery_number) default(rank)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
-// @Param name query string true "name query param"
+// @Param name query string true "Строка поиска, допускаются опечатки"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -1159,7 +1555,7 @@ func (h *Handler) searchMaterial(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialSearchSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -1229,6 +1625,7 @@ func (h *Handler) createCategory(c *gin.Context) {
 	}
 
 	id, err := h.services.Category.Create(c.Request.Context(), domain.MaterialCategory{
+		ParentID:    inp.ParentID,
 		Name:        inp.Name,
 		CompanyID:   info.CompanyId,
 		Description: inp.Description,
@@ -1239,6 +1636,11 @@ func (h *Handler) createCategory(c *gin.Context) {
 		ImgURL:      inp.ImgURL,
 	})
 	if err != nil {
+		if errors.Is(err, domain.ErrMaterialCategoryNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1383,6 +1785,7 @@ func (h *Handler) deleteCategory(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, name, slug, created_at, updated_at, is_active) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -1395,7 +1798,7 @@ func (h *Handler) getCategoryList(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialCategorySortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -1413,11 +1816,18 @@ func (h *Handler) getCategoryList(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	categories, count, err := h.services.Category.List(c.Request.Context(), domain.MaterialParams{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 		CompanyId: info.CompanyId,
 	})
 	if err != nil {
@@ -1428,6 +1838,7 @@ func (h *Handler) getCategoryList(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       categories,
 		TotalCount: count,
+		NextCursor: nextCursor(categories, limit, sort, field),
 	})
 }
 
@@ -1455,7 +1866,7 @@ func (h *Handler) searchCategory(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, materialCategorySortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -1497,3 +1908,154 @@ func (h *Handler) searchCategory(c *gin.Context) {
 		TotalCount: count,
 	})
 }
+
+// @Summary Get material category tree
+// @Security ApiKeyAuth
+// @Tags materials category
+// @Description Дерево категорий материалов компании
+// @ID get-material-category-tree
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/category/tree [GET]
+func (h *Handler) getCategoryTree(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tree, err := h.services.Category.Tree(c.Request.Context(), info.CompanyId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       tree,
+		TotalCount: int64(len(tree)),
+	})
+}
+
+// @Summary Move material category
+// @Security ApiKeyAuth
+// @Tags materials category
+// @Description Перенос категории материала вместе с дочерними категориями под другую категорию
+// @ID move-material-category
+// @Accept json
+// @Produce json
+// @Param id path int true "ID категории материала"
+// @Param input body domain.MoveMaterialCategory true "Необходимо указать новую родительскую категорию"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/category/move/{id} [PUT]
+func (h *Handler) moveCategory(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.MoveMaterialCategory
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+	inp.CompanyID = info.CompanyId
+
+	if err = h.services.Category.Move(c.Request.Context(), inp); err != nil {
+		if errors.Is(err, domain.ErrMaterialCategoryNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrCategoryCycle) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// parseMaterialFilter разбирает фильтры списков материалов. Категории и статусы передаются повторяющимися
+// параметрами или через запятую, дополнительные поля - в виде other_fields[ключ]=значение
+func parseMaterialFilter(c *gin.Context) (domain.MaterialFilter, error) {
+	var (
+		filter domain.MaterialFilter
+		err    error
+	)
+
+	if filter.WarehouseId, err = parseInt64QueryParam(c, "warehouse_id", 0); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	if filter.SupplierId, err = parseInt64QueryParam(c, "supplier_id", 0); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	filter.Categories = parseListQueryParam(c, "category")
+
+	if filter.CategoryIds, err = parseInt64ListQueryParam(c, "category_id"); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	filter.Statuses = parseListQueryParam(c, "status")
+
+	dates := []struct {
+		name     string
+		endOfDay bool
+		value    *time.Time
+	}{
+		{"received_from", false, &filter.ReceivedFrom},
+		{"received_to", true, &filter.ReceivedTo},
+		{"contract_from", false, &filter.ContractFrom},
+		{"contract_to", true, &filter.ContractTo},
+		{"expiration_from", false, &filter.ExpirationFrom},
+		{"expiration_to", true, &filter.ExpirationTo},
+	}
+
+	for _, date := range dates {
+		if *date.value, err = parseTimeQueryParam(c, date.name, date.endOfDay); err != nil {
+			return domain.MaterialFilter{}, err
+		}
+	}
+
+	if filter.QuantityMin, err = parseOptionalInt64QueryParam(c, "quantity_min"); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	if filter.QuantityMax, err = parseOptionalInt64QueryParam(c, "quantity_max"); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	if filter.PriceMin, err = parseOptionalFloatQueryParam(c, "price_min"); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	if filter.PriceMax, err = parseOptionalFloatQueryParam(c, "price_max"); err != nil {
+		return domain.MaterialFilter{}, err
+	}
+
+	if otherFields := c.QueryMap("other_fields"); len(otherFields) > 0 {
+		filter.OtherFields = otherFields
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,352 @@
+package v1
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+	"time"
+)
+
+// @Summary Bulk create planning materials
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное создание планируемых материалов в одной транзакции. В режиме atomic ошибка любой операции
+// @Description отменяет весь пакет, в режиме best_effort сохраняются все операции без ошибок
+// @ID bulk-create-planning-materials
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkCreatePlanningMaterials true "Необходимо указать режим и создаваемые материалы"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/bulk [POST]
+func (h *Handler) bulkCreatePlanning(c *gin.Context) {
+	var inp domain.BulkCreatePlanningMaterials
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	materials := make([]domain.Material, 0, len(inp.Items))
+	for _, item := range inp.Items {
+		materials = append(materials, newPlanningMaterial(item, info.CompanyId))
+	}
+
+	result, err := h.services.Materials.BulkCreatePlanning(c, info, inp.Mode, materials)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newBulkResultResponse(c, result)
+}
+
+// @Summary Bulk update planning materials
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное изменение планируемых материалов в одной транзакции, для каждого материала передаются
+// @Description только изменяемые поля
+// @ID bulk-update-planning-materials
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkUpdatePlanningMaterials true "Необходимо указать режим и изменения материалов"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/bulk [PUT]
+func (h *Handler) bulkUpdatePlanning(c *gin.Context) {
+	var inp domain.BulkUpdatePlanningMaterials
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]domain.UpdatePlanningMaterial, 0, len(inp.Items))
+	for _, item := range inp.Items {
+		item.UpdatePlanningMaterial.ID = item.ID
+		items = append(items, item.UpdatePlanningMaterial)
+	}
+
+	result, err := h.services.Materials.BulkUpdatePlanning(c, info, inp.Mode, items)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newBulkResultResponse(c, result)
+}
+
+// @Summary Bulk delete planning materials
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное удаление планируемых материалов в одной транзакции
+// @ID bulk-delete-planning-materials
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkMaterialIds true "Необходимо указать режим и id материалов"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/bulk/delete [POST]
+func (h *Handler) bulkDeletePlanning(c *gin.Context) {
+	h.bulkMaterialIds(c, h.services.Materials.BulkDeletePlanning)
+}
+
+// @Summary Bulk move planning materials to purchased
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное перемещение планируемых материалов в закупленные в одной транзакции. В результате
+// @Description возвращаются id созданных закупленных материалов
+// @ID bulk-move-planning-to-purchased
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkMaterialIds true "Необходимо указать режим и id материалов"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/bulk/move-to-purchased [POST]
+func (h *Handler) bulkMovePlanningToPurchased(c *gin.Context) {
+	h.bulkMaterialIds(c, h.services.Materials.BulkMovePlanningToPurchased)
+}
+
+// @Summary Bulk create purchased materials
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное создание закупленных материалов в одной транзакции. В режиме atomic ошибка любой операции
+// @Description отменяет весь пакет, в режиме best_effort сохраняются все операции без ошибок
+// @ID bulk-create-purchased-materials
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkCreatePurchasedMaterials true "Необходимо указать режим и создаваемые материалы"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/bulk [POST]
+func (h *Handler) bulkCreatePurchased(c *gin.Context) {
+	var inp domain.BulkCreatePurchasedMaterials
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	materials := make([]domain.Material, 0, len(inp.Items))
+	for _, item := range inp.Items {
+		materials = append(materials, newPurchasedMaterial(item, info.CompanyId))
+	}
+
+	result, err := h.services.Materials.BulkCreatePurchased(c, info, inp.Mode, materials)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newBulkResultResponse(c, result)
+}
+
+// @Summary Bulk update purchased materials
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное изменение закупленных материалов в одной транзакции, для каждого материала передаются
+// @Description только изменяемые поля
+// @ID bulk-update-purchased-materials
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkUpdatePurchasedMaterials true "Необходимо указать режим и изменения материалов"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/bulk [PUT]
+func (h *Handler) bulkUpdatePurchased(c *gin.Context) {
+	var inp domain.BulkUpdatePurchasedMaterials
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]domain.UpdatePurchasedMaterial, 0, len(inp.Items))
+	for _, item := range inp.Items {
+		item.UpdatePurchasedMaterial.ID = item.ID
+		items = append(items, item.UpdatePurchasedMaterial)
+	}
+
+	result, err := h.services.Materials.BulkUpdatePurchased(c, info, inp.Mode, items)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newBulkResultResponse(c, result)
+}
+
+// @Summary Bulk delete purchased materials
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное удаление закупленных материалов в одной транзакции
+// @ID bulk-delete-purchased-materials
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkMaterialIds true "Необходимо указать режим и id материалов"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/bulk/delete [POST]
+func (h *Handler) bulkDeletePurchased(c *gin.Context) {
+	h.bulkMaterialIds(c, h.services.Materials.BulkDeletePurchased)
+}
+
+// @Summary Bulk move purchased materials to archive
+// @Security ApiKeyAuth
+// @Tags materials bulk
+// @Description Пакетное перемещение закупленных материалов в архив в одной транзакции
+// @ID bulk-move-purchased-to-archive
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkMaterialIds true "Необходимо указать режим и id материалов"
+// @Success 200 {object} domain.SuccessResponse{data=domain.BulkResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/bulk/move-to-archive [POST]
+func (h *Handler) bulkMovePurchasedToArchive(c *gin.Context) {
+	h.bulkMaterialIds(c, h.services.Materials.BulkMovePurchasedToArchive)
+}
+
+func (h *Handler) bulkMaterialIds(c *gin.Context,
+	operation func(ctx context.Context, info domain.JWTInfo, mode string, ids []int64) (domain.BulkResult, error)) {
+	var inp domain.BulkMaterialIds
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result, err := operation(c, info, inp.Mode, inp.Ids)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newBulkResultResponse(c, result)
+}
+
+func newBulkResultResponse(c *gin.Context, result domain.BulkResult) {
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       result,
+		TotalCount: int64(len(result.Items)),
+	})
+}
+
+func newPlanningMaterial(inp domain.CreatePlanningMaterial, companyId int64) domain.Material {
+	return domain.Material{
+		WarehouseID:            inp.WarehouseID,
+		ItemID:                 inp.ItemID,
+		Name:                   inp.Name,
+		ByInvoice:              inp.ByInvoice,
+		Article:                inp.Article,
+		ProductCategory:        inp.ProductCategory,
+		CategoryIDs:            inp.CategoryIDs,
+		Unit:                   inp.Unit,
+		UnitID:                 inp.UnitID,
+		TotalQuantity:          inp.TotalQuantity,
+		Volume:                 inp.Volume,
+		PriceWithoutVAT:        inp.PriceWithoutVAT,
+		InputTotalWithoutVAT:   inp.TotalWithoutVAT,
+		VATRate:                inp.VATRate,
+		Currency:               inp.Currency,
+		SupplierID:             inp.SupplierID,
+		ContractDate:           inp.ContractDate,
+		File:                   inp.File,
+		Status:                 inp.Status,
+		Comments:               inp.Comments,
+		ReceivedDate:           inp.ReceivedDate,
+		LastUpdated:            time.Now().UTC(),
+		MinStockLevel:          inp.MinStockLevel,
+		ExpirationDate:         inp.ExpirationDate,
+		ResponsiblePerson:      inp.ResponsiblePerson,
+		StorageCost:            inp.StorageCost,
+		WarehouseSection:       inp.WarehouseSection,
+		IncomingDeliveryNumber: inp.IncomingDeliveryNumber,
+		OtherFields:            inp.OtherFields,
+		CompanyID:              companyId,
+		InternalName:           inp.InternalName,
+		UnitsPerPackage:        inp.UnitsPerPackage,
+		ContractNumber:         inp.ContractNumber,
+	}
+}
+
+func newPurchasedMaterial(inp domain.CreatePurchasedMaterial, companyId int64) domain.Material {
+	return domain.Material{
+		WarehouseID:            inp.WarehouseID,
+		ItemID:                 inp.ItemID,
+		Name:                   inp.Name,
+		ByInvoice:              inp.ByInvoice,
+		Article:                inp.Article,
+		ProductCategory:        inp.ProductCategory,
+		CategoryIDs:            inp.CategoryIDs,
+		Unit:                   inp.Unit,
+		UnitID:                 inp.UnitID,
+		TotalQuantity:          inp.TotalQuantity,
+		Volume:                 inp.Volume,
+		PriceWithoutVAT:        inp.PriceWithoutVAT,
+		InputTotalWithoutVAT:   inp.TotalWithoutVAT,
+		VATRate:                inp.VATRate,
+		Currency:               inp.Currency,
+		SupplierID:             inp.SupplierID,
+		Location:               inp.Location,
+		LocationID:             inp.LocationID,
+		ContractDate:           inp.ContractDate,
+		File:                   inp.File,
+		Status:                 inp.Status,
+		Comments:               inp.Comments,
+		ReceivedDate:           inp.ReceivedDate,
+		LastUpdated:            time.Now().UTC(),
+		MinStockLevel:          inp.MinStockLevel,
+		ExpirationDate:         inp.ExpirationDate,
+		ResponsiblePerson:      inp.ResponsiblePerson,
+		StorageCost:            inp.StorageCost,
+		WarehouseSection:       inp.WarehouseSection,
+		IncomingDeliveryNumber: inp.IncomingDeliveryNumber,
+		OtherFields:            inp.OtherFields,
+		CompanyID:              companyId,
+		InternalName:           inp.InternalName,
+		UnitsPerPackage:        inp.UnitsPerPackage,
+		ContractNumber:         inp.ContractNumber,
+	}
+}
@@ -0,0 +1,180 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"net/http"
+	"time"
+)
+
+// exportContentTypes типы содержимого файлов выгрузки
+var exportContentTypes = map[string]string{
+	domain.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	domain.ExportFormatCSV:  "text/csv; charset=utf-8",
+	domain.ExportFormatPDF:  "application/pdf",
+}
+
+// @Summary Export planning materials
+// @Security ApiKeyAuth
+// @Tags materials export
+// @Description Выгрузка списка планируемых материалов компании в XLSX, CSV или PDF с теми же сортировкой и фильтрами, что и список
+// @ID export-planning-materials
+// @Accept json
+// @Produce application/octet-stream
+// @Param format query string true "Формат файла" Enums(xlsx, csv, pdf)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" default(id)
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param category query []string false "Фильтр по категориям" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param currency query string false "Валюта, в которую пересчитываются цены" Enums(KZT, RUB, USD)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/export [GET]
+func (h *Handler) exportPlanning(c *gin.Context) {
+	h.exportMaterials(c, domain.MaterialTypePlanning, false)
+}
+
+// @Summary Export purchased materials
+// @Security ApiKeyAuth
+// @Tags materials export
+// @Description Выгрузка списка закупленных материалов компании в XLSX, CSV или PDF с теми же сортировкой и фильтрами, что и список
+// @ID export-purchased-materials
+// @Accept json
+// @Produce application/octet-stream
+// @Param format query string true "Формат файла" Enums(xlsx, csv, pdf)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" default(id)
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param category query []string false "Фильтр по категориям" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param currency query string false "Валюта, в которую пересчитываются цены" Enums(KZT, RUB, USD)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/export [GET]
+func (h *Handler) exportPurchased(c *gin.Context) {
+	h.exportMaterials(c, domain.MaterialTypePurchased, false)
+}
+
+// @Summary Export planning materials archive
+// @Security ApiKeyAuth
+// @Tags materials export
+// @Description Выгрузка архива планируемых материалов компании в XLSX, CSV или PDF с теми же сортировкой и фильтрами, что и список
+// @ID export-planning-materials-archive
+// @Accept json
+// @Produce application/octet-stream
+// @Param format query string true "Формат файла" Enums(xlsx, csv, pdf)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" default(id)
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param category query []string false "Фильтр по категориям" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param currency query string false "Валюта, в которую пересчитываются цены" Enums(KZT, RUB, USD)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/archive/planning/export [GET]
+func (h *Handler) exportPlanningArchive(c *gin.Context) {
+	h.exportMaterials(c, domain.MaterialTypePlanning, true)
+}
+
+// @Summary Export purchased materials archive
+// @Security ApiKeyAuth
+// @Tags materials export
+// @Description Выгрузка архива закупленных материалов компании в XLSX, CSV или PDF с теми же сортировкой и фильтрами, что и список
+// @ID export-purchased-materials-archive
+// @Accept json
+// @Produce application/octet-stream
+// @Param format query string true "Формат файла" Enums(xlsx, csv, pdf)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" default(id)
+// @Param warehouse_id query int false "Фильтр по складу"
+// @Param category query []string false "Фильтр по категориям" collectionFormat(multi)
+// @Param category_id query []int false "Фильтр по id категорий" collectionFormat(multi)
+// @Param status query []string false "Фильтр по статусам" collectionFormat(multi)
+// @Param currency query string false "Валюта, в которую пересчитываются цены" Enums(KZT, RUB, USD)
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/archive/purchased/export [GET]
+func (h *Handler) exportPurchasedArchive(c *gin.Context) {
+	h.exportMaterials(c, domain.MaterialTypePurchased, true)
+}
+
+func (h *Handler) exportMaterials(c *gin.Context, materialType string, archive bool) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	format := c.Query("format")
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidExportFormat.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, materialSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	filter, err := parseMaterialFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	name := materialType
+	if archive {
+		name += "_archive"
+	}
+
+	fileName := fmt.Sprintf("materials_%s_%s.%s", name, time.Now().Format("2006-01-02_15-04-05"), format)
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=%s", fileName))
+
+	if err = h.services.Materials.ExportMaterials(c, info, domain.MaterialExportParams{
+		MaterialType: materialType,
+		Archive:      archive,
+		Format:       format,
+		Sort:         sort,
+		SortField:    field,
+		Filter:       filter,
+		Currency:     c.Query("currency"),
+	}, c.Writer); err != nil {
+		// после начала передачи файла статус ответа уже не изменить
+		if c.Writer.Written() {
+			logger.Error(fmt.Sprintf("failed to export materials: %v", err))
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidCurrency):
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrExchangeRateNotFound):
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+	}
+}
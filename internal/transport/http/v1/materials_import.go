@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// @Summary Import planning materials
+// @Security ApiKeyAuth
+// @Tags materials import
+// @Description Импорт планируемых материалов из файла XLSX или CSV. Первая строка файла - заголовки колонок,
+// @Description колонки сопоставляются с полями материала по названию поля или через mapping. Поставщик ищется
+// @Description по наименованию или ИНН (колонка supplier), единица измерения - по аббревиатуре, категории - по названию.
+// @Description Материалы сохраняются одной транзакцией, только если ни одна строка не содержит ошибок
+// @ID import-planning-materials
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "Файл XLSX или CSV"
+// @Param mapping formData string false "Сопоставление колонок с полями материала в формате JSON, например {\"Наименование\":\"name\",\"ИНН\":\"supplier\"}"
+// @Param dry_run query bool false "Только проверка строк без сохранения"
+// @Param warehouse_id query int false "Склад для строк без указанного склада"
+// @Success 200 {object} domain.SuccessResponse{data=domain.ImportResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/planning/import [POST]
+func (h *Handler) importPlanning(c *gin.Context) {
+	h.importMaterials(c, domain.MaterialTypePlanning)
+}
+
+// @Summary Import purchased materials
+// @Security ApiKeyAuth
+// @Tags materials import
+// @Description Импорт закупленных материалов из файла XLSX или CSV. Первая строка файла - заголовки колонок,
+// @Description колонки сопоставляются с полями материала по названию поля или через mapping. Поставщик ищется
+// @Description по наименованию или ИНН (колонка supplier), единица измерения - по аббревиатуре, категории - по названию.
+// @Description Материалы сохраняются одной транзакцией, только если ни одна строка не содержит ошибок
+// @ID import-purchased-materials
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "Файл XLSX или CSV"
+// @Param mapping formData string false "Сопоставление колонок с полями материала в формате JSON, например {\"Наименование\":\"name\",\"ИНН\":\"supplier\"}"
+// @Param dry_run query bool false "Только проверка строк без сохранения"
+// @Param warehouse_id query int false "Склад для строк без указанного склада"
+// @Success 200 {object} domain.SuccessResponse{data=domain.ImportResult}
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/import [POST]
+func (h *Handler) importPurchased(c *gin.Context) {
+	h.importMaterials(c, domain.MaterialTypePurchased)
+}
+
+func (h *Handler) importMaterials(c *gin.Context, materialType string) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	dryRun, err := parseBoolQueryParam(c, "dry_run")
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	warehouseId, err := parseInt64QueryParam(c, "warehouse_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidInputBody.Error())
+		return
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err = json.Unmarshal([]byte(raw), &mapping); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, domain.ErrImportInvalidMapping.Error())
+			return
+		}
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer src.Close()
+
+	result, err := h.services.Materials.ImportMaterials(c.Request.Context(), info, materialType, src, domain.MaterialImportParams{
+		Format:      strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), "."),
+		DryRun:      dryRun,
+		WarehouseID: warehouseId,
+		Mapping:     mapping,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrImportUnsupportedFormat) || errors.Is(err, domain.ErrImportInvalidFile) ||
+			errors.Is(err, domain.ErrImportEmptyFile) || errors.Is(err, domain.ErrImportTooManyRows) ||
+			errors.Is(err, domain.ErrImportInvalidMapping) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       result,
+		TotalCount: int64(result.Total),
+	})
+}
@@ -7,6 +7,10 @@ import (
 	"net/http"
 )
 
+// unitOfMeasureSortFields поля сортировки списка единиц измерения
+var unitOfMeasureSortFields = sortFields("id", "name", "name_en", "abbreviation", "description", "base_unit_id",
+	"conversion_factor")
+
 func (h *Handler) initUnitOfMeasureRoutes(api *gin.RouterGroup) {
 	measure := api.Group("/measure", h.userIdentity)
 	{
@@ -15,6 +19,7 @@ func (h *Handler) initUnitOfMeasureRoutes(api *gin.RouterGroup) {
 		measure.PUT("/:id", h.updateMeasure)
 		measure.DELETE("/:id", h.deleteMeasure)
 		measure.GET("/", h.getMeasureList)
+		measure.GET("/convert", h.convertMeasure)
 	}
 }
 
@@ -45,13 +50,20 @@ func (h *Handler) createMeasure(c *gin.Context) {
 	}
 
 	id, err := h.services.UnitOfMeasure.Create(c.Request.Context(), domain.UnitOfMeasure{
-		Name:         inp.Name,
-		NameEn:       inp.NameEn,
-		Abbreviation: inp.Abbreviation,
-		Description:  inp.Description,
-		CompanyID:    info.CompanyId,
-	})
+		Name:             inp.Name,
+		NameEn:           inp.NameEn,
+		Abbreviation:     inp.Abbreviation,
+		Description:      inp.Description,
+		CompanyID:        info.CompanyId,
+		BaseUnitID:       inp.BaseUnitID,
+		ConversionFactor: inp.ConversionFactor,
+	}, info)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidBaseUnit) || errors.Is(err, domain.ErrInvalidQuantity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -112,7 +124,7 @@ func (h *Handler) getMeasureById(c *gin.Context) {
 // @Param id path int true "ID единицы измерения"
 // @Param input body domain.UpdateUnitOfMeasure true "Необходимо указать данные единицы измерения"
 // @Success 200 {object} domain.SuccessResponse
-// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 400,404,409 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Failure default {object} domain.ErrorResponse
 // @Router /measure/{id} [PUT]
@@ -138,12 +150,22 @@ func (h *Handler) updateMeasure(c *gin.Context) {
 	inp.ID = id
 	inp.CompanyID = info.CompanyId
 
-	if err = h.services.UnitOfMeasure.Update(c.Request.Context(), inp); err != nil {
+	if err = h.services.UnitOfMeasure.Update(c.Request.Context(), inp, info); err != nil {
 		if errors.Is(err, domain.ErrUnitOfMeasureNotFound) {
 			newErrorResponse(c, http.StatusNotFound, err.Error())
 			return
 		}
 
+		if errors.Is(err, domain.ErrInvalidBaseUnit) || errors.Is(err, domain.ErrInvalidQuantity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUnitOfMeasureInUse) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -160,7 +182,7 @@ func (h *Handler) updateMeasure(c *gin.Context) {
 // @Produce json
 // @Param id path int true "ID единицы измерения"
 // @Success 200 {object} domain.SuccessResponse
-// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 400,404,409 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Failure default {object} domain.ErrorResponse
 // @Router /measure/{id} [DELETE]
@@ -177,7 +199,17 @@ func (h *Handler) deleteMeasure(c *gin.Context) {
 		return
 	}
 
-	if err = h.services.UnitOfMeasure.Delete(c, id, info.CompanyId); err != nil {
+	if err = h.services.UnitOfMeasure.Delete(c, id, info); err != nil {
+		if errors.Is(err, domain.ErrUnitOfMeasureNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUnitOfMeasureInUse) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -193,7 +225,7 @@ func (h *Handler) deleteMeasure(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param sort query string true "Sort order" Enums(asc, desc)
-// @Param sort_field query string true "Field to sort by" Enums(id, name, name_en, abbreviation, description) default(name)
+// @Param sort_field query string true "Field to sort by" Enums(id, name, name_en, abbreviation, description, base_unit_id, conversion_factor) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
 // @Success 200 {object} domain.SuccessResponse
@@ -208,7 +240,7 @@ func (h *Handler) getMeasureList(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, unitOfMeasureSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -243,3 +275,65 @@ func (h *Handler) getMeasureList(c *gin.Context) {
 		TotalCount: count,
 	})
 }
+
+// @Summary Convert quantity between units of measure
+// @Security ApiKeyAuth
+// @Tags unit of measure
+// @Description Пересчет количества из одной единицы измерения в другую через общую базовую единицу
+// @ID convert-unit-of-measure
+// @Accept json
+// @Produce json
+// @Param quantity query number true "Количество в исходной единице измерения"
+// @Param from_unit_id query int true "ID исходной единицы измерения"
+// @Param to_unit_id query int true "ID целевой единицы измерения"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /measure/convert [GET]
+func (h *Handler) convertMeasure(c *gin.Context) {
+	quantity, err := parseOptionalFloatQueryParam(c, "quantity")
+	if err != nil || quantity == nil {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidQueryParam.Error())
+		return
+	}
+
+	fromUnitId, err := parseOptionalInt64QueryParam(c, "from_unit_id")
+	if err != nil || fromUnitId == nil {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidQueryParam.Error())
+		return
+	}
+
+	toUnitId, err := parseOptionalInt64QueryParam(c, "to_unit_id")
+	if err != nil || toUnitId == nil {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidQueryParam.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	conversion, err := h.services.UnitOfMeasure.Convert(c.Request.Context(), *quantity, *fromUnitId, *toUnitId, info.CompanyId)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnitOfMeasureNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUnitsNotConvertible) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       conversion,
+		TotalCount: 1,
+	})
+}
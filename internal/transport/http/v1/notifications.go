@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// notificationSortFields поля сортировки списка уведомлений
+var notificationSortFields = sortFields("id", "created_at")
+
+func (h *Handler) initNotificationsRoutes(api *gin.RouterGroup) {
+	notifications := api.Group("/notifications", h.userIdentity)
+	{
+		notifications.GET("/", h.getNotifications)
+		notifications.PUT("/:id/read", h.readNotification)
+		notifications.PUT("/read-all", h.readAllNotifications)
+	}
+}
+
+// @Summary Get notifications
+// @Security ApiKeyAuth
+// @Tags notifications
+// @Description Получение уведомлений текущего пользователя
+// @ID get-notifications
+// @Accept json
+// @Produce json
+// @Param unread query bool false "Только непрочитанные"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, created_at) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /notifications [GET]
+func (h *Handler) getNotifications(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	unread, err := parseBoolQueryParam(c, "unread")
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, notificationSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	notifications, count, err := h.services.Notifications.GetList(c, info, unread, domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+	})
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       notifications,
+		TotalCount: count,
+	})
+}
+
+// @Summary Read notification
+// @Security ApiKeyAuth
+// @Tags notifications
+// @Description Отметить уведомление прочитанным
+// @ID read-notification
+// @Accept json
+// @Produce json
+// @Param id path int true "ID уведомления"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /notifications/{id}/read [PUT]
+func (h *Handler) readNotification(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Notifications.MarkAsRead(c, info, id); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Read all notifications
+// @Security ApiKeyAuth
+// @Tags notifications
+// @Description Отметить все уведомления текущего пользователя прочитанными
+// @ID read-all-notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /notifications/read-all [PUT]
+func (h *Handler) readAllNotifications(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Notifications.MarkAllAsRead(c, info); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"reflect"
+)
+
+// parseCursorQueryParam разбирает курсор следующей страницы. Без курсора список работает в режиме limit/offset.
+// Курсор, выданный для другой сортировки, считается некорректным
+func parseCursorQueryParam(c *gin.Context, sort, field string) (*domain.Cursor, error) {
+	param := c.Query("cursor")
+	if param == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(param)
+	if err != nil {
+		return nil, domain.ErrInvalidCursorParam
+	}
+
+	var cursor domain.Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil || len(cursor.Value) == 0 {
+		return nil, domain.ErrInvalidCursorParam
+	}
+
+	if cursor.SortField != field || cursor.Sort != sort {
+		return nil, domain.ErrInvalidCursorParam
+	}
+
+	return &cursor, nil
+}
+
+// nextCursor возвращает курсор, указывающий на последнюю запись страницы. Для неполной страницы курсор пустой,
+// так же как и для записей без поля сортировки в JSON-представлении
+func nextCursor(items interface{}, limit int64, sort, field string) string {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice || v.Len() == 0 || int64(v.Len()) < limit {
+		return ""
+	}
+
+	data, err := json.Marshal(v.Index(v.Len() - 1).Interface())
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+
+	value, ok := fields[field]
+	if !ok {
+		return ""
+	}
+
+	cursor := domain.Cursor{SortField: field, Sort: sort, Value: value}
+	if err = json.Unmarshal(fields["id"], &cursor.ID); err != nil {
+		return ""
+	}
+
+	data, err = json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,395 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// purchaseOrderSortFields поля сортировки списка заказов поставщикам
+var purchaseOrderSortFields = sortFields("id", "status", "supplier_id", "contract_number", "vat_rate", "currency", "created_at",
+	"updated_at")
+
+func (h *Handler) initPurchaseOrderRoutes(api *gin.RouterGroup) {
+	orders := api.Group("/purchase-orders", h.userIdentity)
+	{
+		orders.POST("/", h.createPurchaseOrder)
+		orders.GET("/", h.getPurchaseOrders)
+		orders.GET("/:id", h.getPurchaseOrder)
+		orders.GET("/:id/pdf", h.getPurchaseOrderPdf)
+		orders.PUT("/:id", h.updatePurchaseOrder)
+		orders.PUT("/:id/send", h.sendPurchaseOrder)
+		orders.PUT("/:id/cancel", h.cancelPurchaseOrder)
+		orders.POST("/:id/receive", h.receivePurchaseOrder)
+	}
+}
+
+// @Summary Create purchase order
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Создание черновика заказа поставщику из планируемых материалов. Количество и цена строк
+// @Description берутся из планируемых материалов
+// @ID create-purchase-order
+// @Accept json
+// @Produce json
+// @Param input body domain.CreatePurchaseOrder true "Необходимо указать поставщика и планируемые материалы"
+// @Success 201 {object} domain.IdResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders [POST]
+func (h *Handler) createPurchaseOrder(c *gin.Context) {
+	var inp domain.CreatePurchaseOrder
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.services.PurchaseOrders.Create(c, info, inp)
+	if err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newCreateSuccessIdResponse(c, id)
+}
+
+// @Summary Get purchase orders
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Получение списка заказов поставщикам компании без строк
+// @ID get-purchase-orders
+// @Accept json
+// @Produce json
+// @Param supplier_id query int false "ID поставщика"
+// @Param status query string false "Статус заказа" Enums(draft, sent, partially_received, received, cancelled)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, status, supplier_id, created_at, updated_at) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders [GET]
+func (h *Handler) getPurchaseOrders(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	supplierId, err := parseInt64QueryParam(c, "supplier_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, purchaseOrderSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	orders, count, err := h.services.PurchaseOrders.GetList(c, info, domain.PurchaseOrderParams{
+		Limit:      limit,
+		Offset:     offset,
+		SupplierId: supplierId,
+		Status:     c.Query("status"),
+		Sort:       sort,
+		SortField:  field,
+	})
+	if err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       orders,
+		TotalCount: count,
+	})
+}
+
+// @Summary Get purchase order
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Получение заказа поставщику со строками, суммами без НДС, НДС и с НДС
+// @ID get-purchase-order
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders/{id} [GET]
+func (h *Handler) getPurchaseOrder(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	order, err := h.services.PurchaseOrders.GetById(c, info, id)
+	if err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       order,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Update purchase order
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Изменение черновика заказа поставщику. При передаче planning_material_ids строки заказа
+// @Description формируются заново
+// @ID update-purchase-order
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param input body domain.UpdatePurchaseOrder true "Изменяемые поля заказа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders/{id} [PUT]
+func (h *Handler) updatePurchaseOrder(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.UpdatePurchaseOrder
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+
+	if err = h.services.PurchaseOrders.Update(c, info, inp); err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Send purchase order
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Отметка черновика заказа отправленным поставщику. После отправки заказ не изменяется
+// @ID send-purchase-order
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders/{id}/send [PUT]
+func (h *Handler) sendPurchaseOrder(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.PurchaseOrders.Send(c, info, id); err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Cancel purchase order
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Отмена заказа поставщику, по которому еще ничего не принято
+// @ID cancel-purchase-order
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders/{id}/cancel [PUT]
+func (h *Handler) cancelPurchaseOrder(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.PurchaseOrders.Cancel(c, info, id); err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Receive purchase order
+// @Security ApiKeyAuth
+// @Tags purchase orders
+// @Description Приемка строк отправленного заказа на склад. Без указания строк принимается весь оставшийся
+// @Description объем заказа, количество 0 в строке означает весь остаток строки
+// @ID receive-purchase-order
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param input body domain.ReceivePurchaseOrder true "Принимаемые строки заказа"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /purchase-orders/{id}/receive [POST]
+func (h *Handler) receivePurchaseOrder(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.ReceivePurchaseOrder
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ids, err := h.services.PurchaseOrders.Receive(c, info, id, inp)
+	if err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       ids,
+		TotalCount: int64(len(ids)),
+	})
+}
+
+// @Summary      Purchase order pdf
+// @Security 	 ApiKeyAuth
+// @Tags         purchase orders
+// @Description  Возвращает документ заказа поставщику в PDF для отправки поставщику
+// @ID           purchase-order-pdf
+// @Accept       json
+// @Produce      application/pdf
+// @Param 		 id path int true "ID заказа"
+// @Success      200 {file} file "PDF файл заказа"
+// @Failure 	 403,404,422 {object} domain.ErrorResponse
+// @Failure 	 500 {object} domain.ErrorResponse
+// @Failure 	 default {object} domain.ErrorResponse
+// @Router       /purchase-orders/{id}/pdf [GET]
+func (h *Handler) getPurchaseOrderPdf(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	pdf, err := h.services.PurchaseOrders.GeneratePdf(c, info, id)
+	if err != nil {
+		purchaseOrderErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=purchase_order_%d.pdf", id))
+	if err = pdf.Output(c.Writer); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func purchaseOrderErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrPurchaseOrderNotFound) || errors.Is(err, domain.ErrPurchaseOrderLineNotFound) ||
+		errors.Is(err, domain.ErrSupplierNotFound) || errors.Is(err, domain.ErrMaterialNotFound) ||
+		errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrLocationNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrPurchaseOrderStatus) || errors.Is(err, domain.ErrPlanningInPurchaseOrder) {
+		newErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrPurchaseOrderSupplier) || errors.Is(err, domain.ErrQuantityExceedsPlanned) ||
+		errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrWarehouseOverCapacity) ||
+		errors.Is(err, domain.ErrLocationWarehouse) || errors.Is(err, domain.ErrInvalidVATRate) ||
+		errors.Is(err, domain.ErrPurchaseOrderCurrency) || errors.Is(err, domain.ErrPurchaseOrderEmpty) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
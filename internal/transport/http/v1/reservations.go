@@ -0,0 +1,279 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// reservationSortFields поля сортировки списка резервов
+var reservationSortFields = sortFields("id", "created_at", "updated_at", "expires_at", "quantity", "status")
+
+// @Summary Create reservation
+// @Security ApiKeyAuth
+// @Tags materials reservations
+// @Description Резервирование количества закупленного материала под заказ или пользователя
+// @ID create-reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param input body domain.CreateReservation true "Необходимо указать данные резерва"
+// @Success 200 {object} domain.IdResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/reservations [POST]
+func (h *Handler) createReservation(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.CreateReservation
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reservationId, err := h.services.Reservations.Create(c, info, id, inp)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) || errors.Is(err, domain.ErrUserNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrInsufficientStock) ||
+			errors.Is(err, domain.ErrReservationTarget) || errors.Is(err, domain.ErrReservationNotActive) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newCreateSuccessIdResponse(c, reservationId)
+}
+
+// @Summary Get reservations
+// @Security ApiKeyAuth
+// @Tags materials reservations
+// @Description Получение резервов закупленного материала
+// @ID get-reservations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, created_at, expires_at, quantity, status) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/reservations [GET]
+func (h *Handler) getReservations(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, reservationSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	reservations, count, err := h.services.Reservations.GetListByPurchasedId(c, info, id, domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       reservations,
+		TotalCount: count,
+	})
+}
+
+// @Summary Get reservation by id
+// @Security ApiKeyAuth
+// @Tags materials reservations
+// @Description Получение резерва по id
+// @ID get-reservation-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "ID резерва"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/reservations/{id} [GET]
+func (h *Handler) getReservationById(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reservation, err := h.services.Reservations.GetById(c, info, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrReservationNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       reservation,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Release reservation
+// @Security ApiKeyAuth
+// @Tags materials reservations
+// @Description Снятие резерва, количество снова становится доступным
+// @ID release-reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID резерва"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/reservations/{id}/release [PUT]
+func (h *Handler) releaseReservation(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Reservations.Release(c, info, id); err != nil {
+		h.reservationActionErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Consume reservation
+// @Security ApiKeyAuth
+// @Tags materials reservations
+// @Description Отпуск зарезервированного количества со склада с записью в журнал движений
+// @ID consume-reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID резерва"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/reservations/{id}/consume [PUT]
+func (h *Handler) consumeReservation(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Reservations.Consume(c, info, id); err != nil {
+		h.reservationActionErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+func (h *Handler) reservationActionErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrReservationNotFound) || errors.Is(err, domain.ErrMaterialNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrReservationNotActive) || errors.Is(err, domain.ErrInsufficientStock) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
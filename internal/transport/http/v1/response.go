@@ -113,6 +113,18 @@ func newBindingErrorResponse(c *gin.Context, err error) {
 	})
 }
 
+// newValidationErrorResponse отвечает 422 со списком некорректных полей запроса в data
+func newValidationErrorResponse(c *gin.Context, err *domain.ValidationError) {
+	logger.Error(err.Error())
+
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, domain.ErrorResponse{
+		Code:    http.StatusUnprocessableEntity,
+		IsError: true,
+		Data:    err.Fields,
+		Message: err.Error(),
+	})
+}
+
 func newSuccessOkResponse(c *gin.Context) {
 	c.JSON(http.StatusOK, domain.SuccessResponse{
 		Data: domain.MessageResponse{Message: "success"},
@@ -7,6 +7,9 @@ import (
 	"net/http"
 )
 
+// sectionSortFields поля сортировки списка разделов
+var sectionSortFields = sortFields("id", "name")
+
 func (h *Handler) initSectionRoutes(api *gin.RouterGroup) {
 	sections := api.Group("/sections")
 	{
@@ -82,7 +85,7 @@ func (h *Handler) getSections(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, sectionSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
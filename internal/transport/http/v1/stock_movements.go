@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// stockMovementSortFields поля сортировки журнала движений
+var stockMovementSortFields = sortFields("id", "created_at", "movement_type", "quantity")
+
+// @Summary Create stock movement
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Создание движения закупленного материала (поступление, отпуск, корректировка, списание)
+// @ID create-stock-movement
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param input body domain.CreateStockMovement true "Необходимо указать данные движения"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/movements [POST]
+func (h *Handler) createStockMovement(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.CreateStockMovement
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	movementId, err := h.services.StockMovements.Create(c, info, id, inp)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidQuantity) || errors.Is(err, domain.ErrInvalidMovementType) ||
+			errors.Is(err, domain.ErrInsufficientStock) || errors.Is(err, domain.ErrWarehouseOverCapacity) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newCreateSuccessIdResponse(c, movementId)
+}
+
+// @Summary Get stock movements
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Получение журнала движений закупленного материала
+// @ID get-stock-movements
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, created_at, movement_type, quantity) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/movements [GET]
+func (h *Handler) getStockMovements(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, stockMovementSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	movements, count, err := h.services.StockMovements.GetListByPurchasedId(c, info, id, domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       movements,
+		TotalCount: count,
+	})
+}
+
+// @Summary Reconcile stock movements
+// @Security ApiKeyAuth
+// @Tags materials purchased
+// @Description Сверка остатка закупленного материала с журналом движений
+// @ID reconcile-stock-movements
+// @Accept json
+// @Produce json
+// @Param id path int true "ID закупленного материала"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /materials/purchased/{id}/movements/reconcile [GET]
+func (h *Handler) reconcileStockMovements(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reconciliation, err := h.services.StockMovements.Reconcile(c, info, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaterialNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       reconciliation,
+		TotalCount: 1,
+	})
+}
@@ -0,0 +1,391 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// stocktakeSortFields поля сортировки списка инвентаризаций
+var stocktakeSortFields = sortFields("id", "warehouse_id", "status", "created_at", "updated_at")
+
+func (h *Handler) initStocktakeRoutes(api *gin.RouterGroup) {
+	stocktakes := api.Group("/stocktakes")
+	{
+		stocktakes.POST("/", h.userIdentity, h.createStocktake)
+		stocktakes.GET("/", h.userIdentity, h.getStocktakes)
+		stocktakes.GET("/:id", h.userIdentity, h.getStocktake)
+		stocktakes.GET("/:id/lines", h.userIdentity, h.getStocktakeLines)
+		stocktakes.POST("/:id/counts", h.userIdentity, h.submitStocktakeCounts)
+		stocktakes.GET("/:id/variance", h.userIdentity, h.getStocktakeVariance)
+
+		// only admin can approve or cancel stocktake
+		stocktakes.PUT("/:id/approve", h.adminIdentity, h.approveStocktake)
+		stocktakes.PUT("/:id/cancel", h.adminIdentity, h.cancelStocktake)
+	}
+}
+
+// @Summary Create stocktake
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Открытие инвентаризации склада. Фиксирует ожидаемые остатки закупленных материалов склада,
+// @Description списанные материалы не учитываются. На складе может быть открыта только одна инвентаризация
+// @ID create-stocktake
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateStocktake true "Необходимо указать склад"
+// @Success 201 {object} domain.IdResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes [POST]
+func (h *Handler) createStocktake(c *gin.Context) {
+	var inp domain.CreateStocktake
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.services.Stocktakes.Create(c, info, inp)
+	if err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newCreateSuccessIdResponse(c, id)
+}
+
+// @Summary Get stocktakes
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Получение списка инвентаризаций компании
+// @ID get-stocktakes
+// @Accept json
+// @Produce json
+// @Param warehouse_id query int false "ID склада"
+// @Param status query string false "Статус инвентаризации" Enums(open, approved, cancelled)
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, warehouse_id, status, created_at, updated_at) default(created_at)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes [GET]
+func (h *Handler) getStocktakes(c *gin.Context) {
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	warehouseId, err := parseInt64QueryParam(c, "warehouse_id", 0)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != domain.StocktakeOpen && status != domain.StocktakeApproved &&
+		status != domain.StocktakeCancelled {
+		newErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidQueryParam.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, stocktakeSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	stocktakes, count, err := h.services.Stocktakes.GetList(c, info, domain.StocktakeParams{
+		Limit:       limit,
+		Offset:      offset,
+		WarehouseId: warehouseId,
+		Status:      status,
+		Sort:        sort,
+		SortField:   field,
+	})
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       stocktakes,
+		TotalCount: count,
+	})
+}
+
+// @Summary Get stocktake
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Получение инвентаризации по id
+// @ID get-stocktake
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инвентаризации"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes/{id} [GET]
+func (h *Handler) getStocktake(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stocktake, err := h.services.Stocktakes.GetById(c, info, id)
+	if err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       stocktake,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Get stocktake lines
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Строки инвентаризации с ожидаемыми и посчитанными количествами
+// @ID get-stocktake-lines
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инвентаризации"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes/{id}/lines [GET]
+func (h *Handler) getStocktakeLines(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	lines, err := h.services.Stocktakes.GetLines(c, info, id)
+	if err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       lines,
+		TotalCount: int64(len(lines)),
+	})
+}
+
+// @Summary Submit stocktake counts
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Внесение подсчетов в открытую инвентаризацию. Партия указывается содержимым QR-кода закупленного
+// @Description материала или item_id, подсчет по item_id распределяется по партиям товара на складе.
+// @Description Повторный подсчет заменяет предыдущий
+// @ID submit-stocktake-counts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инвентаризации"
+// @Param input body domain.SubmitStocktakeCounts true "Необходимо указать подсчеты"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes/{id}/counts [POST]
+func (h *Handler) submitStocktakeCounts(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.SubmitStocktakeCounts
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Stocktakes.SubmitCounts(c, info, id, inp); err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Get stocktake variance report
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Отчет о расхождениях инвентаризации: строки с расхождением, непосчитанные строки,
+// @Description суммарные недостача и излишек
+// @ID get-stocktake-variance
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инвентаризации"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes/{id}/variance [GET]
+func (h *Handler) getStocktakeVariance(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	report, err := h.services.Stocktakes.Variance(c, info, id)
+	if err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       report,
+		TotalCount: int64(len(report.Lines)),
+	})
+}
+
+// @Summary Approve stocktake
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Утверждение инвентаризации администратором. Остаток каждой посчитанной партии приводится
+// @Description к посчитанному количеству корректировкой на разницу с текущим остатком, непосчитанные строки
+// @Description не меняют остаток. Инвентаризация закрывается
+// @ID approve-stocktake
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инвентаризации"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes/{id}/approve [PUT]
+func (h *Handler) approveStocktake(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Stocktakes.Approve(c, info, id); err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Cancel stocktake
+// @Security ApiKeyAuth
+// @Tags stocktakes
+// @Description Отмена инвентаризации администратором без изменения остатков
+// @ID cancel-stocktake
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инвентаризации"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403,404,409,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /stocktakes/{id}/cancel [PUT]
+func (h *Handler) cancelStocktake(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.Stocktakes.Cancel(c, info, id); err != nil {
+		stocktakeErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+func stocktakeErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrStocktakeNotFound) || errors.Is(err, domain.ErrWarehouseNotFound) ||
+		errors.Is(err, domain.ErrStocktakeLineNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrStocktakeNotOpen) || errors.Is(err, domain.ErrStocktakeAlreadyOpen) {
+		newErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrStocktakeCountTarget) || errors.Is(err, domain.ErrInsufficientStock) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
@@ -7,6 +7,11 @@ import (
 	"net/http"
 )
 
+// supplierSortFields поля сортировки списка поставщиков
+var supplierSortFields = sortFields("id", "name", "legal_address", "actual_address", "warehouse_address",
+	"contact_person", "phone", "email", "website", "contract_number", "product_categories", "purchase_amount", "balance",
+	"product_types", "country", "region", "tax_id", "registration_date", "is_active")
+
 func (h *Handler) initSupplierRoutes(api *gin.RouterGroup) {
 	spl := api.Group("/supplier")
 	{
@@ -74,6 +79,7 @@ func (h *Handler) getSupplier(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, name, legal_address, actual_address, warehouse_address, contact_person, phone, email, website, contract_number, product_categories, purchase_amount, balance, product_types, country, region, tax_id, registration_date, is_active) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -86,7 +92,7 @@ func (h *Handler) getSuppliers(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, supplierSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -104,11 +110,18 @@ func (h *Handler) getSuppliers(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	spls, count, err := h.services.Supplier.GetListByCompanyId(c, info.CompanyId, domain.Param{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -118,6 +131,7 @@ func (h *Handler) getSuppliers(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       spls,
 		TotalCount: count,
+		NextCursor: nextCursor(spls, limit, sort, field),
 	})
 }
 
@@ -173,7 +187,7 @@ func (h *Handler) createSupplier(c *gin.Context) {
 		OtherFields:       inp.OtherFields,
 		CompanyId:         info.CompanyId,
 		Locality:          inp.Locality,
-	})
+	}, info)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
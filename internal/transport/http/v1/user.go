@@ -7,6 +7,10 @@ import (
 	"net/http"
 )
 
+// userSortFields поля сортировки списков пользователей
+var userSortFields = sortFields("id", "username", "name", "email", "phone", "created_at", "updated_at", "last_login", "is_active",
+	"role", "country", "is_approved", "position")
+
 func (h *Handler) initUserRoutes(api *gin.RouterGroup) {
 	user := api.Group("/user")
 	{
@@ -286,6 +290,7 @@ func (h *Handler) deleteUser(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, username, name, email, phone, created_at, updated_at, last_login, is_active, role, country, is_approved, position) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -298,7 +303,7 @@ func (h *Handler) getUsers(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, userSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -316,11 +321,18 @@ func (h *Handler) getUsers(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	users, count, err := h.services.User.GetListByCompanyId(c, info.CompanyId, domain.Param{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -330,5 +342,6 @@ func (h *Handler) getUsers(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       users,
 		TotalCount: count,
+		NextCursor: nextCursor(users, limit, sort, field),
 	})
 }
@@ -9,17 +9,32 @@ import (
 	"time"
 )
 
+// warehouseSortFields поля сортировки списка складов
+var warehouseSortFields = sortFields("id", "name", "address", "responsible_person", "phone", "email", "max_capacity",
+	"current_occupancy", "country", "region", "created_at")
+
+// incomeHistorySortFields поля сортировки истории поступлений склада
+var incomeHistorySortFields = sortFields("received_date")
+
 func (h *Handler) initWarehouseRoutes(api *gin.RouterGroup) {
 	wh := api.Group("/warehouse")
 	{
 		wh.GET("/:id", h.userIdentity, h.getWarehouse)
 		wh.GET("/:id/income-history", h.userIdentity, h.getIncomeHistory)
+		wh.GET("/:id/goods-issues", h.userIdentity, h.getWarehouseGoodsIssues)
+		wh.GET("/:id/low-stock", h.userIdentity, h.getLowStock)
+		wh.GET("/:id/locations", h.userIdentity, h.getLocationTree)
+		wh.GET("/:id/locations/:location_id", h.userIdentity, h.getLocation)
+		wh.GET("/:id/locations/:location_id/contents", h.userIdentity, h.getLocationContents)
 		wh.GET("/", h.userIdentity, h.getWarehouses)
 
 		// only admin can create, update, delete warehouse
 		wh.POST("/", h.adminIdentity, h.createWarehouse)
 		wh.PUT("/:id", h.adminIdentity, h.updateWarehouse)
 		wh.DELETE("/:id", h.adminIdentity, h.deleteWarehouse)
+		wh.POST("/:id/locations", h.adminIdentity, h.createLocation)
+		wh.PUT("/:id/locations/:location_id", h.adminIdentity, h.updateLocation)
+		wh.DELETE("/:id/locations/:location_id", h.adminIdentity, h.deleteLocation)
 		wh.GET("/responsible-person", h.adminIdentity, h.getResponsiblePerson)
 
 		wh.GET("/report/:id/xls", h.userIdentity, h.getWarehouseInfoReportXls)
@@ -87,6 +102,7 @@ func (h *Handler) getWarehouse(c *gin.Context) {
 // @Param sort_field query string true "Field to sort by" Enums(id, name, address, responsible_person, phone, email, max_capacity, current_occupancy, country, region, created_at) default(name)
 // @Param limit query int true "limit query param"
 // @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400,404 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
@@ -99,7 +115,7 @@ func (h *Handler) getWarehouses(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, warehouseSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -117,11 +133,18 @@ func (h *Handler) getWarehouses(c *gin.Context) {
 		return
 	}
 
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	whs, count, err := h.services.Warehouse.GetListByCompanyId(c, info.CompanyId, domain.Param{
 		Limit:     limit,
 		Offset:    offset,
 		Sort:      sort,
 		SortField: field,
+		Cursor:    cursor,
 	})
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -131,6 +154,7 @@ func (h *Handler) getWarehouses(c *gin.Context) {
 	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
 		Data:       whs,
 		TotalCount: count,
+		NextCursor: nextCursor(whs, limit, sort, field),
 	})
 }
 
@@ -167,14 +191,13 @@ func (h *Handler) createWarehouse(c *gin.Context) {
 		Phone:             inp.Phone,
 		Email:             inp.Email,
 		MaxCapacity:       inp.MaxCapacity,
-		CurrentOccupancy:  inp.CurrentOccupancy,
 		OtherFields:       inp.OtherFields,
 		Country:           inp.Country,
 		Region:            inp.Region,
 		Locality:          inp.Locality,
 		Comments:          inp.Comments,
 		CompanyId:         info.CompanyId,
-	})
+	}, info)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -186,7 +209,8 @@ func (h *Handler) createWarehouse(c *gin.Context) {
 // @Summary Update warehouse
 // @Security ApiKeyAuth
 // @Tags warehouse
-// @Description Обновление склада своей компании
+// @Description Обновление склада своей компании.
+// @Description Текущая заполняемость рассчитывается по объему закупленных материалов и вручную не изменяется
 // @ID update-warehouse
 // @Accept json
 // @Produce json
@@ -303,7 +327,7 @@ func (h *Handler) getResponsiblePerson(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, userSortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -368,7 +392,7 @@ func (h *Handler) getIncomeHistory(c *gin.Context) {
 		return
 	}
 
-	sort, field, err := parseSortParam(c)
+	sort, field, err := parseSortParam(c, incomeHistorySortFields)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
 		return
@@ -404,6 +428,71 @@ func (h *Handler) getIncomeHistory(c *gin.Context) {
 	})
 }
 
+// @Summary Get warehouse low stock
+// @Security ApiKeyAuth
+// @Tags warehouse
+// @Description Получение товаров склада с остатком ниже минимального уровня запаса
+// @ID get-low-stock
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/low-stock [GET]
+func (h *Handler) getLowStock(c *gin.Context) {
+	id, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	materials, count, err := h.services.Warehouse.GetLowStock(c, id, info, domain.Param{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrWarehouseNotFound) {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       materials,
+		TotalCount: count,
+	})
+}
+
 // @Summary      Generate warehouse info xls
 // @Security 	 ApiKeyAuth
 // @Tags         warehouse
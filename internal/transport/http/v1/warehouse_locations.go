@@ -0,0 +1,321 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rusystem/crm-api/pkg/domain"
+	"net/http"
+)
+
+// @Summary Create warehouse location
+// @Security ApiKeyAuth
+// @Tags warehouse locations
+// @Description Создание места хранения на складе: зона, стеллаж в зоне, полка на стеллаже или ячейка на полке
+// @ID create-warehouse-location
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param input body domain.CreateWarehouseLocation true "Необходимо указать данные места хранения"
+// @Success 201 {object} domain.IdResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/locations [POST]
+func (h *Handler) createLocation(c *gin.Context) {
+	warehouseId, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.CreateWarehouseLocation
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.services.WarehouseLocations.Create(c, info, warehouseId, inp)
+	if err != nil {
+		locationErrorResponse(c, err)
+		return
+	}
+
+	newCreateSuccessIdResponse(c, id)
+}
+
+// @Summary Get warehouse location
+// @Security ApiKeyAuth
+// @Tags warehouse locations
+// @Description Получение места хранения склада. Заполненность включает материалы вложенных мест хранения
+// @ID get-warehouse-location
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param location_id path int true "ID места хранения"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/locations/{location_id} [GET]
+func (h *Handler) getLocation(c *gin.Context) {
+	warehouseId, id, err := parseLocationPathParams(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	location, err := h.services.WarehouseLocations.GetById(c, info, warehouseId, id)
+	if err != nil {
+		locationErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       location,
+		TotalCount: 1,
+	})
+}
+
+// @Summary Get warehouse location tree
+// @Security ApiKeyAuth
+// @Tags warehouse locations
+// @Description Дерево мест хранения склада: зоны, стеллажи, полки и ячейки с вместимостью и заполненностью
+// @ID get-warehouse-location-tree
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/locations [GET]
+func (h *Handler) getLocationTree(c *gin.Context) {
+	warehouseId, err := parseIdIntPathParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tree, err := h.services.WarehouseLocations.Tree(c, info, warehouseId)
+	if err != nil {
+		locationErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       tree,
+		TotalCount: int64(len(tree)),
+	})
+}
+
+// @Summary Update warehouse location
+// @Security ApiKeyAuth
+// @Tags warehouse locations
+// @Description Обновление места хранения склада
+// @ID update-warehouse-location
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param location_id path int true "ID места хранения"
+// @Param input body domain.UpdateWarehouseLocation true "Необходимо указать изменяемые поля места хранения"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/locations/{location_id} [PUT]
+func (h *Handler) updateLocation(c *gin.Context) {
+	warehouseId, id, err := parseLocationPathParams(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var inp domain.UpdateWarehouseLocation
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		newBindingErrorResponse(c, err)
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inp.ID = id
+	inp.WarehouseID = warehouseId
+
+	if err = h.services.WarehouseLocations.Update(c, info, inp); err != nil {
+		locationErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Delete warehouse location
+// @Security ApiKeyAuth
+// @Tags warehouse locations
+// @Description Удаление места хранения склада. Место хранения с вложенными местами хранения или материалами удалить нельзя
+// @ID delete-warehouse-location
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param location_id path int true "ID места хранения"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/locations/{location_id} [DELETE]
+func (h *Handler) deleteLocation(c *gin.Context) {
+	warehouseId, id, err := parseLocationPathParams(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = h.services.WarehouseLocations.Delete(c, info, warehouseId, id); err != nil {
+		locationErrorResponse(c, err)
+		return
+	}
+
+	newSuccessOkResponse(c)
+}
+
+// @Summary Get warehouse location contents
+// @Security ApiKeyAuth
+// @Tags warehouse locations
+// @Description Закупленные материалы, размещенные в месте хранения и во вложенных в него местах хранения
+// @ID get-warehouse-location-contents
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param location_id path int true "ID места хранения"
+// @Param sort query string true "Sort order" Enums(asc, desc)
+// @Param sort_field query string true "Field to sort by" Enums(id, name, article, total_quantity, volume, received_date, expiration_date) default(name)
+// @Param limit query int true "limit query param"
+// @Param offset query int true "offset query param"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor, при его передаче offset не учитывается, а total_count не подсчитывается"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400,403,404,422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure default {object} domain.ErrorResponse
+// @Router /warehouse/{id}/locations/{location_id}/contents [GET]
+func (h *Handler) getLocationContents(c *gin.Context) {
+	warehouseId, id, err := parseLocationPathParams(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	info, err := getUserInfo(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort, field, err := parseSortParam(c, materialSortFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	limit, err := parseLimitQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	offset, err := parseOffsetQueryParam(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	cursor, err := parseCursorQueryParam(c, sort, field)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	materials, count, err := h.services.WarehouseLocations.GetContents(c, info, warehouseId, id, domain.Param{
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sort,
+		SortField: field,
+		Cursor:    cursor,
+	})
+	if err != nil {
+		locationErrorResponse(c, err)
+		return
+	}
+
+	newSuccessResponse(c, http.StatusOK, domain.SuccessResponse{
+		Data:       materials,
+		TotalCount: count,
+		NextCursor: nextCursor(materials, limit, sort, field),
+	})
+}
+
+func parseLocationPathParams(c *gin.Context) (int64, int64, error) {
+	warehouseId, err := parseIdIntPathParam(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	id, err := parseLocationIdPathParam(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return warehouseId, id, nil
+}
+
+func locationErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrWarehouseNotFound) || errors.Is(err, domain.ErrLocationNotFound) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrNotAllowed) {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrLocationAlreadyExists) || errors.Is(err, domain.ErrLocationInUse) {
+		newErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidLocationType) || errors.Is(err, domain.ErrInvalidLocationParent) ||
+		errors.Is(err, domain.ErrLocationCodeEmpty) || errors.Is(err, domain.ErrInvalidQuantity) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
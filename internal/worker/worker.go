@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"github.com/rusystem/crm-api/pkg/logger"
+	"time"
+)
+
+// Periodic запускает job с интервалом interval до отмены ctx. Ошибки задачи логируются и не прерывают работу
+func Periodic(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		logger.Info(fmt.Sprintf("worker %s is disabled", name))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Info(fmt.Sprintf("worker %s started", name))
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(fmt.Sprintf("worker %s stopped", name))
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				logger.Error(fmt.Sprintf("worker %s failed: %v", name, err))
+			}
+		}
+	}
+}
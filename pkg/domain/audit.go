@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// Действия, фиксируемые в журнале аудита
+const (
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"
+	AuditActionMove     = "move"
+	AuditActionTransfer = "transfer"
+	AuditActionReceive  = "receive"
+	AuditActionRestore  = "restore"
+	AuditActionApprove  = "approve"
+	AuditActionCancel   = "cancel"
+	AuditActionPost     = "post"
+	AuditActionSend     = "send"
+)
+
+// Типы сущностей журнала аудита
+const (
+	AuditEntityPlanningMaterial         = "planning_material"
+	AuditEntityPurchasedMaterial        = "purchased_material"
+	AuditEntityPlanningMaterialArchive  = "planning_material_archive"
+	AuditEntityPurchasedMaterialArchive = "purchased_material_archive"
+	AuditEntityWarehouse                = "warehouse"
+	AuditEntitySupplier                 = "supplier"
+	AuditEntityUser                     = "user"
+	AuditEntityCompany                  = "company"
+	AuditEntityUnitOfMeasure            = "unit_of_measure"
+	AuditEntityItem                     = "item"
+	AuditEntityWarehouseLocation        = "warehouse_location"
+	AuditEntityStocktake                = "stocktake"
+	AuditEntityGoodsIssue               = "goods_issue"
+	AuditEntityPurchaseOrder            = "purchase_order"
+	AuditEntityExchangeRate             = "exchange_rate"
+)
+
+// AuditChange представляет изменение одного поля сущности
+type AuditChange struct {
+	Before interface{} `json:"before"` // Значение до изменения
+	After  interface{} `json:"after"`  // Значение после изменения
+}
+
+// AuditLog представляет запись журнала аудита
+type AuditLog struct {
+	ID         int64                  `json:"id"`          // Уникальный идентификатор записи
+	UserID     int64                  `json:"user_id"`     // Пользователь, выполнивший действие
+	CompanyID  int64                  `json:"company_id"`  // Кабинет компании пользователя
+	EntityType string                 `json:"entity_type"` // Тип сущности
+	EntityID   int64                  `json:"entity_id"`   // ID сущности
+	Action     string                 `json:"action"`      // Действие
+	Changes    map[string]AuditChange `json:"changes"`     // Измененные поля
+	CreatedAt  time.Time              `json:"created_at"`  // Дата действия
+}
+
+// AuditParams представляет фильтры журнала аудита
+type AuditParams struct {
+	Limit      int64
+	Offset     int64
+	CompanyId  int64
+	UserId     int64
+	EntityType string
+	EntityId   int64
+	From       time.Time
+	To         time.Time
+	Sort       string
+	SortField  string
+}
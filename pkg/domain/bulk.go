@@ -0,0 +1,75 @@
+package domain
+
+// Режимы выполнения пакетных операций
+const (
+	BulkModeAtomic     = "atomic"      // Все или ничего: первая ошибка откатывает весь пакет
+	BulkModeBestEffort = "best_effort" // Ошибка откатывает только свою операцию, остальные сохраняются
+)
+
+// Статусы операций пакета
+const (
+	BulkItemSucceeded = "succeeded" // Операция выполнена
+	BulkItemFailed    = "failed"    // Операция завершилась ошибкой
+	BulkItemSkipped   = "skipped"   // Операция не выполнена или откатана из-за ошибки другой операции
+)
+
+// BulkCreatePlanningMaterials представляет пакетное создание планируемых материалов
+type BulkCreatePlanningMaterials struct {
+	Mode  string                   `json:"mode" binding:"omitempty,oneof=atomic best_effort" example:"atomic"` // Режим выполнения, по умолчанию atomic
+	Items []CreatePlanningMaterial `json:"items" binding:"required,min=1,max=500,dive"`                        // Создаваемые материалы
+}
+
+// BulkCreatePurchasedMaterials представляет пакетное создание закупленных материалов
+type BulkCreatePurchasedMaterials struct {
+	Mode  string                    `json:"mode" binding:"omitempty,oneof=atomic best_effort" example:"atomic"` // Режим выполнения, по умолчанию atomic
+	Items []CreatePurchasedMaterial `json:"items" binding:"required,min=1,max=500,dive"`                        // Создаваемые материалы
+}
+
+// BulkUpdatePlanningItem представляет изменение одного планируемого материала в пакете
+type BulkUpdatePlanningItem struct {
+	ID int64 `json:"id" binding:"required" example:"1"` // ID планируемого материала
+	UpdatePlanningMaterial
+}
+
+// BulkUpdatePlanningMaterials представляет пакетное изменение планируемых материалов
+type BulkUpdatePlanningMaterials struct {
+	Mode  string                   `json:"mode" binding:"omitempty,oneof=atomic best_effort" example:"atomic"` // Режим выполнения, по умолчанию atomic
+	Items []BulkUpdatePlanningItem `json:"items" binding:"required,min=1,max=500,dive"`                        // Изменения материалов, передаются только изменяемые поля
+}
+
+// BulkUpdatePurchasedItem представляет изменение одного закупленного материала в пакете
+type BulkUpdatePurchasedItem struct {
+	ID int64 `json:"id" binding:"required" example:"1"` // ID закупленного материала
+	UpdatePurchasedMaterial
+}
+
+// BulkUpdatePurchasedMaterials представляет пакетное изменение закупленных материалов
+type BulkUpdatePurchasedMaterials struct {
+	Mode  string                    `json:"mode" binding:"omitempty,oneof=atomic best_effort" example:"atomic"` // Режим выполнения, по умолчанию atomic
+	Items []BulkUpdatePurchasedItem `json:"items" binding:"required,min=1,max=500,dive"`                        // Изменения материалов, передаются только изменяемые поля
+}
+
+// BulkMaterialIds представляет пакетную операцию над материалами по списку id (удаление, перемещение)
+type BulkMaterialIds struct {
+	Mode string  `json:"mode" binding:"omitempty,oneof=atomic best_effort" example:"atomic"` // Режим выполнения, по умолчанию atomic
+	Ids  []int64 `json:"ids" binding:"required,min=1,max=500,dive,min=1"`                    // ID материалов
+}
+
+// BulkItemResult представляет результат одной операции пакета
+type BulkItemResult struct {
+	Index  int          `json:"index"`             // Порядковый номер операции в запросе, начиная с 0
+	ID     int64        `json:"id,omitempty"`      // ID материала, для создания и перемещения - ID новой строки
+	ItemID int64        `json:"item_id,omitempty"` // Идентификатор товара
+	Status string       `json:"status"`            // succeeded, failed, skipped
+	Error  string       `json:"error,omitempty"`   // Текст ошибки
+	Fields []FieldError `json:"fields,omitempty"`  // Некорректные поля операции
+}
+
+// BulkResult представляет результат пакетной операции
+type BulkResult struct {
+	Mode      string           `json:"mode"`      // Режим выполнения
+	Committed bool             `json:"committed"` // Изменения пакета сохранены
+	Succeeded int              `json:"succeeded"` // Количество выполненных операций
+	Failed    int              `json:"failed"`    // Количество операций с ошибкой
+	Items     []BulkItemResult `json:"items"`     // Результаты по операциям в порядке запроса
+}
@@ -19,8 +19,24 @@ var (
 	ErrMaterialNotFound         = errors.New("material doesn`t exists")
 	ErrMaterialCategoryNotFound = errors.New("material category doesn`t exists")
 	ErrUnitOfMeasureNotFound    = errors.New("unit of measure doesn`t exists")
+	ErrItemNotFound             = errors.New("item doesn`t exists")
+	ErrLocationNotFound         = errors.New("warehouse location doesn`t exists")
+	ErrStocktakeNotFound        = errors.New("stocktake doesn`t exists")
+	ErrStocktakeLineNotFound    = errors.New("stocktake has no line for counted material")
+	ErrGoodsIssueNotFound       = errors.New("goods issue doesn`t exists")
+	ErrPurchaseOrderNotFound    = errors.New("purchase order doesn`t exists")
+	ErrExchangeRateNotFound     = errors.New("exchange rate doesn`t exists")
 
 	ErrUserAlreadyExists = errors.New("user with such username or email already exists")
+	ErrItemAlreadyExists = errors.New("item with such article or name already exists")
+	ErrItemInUse         = errors.New("item is used by materials")
+
+	ErrLocationAlreadyExists = errors.New("warehouse location with such code already exists")
+	ErrLocationInUse         = errors.New("warehouse location has child locations or materials")
+	ErrInvalidLocationType   = errors.New("invalid warehouse location type")
+	ErrInvalidLocationParent = errors.New("parent location must be one level higher in the same warehouse")
+	ErrLocationWarehouse     = errors.New("location belongs to another warehouse")
+	ErrLocationCodeEmpty     = errors.New("location code cannot be empty")
 
 	ErrGeneratePassword = errors.New("can`t to generate new password for user")
 	ErrGenerateUUID     = errors.New("can`t to generate uuid")
@@ -33,7 +49,10 @@ var (
 	ErrInvalidSortFieldParam   = errors.New("invalid Sort field param")
 	ErrInvalidQueryParam       = errors.New("invalid query param")
 	ErrInvalidOffsetParam      = errors.New("invalid offset param")
+	ErrInvalidCursorParam      = errors.New("invalid cursor param")
 	ErrInvalidIdParam          = errors.New("invalid id param")
+	ErrInvalidRevisionParam    = errors.New("invalid revision param")
+	ErrInvalidLocationIdParam  = errors.New("invalid location id param")
 	ErrInvalidCountryCodeParam = errors.New("invalid country code param")
 	ErrInvalidAdminCodeParam   = errors.New("invalid admin code param")
 	ErrInvalidEmailParam       = errors.New("invalid email")
@@ -43,6 +62,60 @@ var (
 	ErrRefreshTokenNotFound    = errors.New("refresh token not found")
 	ErrExpiredRefreshToken     = errors.New("refresh token expired")
 	ErrInvalidTimezone         = errors.New("invalid timezone")
+	ErrInvalidQuantity         = errors.New("invalid quantity")
+	ErrInvalidMovementType     = errors.New("invalid movement type")
+	ErrInvalidCurrency         = errors.New("invalid currency, KZT, RUB or USD expected")
+	ErrInvalidVATRate          = errors.New("vat rate must be between 0 and 100")
+	ErrInvalidExchangeRate     = errors.New("exchange rate must be greater than zero")
+
+	ErrInsufficientStock     = errors.New("not enough quantity in stock")
+	ErrSameWarehouse         = errors.New("source and target warehouses are the same")
+	ErrWarehouseOverCapacity = errors.New("placement exceeds warehouse max capacity")
+
+	ErrQuantityExceedsPlanned = errors.New("received quantity exceeds remaining planned quantity")
+	ErrRevisionNotFound       = errors.New("material revision doesn`t exists")
+
+	ErrReservationNotFound  = errors.New("reservation doesn`t exists")
+	ErrReservationNotActive = errors.New("reservation is not active")
+	ErrReservationTarget    = errors.New("order reference or user is required for reservation")
+
+	ErrNotificationNotFound = errors.New("notification doesn`t exists")
+
+	ErrStocktakeNotOpen     = errors.New("stocktake is not open")
+	ErrStocktakeAlreadyOpen = errors.New("warehouse already has an open stocktake")
+	ErrStocktakeCountTarget = errors.New("item_id or code is required")
+
+	ErrGoodsIssueNotDraft          = errors.New("goods issue is already posted")
+	ErrGoodsIssueMaterialWarehouse = errors.New("material is stored in another warehouse")
+
+	ErrPurchaseOrderLineNotFound = errors.New("purchase order line doesn`t exists")
+	ErrPurchaseOrderStatus       = errors.New("operation is not allowed in current purchase order status")
+	ErrPurchaseOrderSupplier     = errors.New("planning material has another supplier")
+	ErrPlanningInPurchaseOrder   = errors.New("planning material is already included in another purchase order")
+	ErrPurchaseOrderCurrency     = errors.New("planning materials of purchase order have different currencies")
+	ErrPurchaseOrderEmpty        = errors.New("purchase order must contain at least one planning material")
+
+	ErrExchangeRateAlreadyExists = errors.New("exchange rate for these currencies and date already exists")
+	ErrSameCurrency              = errors.New("source and target currencies are the same")
+
+	ErrFefoTarget = errors.New("item_id or article is required")
+
+	ErrInvalidBaseUnit      = errors.New("base unit must be a base unit of the same company")
+	ErrUnitOfMeasureInUse   = errors.New("unit of measure is a base unit for other units")
+	ErrUnitsNotConvertible  = errors.New("units of measure have different base units")
+	ErrFractionalQuantity   = errors.New("quantity doesn't convert to a whole number of material units")
+	ErrUnitsPerPackageEmpty = errors.New("units_per_package of material is not set")
+
+	ErrCategoryCycle = errors.New("category can't be moved into its own subtree")
+
+	ErrImportUnsupportedFormat = errors.New("unsupported import file format, xlsx or csv expected")
+	ErrImportEmptyFile         = errors.New("import file has no data rows")
+	ErrImportTooManyRows       = errors.New("too many rows in import file")
+	ErrImportInvalidMapping    = errors.New("invalid import column mapping")
+	ErrImportInvalidFile       = errors.New("invalid import file")
+	ErrImportInvalidValue      = errors.New("invalid value")
+
+	ErrInvalidExportFormat = errors.New("invalid export format, xlsx, csv or pdf expected")
 
 	ErrCreateUser    = errors.New("can`t to create new user")
 	ErrCreateCompany = errors.New("can`t to create new company")
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// ExchangeRate представляет курс валюты компании на дату: 1 единица FromCurrency стоит Rate единиц ToCurrency
+type ExchangeRate struct {
+	ID           int64           `json:"id"`
+	CompanyID    int64           `json:"company_id"`
+	FromCurrency string          `json:"from_currency"`
+	ToCurrency   string          `json:"to_currency"`
+	Rate         decimal.Decimal `json:"rate" swaggertype:"number"`
+	RateDate     time.Time       `json:"rate_date"`
+	CreatedBy    int64           `json:"created_by"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type CreateExchangeRate struct {
+	FromCurrency string          `json:"from_currency" binding:"required" example:"USD"` // Исходная валюта
+	ToCurrency   string          `json:"to_currency" binding:"required" example:"KZT"`   // Целевая валюта
+	Rate         decimal.Decimal `json:"rate" swaggertype:"number" example:"495.5"`      // Стоимость единицы исходной валюты в целевой
+	RateDate     *time.Time      `json:"rate_date" example:"2024-08-15T00:00:00Z"`       // Дата курса, если не указана - текущая дата
+}
+
+type UpdateExchangeRate struct {
+	ID       int64            `json:"-"`
+	Rate     *decimal.Decimal `json:"rate" swaggertype:"number" example:"495.5"` // Стоимость единицы исходной валюты в целевой
+	RateDate *time.Time       `json:"rate_date" example:"2024-08-15T00:00:00Z"`  // Дата курса
+}
+
+// ExchangeRateParams параметры списка курсов валют
+type ExchangeRateParams struct {
+	Limit        int64
+	Offset       int64
+	CompanyId    int64
+	FromCurrency string
+	ToCurrency   string
+	Sort         string
+	SortField    string
+}
+
+// CurrencyConversion представляет результат пересчета суммы из одной валюты в другую
+type CurrencyConversion struct {
+	Amount       decimal.Decimal `json:"amount" swaggertype:"number" example:"100"`   // Исходная сумма
+	FromCurrency string          `json:"from_currency" example:"USD"`                 // Исходная валюта
+	ToCurrency   string          `json:"to_currency" example:"KZT"`                   // Целевая валюта
+	Rate         decimal.Decimal `json:"rate" swaggertype:"number" example:"495.5"`   // Примененный курс
+	Result       decimal.Decimal `json:"result" swaggertype:"number" example:"49550"` // Сумма в целевой валюте
+	Date         time.Time       `json:"date" example:"2024-08-15T00:00:00Z"`         // Дата, на которую взят курс
+}
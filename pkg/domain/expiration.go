@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// MaterialStatusWrittenOff статус партии, списанной по истечении срока годности
+const MaterialStatusWrittenOff = "written_off"
+
+// ExpiringLot представляет партию закупленного материала с истекающим сроком годности
+type ExpiringLot struct {
+	PurchasedMaterialID int64     `json:"purchased_material_id"` // ID закупленного материала
+	ItemID              int64     `json:"item_id"`               // Идентификатор товара
+	Name                string    `json:"name"`                  // Наименование материала
+	Article             string    `json:"article"`               // Артикул материала
+	Unit                string    `json:"unit"`                  // Единица измерения
+	TotalQuantity       int64     `json:"total_quantity"`        // Остаток партии
+	ReservedQuantity    int64     `json:"reserved_quantity"`     // Количество в активных резервах
+	ExpirationDate      time.Time `json:"expiration_date"`       // Срок годности
+	DaysLeft            int64     `json:"days_left"`             // Дней до истечения срока, отрицательное для просроченных
+	IsExpired           bool      `json:"is_expired"`            // Срок годности истек
+	Status              string    `json:"status"`                // Статус товара
+}
+
+// ExpiringWarehouse представляет партии с истекающим сроком годности на одном складе
+type ExpiringWarehouse struct {
+	WarehouseID   int64         `json:"warehouse_id"`   // Склад(место хранения) id
+	WarehouseName string        `json:"warehouse_name"` // Название склада
+	Lots          []ExpiringLot `json:"lots"`           // Партии, отсортированные по сроку годности
+}
+
+// FefoParams представляет запрос на подбор партий по принципу FEFO (first expired, first out)
+type FefoParams struct {
+	ItemID      int64  // Идентификатор товара
+	Article     string // Артикул материала, если не указан item_id
+	Quantity    int64  // Требуемое количество
+	WarehouseID int64  // Склад, если подбор нужен только с одного склада
+	CompanyID   int64
+}
+
+// FefoPick представляет партию и количество, которое нужно из нее взять
+type FefoPick struct {
+	PurchasedMaterialID int64     `json:"purchased_material_id"` // ID закупленного материала
+	WarehouseID         int64     `json:"warehouse_id"`          // Склад(место хранения) id
+	Location            string    `json:"location"`              // Локация на складе
+	WarehouseSection    string    `json:"warehouse_section"`     // Секция хранения
+	ExpirationDate      time.Time `json:"expiration_date"`       // Срок годности партии
+	AvailableQuantity   int64     `json:"available_quantity"`    // Доступное количество в партии
+	Quantity            int64     `json:"quantity"`              // Количество к отбору
+}
+
+// FefoSuggestion представляет результат подбора партий
+type FefoSuggestion struct {
+	RequestedQuantity int64      `json:"requested_quantity"` // Требуемое количество
+	AllocatedQuantity int64      `json:"allocated_quantity"` // Подобранное количество
+	Shortage          int64      `json:"shortage"`           // Недостающее количество
+	Picks             []FefoPick `json:"picks"`              // Партии в порядке отбора
+}
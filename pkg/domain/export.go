@@ -0,0 +1,22 @@
+package domain
+
+// Форматы выгрузки списков материалов
+const (
+	ExportFormatXLSX = "xlsx"
+	ExportFormatCSV  = "csv"
+	ExportFormatPDF  = "pdf"
+)
+
+// MaxPdfExportRows максимальное количество строк в PDF выгрузке, остальные строки доступны в XLSX и CSV
+const MaxPdfExportRows = 5000
+
+// MaterialExportParams представляет параметры выгрузки списка материалов
+type MaterialExportParams struct {
+	MaterialType string // planning или purchased
+	Archive      bool   // Выгрузка из архива
+	Format       string // xlsx, csv или pdf
+	Sort         string
+	SortField    string
+	Filter       MaterialFilter
+	Currency     string // Валюта, в которую пересчитываются цены, если не указана - цены выгружаются в валюте материалов
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Статусы документа отпуска материалов
+const (
+	GoodsIssueDraft  = "draft"  // Черновик, остатки не изменены
+	GoodsIssuePosted = "posted" // Проведен, материалы списаны со склада
+)
+
+// GoodsIssue представляет документ отпуска материалов со склада в производство (требование-накладная)
+type GoodsIssue struct {
+	ID                int64            `json:"id"`                  // Уникальный идентификатор документа
+	WarehouseID       int64            `json:"warehouse_id"`        // Склад, с которого отпускаются материалы
+	CompanyID         int64            `json:"company_id"`          // Кабинет компании
+	ResponsibleUserID int64            `json:"responsible_user_id"` // Пользователь, получающий материалы
+	Status            string           `json:"status"`              // Статус документа
+	Comment           string           `json:"comment"`             // Назначение отпуска, комментарий
+	CreatedBy         int64            `json:"created_by"`          // Пользователь, создавший документ
+	PostedBy          int64            `json:"posted_by"`           // Пользователь, проведший документ
+	CreatedAt         time.Time        `json:"created_at"`          // Дата создания
+	UpdatedAt         time.Time        `json:"updated_at"`          // Дата последнего изменения
+	PostedAt          *time.Time       `json:"posted_at"`           // Дата проведения
+	Lines             []GoodsIssueLine `json:"lines,omitempty"`     // Строки документа
+}
+
+// GoodsIssueLine представляет строку документа отпуска: партию закупленного материала и отпускаемое количество
+type GoodsIssueLine struct {
+	ID                  int64  `json:"id"`                    // Уникальный идентификатор строки
+	GoodsIssueID        int64  `json:"goods_issue_id"`        // ID документа
+	PurchasedMaterialID int64  `json:"purchased_material_id"` // ID закупленного материала
+	ItemID              int64  `json:"item_id"`               // Идентификатор товара
+	Name                string `json:"name"`                  // Наименование материала
+	Article             string `json:"article"`               // Артикул
+	Unit                string `json:"unit"`                  // Единица измерения
+	Quantity            int64  `json:"quantity"`              // Отпускаемое количество
+	Comment             string `json:"comment"`               // Комментарий
+}
+
+// CreateGoodsIssue представляет структуру создания документа отпуска материалов
+type CreateGoodsIssue struct {
+	WarehouseID       int64                  `json:"warehouse_id" binding:"required" example:"1"` // Склад
+	ResponsibleUserID int64                  `json:"responsible_user_id" example:"1"`             // Получатель материалов, по умолчанию - создатель документа
+	Comment           string                 `json:"comment" example:"Заказ 123, участок сборки"` // Назначение отпуска
+	Lines             []CreateGoodsIssueLine `json:"lines" binding:"required,min=1,dive"`         // Строки документа
+}
+
+// CreateGoodsIssueLine представляет строку создаваемого документа отпуска
+type CreateGoodsIssueLine struct {
+	PurchasedMaterialID int64  `json:"purchased_material_id" binding:"required" example:"1"` // ID закупленного материала
+	Quantity            int64  `json:"quantity" binding:"required,min=1" example:"10"`       // Отпускаемое количество
+	Comment             string `json:"comment" example:"Для изделия А-12"`                   // Комментарий
+}
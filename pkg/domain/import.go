@@ -0,0 +1,80 @@
+package domain
+
+// Форматы файлов импорта материалов
+const (
+	ImportFormatXLSX = "xlsx"
+	ImportFormatCSV  = "csv"
+)
+
+// MaxImportRows максимальное количество строк в одном файле импорта
+const MaxImportRows = 5000
+
+// Статусы строк импорта
+const (
+	ImportRowValid   = "valid"   // Строка прошла проверку (пробный импорт)
+	ImportRowInvalid = "invalid" // Строка содержит ошибки
+	ImportRowCreated = "created" // Материал создан
+	ImportRowSkipped = "skipped" // Строка не импортирована из-за ошибок в других строках
+)
+
+// Поля материала, которые можно загрузить из файла импорта. Заголовок колонки сопоставляется с полем
+// без учета регистра, либо через явное сопоставление колонок в запросе
+const (
+	ImportFieldWarehouseID            = "warehouse_id"
+	ImportFieldName                   = "name"
+	ImportFieldByInvoice              = "by_invoice"
+	ImportFieldArticle                = "article"
+	ImportFieldProductCategory        = "product_category" // Названия категорий через запятую или точку с запятой
+	ImportFieldUnit                   = "unit"             // Аббревиатура единицы измерения
+	ImportFieldTotalQuantity          = "total_quantity"
+	ImportFieldVolume                 = "volume"
+	ImportFieldPriceWithoutVAT        = "price_without_vat"
+	ImportFieldTotalWithoutVAT        = "total_without_vat"
+	ImportFieldVATRate                = "vat_rate"
+	ImportFieldCurrency               = "currency" // Код валюты: KZT, RUB или USD
+	ImportFieldSupplierID             = "supplier_id"
+	ImportFieldSupplier               = "supplier" // Наименование или ИНН поставщика
+	ImportFieldLocation               = "location" // Только для закупленных материалов
+	ImportFieldContractDate           = "contract_date"
+	ImportFieldFile                   = "file"
+	ImportFieldStatus                 = "status"
+	ImportFieldComments               = "comments"
+	ImportFieldReceivedDate           = "received_date"
+	ImportFieldMinStockLevel          = "min_stock_level"
+	ImportFieldExpirationDate         = "expiration_date"
+	ImportFieldResponsiblePerson      = "responsible_person"
+	ImportFieldStorageCost            = "storage_cost"
+	ImportFieldWarehouseSection       = "warehouse_section"
+	ImportFieldIncomingDeliveryNumber = "incoming_delivery_number"
+	ImportFieldInternalName           = "internal_name"
+	ImportFieldUnitsPerPackage        = "units_per_package"
+	ImportFieldContractNumber         = "contract_number"
+)
+
+// MaterialImportParams представляет параметры импорта материалов из файла
+type MaterialImportParams struct {
+	Format      string            // xlsx или csv
+	DryRun      bool              // Только проверка строк, без сохранения
+	WarehouseID int64             // Склад по умолчанию для строк без склада
+	Mapping     map[string]string // Сопоставление заголовков колонок с полями материала
+}
+
+// ImportRowResult представляет результат импорта одной строки файла
+type ImportRowResult struct {
+	Row    int      `json:"row"`               // Номер строки в файле, начиная с 1 (с учетом заголовка)
+	ID     int64    `json:"id,omitempty"`      // ID созданного материала
+	ItemID int64    `json:"item_id,omitempty"` // Идентификатор товара
+	Status string   `json:"status"`            // valid, invalid, created, skipped
+	Errors []string `json:"errors,omitempty"`  // Ошибки проверки строки
+}
+
+// ImportResult представляет результат импорта материалов из файла
+type ImportResult struct {
+	DryRun    bool              `json:"dry_run"`   // Пробный импорт без сохранения
+	Committed bool              `json:"committed"` // Материалы сохранены
+	Total     int               `json:"total"`     // Количество строк с данными
+	Valid     int               `json:"valid"`     // Количество строк без ошибок
+	Invalid   int               `json:"invalid"`   // Количество строк с ошибками
+	Columns   map[string]string `json:"columns"`   // Использованное сопоставление колонок с полями материала
+	Rows      []ImportRowResult `json:"rows"`      // Результаты по строкам
+}
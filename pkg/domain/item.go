@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Item представляет товар из каталога компании. Партии планируемых и закупленных материалов ссылаются на товар
+// через item_id, поэтому один и тот же товар, закупленный несколько раз, имеет общий идентификатор
+type Item struct {
+	ID                int64     `json:"id" example:"1"`                               // Уникальный идентификатор товара
+	CompanyID         int64     `json:"company_id" example:"1"`                       // Кабинет компании
+	Name              string    `json:"name" example:"Стальная балка"`                // Каноническое наименование
+	Article           string    `json:"article" example:"SB-1234"`                    // Артикул
+	DefaultUnitID     int64     `json:"default_unit_id" example:"1"`                  // Единица измерения по умолчанию
+	CategoryID        int64     `json:"category_id" example:"1"`                      // Категория товара
+	Barcode           string    `json:"barcode" example:"4601234567890"`              // Штрихкод
+	DefaultSupplierID int64     `json:"default_supplier_id" example:"1"`              // Поставщик по умолчанию
+	Description       string    `json:"description" example:"Балка двутавровая 20Б1"` // Описание
+	IsActive          bool      `json:"is_active" example:"true"`                     // Товар используется
+	CreatedAt         time.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`    // Дата создания
+	UpdatedAt         time.Time `json:"updated_at" example:"2024-01-02T12:00:00Z"`    // Дата последнего изменения
+}
+
+// CreateItem представляет структуру создания товара каталога
+type CreateItem struct {
+	Name              string `json:"name" binding:"required,min=1,max=255" example:"Стальная балка"` // Каноническое наименование
+	Article           string `json:"article" binding:"max=255" example:"SB-1234"`                    // Артикул
+	DefaultUnitID     int64  `json:"default_unit_id" example:"1"`                                    // Единица измерения по умолчанию
+	CategoryID        int64  `json:"category_id" example:"1"`                                        // Категория товара
+	Barcode           string `json:"barcode" binding:"max=255" example:"4601234567890"`              // Штрихкод
+	DefaultSupplierID int64  `json:"default_supplier_id" example:"1"`                                // Поставщик по умолчанию
+	Description       string `json:"description" example:"Балка двутавровая 20Б1"`                   // Описание
+}
+
+// UpdateItem представляет структуру обновления товара каталога
+type UpdateItem struct {
+	ID                int64   `json:"-"`
+	Name              *string `json:"name" example:"Стальная балка"`                // Каноническое наименование
+	Article           *string `json:"article" example:"SB-1234"`                    // Артикул
+	DefaultUnitID     *int64  `json:"default_unit_id" example:"1"`                  // Единица измерения по умолчанию
+	CategoryID        *int64  `json:"category_id" example:"1"`                      // Категория товара
+	Barcode           *string `json:"barcode" example:"4601234567890"`              // Штрихкод
+	DefaultSupplierID *int64  `json:"default_supplier_id" example:"1"`              // Поставщик по умолчанию
+	Description       *string `json:"description" example:"Балка двутавровая 20Б1"` // Описание
+	IsActive          *bool   `json:"is_active" example:"true"`                     // Товар используется
+}
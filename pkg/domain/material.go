@@ -1,42 +1,85 @@
 package domain
 
-import "time"
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
 
 // Material представляет структуру товара
 type Material struct {
-	ID                     int64                  `json:"id"`                       // Уникальный идентификатор записи
-	WarehouseID            int64                  `json:"warehouse_id"`             // Склад(место хранения) id
-	ItemID                 int64                  `json:"item_id"`                  // Идентификатор товара
-	Name                   string                 `json:"name"`                     // Наименование материала от поставщика
-	ByInvoice              string                 `json:"by_invoice"`               // Номер товарной накладной
-	Article                string                 `json:"article"`                  // Артикул материала
-	ProductCategory        []string               `json:"product_category"`         // Категории материала
-	Unit                   string                 `json:"unit"`                     // Единица измерения
-	TotalQuantity          int64                  `json:"total_quantity"`           // Количество материала
-	Volume                 int64                  `json:"volume"`                   // Объем товара
-	PriceWithoutVAT        float64                `json:"price_without_vat"`        // Цена без НДС
-	TotalWithoutVAT        float64                `json:"total_without_vat"`        // Общая стоимость без НДС
-	SupplierID             int64                  `json:"supplier_id"`              // Поставщик товара
-	Location               string                 `json:"location"`                 // Локация на складе
-	ContractDate           time.Time              `json:"contract_date"`            // Дата договора
-	File                   string                 `json:"file"`                     // Файл, связанный с товаром
-	Status                 string                 `json:"status"`                   // Статус товара
-	Comments               string                 `json:"comments"`                 // Комментарии
-	Reserve                string                 `json:"reserve"`                  // Количество на резерве
-	ReceivedDate           time.Time              `json:"received_date"`            // Дата поступления на склад
-	LastUpdated            time.Time              `json:"last_updated"`             // Дата последнего обновления информации о товаре
-	MinStockLevel          int64                  `json:"min_stock_level"`          // Минимальный уровень запаса
-	ExpirationDate         time.Time              `json:"expiration_date"`          // Срок годности материала
-	ResponsiblePerson      int64                  `json:"responsible_person"`       // ID ответственного лица за закуп
-	StorageCost            float64                `json:"storage_cost"`             // Стоимость хранения единицы материала
-	WarehouseSection       string                 `json:"warehouse_section"`        // Секция хранения
-	IncomingDeliveryNumber string                 `json:"incoming_delivery_number"` // Входящий номер поставки
-	OtherFields            map[string]interface{} `json:"other_fields"`             // Дополнительные пользовательские поля
-	CompanyID              int64                  `json:"company_id"`               // Кабинет компании к кому привязан товар
-	InternalName           string                 `json:"internal_name"`            // Наименование материала для внутреннего пользования
-	UnitsPerPackage        int64                  `json:"units_per_package"`        // Количество в одной упаковке
-	SupplierName           string                 `json:"supplier_name"`            // Поставщик
-	ContractNumber         string                 `json:"contract_number"`          // Номер договора
+	ID                     int64                  `json:"id"`                                      // Уникальный идентификатор записи
+	WarehouseID            int64                  `json:"warehouse_id"`                            // Склад(место хранения) id
+	ItemID                 int64                  `json:"item_id"`                                 // Идентификатор товара
+	Name                   string                 `json:"name"`                                    // Наименование материала от поставщика
+	ByInvoice              string                 `json:"by_invoice"`                              // Номер товарной накладной
+	Article                string                 `json:"article"`                                 // Артикул материала
+	ProductCategory        []string               `json:"product_category"`                        // Названия категорий материала
+	CategoryIDs            []int64                `json:"category_ids"`                            // Категории материала
+	Unit                   string                 `json:"unit"`                                    // Единица измерения
+	UnitID                 int64                  `json:"unit_id"`                                 // Единица измерения из справочника
+	TotalQuantity          int64                  `json:"total_quantity"`                          // Количество материала
+	Volume                 int64                  `json:"volume"`                                  // Объем товара
+	PriceWithoutVAT        decimal.Decimal        `json:"price_without_vat" swaggertype:"number"`  // Цена без НДС
+	TotalWithoutVAT        decimal.Decimal        `json:"total_without_vat" swaggertype:"number"`  // Общая стоимость без НДС
+	VATRate                decimal.Decimal        `json:"vat_rate" swaggertype:"number"`           // Ставка НДС, %
+	PriceWithVAT           decimal.Decimal        `json:"price_with_vat" swaggertype:"number"`     // Цена с НДС, рассчитывается по цене без НДС и ставке
+	TotalWithVAT           decimal.Decimal        `json:"total_with_vat" swaggertype:"number"`     // Общая стоимость с НДС, рассчитывается по стоимости без НДС и ставке
+	Currency               string                 `json:"currency"`                                // Валюта цен материала: KZT, RUB или USD
+	SupplierID             int64                  `json:"supplier_id"`                             // Поставщик товара
+	Location               string                 `json:"location"`                                // Локация на складе
+	LocationID             int64                  `json:"location_id"`                             // Место хранения на складе
+	ContractDate           time.Time              `json:"contract_date"`                           // Дата договора
+	File                   string                 `json:"file"`                                    // Файл, связанный с товаром
+	Status                 string                 `json:"status"`                                  // Статус товара
+	Comments               string                 `json:"comments"`                                // Комментарии
+	ReceivedDate           time.Time              `json:"received_date"`                           // Дата поступления на склад
+	LastUpdated            time.Time              `json:"last_updated"`                            // Дата последнего обновления информации о товаре
+	MinStockLevel          int64                  `json:"min_stock_level"`                         // Минимальный уровень запаса
+	ExpirationDate         time.Time              `json:"expiration_date"`                         // Срок годности материала
+	ResponsiblePerson      int64                  `json:"responsible_person"`                      // ID ответственного лица за закуп
+	StorageCost            decimal.Decimal        `json:"storage_cost" swaggertype:"number"`       // Стоимость хранения единицы материала
+	TotalStorageCost       decimal.Decimal        `json:"total_storage_cost" swaggertype:"number"` // Стоимость хранения всего количества, рассчитывается по стоимости хранения единицы
+	WarehouseSection       string                 `json:"warehouse_section"`                       // Секция хранения
+	IncomingDeliveryNumber string                 `json:"incoming_delivery_number"`                // Входящий номер поставки
+	OtherFields            map[string]interface{} `json:"other_fields"`                            // Дополнительные пользовательские поля
+	CompanyID              int64                  `json:"company_id"`                              // Кабинет компании к кому привязан товар
+	InternalName           string                 `json:"internal_name"`                           // Наименование материала для внутреннего пользования
+	UnitsPerPackage        int64                  `json:"units_per_package"`                       // Количество в одной упаковке
+	SupplierName           string                 `json:"supplier_name"`                           // Поставщик
+	ContractNumber         string                 `json:"contract_number"`                         // Номер договора
+	ReceivedQuantity       int64                  `json:"received_quantity"`                       // Уже принятое на склад количество (для планируемых материалов)
+	ReservedQuantity       int64                  `json:"reserved_quantity"`                       // Количество в активных резервах (для закупленных материалов)
+	AvailableQuantity      int64                  `json:"available_quantity"`                      // Доступное количество за вычетом резервов (для закупленных материалов)
+	InputTotalWithoutVAT   *decimal.Decimal       `json:"-"`                                       // Общая стоимость без НДС, переданная при создании; nil - не передана
+}
+
+// CalculateTotals пересчитывает общую стоимость без НДС по цене и количеству материала, а затем производные суммы
+func (m *Material) CalculateTotals() {
+	m.TotalWithoutVAT = LineTotal(m.PriceWithoutVAT, m.TotalQuantity)
+	m.CalculateDerived()
+}
+
+// CalculateDerived заполняет цену и общую стоимость с НДС и стоимость хранения всего количества. Эти суммы
+// не хранятся в базе и рассчитываются при чтении материала
+func (m *Material) CalculateDerived() {
+	m.PriceWithVAT = WithVAT(m.PriceWithoutVAT, m.VATRate)
+	m.TotalWithVAT = WithVAT(m.TotalWithoutVAT, m.VATRate)
+	m.TotalStorageCost = LineTotal(m.StorageCost, m.TotalQuantity)
+}
+
+// MaterialUpdate изменение материала. Apply применяется к состоянию материала, прочитанному под блокировкой строки,
+// поэтому изменения остатка, сделанные параллельными операциями, не перезаписываются устаревшими значениями
+type MaterialUpdate struct {
+	ID    int64
+	Apply func(current Material) (Material, error)
+}
+
+// MaterialSearchResult представляет найденный материал с таблицей, в которой он хранится, и релевантностью
+type MaterialSearchResult struct {
+	Material
+	Source   string  `json:"source" example:"purchased_materials"` // Таблица материала: planning_materials, purchased_materials, planning_materials_archive, purchased_materials_archive
+	Rank     float64 `json:"rank"`                                 // Релевантность совпадения, чем больше, тем точнее
+	Headline string  `json:"headline"`                             // Фрагмент текста с выделенными совпадениями
 }
 
 type MaterialParams struct {
@@ -46,31 +89,57 @@ type MaterialParams struct {
 	Query     string
 	Sort      string `json:"sort"`
 	SortField string `json:"sort_field"`
+	Filter    MaterialFilter
+	Cursor    *Cursor
+}
+
+// MaterialFilter представляет фильтры списков материалов. Незаполненные поля не ограничивают выборку
+type MaterialFilter struct {
+	WarehouseId    int64             // Склад
+	SupplierId     int64             // Поставщик
+	Categories     []string          // Материал относится хотя бы к одной из категорий с этими названиями или к их потомкам
+	CategoryIds    []int64           // Материал относится хотя бы к одной из категорий или к их потомкам
+	Statuses       []string          // Статус материала равен одному из значений
+	ReceivedFrom   time.Time         // Дата поступления на склад, начало периода
+	ReceivedTo     time.Time         // Дата поступления на склад, конец периода
+	ContractFrom   time.Time         // Дата договора, начало периода
+	ContractTo     time.Time         // Дата договора, конец периода
+	ExpirationFrom time.Time         // Срок годности, начало периода
+	ExpirationTo   time.Time         // Срок годности, конец периода
+	QuantityMin    *int64            // Минимальное количество
+	QuantityMax    *int64            // Максимальное количество
+	PriceMin       *float64          // Минимальная цена без НДС
+	PriceMax       *float64          // Максимальная цена без НДС
+	OtherFields    map[string]string // Значения дополнительных пользовательских полей
 }
 
 // CreatePlanningMaterial представляет структуру создания товара
 type CreatePlanningMaterial struct {
 	WarehouseID            int64                  `json:"warehouse_id" example:"1"`                                                   // Склад(место хранения) id
+	ItemID                 int64                  `json:"item_id" example:"1"`                                                        // Товар из каталога, если не указан - определяется по артикулу или наименованию
 	Name                   string                 `json:"name" binding:"required" example:"Steel Beam"`                               // Наименование материала от поставщика
 	ByInvoice              string                 `json:"by_invoice" example:"INV-987654"`                                            // Номер товарной накладной
 	Article                string                 `json:"article" example:"SB-1234"`                                                  // Артикул материала
-	ProductCategory        []string               `json:"product_category" example:"Construction,Bricks"`                             // Категории материала
+	ProductCategory        []string               `json:"product_category" example:"Construction,Bricks"`                             // Названия категорий материала, используются если не указаны category_ids
+	CategoryIDs            []int64                `json:"category_ids" example:"1,2"`                                                 // Категории материала
 	Unit                   string                 `json:"unit" example:"pcs"`                                                         // Единица измерения
+	UnitID                 int64                  `json:"unit_id" example:"1"`                                                        // Единица измерения из справочника, если не указана - определяется по аббревиатуре unit
 	TotalQuantity          int64                  `json:"total_quantity" example:"500"`                                               // Количество материала
 	Volume                 int64                  `json:"volume" example:"25"`                                                        // Объем товара
-	PriceWithoutVAT        float64                `json:"price_without_vat" example:"150.75"`                                         // Цена без НДС
-	TotalWithoutVAT        float64                `json:"total_without_vat" example:"75375.00"`                                       // Общая стоимость без НДС
+	PriceWithoutVAT        decimal.Decimal        `json:"price_without_vat" swaggertype:"number" example:"150.75"`                    // Цена без НДС
+	TotalWithoutVAT        *decimal.Decimal       `json:"total_without_vat" swaggertype:"number" example:"75375.00"`                  // Общая стоимость без НДС, рассчитывается сервером; если указана - должна совпадать с ценой × количество
+	VATRate                decimal.Decimal        `json:"vat_rate" swaggertype:"number" example:"12"`                                 // Ставка НДС, %
+	Currency               string                 `json:"currency" example:"KZT"`                                                     // Валюта цен, если не указана - валюта компании по умолчанию
 	SupplierID             int64                  `json:"supplier_id" example:"1"`                                                    // Поставщик товара
 	ContractDate           time.Time              `json:"contract_date" example:"2023-08-15T10:00:00Z"`                               // Дата договора
 	File                   string                 `json:"file" example:"contract_1234.pdf"`                                           // Файл, связанный с товаром
 	Status                 string                 `json:"status" example:"active"`                                                    // Статус товара
 	Comments               string                 `json:"comments" example:"Urgent order"`                                            // Комментарии
-	Reserve                string                 `json:"reserve" example:"50"`                                                       // Количество на резерве
 	ReceivedDate           time.Time              `json:"received_date" example:"2023-08-20T10:00:00Z"`                               // Дата поступления на склад
 	MinStockLevel          int64                  `json:"min_stock_level" example:"10"`                                               // Минимальный уровень запаса
 	ExpirationDate         time.Time              `json:"expiration_date" example:"2024-08-15T10:00:00Z"`                             // Срок годности материала
 	ResponsiblePerson      int64                  `json:"responsible_person" example:"1"`                                             // ID ответственного лица за закуп
-	StorageCost            float64                `json:"storage_cost" example:"500.00"`                                              // Стоимость хранения единицы материала
+	StorageCost            decimal.Decimal        `json:"storage_cost" swaggertype:"number" example:"500.00"`                         // Стоимость хранения единицы материала
 	WarehouseSection       string                 `json:"warehouse_section" example:"B-Section-2"`                                    // Секция хранения
 	IncomingDeliveryNumber string                 `json:"incoming_delivery_number" example:"DEL-56789"`                               // Входящий номер поставки
 	OtherFields            map[string]interface{} `json:"other_fields"`                                                               // Дополнительные пользовательские поля
@@ -86,24 +155,28 @@ type UpdatePlanningMaterial struct {
 	Name                   *string                 `json:"name" example:"Steel Beam"`                                                  // Наименование материала от поставщика
 	ByInvoice              *string                 `json:"by_invoice" example:"INV-987654"`                                            // Номер товарной накладной
 	Article                *string                 `json:"article" example:"SB-1234"`                                                  // Артикул материала
-	ProductCategory        *[]string               `json:"product_category" example:"Construction,Bricks"`                             // Категории материала
+	ProductCategory        *[]string               `json:"product_category" example:"Construction,Bricks"`                             // Названия категорий материала, используются если не указаны category_ids
+	CategoryIDs            *[]int64                `json:"category_ids" example:"1,2"`                                                 // Категории материала
 	Unit                   *string                 `json:"unit" example:"pcs"`                                                         // Единица измерения
+	UnitID                 *int64                  `json:"unit_id" example:"1"`                                                        // Единица измерения из справочника
 	TotalQuantity          *int64                  `json:"total_quantity" example:"500"`                                               // Количество материала
 	Volume                 *int64                  `json:"volume" example:"25"`                                                        // Объем товара
-	PriceWithoutVAT        *float64                `json:"price_without_vat" example:"150.75"`                                         // Цена без НДС
-	TotalWithoutVAT        *float64                `json:"total_without_vat" example:"75375.00"`                                       // Общая стоимость без НДС
+	PriceWithoutVAT        *decimal.Decimal        `json:"price_without_vat" swaggertype:"number" example:"150.75"`                    // Цена без НДС
+	TotalWithoutVAT        *decimal.Decimal        `json:"total_without_vat" swaggertype:"number" example:"75375.00"`                  // Общая стоимость без НДС, рассчитывается сервером; если указана - должна совпадать с ценой × количество
+	VATRate                *decimal.Decimal        `json:"vat_rate" swaggertype:"number" example:"12"`                                 // Ставка НДС, %
+	Currency               *string                 `json:"currency" example:"KZT"`                                                     // Валюта цен: KZT, RUB или USD
 	SupplierID             *int64                  `json:"supplier_id" example:"1"`                                                    // Поставщик товара
 	Location               *string                 `json:"location" example:"A1-Section-3"`                                            // Локация на складе
+	LocationID             *int64                  `json:"location_id" example:"1"`                                                    // Место хранения на складе
 	ContractDate           *time.Time              `json:"contract_date" example:"2023-08-15T10:00:00Z"`                               // Дата договора
 	File                   *string                 `json:"file" example:"contract_1234.pdf"`                                           // Файл, связанный с товаром
 	Status                 *string                 `json:"status" example:"active"`                                                    // Статус товара
 	Comments               *string                 `json:"comments" example:"Urgent order"`                                            // Комментарии
-	Reserve                *string                 `json:"reserve" example:"50"`                                                       // Количество на резерве
 	ReceivedDate           *time.Time              `json:"received_date" example:"2023-08-20T10:00:00Z"`                               // Дата поступления на склад
 	MinStockLevel          *int64                  `json:"min_stock_level" example:"10"`                                               // Минимальный уровень запаса
 	ExpirationDate         *time.Time              `json:"expiration_date" example:"2024-08-15T10:00:00Z"`                             // Срок годности материала
 	ResponsiblePerson      *int64                  `json:"responsible_person" example:"1"`                                             // ID ответственного лица за закуп
-	StorageCost            *float64                `json:"storage_cost" example:"500.00"`                                              // Стоимость хранения единицы материала
+	StorageCost            *decimal.Decimal        `json:"storage_cost" swaggertype:"number" example:"500.00"`                         // Стоимость хранения единицы материала
 	WarehouseSection       *string                 `json:"warehouse_section" example:"B-Section-2"`                                    // Секция хранения
 	IncomingDeliveryNumber *string                 `json:"incoming_delivery_number" example:"DEL-56789"`                               // Входящий номер поставки
 	OtherFields            *map[string]interface{} `json:"other_fields"`                                                               // Дополнительные пользовательские поля
@@ -112,35 +185,62 @@ type UpdatePlanningMaterial struct {
 	ContractNumber         *string                 `json:"contract_number" example:"22"`                                               // Номер договора
 }
 
+// ReceivePlanningMaterial представляет структуру приемки планируемого материала, в том числе частичной
+type ReceivePlanningMaterial struct {
+	ID                     int64            `json:"-"`                                                       // ID планируемого материала
+	Quantity               int64            `json:"quantity" example:"100"`                                  // Принимаемое количество, если не указано - принимается весь остаток
+	PriceWithoutVAT        *decimal.Decimal `json:"price_without_vat" swaggertype:"number" example:"150.75"` // Фактическая цена без НДС
+	ByInvoice              *string          `json:"by_invoice" example:"INV-987654"`                         // Номер товарной накладной
+	IncomingDeliveryNumber *string          `json:"incoming_delivery_number" example:"DEL-56789"`            // Входящий номер поставки
+	ReceivedDate           *time.Time       `json:"received_date" example:"2023-08-20T10:00:00Z"`            // Дата поступления на склад
+	LocationID             *int64           `json:"location_id" example:"1"`                                 // Место хранения принятого материала на складе
+}
+
 type PurchasedIdResponse struct {
 	ID     int64 `json:"id"`
 	ItemId int64 `json:"item_id"`
 }
 
+// TransferPurchasedMaterial представляет структуру перемещения закупленного материала на другой склад
+type TransferPurchasedMaterial struct {
+	ID          int64  `json:"-"`                                            // ID закупленного материала
+	WarehouseID int64  `json:"warehouse_id" binding:"required" example:"2"`  // Склад назначения
+	Quantity    int64  `json:"quantity" example:"100"`                       // Перемещаемое количество, если не указано и не указаны упаковки - перемещается весь остаток
+	UnitID      int64  `json:"unit_id" example:"0"`                          // Единица измерения quantity, если не указана - единица материала
+	Packages    int64  `json:"packages" example:"0"`                         // Количество перемещаемых упаковок, пересчитывается через units_per_package материала
+	LocationID  int64  `json:"location_id" example:"0"`                      // Место хранения на складе назначения
+	Comment     string `json:"comment" example:"Перераспределение остатков"` // Комментарий
+}
+
 // CreatePurchasedMaterial представляет структуру товара
 type CreatePurchasedMaterial struct {
 	WarehouseID            int64                  `json:"warehouse_id" example:"1"`                                                   // Склад(место хранения) id
+	ItemID                 int64                  `json:"item_id" example:"1"`                                                        // Товар из каталога, если не указан - определяется по артикулу или наименованию
 	Name                   string                 `json:"name" binding:"required" example:"Steel Beam"`                               // Наименование материала от поставщика
 	ByInvoice              string                 `json:"by_invoice" example:"INV-987654"`                                            // Номер товарной накладной
 	Article                string                 `json:"article" example:"SB-1234"`                                                  // Артикул материала
-	ProductCategory        []string               `json:"product_category" example:"Construction,Bricks"`                             // Категории материала
+	ProductCategory        []string               `json:"product_category" example:"Construction,Bricks"`                             // Названия категорий материала, используются если не указаны category_ids
+	CategoryIDs            []int64                `json:"category_ids" example:"1,2"`                                                 // Категории материала
 	Unit                   string                 `json:"unit" example:"pcs"`                                                         // Единица измерения
+	UnitID                 int64                  `json:"unit_id" example:"1"`                                                        // Единица измерения из справочника, если не указана - определяется по аббревиатуре unit
 	TotalQuantity          int64                  `json:"total_quantity" example:"500"`                                               // Количество материала
 	Volume                 int64                  `json:"volume" example:"25"`                                                        // Объем товара
-	PriceWithoutVAT        float64                `json:"price_without_vat" example:"150.75"`                                         // Цена без НДС
-	TotalWithoutVAT        float64                `json:"total_without_vat" example:"75375.00"`                                       // Общая стоимость без НДС
+	PriceWithoutVAT        decimal.Decimal        `json:"price_without_vat" swaggertype:"number" example:"150.75"`                    // Цена без НДС
+	TotalWithoutVAT        *decimal.Decimal       `json:"total_without_vat" swaggertype:"number" example:"75375.00"`                  // Общая стоимость без НДС, рассчитывается сервером; если указана - должна совпадать с ценой × количество
+	VATRate                decimal.Decimal        `json:"vat_rate" swaggertype:"number" example:"12"`                                 // Ставка НДС, %
+	Currency               string                 `json:"currency" example:"KZT"`                                                     // Валюта цен, если не указана - валюта компании по умолчанию
 	SupplierID             int64                  `json:"supplier_id" example:"1"`                                                    // Поставщик товара
 	Location               string                 `json:"location" example:"A1-Section-3"`                                            // Локация на складе
+	LocationID             int64                  `json:"location_id" example:"1"`                                                    // Место хранения на складе
 	ContractDate           time.Time              `json:"contract_date" example:"2023-08-15T10:00:00Z"`                               // Дата договора
 	File                   string                 `json:"file" example:"contract_1234.pdf"`                                           // Файл, связанный с товаром
 	Status                 string                 `json:"status" example:"active"`                                                    // Статус товара
 	Comments               string                 `json:"comments" example:"Urgent order"`                                            // Комментарии
-	Reserve                string                 `json:"reserve" example:"50"`                                                       // Количество на резерве
 	ReceivedDate           time.Time              `json:"received_date" example:"2023-08-20T10:00:00Z"`                               // Дата поступления на склад
 	MinStockLevel          int64                  `json:"min_stock_level" example:"10"`                                               // Минимальный уровень запаса
 	ExpirationDate         time.Time              `json:"expiration_date" example:"2024-08-15T10:00:00Z"`                             // Срок годности материала
 	ResponsiblePerson      int64                  `json:"responsible_person" example:"1"`                                             // ID ответственного лица за закуп
-	StorageCost            float64                `json:"storage_cost" example:"500.00"`                                              // Стоимость хранения единицы материала
+	StorageCost            decimal.Decimal        `json:"storage_cost" swaggertype:"number" example:"500.00"`                         // Стоимость хранения единицы материала
 	WarehouseSection       string                 `json:"warehouse_section" example:"B-Section-2"`                                    // Секция хранения
 	IncomingDeliveryNumber string                 `json:"incoming_delivery_number" example:"DEL-56789"`                               // Входящий номер поставки
 	OtherFields            map[string]interface{} `json:"other_fields"`                                                               // Дополнительные пользовательские поля
@@ -156,24 +256,28 @@ type UpdatePurchasedMaterial struct {
 	Name                   *string                 `json:"name" example:"Steel Beam"`                                                  // Наименование материала от поставщика
 	ByInvoice              *string                 `json:"by_invoice" example:"INV-987654"`                                            // Номер товарной накладной
 	Article                *string                 `json:"article" example:"SB-1234"`                                                  // Артикул материала
-	ProductCategory        *[]string               `json:"product_category" example:"Construction,Bricks"`                             // Категории материала
+	ProductCategory        *[]string               `json:"product_category" example:"Construction,Bricks"`                             // Названия категорий материала, используются если не указаны category_ids
+	CategoryIDs            *[]int64                `json:"category_ids" example:"1,2"`                                                 // Категории материала
 	Unit                   *string                 `json:"unit" example:"pcs"`                                                         // Единица измерения
+	UnitID                 *int64                  `json:"unit_id" example:"1"`                                                        // Единица измерения из справочника
 	TotalQuantity          *int64                  `json:"total_quantity" example:"500"`                                               // Количество материала
 	Volume                 *int64                  `json:"volume" example:"25"`                                                        // Объем товара
-	PriceWithoutVAT        *float64                `json:"price_without_vat" example:"150.75"`                                         // Цена без НДС
-	TotalWithoutVAT        *float64                `json:"total_without_vat" example:"75375.00"`                                       // Общая стоимость без НДС
+	PriceWithoutVAT        *decimal.Decimal        `json:"price_without_vat" swaggertype:"number" example:"150.75"`                    // Цена без НДС
+	TotalWithoutVAT        *decimal.Decimal        `json:"total_without_vat" swaggertype:"number" example:"75375.00"`                  // Общая стоимость без НДС, рассчитывается сервером; если указана - должна совпадать с ценой × количество
+	VATRate                *decimal.Decimal        `json:"vat_rate" swaggertype:"number" example:"12"`                                 // Ставка НДС, %
+	Currency               *string                 `json:"currency" example:"KZT"`                                                     // Валюта цен: KZT, RUB или USD
 	SupplierID             *int64                  `json:"supplier_id" example:"1"`                                                    // Поставщик товара
 	Location               *string                 `json:"location" example:"A1-Section-3"`                                            // Локация на складе
+	LocationID             *int64                  `json:"location_id" example:"1"`                                                    // Место хранения на складе
 	ContractDate           *time.Time              `json:"contract_date" example:"2023-08-15T10:00:00Z"`                               // Дата договора
 	File                   *string                 `json:"file" example:"contract_1234.pdf"`                                           // Файл, связанный с товаром
 	Status                 *string                 `json:"status" example:"active"`                                                    // Статус товара
 	Comments               *string                 `json:"comments" example:"Urgent order"`                                            // Комментарии
-	Reserve                *string                 `json:"reserve" example:"50"`                                                       // Количество на резерве
 	ReceivedDate           *time.Time              `json:"received_date" example:"2023-08-20T10:00:00Z"`                               // Дата поступления на склад
 	MinStockLevel          *int64                  `json:"min_stock_level" example:"10"`                                               // Минимальный уровень запаса
 	ExpirationDate         *time.Time              `json:"expiration_date" example:"2024-08-15T10:00:00Z"`                             // Срок годности материала
 	ResponsiblePerson      *int64                  `json:"responsible_person" example:"1"`                                             // ID ответственного лица за закуп
-	StorageCost            *float64                `json:"storage_cost" example:"500.00"`                                              // Стоимость хранения единицы материала
+	StorageCost            *decimal.Decimal        `json:"storage_cost" swaggertype:"number" example:"500.00"`                         // Стоимость хранения единицы материала
 	WarehouseSection       *string                 `json:"warehouse_section" example:"B-Section-2"`                                    // Секция хранения
 	IncomingDeliveryNumber *string                 `json:"incoming_delivery_number" example:"DEL-56789"`                               // Входящий номер поставки
 	OtherFields            *map[string]interface{} `json:"other_fields"`                                                               // Дополнительные пользовательские поля
@@ -181,3 +285,20 @@ type UpdatePurchasedMaterial struct {
 	UnitsPerPackage        *int64                  `json:"units_per_package" example:"10"`                                             // Количество в одной упаковке
 	ContractNumber         *string                 `json:"contract_number" example:"44"`                                               // Номер договора
 }
+
+// LowStockMaterial представляет товар на складе, остаток которого опустился ниже минимального уровня запаса
+type LowStockMaterial struct {
+	ItemID            int64   `json:"item_id"`            // Идентификатор товара
+	WarehouseID       int64   `json:"warehouse_id"`       // Склад(место хранения) id
+	WarehouseName     string  `json:"warehouse_name"`     // Название склада
+	Name              string  `json:"name"`               // Наименование материала
+	Article           string  `json:"article"`            // Артикул материала
+	Unit              string  `json:"unit"`               // Базовая единица измерения, в которой приведены количества
+	UnitID            int64   `json:"unit_id"`            // Базовая единица измерения из справочника
+	TotalQuantity     float64 `json:"total_quantity"`     // Остаток товара на складе
+	ReservedQuantity  float64 `json:"reserved_quantity"`  // Количество в активных резервах
+	MinStockLevel     float64 `json:"min_stock_level"`    // Минимальный уровень запаса
+	Shortage          float64 `json:"shortage"`           // Недостающее до минимального уровня количество
+	ResponsiblePerson int64   `json:"responsible_person"` // ID ответственного лица за склад
+	CompanyID         int64   `json:"company_id"`         // Кабинет компании
+}
@@ -6,6 +6,7 @@ import (
 
 type MaterialCategory struct {
 	ID          int64     `json:"id" example:"1"`
+	ParentID    int64     `json:"parent_id" example:"0"` // Родительская категория, 0 - категория верхнего уровня
 	Name        string    `json:"name" example:"Стальные трубы" binding:"required"`
 	CompanyID   int64     `json:"company_id" example:"123"`
 	Description string    `json:"description" example:"Категория для стальных труб различного диаметра"`
@@ -17,6 +18,7 @@ type MaterialCategory struct {
 }
 
 type CreateMaterialCategory struct {
+	ParentID    int64  `json:"parent_id" example:"0"` // Родительская категория, если не указана - категория верхнего уровня
 	Name        string `json:"name" example:"Стальные трубы"`
 	Description string `json:"description" example:"Категория для стальных труб различного диаметра"`
 	Slug        string `json:"slug" example:"stalnye-truby"`
@@ -32,3 +34,16 @@ type UpdateMaterialCategory struct {
 	IsActive    *bool   `json:"is_active" example:"true"`
 	ImgURL      *string `json:"img_url" example:"https://example.com/images/stalnye-truby.jpg"`
 }
+
+// MaterialCategoryNode представляет категорию в дереве категорий вместе с дочерними категориями
+type MaterialCategoryNode struct {
+	MaterialCategory
+	Children []MaterialCategoryNode `json:"children"`
+}
+
+// MoveMaterialCategory представляет перенос категории вместе с поддеревом под другую родительскую категорию
+type MoveMaterialCategory struct {
+	ID        int64 `json:"-"`
+	CompanyID int64 `json:"-"`
+	ParentID  int64 `json:"parent_id" example:"2"` // Новая родительская категория, 0 - перенос на верхний уровень
+}
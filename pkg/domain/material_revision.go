@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Типы материалов, для которых ведется история версий
+const (
+	MaterialTypePlanning  = "planning"
+	MaterialTypePurchased = "purchased"
+)
+
+// MaterialRevision представляет сохраненное состояние материала до изменения
+type MaterialRevision struct {
+	ID           int64     `json:"id"`            // Уникальный идентификатор версии
+	MaterialType string    `json:"material_type"` // Тип материала: planning, purchased
+	MaterialID   int64     `json:"material_id"`   // ID материала
+	Revision     int64     `json:"revision"`      // Порядковый номер версии материала
+	Snapshot     Material  `json:"snapshot"`      // Полное состояние материала до изменения
+	UserID       int64     `json:"user_id"`       // Пользователь, изменивший материал
+	CompanyID    int64     `json:"company_id"`    // Кабинет компании
+	CreatedAt    time.Time `json:"created_at"`    // Дата изменения
+}
@@ -2,26 +2,41 @@ package domain
 
 // UnitOfMeasure представляет модель единицы измерения
 type UnitOfMeasure struct {
-	ID           int64  `json:"id" example:"1"`                               // Уникальный идентификатор
-	Name         string `json:"name" example:"Килограмм"`                     // Название на языке системы
-	NameEn       string `json:"name_en" example:"Kilogram"`                   // Название на английском
-	Abbreviation string `json:"abbreviation" example:"kg"`                    // Аббревиатура
-	Description  string `json:"description" example:"Единица измерения веса"` // Описание
-	CompanyID    int64  `json:"company_id" example:"1"`                       // ID компании
+	ID               int64   `json:"id" example:"1"`                               // Уникальный идентификатор
+	Name             string  `json:"name" example:"Килограмм"`                     // Название на языке системы
+	NameEn           string  `json:"name_en" example:"Kilogram"`                   // Название на английском
+	Abbreviation     string  `json:"abbreviation" example:"kg"`                    // Аббревиатура
+	Description      string  `json:"description" example:"Единица измерения веса"` // Описание
+	CompanyID        int64   `json:"company_id" example:"1"`                       // ID компании
+	BaseUnitID       int64   `json:"base_unit_id" example:"0"`                     // Базовая единица измерения, 0 - единица сама является базовой
+	ConversionFactor float64 `json:"conversion_factor" example:"1"`                // Количество базовых единиц в одной единице, для базовой единицы равно 1
 }
 
 type CreateUnitOfMeasure struct {
-	Name         string `json:"name" binding:"required,min=1,max=140" example:"Килограмм"`   // Название на языке системы
-	NameEn       string `json:"name_en" binding:"required,min=1,max=140" example:"Kilogram"` // Название на английском
-	Abbreviation string `json:"abbreviation" example:"kg" binding:"required,min=1,max=140"`  // Аббревиатура
-	Description  string `json:"description" example:"Единица измерения веса"`                // Описание
+	Name             string  `json:"name" binding:"required,min=1,max=140" example:"Килограмм"`   // Название на языке системы
+	NameEn           string  `json:"name_en" binding:"required,min=1,max=140" example:"Kilogram"` // Название на английском
+	Abbreviation     string  `json:"abbreviation" example:"kg" binding:"required,min=1,max=140"`  // Аббревиатура
+	Description      string  `json:"description" example:"Единица измерения веса"`                // Описание
+	BaseUnitID       int64   `json:"base_unit_id" example:"0"`                                    // Базовая единица измерения, если не указана - единица является базовой
+	ConversionFactor float64 `json:"conversion_factor" binding:"omitempty,gt=0" example:"1000"`   // Количество базовых единиц в одной единице, например 1000 для т при базовой кг
 }
 
 type UpdateUnitOfMeasure struct {
-	ID           int64   `json:"-"`
-	CompanyID    int64   `json:"-"`
-	Name         *string `json:"name" example:"Килограмм"`                     // Название на языке системы
-	NameEn       *string `json:"name_en" example:"Kilogram"`                   // Название на английском
-	Abbreviation *string `json:"abbreviation" example:"kg"`                    // Аббревиатура
-	Description  *string `json:"description" example:"Единица измерения веса"` // Описание
+	ID               int64    `json:"-"`
+	CompanyID        int64    `json:"-"`
+	Name             *string  `json:"name" example:"Килограмм"`                                  // Название на языке системы
+	NameEn           *string  `json:"name_en" example:"Kilogram"`                                // Название на английском
+	Abbreviation     *string  `json:"abbreviation" example:"kg"`                                 // Аббревиатура
+	Description      *string  `json:"description" example:"Единица измерения веса"`              // Описание
+	BaseUnitID       *int64   `json:"base_unit_id" example:"0"`                                  // Базовая единица измерения, 0 - единица становится базовой
+	ConversionFactor *float64 `json:"conversion_factor" binding:"omitempty,gt=0" example:"1000"` // Количество базовых единиц в одной единице
+}
+
+// UnitConversion представляет результат пересчета количества из одной единицы измерения в другую
+type UnitConversion struct {
+	Quantity   float64 `json:"quantity" example:"2.5"`   // Исходное количество
+	FromUnitID int64   `json:"from_unit_id" example:"2"` // Исходная единица измерения
+	ToUnitID   int64   `json:"to_unit_id" example:"1"`   // Целевая единица измерения
+	Result     float64 `json:"result" example:"2500"`    // Количество в целевой единице измерения
+	BaseUnitID int64   `json:"base_unit_id" example:"1"` // Общая базовая единица обеих единиц измерения
 }
@@ -0,0 +1,54 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// Валюты цен материалов
+const (
+	CurrencyKZT = "KZT"
+	CurrencyRUB = "RUB"
+	CurrencyUSD = "USD"
+)
+
+// DefaultCurrency валюта компании, если она не выбрана
+const DefaultCurrency = CurrencyKZT
+
+// MoneyPlaces количество знаков после запятой в рассчитанных суммах
+const MoneyPlaces = 2
+
+var currencies = map[string]bool{
+	CurrencyKZT: true,
+	CurrencyRUB: true,
+	CurrencyUSD: true,
+}
+
+var hundred = decimal.NewFromInt(100)
+
+func init() {
+	// денежные суммы отдаются в JSON числами, как и до перехода на decimal
+	decimal.MarshalJSONWithoutQuotes = true
+}
+
+// IsValidCurrency проверяет, что код валюты поддерживается
+func IsValidCurrency(code string) bool {
+	return currencies[code]
+}
+
+// IsValidVATRate проверяет, что ставка НДС в процентах находится в диапазоне от 0 до 100
+func IsValidVATRate(rate decimal.Decimal) bool {
+	return !rate.IsNegative() && rate.LessThanOrEqual(hundred)
+}
+
+// VATAmount возвращает сумму НДС по ставке rate в процентах, округленную до копеек
+func VATAmount(amount, rate decimal.Decimal) decimal.Decimal {
+	return amount.Mul(rate).Div(hundred).Round(MoneyPlaces)
+}
+
+// WithVAT возвращает сумму вместе с НДС по ставке rate в процентах
+func WithVAT(amount, rate decimal.Decimal) decimal.Decimal {
+	return amount.Round(MoneyPlaces).Add(VATAmount(amount, rate))
+}
+
+// LineTotal возвращает стоимость quantity единиц по цене price, округленную до копеек
+func LineTotal(price decimal.Decimal, quantity int64) decimal.Decimal {
+	return price.Mul(decimal.NewFromInt(quantity)).Round(MoneyPlaces)
+}
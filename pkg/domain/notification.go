@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Типы уведомлений
+const (
+	NotificationLowStock     = "low_stock"     // Остаток ниже минимального уровня запаса
+	NotificationOverCapacity = "over_capacity" // Заполняемость склада превысила максимальную вместимость
+)
+
+// Notification представляет системное уведомление пользователя
+type Notification struct {
+	ID          int64      `json:"id"`           // Уникальный идентификатор уведомления
+	UserID      int64      `json:"user_id"`      // Получатель уведомления
+	CompanyID   int64      `json:"company_id"`   // Кабинет компании
+	Type        string     `json:"type"`         // Тип уведомления
+	Title       string     `json:"title"`        // Заголовок
+	Message     string     `json:"message"`      // Текст уведомления
+	WarehouseID int64      `json:"warehouse_id"` // Склад, к которому относится уведомление
+	ItemID      int64      `json:"item_id"`      // Товар, к которому относится уведомление
+	DedupKey    string     `json:"-"`            // Ключ для исключения повторных уведомлений
+	IsRead      bool       `json:"is_read"`      // Прочитано ли уведомление
+	ReadAt      *time.Time `json:"read_at"`      // Дата прочтения
+	CreatedAt   time.Time  `json:"created_at"`   // Дата создания
+}
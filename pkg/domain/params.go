@@ -1,10 +1,22 @@
 package domain
 
+import "encoding/json"
+
 type Param struct {
-	Limit     int64  `json:"limit"`
-	Offset    int64  `json:"offset"`
-	CompanyId int64  `json:"company_id"`
-	Query     string `json:"query"`
-	Sort      string `json:"sort"`
-	SortField string `json:"sort_field"`
+	Limit     int64   `json:"limit"`
+	Offset    int64   `json:"offset"`
+	CompanyId int64   `json:"company_id"`
+	Query     string  `json:"query"`
+	Sort      string  `json:"sort"`
+	SortField string  `json:"sort_field"`
+	Cursor    *Cursor `json:"-"`
+}
+
+// Cursor позиция последней записи страницы для постраничной выборки по ключу (keyset). Передается клиенту
+// в закодированном виде, сортировка курсора должна совпадать с сортировкой запроса
+type Cursor struct {
+	SortField string          `json:"f"`  // Поле сортировки
+	Sort      string          `json:"s"`  // Направление сортировки
+	Value     json.RawMessage `json:"v"`  // Значение поля сортировки последней записи
+	ID        int64           `json:"id"` // ID последней записи
 }
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Статусы заказа поставщику
+const (
+	PurchaseOrderDraft             = "draft"              // Черновик, строки можно изменять
+	PurchaseOrderSent              = "sent"               // Отправлен поставщику
+	PurchaseOrderPartiallyReceived = "partially_received" // Принята часть строк
+	PurchaseOrderReceived          = "received"           // Все строки приняты на склад
+	PurchaseOrderCancelled         = "cancelled"          // Отменен
+)
+
+// PurchaseOrder представляет заказ поставщику, объединяющий несколько планируемых материалов
+type PurchaseOrder struct {
+	ID                   int64               `json:"id"`                                     // Уникальный идентификатор заказа
+	SupplierID           int64               `json:"supplier_id"`                            // Поставщик
+	CompanyID            int64               `json:"company_id"`                             // Кабинет компании
+	ContractNumber       string              `json:"contract_number"`                        // Номер договора
+	ContractDate         *time.Time          `json:"contract_date"`                          // Дата договора
+	ExpectedDeliveryDate *time.Time          `json:"expected_delivery_date"`                 // Ожидаемая дата поставки
+	Status               string              `json:"status"`                                 // Статус заказа
+	VATRate              decimal.Decimal     `json:"vat_rate" swaggertype:"number"`          // Ставка НДС, %
+	Currency             string              `json:"currency"`                               // Валюта заказа, совпадает с валютой цен его материалов
+	Comment              string              `json:"comment"`                                // Комментарий
+	CreatedBy            int64               `json:"created_by"`                             // Пользователь, создавший заказ
+	CreatedAt            time.Time           `json:"created_at"`                             // Дата создания
+	UpdatedAt            time.Time           `json:"updated_at"`                             // Дата последнего изменения
+	TotalWithoutVAT      decimal.Decimal     `json:"total_without_vat" swaggertype:"number"` // Сумма заказа без НДС
+	VATAmount            decimal.Decimal     `json:"vat_amount" swaggertype:"number"`        // Сумма НДС
+	TotalWithVAT         decimal.Decimal     `json:"total_with_vat" swaggertype:"number"`    // Сумма заказа с НДС
+	Lines                []PurchaseOrderLine `json:"lines,omitempty"`                        // Строки заказа
+}
+
+// PurchaseOrderLine представляет строку заказа поставщику. Наименование, количество и цена фиксируются при
+// добавлении планируемого материала в заказ
+type PurchaseOrderLine struct {
+	ID                 int64           `json:"id"`                                     // Уникальный идентификатор строки
+	PurchaseOrderID    int64           `json:"purchase_order_id"`                      // ID заказа
+	PlanningMaterialID int64           `json:"planning_material_id"`                   // ID планируемого материала
+	ItemID             int64           `json:"item_id"`                                // Идентификатор товара
+	Name               string          `json:"name"`                                   // Наименование материала
+	Article            string          `json:"article"`                                // Артикул
+	Unit               string          `json:"unit"`                                   // Единица измерения
+	Quantity           int64           `json:"quantity"`                               // Заказанное количество
+	ReceivedQuantity   int64           `json:"received_quantity"`                      // Принятое количество
+	PriceWithoutVAT    decimal.Decimal `json:"price_without_vat" swaggertype:"number"` // Цена без НДС
+	TotalWithoutVAT    decimal.Decimal `json:"total_without_vat" swaggertype:"number"` // Сумма строки без НДС
+}
+
+// CreatePurchaseOrder представляет структуру создания заказа поставщику
+type CreatePurchaseOrder struct {
+	SupplierID           int64           `json:"supplier_id" binding:"required" example:"1"`                              // Поставщик
+	ContractNumber       string          `json:"contract_number" example:"Д-15/24"`                                       // Номер договора
+	ContractDate         *time.Time      `json:"contract_date" example:"2024-01-15T00:00:00Z"`                            // Дата договора
+	ExpectedDeliveryDate *time.Time      `json:"expected_delivery_date" example:"2024-02-01T00:00:00Z"`                   // Ожидаемая дата поставки
+	VATRate              decimal.Decimal `json:"vat_rate" swaggertype:"number" example:"20"`                              // Ставка НДС, %
+	Comment              string          `json:"comment" example:"Поставка для заказа 123"`                               // Комментарий
+	PlanningMaterialIDs  []int64         `json:"planning_material_ids" binding:"required,min=1,dive,min=1" example:"1,2"` // Планируемые материалы
+}
+
+// UpdatePurchaseOrder представляет структуру обновления черновика заказа поставщику
+type UpdatePurchaseOrder struct {
+	ID                   int64            `json:"-"`                                                                        // ID заказа
+	SupplierID           *int64           `json:"supplier_id" example:"1"`                                                  // Поставщик
+	ContractNumber       *string          `json:"contract_number" example:"Д-15/24"`                                        // Номер договора
+	ContractDate         *time.Time       `json:"contract_date" example:"2024-01-15T00:00:00Z"`                             // Дата договора
+	ExpectedDeliveryDate *time.Time       `json:"expected_delivery_date" example:"2024-02-01T00:00:00Z"`                    // Ожидаемая дата поставки
+	VATRate              *decimal.Decimal `json:"vat_rate" swaggertype:"number" example:"20"`                               // Ставка НДС, %
+	Comment              *string          `json:"comment" example:"Поставка для заказа 123"`                                // Комментарий
+	PlanningMaterialIDs  *[]int64         `json:"planning_material_ids" binding:"omitempty,min=1,dive,min=1" example:"1,2"` // Планируемые материалы, заменяют строки заказа
+}
+
+// ReceivePurchaseOrder представляет структуру приемки по заказу поставщику. Без строк принимается весь
+// непринятый остаток заказа
+type ReceivePurchaseOrder struct {
+	Lines                  []ReceivePurchaseOrderLine `json:"lines" binding:"dive"`                         // Принимаемые строки
+	ByInvoice              *string                    `json:"by_invoice" example:"INV-987654"`              // Номер товарной накладной
+	IncomingDeliveryNumber *string                    `json:"incoming_delivery_number" example:"DEL-56789"` // Входящий номер поставки
+	ReceivedDate           *time.Time                 `json:"received_date" example:"2023-08-20T10:00:00Z"` // Дата поступления на склад
+}
+
+// ReceivePurchaseOrderLine представляет принимаемую строку заказа поставщику
+type ReceivePurchaseOrderLine struct {
+	LineID          int64            `json:"line_id" binding:"required" example:"1"`                  // ID строки заказа
+	Quantity        int64            `json:"quantity" binding:"min=0" example:"100"`                  // Принимаемое количество, если не указано - принимается весь остаток строки
+	PriceWithoutVAT *decimal.Decimal `json:"price_without_vat" swaggertype:"number" example:"150.75"` // Фактическая цена без НДС
+	LocationID      *int64           `json:"location_id" example:"1"`                                 // Место хранения принятого материала на складе
+}
+
+// PurchaseOrderParams параметры списка заказов поставщикам
+type PurchaseOrderParams struct {
+	Limit      int64
+	Offset     int64
+	CompanyId  int64
+	SupplierId int64
+	Status     string
+	Sort       string
+	SortField  string
+}
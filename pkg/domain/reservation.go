@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Статусы резерва
+const (
+	ReservationActive   = "active"   // Действующий резерв
+	ReservationReleased = "released" // Снят вручную
+	ReservationConsumed = "consumed" // Отпущен со склада
+	ReservationExpired  = "expired"  // Снят по истечении срока
+)
+
+// Reservation представляет резерв количества закупленного материала под заказ или пользователя
+type Reservation struct {
+	ID                  int64      `json:"id"`                    // Уникальный идентификатор резерва
+	PurchasedMaterialID int64      `json:"purchased_material_id"` // ID закупленного материала
+	ItemID              int64      `json:"item_id"`               // Идентификатор товара
+	WarehouseID         int64      `json:"warehouse_id"`          // Склад, на котором лежит материал
+	Quantity            int64      `json:"quantity"`              // Зарезервированное количество
+	OrderReference      string     `json:"order_reference"`       // Номер заказа
+	ReservedFor         int64      `json:"reserved_for"`          // ID пользователя, для которого создан резерв
+	Status              string     `json:"status"`                // Статус резерва
+	ExpiresAt           *time.Time `json:"expires_at"`            // Срок действия резерва
+	Comment             string     `json:"comment"`               // Комментарий
+	CreatedBy           int64      `json:"created_by"`            // Пользователь, создавший резерв
+	CompanyID           int64      `json:"company_id"`            // Кабинет компании
+	CreatedAt           time.Time  `json:"created_at"`            // Дата создания
+	UpdatedAt           time.Time  `json:"updated_at"`            // Дата последнего изменения статуса
+}
+
+// CreateReservation представляет структуру создания резерва
+type CreateReservation struct {
+	Quantity       int64      `json:"quantity" binding:"required,min=1" example:"10"` // Резервируемое количество
+	OrderReference string     `json:"order_reference" example:"ORD-123"`              // Номер заказа
+	ReservedFor    int64      `json:"reserved_for" example:"1"`                       // ID пользователя, для которого создается резерв
+	ExpiresAt      *time.Time `json:"expires_at" example:"2024-12-31T00:00:00Z"`      // Срок действия резерва, без срока резерв бессрочный
+	Comment        string     `json:"comment" example:"Под заказ клиента"`            // Комментарий
+}
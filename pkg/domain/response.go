@@ -15,6 +15,7 @@ type SuccessResponse struct {
 	IsError    bool        `json:"is_error"`
 	Data       interface{} `json:"data"`
 	TotalCount int64       `json:"total_count"`
+	NextCursor string      `json:"next_cursor,omitempty"` // Курсор следующей страницы, пустой на последней странице
 }
 
 type AvatarResponse struct {
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Типы движения материала
+const (
+	MovementReceipt    = "receipt"    // Поступление
+	MovementIssue      = "issue"      // Отпуск со склада
+	MovementAdjustment = "adjustment" // Корректировка остатка
+	MovementTransfer   = "transfer"   // Перемещение между складами
+	MovementWriteOff   = "write_off"  // Списание
+)
+
+// StockMovement представляет запись журнала движения закупленного материала
+type StockMovement struct {
+	ID                  int64     `json:"id"`                    // Уникальный идентификатор записи
+	PurchasedMaterialID int64     `json:"purchased_material_id"` // ID закупленного материала
+	ItemID              int64     `json:"item_id"`               // Идентификатор товара
+	WarehouseID         int64     `json:"warehouse_id"`          // Склад, на котором произошло движение
+	MovementType        string    `json:"movement_type"`         // Тип движения
+	Quantity            int64     `json:"quantity"`              // Изменение количества со знаком
+	BalanceAfter        int64     `json:"balance_after"`         // Остаток после движения
+	TargetWarehouseID   int64     `json:"target_warehouse_id"`   // Склад назначения для перемещений
+	Reference           string    `json:"reference"`             // Основание движения (номер документа, заказа)
+	Comment             string    `json:"comment"`               // Комментарий
+	UserID              int64     `json:"user_id"`               // Пользователь, создавший движение
+	CompanyID           int64     `json:"company_id"`            // Кабинет компании
+	CreatedAt           time.Time `json:"created_at"`            // Дата создания
+}
+
+// CreateStockMovement представляет структуру создания движения материала
+type CreateStockMovement struct {
+	MovementType string `json:"movement_type" binding:"required,oneof=receipt issue adjustment write_off" example:"issue"` // Тип движения
+	Quantity     int64  `json:"quantity" binding:"required" example:"10"`                                                  // Количество, для корректировки указывается со знаком
+	Reference    string `json:"reference" example:"ORD-123"`                                                               // Основание движения
+	Comment      string `json:"comment" example:"Выдано в производство"`                                                   // Комментарий
+}
+
+// StockReconciliation представляет сверку остатка материала с журналом движений
+type StockReconciliation struct {
+	PurchasedMaterialID int64 `json:"purchased_material_id"` // ID закупленного материала
+	TotalQuantity       int64 `json:"total_quantity"`        // Остаток в карточке материала
+	LedgerQuantity      int64 `json:"ledger_quantity"`       // Остаток по журналу движений
+	Difference          int64 `json:"difference"`            // Расхождение
+}
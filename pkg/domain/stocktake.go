@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// Статусы инвентаризации
+const (
+	StocktakeOpen      = "open"      // Идет подсчет
+	StocktakeApproved  = "approved"  // Расхождения проведены, инвентаризация закрыта
+	StocktakeCancelled = "cancelled" // Отменена без проведения расхождений
+)
+
+// Stocktake представляет инвентаризацию склада. При открытии фиксируются ожидаемые остатки закупленных материалов
+type Stocktake struct {
+	ID          int64      `json:"id"`           // Уникальный идентификатор инвентаризации
+	WarehouseID int64      `json:"warehouse_id"` // Склад
+	CompanyID   int64      `json:"company_id"`   // Кабинет компании
+	Status      string     `json:"status"`       // Статус инвентаризации
+	Comment     string     `json:"comment"`      // Комментарий
+	CreatedBy   int64      `json:"created_by"`   // Пользователь, открывший инвентаризацию
+	ApprovedBy  int64      `json:"approved_by"`  // Администратор, утвердивший или отменивший инвентаризацию
+	CreatedAt   time.Time  `json:"created_at"`   // Дата открытия
+	UpdatedAt   time.Time  `json:"updated_at"`   // Дата последнего изменения
+	ClosedAt    *time.Time `json:"closed_at"`    // Дата утверждения или отмены
+}
+
+// StocktakeLine представляет строку инвентаризации по одной партии закупленного материала
+type StocktakeLine struct {
+	ID                  int64      `json:"id"`                    // Уникальный идентификатор строки
+	StocktakeID         int64      `json:"stocktake_id"`          // ID инвентаризации
+	PurchasedMaterialID int64      `json:"purchased_material_id"` // ID закупленного материала
+	ItemID              int64      `json:"item_id"`               // Идентификатор товара
+	Name                string     `json:"name"`                  // Наименование материала
+	Article             string     `json:"article"`               // Артикул
+	Unit                string     `json:"unit"`                  // Единица измерения
+	Location            string     `json:"location"`              // Место хранения
+	ExpectedQuantity    int64      `json:"expected_quantity"`     // Остаток на момент открытия инвентаризации
+	CountedQuantity     *int64     `json:"counted_quantity"`      // Посчитанное количество, null - строка не посчитана
+	CountedBy           int64      `json:"counted_by"`            // Пользователь, внесший подсчет
+	CountedAt           *time.Time `json:"counted_at"`            // Дата подсчета
+	Comment             string     `json:"comment"`               // Комментарий к подсчету
+}
+
+// StocktakeCount представляет подсчет количества. Партия определяется по item_id или по содержимому QR-кода
+// закупленного материала. Подсчет по item_id распределяется по партиям товара на складе
+type StocktakeCount struct {
+	ItemID   int64     `json:"item_id" example:"1"`                         // Идентификатор товара
+	Code     *CodeInfo `json:"code"`                                        // Содержимое отсканированного QR-кода
+	Quantity int64     `json:"quantity" binding:"min=0" example:"10"`       // Посчитанное количество
+	Comment  string    `json:"comment" example:"Пересчитано на стеллаже A"` // Комментарий
+}
+
+// SubmitStocktakeCounts представляет структуру внесения подсчетов. Повторный подсчет заменяет предыдущий
+type SubmitStocktakeCounts struct {
+	Counts []StocktakeCount `json:"counts" binding:"required,min=1,dive"` // Подсчеты
+}
+
+// CreateStocktake представляет структуру открытия инвентаризации
+type CreateStocktake struct {
+	WarehouseID int64  `json:"warehouse_id" binding:"required" example:"1"`  // Склад
+	Comment     string `json:"comment" example:"Квартальная инвентаризация"` // Комментарий
+}
+
+// StocktakeVariance представляет расхождение по строке инвентаризации
+type StocktakeVariance struct {
+	StocktakeLine
+	Difference int64 `json:"difference"` // Посчитанное количество минус ожидаемое, 0 для непосчитанных строк
+}
+
+// StocktakeVarianceReport представляет отчет о расхождениях инвентаризации
+type StocktakeVarianceReport struct {
+	Stocktake      Stocktake           `json:"stocktake"`       // Инвентаризация
+	Lines          []StocktakeVariance `json:"lines"`           // Строки с расхождениями или без подсчета
+	TotalLines     int64               `json:"total_lines"`     // Количество строк инвентаризации
+	CountedLines   int64               `json:"counted_lines"`   // Количество посчитанных строк
+	UncountedLines int64               `json:"uncounted_lines"` // Количество непосчитанных строк, остаток по ним не меняется
+	Shortage       int64               `json:"shortage"`        // Суммарная недостача
+	Surplus        int64               `json:"surplus"`         // Суммарный излишек
+}
+
+// StocktakeParams параметры списка инвентаризаций
+type StocktakeParams struct {
+	Limit       int64
+	Offset      int64
+	CompanyId   int64
+	WarehouseId int64
+	Status      string
+	Sort        string
+	SortField   string
+}
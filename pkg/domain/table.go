@@ -13,4 +13,19 @@ const (
 	TableMaterialCategories        = "material_categories"
 	SectionsTable                  = "sections"
 	UnitsOfMeasureTable            = "units_of_measure"
+	TableStockMovements            = "stock_movements"
+	TableReservations              = "reservations"
+	TableNotifications             = "notifications"
+	TableAuditLog                  = "audit_log"
+	TableMaterialRevisions         = "material_revisions"
+	TableItems                     = "items"
+	TableMaterialCategoryLinks     = "material_category_links"
+	TableWarehouseLocations        = "warehouse_locations"
+	TableStocktakes                = "stocktakes"
+	TableStocktakeLines            = "stocktake_lines"
+	TableGoodsIssues               = "goods_issues"
+	TableGoodsIssueLines           = "goods_issue_lines"
+	TablePurchaseOrders            = "purchase_orders"
+	TablePurchaseOrderLines        = "purchase_order_lines"
+	TableExchangeRates             = "exchange_rates"
 )
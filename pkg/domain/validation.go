@@ -0,0 +1,37 @@
+package domain
+
+import "strings"
+
+// FieldError описывает некорректное значение поля запроса
+type FieldError struct {
+	Field   string `json:"field" example:"total_without_vat"`                                                           // Поле запроса
+	Message string `json:"message" example:"total_without_vat must equal price_without_vat × total_quantity: 75375.00"` // Описание ошибки
+}
+
+// ValidationError ошибка проверки запроса со списком некорректных полей
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Add добавляет ошибку поля
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err возвращает ошибку, если добавлена хотя бы одна ошибка поля
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+
+	return "invalid fields: " + strings.Join(messages, "; ")
+}
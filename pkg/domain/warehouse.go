@@ -10,7 +10,7 @@ type Warehouse struct {
 	Phone             string                 `json:"phone"`                // Контактный телефон склада
 	Email             string                 `json:"email"`                // Электронная почта для связи
 	MaxCapacity       int64                  `json:"max_capacity"`         // Максимальная вместимость склада
-	CurrentOccupancy  int64                  `json:"current_occupancy"`    // Текущая заполняемость склада
+	CurrentOccupancy  int64                  `json:"current_occupancy"`    // Текущая заполняемость склада, рассчитывается по объему закупленных материалов
 	OtherFields       map[string]interface{} `json:"other_fields"`         // Дополнительные пользовательские поля
 	Country           string                 `json:"country"`              // Страна склада
 	Region            string                 `json:"region"`               // Регион слада
@@ -27,7 +27,6 @@ type InputWarehouse struct {
 	Phone             string                 `json:"phone" binding:"required,min=5" example:"Контактный телефон склада"` // Контактный телефон склада
 	Email             string                 `json:"email" example:"Электронная почта для связи"`                        // Электронная почта для связи
 	MaxCapacity       int64                  `json:"max_capacity" example:"100"`                                         // Максимальная вместимость склада
-	CurrentOccupancy  int64                  `json:"current_occupancy" example:"50"`                                     // Текущая заполняемость склада
 	OtherFields       map[string]interface{} `json:"other_fields"`                                                       // Дополнительные пользовательские поля
 	Country           string                 `json:"country" example:"Страна склада"`                                    // Страна склада
 	Region            string                 `json:"region" example:"Регион склада"`                                     // Регион склада
@@ -43,7 +42,6 @@ type WarehouseUpdate struct {
 	Phone             *string                 `json:"phone"`              // Контактный телефон склада
 	Email             *string                 `json:"email"`              // Электронная почта для связи
 	MaxCapacity       *int64                  `json:"max_capacity"`       // Максимальная вместимость склада
-	CurrentOccupancy  *int64                  `json:"current_occupancy"`  // Текущая заполняемость склада
 	OtherFields       *map[string]interface{} `json:"other_fields"`       // Дополнительные пользовательские поля
 	Country           *string                 `json:"country"`            // Страна склада
 	Region            *string                 `json:"region"`             // Регион склада
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// Уровни иерархии мест хранения склада: зона → стеллаж → полка → ячейка
+const (
+	LocationTypeZone  = "zone"
+	LocationTypeRack  = "rack"
+	LocationTypeShelf = "shelf"
+	LocationTypeBin   = "bin"
+)
+
+// LocationParentTypes задает допустимый тип родителя для каждого уровня. Зона располагается в корне склада
+var LocationParentTypes = map[string]string{
+	LocationTypeZone:  "",
+	LocationTypeRack:  LocationTypeZone,
+	LocationTypeShelf: LocationTypeRack,
+	LocationTypeBin:   LocationTypeShelf,
+}
+
+// WarehouseLocation представляет место хранения на складе. Вместимость и заполненность измеряются в единицах объема
+// материалов (volume * total_quantity), заполненность учитывает материалы вложенных мест хранения
+type WarehouseLocation struct {
+	ID          int64     `json:"id" example:"1"`                              // Уникальный идентификатор места хранения
+	WarehouseID int64     `json:"warehouse_id" example:"1"`                    // Склад
+	CompanyID   int64     `json:"company_id" example:"1"`                      // Кабинет компании
+	ParentID    int64     `json:"parent_id" example:"0"`                       // Родительское место хранения, 0 - зона в корне склада
+	Type        string    `json:"type" example:"bin"`                          // Уровень: zone, rack, shelf, bin
+	Code        string    `json:"code" example:"A1-03"`                        // Код места хранения, уникальный в пределах склада
+	Name        string    `json:"name" example:"Ячейка 3 полки A1"`            // Название
+	Capacity    int64     `json:"capacity" example:"1000"`                     // Вместимость, 0 - не ограничена
+	Occupancy   int64     `json:"occupancy" example:"250"`                     // Текущая заполненность
+	Description string    `json:"description" example:"Крупногабаритный груз"` // Описание
+	IsActive    bool      `json:"is_active" example:"true"`                    // Место хранения используется
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`   // Дата создания
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-02T12:00:00Z"`   // Дата последнего изменения
+}
+
+// WarehouseLocationNode представляет место хранения в дереве мест хранения склада
+type WarehouseLocationNode struct {
+	WarehouseLocation
+	Children []WarehouseLocationNode `json:"children"`
+}
+
+// CreateWarehouseLocation представляет структуру создания места хранения
+type CreateWarehouseLocation struct {
+	ParentID    int64  `json:"parent_id" example:"0"`                                           // Родительское место хранения
+	Type        string `json:"type" binding:"required,oneof=zone rack shelf bin" example:"bin"` // Уровень: zone, rack, shelf, bin
+	Code        string `json:"code" binding:"required,min=1,max=100" example:"A1-03"`           // Код места хранения
+	Name        string `json:"name" binding:"max=255" example:"Ячейка 3 полки A1"`              // Название
+	Capacity    int64  `json:"capacity" binding:"gte=0" example:"1000"`                         // Вместимость, 0 - не ограничена
+	Description string `json:"description" example:"Крупногабаритный груз"`                     // Описание
+}
+
+// UpdateWarehouseLocation представляет структуру обновления места хранения
+type UpdateWarehouseLocation struct {
+	ID          int64   `json:"-"`
+	WarehouseID int64   `json:"-"`
+	ParentID    *int64  `json:"parent_id" example:"2"`                       // Родительское место хранения того же уровня, что и прежнее
+	Code        *string `json:"code" example:"A1-03"`                        // Код места хранения
+	Name        *string `json:"name" example:"Ячейка 3 полки A1"`            // Название
+	Capacity    *int64  `json:"capacity" example:"1000"`                     // Вместимость, 0 - не ограничена
+	Description *string `json:"description" example:"Крупногабаритный груз"` // Описание
+	IsActive    *bool   `json:"is_active" example:"true"`                    // Место хранения используется
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_movements;
+DROP SEQUENCE IF EXISTS stock_movements_id_seq;
@@ -0,0 +1,44 @@
+CREATE SEQUENCE stock_movements_id_seq;
+
+-- Журнал движения закупленных материалов
+CREATE TABLE "stock_movements"
+(
+    "id"                    INT PRIMARY KEY DEFAULT nextval('stock_movements_id_seq'),
+    "purchased_material_id" INT         NOT NULL,                -- ID закупленного материала
+    "item_id"               INT,                                 -- Идентификатор товара
+    "warehouse_id"          INT,                                 -- Склад, на котором произошло движение
+    "movement_type"         VARCHAR(50) NOT NULL,                -- receipt, issue, adjustment, transfer, write_off
+    "quantity"              INT         NOT NULL,                -- Изменение количества со знаком
+    "balance_after"         INT         NOT NULL,                -- Остаток после движения
+    "target_warehouse_id"   INT,                                 -- Склад назначения для перемещений
+    "reference"             VARCHAR(255),                        -- Основание движения
+    "comment"               TEXT,                                -- Комментарий
+    "user_id"               INT,                                 -- Пользователь, создавший движение
+    "company_id"            INT,                                 -- ID компании
+    "created_at"            TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+ALTER TABLE "stock_movements"
+    ADD FOREIGN KEY ("warehouse_id") REFERENCES "warehouses" ("id");
+
+ALTER TABLE "stock_movements"
+    ADD FOREIGN KEY ("target_warehouse_id") REFERENCES "warehouses" ("id");
+
+ALTER TABLE "stock_movements"
+    ADD FOREIGN KEY ("company_id") REFERENCES "companies" ("id");
+
+CREATE INDEX idx_stock_movements_purchased_material_id ON stock_movements (purchased_material_id, created_at);
+CREATE INDEX idx_stock_movements_company_id ON stock_movements (company_id);
+
+-- Начальные остатки, чтобы журнал сходился с текущим total_quantity
+INSERT INTO "stock_movements" ("purchased_material_id", "item_id", "warehouse_id", "movement_type", "quantity",
+                               "balance_after", "comment", "company_id")
+SELECT "id",
+       "item_id",
+       "warehouse_id",
+       'adjustment',
+       COALESCE("total_quantity", 0),
+       COALESCE("total_quantity", 0),
+       'Начальный остаток',
+       "company_id"
+FROM "purchased_materials";
@@ -0,0 +1,8 @@
+UPDATE "planning_materials_archive"
+SET "total_quantity" = "received_quantity";
+
+ALTER TABLE "planning_materials_archive"
+    DROP COLUMN IF EXISTS "received_quantity";
+
+ALTER TABLE "planning_materials"
+    DROP COLUMN IF EXISTS "received_quantity";
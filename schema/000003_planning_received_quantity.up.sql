@@ -0,0 +1,11 @@
+-- Количество, уже принятое на склад по планируемому материалу. total_quantity хранит оставшийся к приемке объем
+ALTER TABLE "planning_materials"
+    ADD COLUMN "received_quantity" INT NOT NULL DEFAULT 0;
+
+ALTER TABLE "planning_materials_archive"
+    ADD COLUMN "received_quantity" INT NOT NULL DEFAULT 0;
+
+-- Строки в архиве были приняты целиком
+UPDATE "planning_materials_archive"
+SET "received_quantity" = COALESCE("total_quantity", 0),
+    "total_quantity"    = 0;
@@ -0,0 +1,37 @@
+ALTER TABLE "planning_materials" ADD COLUMN "reserve" VARCHAR(255);
+ALTER TABLE "purchased_materials" ADD COLUMN "reserve" VARCHAR(255);
+ALTER TABLE "planning_materials_archive" ADD COLUMN "reserve" VARCHAR(255);
+ALTER TABLE "purchased_materials_archive" ADD COLUMN "reserve" VARCHAR(255);
+
+-- Возвращаем исходные значения, сохраненные в other_fields при миграции
+UPDATE "planning_materials"
+SET "reserve"      = "other_fields" ->> 'reserve',
+    "other_fields" = NULLIF("other_fields" - 'reserve', '{}'::JSONB)
+WHERE "other_fields" ? 'reserve';
+
+UPDATE "purchased_materials"
+SET "reserve"      = "other_fields" ->> 'reserve',
+    "other_fields" = NULLIF("other_fields" - 'reserve', '{}'::JSONB)
+WHERE "other_fields" ? 'reserve';
+
+UPDATE "planning_materials_archive"
+SET "reserve"      = "other_fields" ->> 'reserve',
+    "other_fields" = NULLIF("other_fields" - 'reserve', '{}'::JSONB)
+WHERE "other_fields" ? 'reserve';
+
+UPDATE "purchased_materials_archive"
+SET "reserve"      = "other_fields" ->> 'reserve',
+    "other_fields" = NULLIF("other_fields" - 'reserve', '{}'::JSONB)
+WHERE "other_fields" ? 'reserve';
+
+-- Возвращаем суммарный действующий резерв в текстовое поле
+UPDATE "purchased_materials" p
+SET "reserve" = r.quantity::VARCHAR
+FROM (SELECT "purchased_material_id", SUM("quantity") AS quantity
+      FROM "reservations"
+      WHERE "status" = 'active'
+      GROUP BY "purchased_material_id") r
+WHERE r.purchased_material_id = p.id;
+
+DROP TABLE IF EXISTS "reservations";
+DROP SEQUENCE IF EXISTS reservations_id_seq;
@@ -0,0 +1,69 @@
+CREATE SEQUENCE reservations_id_seq;
+
+-- Резервы закупленных материалов под заказ или пользователя
+CREATE TABLE "reservations"
+(
+    "id"                    INT PRIMARY KEY DEFAULT nextval('reservations_id_seq'),
+    "purchased_material_id" INT         NOT NULL,                   -- ID закупленного материала
+    "item_id"               INT,                                    -- Идентификатор товара
+    "warehouse_id"          INT,                                    -- Склад, на котором лежит материал
+    "quantity"              INT         NOT NULL CHECK (quantity > 0), -- Зарезервированное количество
+    "order_reference"       VARCHAR(255),                           -- Номер заказа
+    "reserved_for"          INT,                                    -- Пользователь, для которого создан резерв
+    "status"                VARCHAR(50) NOT NULL DEFAULT 'active',  -- active, released, consumed, expired
+    "expires_at"            TIMESTAMP,                              -- Срок действия резерва
+    "comment"               TEXT,                                   -- Комментарий
+    "created_by"            INT,                                    -- Пользователь, создавший резерв
+    "company_id"            INT,                                    -- ID компании
+    "created_at"            TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"            TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+ALTER TABLE "reservations"
+    ADD FOREIGN KEY ("warehouse_id") REFERENCES "warehouses" ("id");
+
+ALTER TABLE "reservations"
+    ADD FOREIGN KEY ("reserved_for") REFERENCES "users" ("id");
+
+ALTER TABLE "reservations"
+    ADD FOREIGN KEY ("company_id") REFERENCES "companies" ("id");
+
+CREATE INDEX idx_reservations_purchased_material_id ON reservations (purchased_material_id, status);
+CREATE INDEX idx_reservations_active_expires_at ON reservations (expires_at) WHERE status = 'active';
+
+-- Числовые значения текстового поля reserve закупленных материалов переносим в резервы (не больше остатка)
+INSERT INTO "reservations" ("purchased_material_id", "item_id", "warehouse_id", "quantity", "status", "comment",
+                            "company_id")
+SELECT "id", "item_id", "warehouse_id", "quantity", 'active', 'Перенесено из поля reserve: ' || "reserve", "company_id"
+FROM (SELECT "id",
+             "item_id",
+             "warehouse_id",
+             "company_id",
+             TRIM("reserve") AS "reserve",
+             LEAST(CASE WHEN TRIM("reserve") ~ '^[0-9]{1,9}$' THEN TRIM("reserve")::INT ELSE 0 END,
+                   COALESCE("total_quantity", 0)) AS "quantity"
+      FROM "purchased_materials") parsed
+WHERE "quantity" > 0;
+
+-- Исходный текст всех непустых значений reserve, включая нечисловые и значения планируемых и архивных материалов,
+-- сохраняем в other_fields под ключом reserve, чтобы удаление столбцов не приводило к потере данных
+UPDATE "planning_materials"
+SET "other_fields" = COALESCE("other_fields", '{}'::JSONB) || JSONB_BUILD_OBJECT('reserve', TRIM("reserve"))
+WHERE TRIM("reserve") <> '';
+
+UPDATE "purchased_materials"
+SET "other_fields" = COALESCE("other_fields", '{}'::JSONB) || JSONB_BUILD_OBJECT('reserve', TRIM("reserve"))
+WHERE TRIM("reserve") <> '';
+
+UPDATE "planning_materials_archive"
+SET "other_fields" = COALESCE("other_fields", '{}'::JSONB) || JSONB_BUILD_OBJECT('reserve', TRIM("reserve"))
+WHERE TRIM("reserve") <> '';
+
+UPDATE "purchased_materials_archive"
+SET "other_fields" = COALESCE("other_fields", '{}'::JSONB) || JSONB_BUILD_OBJECT('reserve', TRIM("reserve"))
+WHERE TRIM("reserve") <> '';
+
+ALTER TABLE "planning_materials" DROP COLUMN "reserve";
+ALTER TABLE "purchased_materials" DROP COLUMN "reserve";
+ALTER TABLE "planning_materials_archive" DROP COLUMN "reserve";
+ALTER TABLE "purchased_materials_archive" DROP COLUMN "reserve";
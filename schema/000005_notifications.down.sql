@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "notifications";
+DROP SEQUENCE IF EXISTS notifications_id_seq;
@@ -0,0 +1,30 @@
+CREATE SEQUENCE notifications_id_seq;
+
+-- Системные уведомления пользователей
+CREATE TABLE "notifications"
+(
+    "id"           INT PRIMARY KEY DEFAULT nextval('notifications_id_seq'),
+    "user_id"      INT          NOT NULL,              -- Получатель уведомления
+    "company_id"   INT,                                -- ID компании
+    "type"         VARCHAR(50)  NOT NULL,              -- Тип уведомления (low_stock)
+    "title"        VARCHAR(255) NOT NULL,              -- Заголовок
+    "message"      TEXT,                               -- Текст уведомления
+    "warehouse_id" INT,                                -- Склад, к которому относится уведомление
+    "item_id"      INT,                                -- Товар, к которому относится уведомление
+    "dedup_key"    VARCHAR(255),                       -- Ключ для исключения повторных уведомлений
+    "is_read"      BOOLEAN      NOT NULL DEFAULT false, -- Прочитано ли уведомление
+    "read_at"      TIMESTAMP,                          -- Дата прочтения
+    "created_at"   TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+ALTER TABLE "notifications"
+    ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "notifications"
+    ADD FOREIGN KEY ("company_id") REFERENCES "companies" ("id");
+
+ALTER TABLE "notifications"
+    ADD FOREIGN KEY ("warehouse_id") REFERENCES "warehouses" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, is_read, created_at);
+CREATE INDEX idx_notifications_dedup_key ON notifications (user_id, dedup_key);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_purchased_materials_expiration_date;
@@ -0,0 +1,2 @@
+-- Выборка партий по сроку годности для контроля истечения и подбора FEFO
+CREATE INDEX idx_purchased_materials_expiration_date ON purchased_materials (company_id, expiration_date);
@@ -0,0 +1,18 @@
+CREATE SEQUENCE audit_log_id_seq;
+
+-- Журнал аудита изменяющих операций
+CREATE TABLE "audit_log"
+(
+    "id"          INT PRIMARY KEY DEFAULT nextval('audit_log_id_seq'),
+    "user_id"     INT,                                  -- Пользователь, выполнивший действие
+    "company_id"  INT,                                  -- Кабинет компании, которой принадлежит сущность
+    "entity_type" VARCHAR(50) NOT NULL,                 -- Тип сущности
+    "entity_id"   INT         NOT NULL,                 -- ID сущности
+    "action"      VARCHAR(50) NOT NULL,                 -- create, update, delete, move, transfer, receive
+    "changes"     JSONB       NOT NULL DEFAULT '{}',    -- Измененные поля: {"field": {"before": ..., "after": ...}}
+    "created_at"  TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX idx_audit_log_company_created_at ON audit_log (company_id, created_at);
+CREATE INDEX idx_audit_log_user_id ON audit_log (user_id);
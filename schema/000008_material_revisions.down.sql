@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "material_revisions";
+DROP SEQUENCE IF EXISTS material_revisions_id_seq;
@@ -0,0 +1,15 @@
+CREATE SEQUENCE material_revisions_id_seq;
+
+-- История версий материалов: состояние строки до каждого изменения
+CREATE TABLE "material_revisions"
+(
+    "id"            INT PRIMARY KEY DEFAULT nextval('material_revisions_id_seq'),
+    "material_type" VARCHAR(20) NOT NULL, -- planning, purchased
+    "material_id"   INT         NOT NULL, -- ID материала
+    "revision"      INT         NOT NULL, -- Порядковый номер версии материала
+    "snapshot"      JSONB       NOT NULL, -- Полное состояние материала до изменения
+    "user_id"       INT,                  -- Пользователь, изменивший материал
+    "company_id"    INT         NOT NULL, -- Кабинет компании
+    "created_at"    TIMESTAMP DEFAULT (CURRENT_TIMESTAMP),
+    UNIQUE ("material_type", "material_id", "revision")
+);
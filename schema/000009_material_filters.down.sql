@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_purchased_materials_product_category;
+DROP INDEX IF EXISTS idx_planning_materials_product_category;
+
+ALTER TABLE "planning_materials"
+    ALTER COLUMN "product_category" TYPE VARCHAR(255) USING "product_category"::TEXT;
+
+ALTER TABLE "purchased_materials"
+    ALTER COLUMN "product_category" TYPE VARCHAR(255) USING "product_category"::TEXT;
+
+ALTER TABLE "planning_materials_archive"
+    ALTER COLUMN "product_category" TYPE VARCHAR(255) USING "product_category"::TEXT;
+
+ALTER TABLE "purchased_materials_archive"
+    ALTER COLUMN "product_category" TYPE VARCHAR(255) USING "product_category"::TEXT;
@@ -0,0 +1,31 @@
+-- Категории материалов хранятся массивом, чтобы фильтровать списки по пересечению категорий
+ALTER TABLE "planning_materials"
+    ALTER COLUMN "product_category" TYPE TEXT[] USING CASE
+        WHEN "product_category" IS NULL OR "product_category" = '' THEN '{}'::TEXT[]
+        WHEN "product_category" LIKE '{%}' THEN "product_category"::TEXT[]
+        ELSE ARRAY ["product_category"]
+        END;
+
+ALTER TABLE "purchased_materials"
+    ALTER COLUMN "product_category" TYPE TEXT[] USING CASE
+        WHEN "product_category" IS NULL OR "product_category" = '' THEN '{}'::TEXT[]
+        WHEN "product_category" LIKE '{%}' THEN "product_category"::TEXT[]
+        ELSE ARRAY ["product_category"]
+        END;
+
+ALTER TABLE "planning_materials_archive"
+    ALTER COLUMN "product_category" TYPE TEXT[] USING CASE
+        WHEN "product_category" IS NULL OR "product_category" = '' THEN '{}'::TEXT[]
+        WHEN "product_category" LIKE '{%}' THEN "product_category"::TEXT[]
+        ELSE ARRAY ["product_category"]
+        END;
+
+ALTER TABLE "purchased_materials_archive"
+    ALTER COLUMN "product_category" TYPE TEXT[] USING CASE
+        WHEN "product_category" IS NULL OR "product_category" = '' THEN '{}'::TEXT[]
+        WHEN "product_category" LIKE '{%}' THEN "product_category"::TEXT[]
+        ELSE ARRAY ["product_category"]
+        END;
+
+CREATE INDEX idx_planning_materials_product_category ON planning_materials USING GIN (product_category);
+CREATE INDEX idx_purchased_materials_product_category ON purchased_materials USING GIN (product_category);
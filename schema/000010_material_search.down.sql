@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_planning_materials_search_fts;
+DROP INDEX IF EXISTS idx_planning_materials_search_trgm;
+DROP INDEX IF EXISTS idx_purchased_materials_search_fts;
+DROP INDEX IF EXISTS idx_purchased_materials_search_trgm;
+DROP INDEX IF EXISTS idx_planning_materials_archive_search_fts;
+DROP INDEX IF EXISTS idx_planning_materials_archive_search_trgm;
+DROP INDEX IF EXISTS idx_purchased_materials_archive_search_fts;
+DROP INDEX IF EXISTS idx_purchased_materials_archive_search_trgm;
+DROP INDEX IF EXISTS idx_suppliers_name_fts;
+DROP INDEX IF EXISTS idx_suppliers_name_trgm;
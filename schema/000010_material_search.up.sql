@@ -0,0 +1,25 @@
+-- Полнотекстовый и нечеткий поиск материалов. Выражения индексов совпадают с materialSearchDocument
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_planning_materials_search_fts ON planning_materials USING GIN (to_tsvector('russian', (COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, ''))));
+CREATE INDEX idx_planning_materials_search_trgm ON planning_materials USING GIN ((COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, '')) gin_trgm_ops);
+
+CREATE INDEX idx_purchased_materials_search_fts ON purchased_materials USING GIN (to_tsvector('russian', (COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, ''))));
+CREATE INDEX idx_purchased_materials_search_trgm ON purchased_materials USING GIN ((COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, '')) gin_trgm_ops);
+
+CREATE INDEX idx_planning_materials_archive_search_fts ON planning_materials_archive USING GIN (to_tsvector('russian', (COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, ''))));
+CREATE INDEX idx_planning_materials_archive_search_trgm ON planning_materials_archive USING GIN ((COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, '')) gin_trgm_ops);
+
+CREATE INDEX idx_purchased_materials_archive_search_fts ON purchased_materials_archive USING GIN (to_tsvector('russian', (COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, ''))));
+CREATE INDEX idx_purchased_materials_archive_search_trgm ON purchased_materials_archive USING GIN ((COALESCE(name, '') || ' ' || COALESCE(internal_name, '') || ' ' || COALESCE(article, '') || ' ' ||
+     COALESCE(supplier_name, '') || ' ' || COALESCE(by_invoice, '')) gin_trgm_ops);
+
+CREATE INDEX idx_suppliers_name_fts ON suppliers USING GIN (to_tsvector('russian', name));
+CREATE INDEX idx_suppliers_name_trgm ON suppliers USING GIN (name gin_trgm_ops);
@@ -0,0 +1,22 @@
+-- Объединенные при дедупликации item_id не разделяются обратно
+DROP INDEX IF EXISTS idx_purchased_materials_archive_item_id;
+DROP INDEX IF EXISTS idx_planning_materials_archive_item_id;
+DROP INDEX IF EXISTS idx_purchased_materials_item_id;
+DROP INDEX IF EXISTS idx_planning_materials_item_id;
+
+ALTER TABLE "purchased_materials_archive"
+    DROP CONSTRAINT IF EXISTS purchased_materials_archive_item_id_fkey;
+
+ALTER TABLE "planning_materials_archive"
+    DROP CONSTRAINT IF EXISTS planning_materials_archive_item_id_fkey;
+
+ALTER TABLE "purchased_materials"
+    DROP CONSTRAINT IF EXISTS purchased_materials_item_id_fkey;
+
+ALTER TABLE "planning_materials"
+    DROP CONSTRAINT IF EXISTS planning_materials_item_id_fkey;
+
+ALTER TABLE "purchased_materials"
+    ALTER COLUMN "item_id" SET DEFAULT nextval('item_id_seq');
+
+DROP TABLE IF EXISTS "items";
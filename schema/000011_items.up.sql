@@ -0,0 +1,132 @@
+-- Каталог товаров: один товар на компанию независимо от количества партий
+CREATE TABLE "items"
+(
+    "id"                  INT PRIMARY KEY DEFAULT nextval('item_id_seq'),
+    "company_id"          INT          NOT NULL REFERENCES "companies" ("id"),
+    "name"                VARCHAR(255) NOT NULL,                                               -- Каноническое наименование
+    "article"             VARCHAR(255) NOT NULL DEFAULT '',                                    -- Артикул
+    "default_unit_id"     INT REFERENCES "units_of_measure" ("id") ON DELETE SET NULL,         -- Единица измерения по умолчанию
+    "category_id"         INT REFERENCES "material_categories" ("id") ON DELETE SET NULL,      -- Категория
+    "barcode"             VARCHAR(255) NOT NULL DEFAULT '',                                    -- Штрихкод
+    "default_supplier_id" INT REFERENCES "suppliers" ("id") ON DELETE SET NULL,                -- Поставщик по умолчанию
+    "description"         TEXT         NOT NULL DEFAULT '',                                    -- Описание
+    "is_active"           BOOLEAN      NOT NULL DEFAULT true,
+    "created_at"          TIMESTAMP             DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"          TIMESTAMP             DEFAULT (CURRENT_TIMESTAMP)
+);
+
+-- Товар определяется артикулом, а при его отсутствии - наименованием
+CREATE UNIQUE INDEX idx_items_company_article ON items (company_id, LOWER(article)) WHERE article <> '';
+CREATE UNIQUE INDEX idx_items_company_name ON items (company_id, LOWER(name)) WHERE article = '';
+CREATE INDEX idx_items_barcode ON items (company_id, barcode) WHERE barcode <> '';
+CREATE INDEX idx_items_name_trgm ON items USING GIN (name gin_trgm_ops);
+
+-- Дедупликация: строки материалов с одинаковым артикулом (или наименованием при пустом артикуле) становятся
+-- одним товаром. Товар по возможности сохраняет один из прежних item_id, чтобы не менялись напечатанные коды
+CREATE TEMP TABLE item_dedup AS
+SELECT m.src,
+       m.id,
+       m.company_id,
+       m.item_id,
+       TRIM(COALESCE(m.name, ''))    AS name,
+       TRIM(COALESCE(m.article, '')) AS article,
+       m.supplier_id,
+       CASE
+           WHEN TRIM(COALESCE(m.article, '')) <> '' THEN 'a:' || LOWER(TRIM(m.article))
+           ELSE 'n:' || LOWER(TRIM(COALESCE(m.name, '')))
+           END                       AS item_key,
+       NULL::INT                     AS new_item_id
+FROM (SELECT 'planning_materials' AS src, id, company_id, item_id, name, article, supplier_id
+      FROM planning_materials
+      UNION ALL
+      SELECT 'purchased_materials', id, company_id, item_id, name, article, supplier_id
+      FROM purchased_materials
+      UNION ALL
+      SELECT 'planning_materials_archive', id, company_id, item_id, name, article, supplier_id
+      FROM planning_materials_archive
+      UNION ALL
+      SELECT 'purchased_materials_archive', id, company_id, item_id, name, article, supplier_id
+      FROM purchased_materials_archive) m
+WHERE m.company_id IS NOT NULL;
+
+DO
+$$
+    DECLARE
+        g       RECORD;
+        v_item_id INT;
+    BEGIN
+        FOR g IN
+            SELECT company_id,
+                   item_key,
+                   ARRAY_AGG(DISTINCT d.item_id) FILTER (WHERE d.item_id IS NOT NULL)                   AS item_ids,
+                   (ARRAY_AGG(name ORDER BY src LIKE 'purchased%' DESC, id DESC))[1]                    AS name,
+                   (ARRAY_AGG(article ORDER BY src LIKE 'purchased%' DESC, id DESC))[1]                 AS article,
+                   (ARRAY_AGG(supplier_id ORDER BY id DESC) FILTER (WHERE supplier_id IS NOT NULL))[1] AS supplier_id
+            FROM item_dedup d
+            GROUP BY company_id, item_key
+            ORDER BY company_id, item_key
+            LOOP
+                SELECT i
+                INTO v_item_id
+                FROM UNNEST(g.item_ids) AS i
+                WHERE NOT EXISTS (SELECT 1 FROM items WHERE id = i)
+                ORDER BY i
+                LIMIT 1;
+
+                IF v_item_id IS NULL THEN
+                    v_item_id := nextval('item_id_seq');
+                END IF;
+
+                INSERT INTO items (id, company_id, name, article, default_supplier_id)
+                VALUES (v_item_id, g.company_id, COALESCE(NULLIF(g.name, ''), 'Без наименования'), g.article,
+                        g.supplier_id);
+
+                UPDATE item_dedup SET new_item_id = v_item_id WHERE company_id = g.company_id AND item_key = g.item_key;
+            END LOOP;
+    END
+$$;
+
+UPDATE planning_materials m SET item_id = d.new_item_id FROM item_dedup d WHERE d.src = 'planning_materials' AND d.id = m.id;
+UPDATE purchased_materials m SET item_id = d.new_item_id FROM item_dedup d WHERE d.src = 'purchased_materials' AND d.id = m.id;
+UPDATE planning_materials_archive m SET item_id = d.new_item_id FROM item_dedup d WHERE d.src = 'planning_materials_archive' AND d.id = m.id;
+UPDATE purchased_materials_archive m SET item_id = d.new_item_id FROM item_dedup d WHERE d.src = 'purchased_materials_archive' AND d.id = m.id;
+
+UPDATE planning_materials SET item_id = NULL WHERE company_id IS NULL;
+UPDATE purchased_materials SET item_id = NULL WHERE company_id IS NULL;
+UPDATE planning_materials_archive SET item_id = NULL WHERE company_id IS NULL;
+UPDATE purchased_materials_archive SET item_id = NULL WHERE company_id IS NULL;
+
+-- Движения, резервы и уведомления переводим на товар, который получила строка материала с прежним item_id
+CREATE TEMP TABLE item_id_map AS
+SELECT DISTINCT ON (item_id) item_id AS old_item_id, new_item_id
+FROM item_dedup
+WHERE item_id IS NOT NULL
+ORDER BY item_id, src LIKE 'purchased%' DESC, id DESC;
+
+UPDATE stock_movements s SET item_id = m.new_item_id FROM item_id_map m WHERE s.item_id = m.old_item_id;
+UPDATE reservations r SET item_id = m.new_item_id FROM item_id_map m WHERE r.item_id = m.old_item_id;
+UPDATE notifications n SET item_id = m.new_item_id FROM item_id_map m WHERE n.item_id = m.old_item_id;
+
+DROP TABLE item_id_map;
+DROP TABLE item_dedup;
+
+-- item_id больше не выдается последовательностью для каждой закупки, а ссылается на каталог
+ALTER TABLE "purchased_materials"
+    ALTER COLUMN "item_id" DROP DEFAULT;
+
+ALTER TABLE "planning_materials"
+    ADD CONSTRAINT planning_materials_item_id_fkey FOREIGN KEY ("item_id") REFERENCES "items" ("id");
+
+ALTER TABLE "purchased_materials"
+    ADD CONSTRAINT purchased_materials_item_id_fkey FOREIGN KEY ("item_id") REFERENCES "items" ("id");
+
+ALTER TABLE "planning_materials_archive"
+    ADD CONSTRAINT planning_materials_archive_item_id_fkey FOREIGN KEY ("item_id") REFERENCES "items" ("id");
+
+ALTER TABLE "purchased_materials_archive"
+    ADD CONSTRAINT purchased_materials_archive_item_id_fkey FOREIGN KEY ("item_id") REFERENCES "items" ("id");
+
+CREATE INDEX idx_planning_materials_item_id ON planning_materials (item_id);
+CREATE INDEX idx_purchased_materials_item_id ON purchased_materials (item_id);
+CREATE INDEX idx_planning_materials_archive_item_id ON planning_materials_archive (item_id);
+CREATE INDEX idx_purchased_materials_archive_item_id ON purchased_materials_archive (item_id);
@@ -0,0 +1,22 @@
+DROP INDEX IF EXISTS idx_purchased_materials_archive_unit_id;
+DROP INDEX IF EXISTS idx_planning_materials_archive_unit_id;
+DROP INDEX IF EXISTS idx_purchased_materials_unit_id;
+DROP INDEX IF EXISTS idx_planning_materials_unit_id;
+
+ALTER TABLE "purchased_materials_archive"
+    DROP COLUMN IF EXISTS "unit_id";
+
+ALTER TABLE "planning_materials_archive"
+    DROP COLUMN IF EXISTS "unit_id";
+
+ALTER TABLE "purchased_materials"
+    DROP COLUMN IF EXISTS "unit_id";
+
+ALTER TABLE "planning_materials"
+    DROP COLUMN IF EXISTS "unit_id";
+
+DROP INDEX IF EXISTS idx_units_of_measure_base_unit_id;
+
+ALTER TABLE "units_of_measure"
+    DROP COLUMN IF EXISTS "conversion_factor",
+    DROP COLUMN IF EXISTS "base_unit_id";
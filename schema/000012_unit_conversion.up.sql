@@ -0,0 +1,62 @@
+-- Единица измерения может ссылаться на базовую единицу: например, коробка = 12 шт, т = 1000 кг.
+-- Базовая единица сама не может иметь базовой единицы, поэтому пересчет выполняется в один шаг
+ALTER TABLE "units_of_measure"
+    ADD COLUMN "base_unit_id"      INT REFERENCES "units_of_measure" ("id"),
+    ADD COLUMN "conversion_factor" NUMERIC(20, 6) NOT NULL DEFAULT 1 CHECK ("conversion_factor" > 0);
+
+CREATE INDEX idx_units_of_measure_base_unit_id ON units_of_measure (base_unit_id);
+
+-- Материалы ссылаются на единицу измерения из справочника, текстовое поле unit сохраняется для совместимости
+ALTER TABLE "planning_materials"
+    ADD COLUMN "unit_id" INT REFERENCES "units_of_measure" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "purchased_materials"
+    ADD COLUMN "unit_id" INT REFERENCES "units_of_measure" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "planning_materials_archive"
+    ADD COLUMN "unit_id" INT REFERENCES "units_of_measure" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "purchased_materials_archive"
+    ADD COLUMN "unit_id" INT REFERENCES "units_of_measure" ("id") ON DELETE SET NULL;
+
+-- Заполняем unit_id по совпадению текста единицы с аббревиатурой или названием единицы компании
+UPDATE planning_materials m
+SET unit_id = (SELECT u.id
+               FROM units_of_measure u
+               WHERE u.company_id = m.company_id
+                 AND (LOWER(u.abbreviation) = LOWER(TRIM(m.unit)) OR LOWER(u.name) = LOWER(TRIM(m.unit)))
+               ORDER BY u.id
+               LIMIT 1)
+WHERE COALESCE(TRIM(m.unit), '') <> '';
+
+UPDATE purchased_materials m
+SET unit_id = (SELECT u.id
+               FROM units_of_measure u
+               WHERE u.company_id = m.company_id
+                 AND (LOWER(u.abbreviation) = LOWER(TRIM(m.unit)) OR LOWER(u.name) = LOWER(TRIM(m.unit)))
+               ORDER BY u.id
+               LIMIT 1)
+WHERE COALESCE(TRIM(m.unit), '') <> '';
+
+UPDATE planning_materials_archive m
+SET unit_id = (SELECT u.id
+               FROM units_of_measure u
+               WHERE u.company_id = m.company_id
+                 AND (LOWER(u.abbreviation) = LOWER(TRIM(m.unit)) OR LOWER(u.name) = LOWER(TRIM(m.unit)))
+               ORDER BY u.id
+               LIMIT 1)
+WHERE COALESCE(TRIM(m.unit), '') <> '';
+
+UPDATE purchased_materials_archive m
+SET unit_id = (SELECT u.id
+               FROM units_of_measure u
+               WHERE u.company_id = m.company_id
+                 AND (LOWER(u.abbreviation) = LOWER(TRIM(m.unit)) OR LOWER(u.name) = LOWER(TRIM(m.unit)))
+               ORDER BY u.id
+               LIMIT 1)
+WHERE COALESCE(TRIM(m.unit), '') <> '';
+
+CREATE INDEX idx_planning_materials_unit_id ON planning_materials (unit_id);
+CREATE INDEX idx_purchased_materials_unit_id ON purchased_materials (unit_id);
+CREATE INDEX idx_planning_materials_archive_unit_id ON planning_materials_archive (unit_id);
+CREATE INDEX idx_purchased_materials_archive_unit_id ON purchased_materials_archive (unit_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "material_category_links";
+
+DROP INDEX IF EXISTS idx_material_categories_company_id;
+DROP INDEX IF EXISTS idx_material_categories_parent_id;
+
+ALTER TABLE "material_categories"
+    DROP COLUMN IF EXISTS "parent_id";
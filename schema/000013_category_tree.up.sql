@@ -0,0 +1,121 @@
+-- Категории образуют дерево, при удалении родителя дочерние категории переносятся на уровень выше
+ALTER TABLE "material_categories"
+    ADD COLUMN "parent_id" INT REFERENCES "material_categories" ("id") ON DELETE SET NULL;
+
+CREATE INDEX idx_material_categories_parent_id ON material_categories (parent_id);
+CREATE INDEX idx_material_categories_company_id ON material_categories (company_id);
+
+-- Связь строк материалов с категориями по id, material_type - имя таблицы материала.
+-- product_category сохраняется как кэш названий категорий
+CREATE TABLE "material_category_links"
+(
+    "material_type" VARCHAR(50) NOT NULL,
+    "material_id"   INT         NOT NULL,
+    "category_id"   INT         NOT NULL REFERENCES "material_categories" ("id") ON DELETE CASCADE,
+    PRIMARY KEY ("material_type", "material_id", "category_id")
+);
+
+CREATE INDEX idx_material_category_links_category_id ON material_category_links (category_id);
+
+-- Создаем категории, которые указаны у материалов, но отсутствуют в справочнике компании
+INSERT INTO material_categories (name, company_id, description, slug, created_at, updated_at, is_active, img_url)
+SELECT MIN(n.name), n.company_id, '', '', NOW(), NOW(), true, ''
+FROM (SELECT m.company_id, TRIM(c) AS name
+      FROM planning_materials m, UNNEST(m.product_category) c
+      UNION ALL
+      SELECT m.company_id, TRIM(c)
+      FROM purchased_materials m, UNNEST(m.product_category) c
+      UNION ALL
+      SELECT m.company_id, TRIM(c)
+      FROM planning_materials_archive m, UNNEST(m.product_category) c
+      UNION ALL
+      SELECT m.company_id, TRIM(c)
+      FROM purchased_materials_archive m, UNNEST(m.product_category) c) n
+WHERE n.name <> ''
+  AND NOT EXISTS (SELECT 1
+                  FROM material_categories mc
+                  WHERE mc.company_id = n.company_id
+                    AND LOWER(mc.name) = LOWER(n.name))
+GROUP BY n.company_id, LOWER(n.name);
+
+-- Связываем материалы с категориями по названию без учета регистра
+INSERT INTO material_category_links (material_type, material_id, category_id)
+SELECT DISTINCT 'planning_materials', m.id, c.id
+FROM planning_materials m,
+     UNNEST(m.product_category) n,
+     LATERAL (SELECT mc.id
+              FROM material_categories mc
+              WHERE mc.company_id = m.company_id
+                AND LOWER(mc.name) = LOWER(TRIM(n))
+              ORDER BY mc.id
+              LIMIT 1) c;
+
+INSERT INTO material_category_links (material_type, material_id, category_id)
+SELECT DISTINCT 'purchased_materials', m.id, c.id
+FROM purchased_materials m,
+     UNNEST(m.product_category) n,
+     LATERAL (SELECT mc.id
+              FROM material_categories mc
+              WHERE mc.company_id = m.company_id
+                AND LOWER(mc.name) = LOWER(TRIM(n))
+              ORDER BY mc.id
+              LIMIT 1) c;
+
+INSERT INTO material_category_links (material_type, material_id, category_id)
+SELECT DISTINCT 'planning_materials_archive', m.id, c.id
+FROM planning_materials_archive m,
+     UNNEST(m.product_category) n,
+     LATERAL (SELECT mc.id
+              FROM material_categories mc
+              WHERE mc.company_id = m.company_id
+                AND LOWER(mc.name) = LOWER(TRIM(n))
+              ORDER BY mc.id
+              LIMIT 1) c;
+
+INSERT INTO material_category_links (material_type, material_id, category_id)
+SELECT DISTINCT 'purchased_materials_archive', m.id, c.id
+FROM purchased_materials_archive m,
+     UNNEST(m.product_category) n,
+     LATERAL (SELECT mc.id
+              FROM material_categories mc
+              WHERE mc.company_id = m.company_id
+                AND LOWER(mc.name) = LOWER(TRIM(n))
+              ORDER BY mc.id
+              LIMIT 1) c;
+
+-- Приводим кэш названий к названиям категорий из справочника
+UPDATE planning_materials m
+SET product_category = ARRAY(SELECT c.name
+                             FROM material_category_links l
+                                      JOIN material_categories c ON c.id = l.category_id
+                             WHERE l.material_type = 'planning_materials'
+                               AND l.material_id = m.id
+                             ORDER BY c.id)
+WHERE CARDINALITY(m.product_category) > 0;
+
+UPDATE purchased_materials m
+SET product_category = ARRAY(SELECT c.name
+                             FROM material_category_links l
+                                      JOIN material_categories c ON c.id = l.category_id
+                             WHERE l.material_type = 'purchased_materials'
+                               AND l.material_id = m.id
+                             ORDER BY c.id)
+WHERE CARDINALITY(m.product_category) > 0;
+
+UPDATE planning_materials_archive m
+SET product_category = ARRAY(SELECT c.name
+                             FROM material_category_links l
+                                      JOIN material_categories c ON c.id = l.category_id
+                             WHERE l.material_type = 'planning_materials_archive'
+                               AND l.material_id = m.id
+                             ORDER BY c.id)
+WHERE CARDINALITY(m.product_category) > 0;
+
+UPDATE purchased_materials_archive m
+SET product_category = ARRAY(SELECT c.name
+                             FROM material_category_links l
+                                      JOIN material_categories c ON c.id = l.category_id
+                             WHERE l.material_type = 'purchased_materials_archive'
+                               AND l.material_id = m.id
+                             ORDER BY c.id)
+WHERE CARDINALITY(m.product_category) > 0;
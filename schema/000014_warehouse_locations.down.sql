@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS idx_purchased_materials_archive_location_id;
+DROP INDEX IF EXISTS idx_planning_materials_archive_location_id;
+DROP INDEX IF EXISTS idx_purchased_materials_location_id;
+DROP INDEX IF EXISTS idx_planning_materials_location_id;
+
+ALTER TABLE "purchased_materials_archive"
+    DROP COLUMN IF EXISTS "location_id";
+
+ALTER TABLE "planning_materials_archive"
+    DROP COLUMN IF EXISTS "location_id";
+
+ALTER TABLE "purchased_materials"
+    DROP COLUMN IF EXISTS "location_id";
+
+ALTER TABLE "planning_materials"
+    DROP COLUMN IF EXISTS "location_id";
+
+DROP TABLE IF EXISTS "warehouse_locations";
+
+DROP SEQUENCE IF EXISTS warehouse_locations_id_seq;
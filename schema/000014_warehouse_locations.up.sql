@@ -0,0 +1,39 @@
+CREATE SEQUENCE warehouse_locations_id_seq;
+
+-- Места хранения склада: зона → стеллаж → полка → ячейка
+CREATE TABLE "warehouse_locations"
+(
+    "id"           INT PRIMARY KEY DEFAULT nextval('warehouse_locations_id_seq'),
+    "warehouse_id" INT          NOT NULL REFERENCES "warehouses" ("id") ON DELETE CASCADE,
+    "company_id"   INT          NOT NULL,
+    "parent_id"    INT REFERENCES "warehouse_locations" ("id"),
+    "type"         VARCHAR(20)  NOT NULL CHECK ("type" IN ('zone', 'rack', 'shelf', 'bin')),
+    "code"         VARCHAR(100) NOT NULL,
+    "name"         VARCHAR(255) NOT NULL DEFAULT '',
+    "capacity"     BIGINT       NOT NULL DEFAULT 0 CHECK ("capacity" >= 0), -- Вместимость в единицах объема, 0 - не ограничена
+    "description"  TEXT         NOT NULL DEFAULT '',
+    "is_active"    BOOLEAN      NOT NULL DEFAULT true,
+    "created_at"   TIMESTAMP             DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"   TIMESTAMP             DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE UNIQUE INDEX idx_warehouse_locations_code ON warehouse_locations (warehouse_id, LOWER(code));
+CREATE INDEX idx_warehouse_locations_parent_id ON warehouse_locations (parent_id);
+
+-- Материалы ссылаются на место хранения, текстовое поле location сохраняется и заполняется кодом места хранения
+ALTER TABLE "planning_materials"
+    ADD COLUMN "location_id" INT REFERENCES "warehouse_locations" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "purchased_materials"
+    ADD COLUMN "location_id" INT REFERENCES "warehouse_locations" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "planning_materials_archive"
+    ADD COLUMN "location_id" INT REFERENCES "warehouse_locations" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "purchased_materials_archive"
+    ADD COLUMN "location_id" INT REFERENCES "warehouse_locations" ("id") ON DELETE SET NULL;
+
+CREATE INDEX idx_planning_materials_location_id ON planning_materials (location_id);
+CREATE INDEX idx_purchased_materials_location_id ON purchased_materials (location_id);
+CREATE INDEX idx_planning_materials_archive_location_id ON planning_materials_archive (location_id);
+CREATE INDEX idx_purchased_materials_archive_location_id ON purchased_materials_archive (location_id);
@@ -0,0 +1,7 @@
+ALTER TABLE "companies"
+    DROP COLUMN IF EXISTS "allow_over_capacity";
+
+ALTER TABLE "warehouses"
+    ALTER COLUMN "current_occupancy" DROP NOT NULL,
+    ALTER COLUMN "current_occupancy" DROP DEFAULT,
+    ALTER COLUMN "current_occupancy" TYPE INT;
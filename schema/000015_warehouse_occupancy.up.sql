@@ -0,0 +1,16 @@
+-- Заполняемость склада рассчитывается по объему и количеству размещенных на нем закупленных материалов
+ALTER TABLE "warehouses"
+    ALTER COLUMN "current_occupancy" TYPE BIGINT;
+
+UPDATE "warehouses" w
+SET current_occupancy = (SELECT COALESCE(SUM(COALESCE(p.volume, 0)::BIGINT * COALESCE(p.total_quantity, 0)), 0)
+                         FROM purchased_materials p
+                         WHERE p.warehouse_id = w.id);
+
+ALTER TABLE "warehouses"
+    ALTER COLUMN "current_occupancy" SET DEFAULT 0,
+    ALTER COLUMN "current_occupancy" SET NOT NULL;
+
+-- Разрешает размещать материалы сверх максимальной вместимости склада с предупреждением вместо отказа
+ALTER TABLE "companies"
+    ADD COLUMN "allow_over_capacity" BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "stocktake_lines";
+DROP TABLE IF EXISTS "stocktakes";
+
+DROP SEQUENCE IF EXISTS stocktake_lines_id_seq;
+DROP SEQUENCE IF EXISTS stocktakes_id_seq;
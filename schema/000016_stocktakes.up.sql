@@ -0,0 +1,41 @@
+CREATE SEQUENCE stocktakes_id_seq;
+CREATE SEQUENCE stocktake_lines_id_seq;
+
+-- Инвентаризации склада
+CREATE TABLE "stocktakes"
+(
+    "id"           INT PRIMARY KEY DEFAULT nextval('stocktakes_id_seq'),
+    "warehouse_id" INT         NOT NULL REFERENCES "warehouses" ("id") ON DELETE CASCADE,
+    "company_id"   INT         NOT NULL,
+    "status"       VARCHAR(20) NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'approved', 'cancelled')),
+    "comment"      TEXT        NOT NULL DEFAULT '',
+    "created_by"   INT,                                 -- Пользователь, открывший инвентаризацию
+    "approved_by"  INT,                                 -- Администратор, утвердивший или отменивший инвентаризацию
+    "created_at"   TIMESTAMP            DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"   TIMESTAMP            DEFAULT (CURRENT_TIMESTAMP),
+    "closed_at"    TIMESTAMP
+);
+
+-- На складе может быть открыта только одна инвентаризация
+CREATE UNIQUE INDEX idx_stocktakes_open_warehouse ON stocktakes (warehouse_id) WHERE status = 'open';
+CREATE INDEX idx_stocktakes_company_id ON stocktakes (company_id, created_at);
+
+-- Строки инвентаризации: снимок остатков закупленных материалов на момент открытия и посчитанные количества
+CREATE TABLE "stocktake_lines"
+(
+    "id"                    INT PRIMARY KEY DEFAULT nextval('stocktake_lines_id_seq'),
+    "stocktake_id"          INT          NOT NULL REFERENCES "stocktakes" ("id") ON DELETE CASCADE,
+    "purchased_material_id" INT REFERENCES "purchased_materials" ("id") ON DELETE SET NULL,
+    "item_id"               INT,
+    "name"                  VARCHAR(255) NOT NULL DEFAULT '',
+    "article"               VARCHAR(255) NOT NULL DEFAULT '',
+    "unit"                  VARCHAR(50)  NOT NULL DEFAULT '',
+    "location"              VARCHAR(255) NOT NULL DEFAULT '',
+    "expected_quantity"     BIGINT       NOT NULL DEFAULT 0, -- Остаток на момент открытия
+    "counted_quantity"      BIGINT CHECK ("counted_quantity" >= 0), -- NULL - строка не посчитана
+    "counted_by"            INT,
+    "counted_at"            TIMESTAMP,
+    "comment"               TEXT         NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_stocktake_lines_stocktake_id ON stocktake_lines (stocktake_id, item_id);
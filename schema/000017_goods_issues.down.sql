@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "goods_issue_lines";
+DROP TABLE IF EXISTS "goods_issues";
+
+DROP SEQUENCE IF EXISTS goods_issue_lines_id_seq;
+DROP SEQUENCE IF EXISTS goods_issues_id_seq;
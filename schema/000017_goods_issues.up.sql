@@ -0,0 +1,36 @@
+CREATE SEQUENCE goods_issues_id_seq;
+CREATE SEQUENCE goods_issue_lines_id_seq;
+
+-- Документы отпуска материалов со склада в производство
+CREATE TABLE "goods_issues"
+(
+    "id"                  INT PRIMARY KEY DEFAULT nextval('goods_issues_id_seq'),
+    "warehouse_id"        INT         NOT NULL REFERENCES "warehouses" ("id"),
+    "company_id"          INT         NOT NULL,
+    "responsible_user_id" INT REFERENCES "users" ("id"),   -- Пользователь, получающий материалы
+    "status"              VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK ("status" IN ('draft', 'posted')),
+    "comment"             TEXT        NOT NULL DEFAULT '',
+    "created_by"          INT,
+    "posted_by"           INT,
+    "created_at"          TIMESTAMP            DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"          TIMESTAMP            DEFAULT (CURRENT_TIMESTAMP),
+    "posted_at"           TIMESTAMP
+);
+
+CREATE INDEX idx_goods_issues_warehouse_id ON goods_issues (warehouse_id, created_at);
+
+-- Строки документа отпуска. Наименование, артикул и единица измерения сохраняются на момент создания документа
+CREATE TABLE "goods_issue_lines"
+(
+    "id"                    INT PRIMARY KEY DEFAULT nextval('goods_issue_lines_id_seq'),
+    "goods_issue_id"        INT          NOT NULL REFERENCES "goods_issues" ("id") ON DELETE CASCADE,
+    "purchased_material_id" INT          NOT NULL,
+    "item_id"               INT,
+    "name"                  VARCHAR(255) NOT NULL DEFAULT '',
+    "article"               VARCHAR(255) NOT NULL DEFAULT '',
+    "unit"                  VARCHAR(50)  NOT NULL DEFAULT '',
+    "quantity"              BIGINT       NOT NULL CHECK ("quantity" > 0),
+    "comment"               TEXT         NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_goods_issue_lines_goods_issue_id ON goods_issue_lines (goods_issue_id);
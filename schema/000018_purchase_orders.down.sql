@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "purchase_order_lines";
+DROP TABLE IF EXISTS "purchase_orders";
+
+DROP SEQUENCE IF EXISTS purchase_order_lines_id_seq;
+DROP SEQUENCE IF EXISTS purchase_orders_id_seq;
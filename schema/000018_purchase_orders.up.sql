@@ -0,0 +1,42 @@
+CREATE SEQUENCE purchase_orders_id_seq;
+CREATE SEQUENCE purchase_order_lines_id_seq;
+
+-- Заказы поставщикам
+CREATE TABLE "purchase_orders"
+(
+    "id"                     INT PRIMARY KEY DEFAULT nextval('purchase_orders_id_seq'),
+    "supplier_id"            INT           NOT NULL REFERENCES "suppliers" ("id"),
+    "company_id"             INT           NOT NULL,
+    "contract_number"        VARCHAR(255)  NOT NULL DEFAULT '',
+    "contract_date"          DATE,
+    "expected_delivery_date" DATE,
+    "status"                 VARCHAR(30)   NOT NULL DEFAULT 'draft'
+        CHECK ("status" IN ('draft', 'sent', 'partially_received', 'received', 'cancelled')),
+    "vat_rate"               DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK ("vat_rate" >= 0 AND "vat_rate" <= 100),
+    "comment"                TEXT          NOT NULL DEFAULT '',
+    "created_by"             INT,
+    "created_at"             TIMESTAMP              DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"             TIMESTAMP              DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX idx_purchase_orders_company_id ON purchase_orders (company_id, created_at);
+CREATE INDEX idx_purchase_orders_supplier_id ON purchase_orders (supplier_id);
+
+-- Строки заказа. Полностью принятый планируемый материал переносится в архив, поэтому ссылка на него не
+-- ограничивается внешним ключом, а данные строки сохраняются на момент добавления в заказ
+CREATE TABLE "purchase_order_lines"
+(
+    "id"                   INT PRIMARY KEY DEFAULT nextval('purchase_order_lines_id_seq'),
+    "purchase_order_id"    INT          NOT NULL REFERENCES "purchase_orders" ("id") ON DELETE CASCADE,
+    "planning_material_id" INT          NOT NULL,
+    "item_id"              INT,
+    "name"                 VARCHAR(255) NOT NULL DEFAULT '',
+    "article"              VARCHAR(255) NOT NULL DEFAULT '',
+    "unit"                 VARCHAR(50)  NOT NULL DEFAULT '',
+    "quantity"             BIGINT       NOT NULL CHECK ("quantity" > 0),
+    "received_quantity"    BIGINT       NOT NULL DEFAULT 0 CHECK ("received_quantity" >= 0),
+    "price_without_vat"    DECIMAL      NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_purchase_order_lines_purchase_order_id ON purchase_order_lines (purchase_order_id);
+CREATE INDEX idx_purchase_order_lines_planning_material_id ON purchase_order_lines (planning_material_id);
@@ -0,0 +1,39 @@
+DROP TABLE IF EXISTS "exchange_rates";
+
+DROP SEQUENCE IF EXISTS exchange_rates_id_seq;
+
+ALTER TABLE "purchase_orders" DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "purchased_materials_archive"
+    ALTER COLUMN "price_without_vat" DROP NOT NULL,
+    ALTER COLUMN "price_without_vat" DROP DEFAULT,
+    ALTER COLUMN "total_without_vat" DROP NOT NULL,
+    ALTER COLUMN "total_without_vat" DROP DEFAULT,
+    DROP COLUMN IF EXISTS "currency",
+    DROP COLUMN IF EXISTS "vat_rate";
+
+ALTER TABLE "planning_materials_archive"
+    ALTER COLUMN "price_without_vat" DROP NOT NULL,
+    ALTER COLUMN "price_without_vat" DROP DEFAULT,
+    ALTER COLUMN "total_without_vat" DROP NOT NULL,
+    ALTER COLUMN "total_without_vat" DROP DEFAULT,
+    DROP COLUMN IF EXISTS "currency",
+    DROP COLUMN IF EXISTS "vat_rate";
+
+ALTER TABLE "purchased_materials"
+    ALTER COLUMN "price_without_vat" DROP NOT NULL,
+    ALTER COLUMN "price_without_vat" DROP DEFAULT,
+    ALTER COLUMN "total_without_vat" DROP NOT NULL,
+    ALTER COLUMN "total_without_vat" DROP DEFAULT,
+    DROP COLUMN IF EXISTS "currency",
+    DROP COLUMN IF EXISTS "vat_rate";
+
+ALTER TABLE "planning_materials"
+    ALTER COLUMN "price_without_vat" DROP NOT NULL,
+    ALTER COLUMN "price_without_vat" DROP DEFAULT,
+    ALTER COLUMN "total_without_vat" DROP NOT NULL,
+    ALTER COLUMN "total_without_vat" DROP DEFAULT,
+    DROP COLUMN IF EXISTS "currency",
+    DROP COLUMN IF EXISTS "vat_rate";
+
+ALTER TABLE "companies" DROP COLUMN IF EXISTS "default_currency";
@@ -0,0 +1,75 @@
+-- Валюта компании по умолчанию
+ALTER TABLE "companies"
+    ADD COLUMN "default_currency" VARCHAR(3) NOT NULL DEFAULT 'KZT';
+
+-- Цены материалов хранятся точными десятичными значениями со ставкой НДС и валютой. Цена и стоимость с НДС
+-- рассчитываются приложением
+ALTER TABLE "planning_materials"
+    ADD COLUMN "vat_rate" DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK ("vat_rate" >= 0 AND "vat_rate" <= 100),
+    ADD COLUMN "currency" VARCHAR(3)    NOT NULL DEFAULT 'KZT';
+
+ALTER TABLE "purchased_materials"
+    ADD COLUMN "vat_rate" DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK ("vat_rate" >= 0 AND "vat_rate" <= 100),
+    ADD COLUMN "currency" VARCHAR(3)    NOT NULL DEFAULT 'KZT';
+
+ALTER TABLE "planning_materials_archive"
+    ADD COLUMN "vat_rate" DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK ("vat_rate" >= 0 AND "vat_rate" <= 100),
+    ADD COLUMN "currency" VARCHAR(3)    NOT NULL DEFAULT 'KZT';
+
+ALTER TABLE "purchased_materials_archive"
+    ADD COLUMN "vat_rate" DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK ("vat_rate" >= 0 AND "vat_rate" <= 100),
+    ADD COLUMN "currency" VARCHAR(3)    NOT NULL DEFAULT 'KZT';
+
+UPDATE planning_materials SET price_without_vat = 0 WHERE price_without_vat IS NULL;
+UPDATE planning_materials SET total_without_vat = 0 WHERE total_without_vat IS NULL;
+UPDATE purchased_materials SET price_without_vat = 0 WHERE price_without_vat IS NULL;
+UPDATE purchased_materials SET total_without_vat = 0 WHERE total_without_vat IS NULL;
+UPDATE planning_materials_archive SET price_without_vat = 0 WHERE price_without_vat IS NULL;
+UPDATE planning_materials_archive SET total_without_vat = 0 WHERE total_without_vat IS NULL;
+UPDATE purchased_materials_archive SET price_without_vat = 0 WHERE price_without_vat IS NULL;
+UPDATE purchased_materials_archive SET total_without_vat = 0 WHERE total_without_vat IS NULL;
+
+ALTER TABLE "planning_materials"
+    ALTER COLUMN "price_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "price_without_vat" SET NOT NULL,
+    ALTER COLUMN "total_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "total_without_vat" SET NOT NULL;
+
+ALTER TABLE "purchased_materials"
+    ALTER COLUMN "price_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "price_without_vat" SET NOT NULL,
+    ALTER COLUMN "total_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "total_without_vat" SET NOT NULL;
+
+ALTER TABLE "planning_materials_archive"
+    ALTER COLUMN "price_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "price_without_vat" SET NOT NULL,
+    ALTER COLUMN "total_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "total_without_vat" SET NOT NULL;
+
+ALTER TABLE "purchased_materials_archive"
+    ALTER COLUMN "price_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "price_without_vat" SET NOT NULL,
+    ALTER COLUMN "total_without_vat" SET DEFAULT 0,
+    ALTER COLUMN "total_without_vat" SET NOT NULL;
+
+-- Валюта заказа поставщику совпадает с валютой цен его материалов
+ALTER TABLE "purchase_orders"
+    ADD COLUMN "currency" VARCHAR(3) NOT NULL DEFAULT 'KZT';
+
+CREATE SEQUENCE exchange_rates_id_seq;
+
+-- Курсы валют компании: 1 единица from_currency стоит rate единиц to_currency на дату rate_date
+CREATE TABLE "exchange_rates"
+(
+    "id"            INT PRIMARY KEY DEFAULT nextval('exchange_rates_id_seq'),
+    "company_id"    INT            NOT NULL,
+    "from_currency" VARCHAR(3)     NOT NULL,
+    "to_currency"   VARCHAR(3)     NOT NULL CHECK ("to_currency" <> "from_currency"),
+    "rate"          DECIMAL(18, 6) NOT NULL CHECK ("rate" > 0),
+    "rate_date"     DATE           NOT NULL,
+    "created_by"    INT,
+    "created_at"    TIMESTAMP               DEFAULT (CURRENT_TIMESTAMP),
+    "updated_at"    TIMESTAMP               DEFAULT (CURRENT_TIMESTAMP),
+    UNIQUE ("company_id", "from_currency", "to_currency", "rate_date")
+);